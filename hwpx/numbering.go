@@ -0,0 +1,101 @@
+package hwpx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NumberFormats lists the number format names in the order of their HWP codes
+var NumberFormats = []string{
+	"DIGIT",
+	"CIRCLED_DIGIT",
+	"ROMAN_CAPITAL",
+	"ROMAN_SMALL",
+	"LATIN_CAPITAL",
+	"LATIN_SMALL",
+	"CIRCLED_LATIN_CAPITAL",
+	"CIRCLED_LATIN_SMALL",
+	"HANGUL_SYLLABLE",
+	"CIRCLED_HANGUL_SYLLABLE",
+	"HANGUL_JAMO",
+	"CIRCLED_HANGUL_JAMO",
+	"HANGUL_PHONETIC",
+	"IDEOGRAPH",
+	"CIRCLED_IDEOGRAPH",
+}
+
+var (
+	hangulSyllables = []rune("가나다라마바사아자차카타파하")
+	hangulJamo      = []rune("ㄱㄴㄷㄹㅁㅂㅅㅇㅈㅊㅋㅌㅍㅎ")
+	ideographs      = []rune("一二三四五六七八九十")
+	hangulPhonetic  = []string{"일", "이", "삼", "사", "오", "육", "칠", "팔", "구", "십"}
+)
+
+// FormatNumber formats n using a number format name such as DIGIT or ROMAN_SMALL.
+// Unknown formats and numbers outside a format's range fall back to digits.
+func FormatNumber(format string, n int) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+
+	switch format {
+	case "CIRCLED_DIGIT":
+		if n <= 20 {
+			return string(rune('①' + n - 1))
+		}
+	case "ROMAN_CAPITAL":
+		return roman(n)
+	case "ROMAN_SMALL":
+		return strings.ToLower(roman(n))
+	case "LATIN_CAPITAL":
+		return cycle([]rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ"), n)
+	case "LATIN_SMALL":
+		return cycle([]rune("abcdefghijklmnopqrstuvwxyz"), n)
+	case "CIRCLED_LATIN_CAPITAL":
+		return string(rune('Ⓐ' + (n-1)%26))
+	case "CIRCLED_LATIN_SMALL":
+		return string(rune('ⓐ' + (n-1)%26))
+	case "HANGUL_SYLLABLE":
+		return cycle(hangulSyllables, n)
+	case "CIRCLED_HANGUL_SYLLABLE":
+		return string(rune('㉮' + (n-1)%14))
+	case "HANGUL_JAMO":
+		return cycle(hangulJamo, n)
+	case "CIRCLED_HANGUL_JAMO":
+		return string(rune('㉠' + (n-1)%14))
+	case "HANGUL_PHONETIC":
+		if n <= len(hangulPhonetic) {
+			return hangulPhonetic[n-1]
+		}
+	case "IDEOGRAPH":
+		if n <= len(ideographs) {
+			return string(ideographs[n-1])
+		}
+	case "CIRCLED_IDEOGRAPH":
+		if n <= 10 {
+			return string(rune('㊀' + n - 1))
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+// cycle returns the symbol for n, repeating symbols once the list runs out
+func cycle(symbols []rune, n int) string {
+	symbol := string(symbols[(n-1)%len(symbols)])
+	return strings.Repeat(symbol, (n-1)/len(symbols)+1)
+}
+
+// roman returns n as an upper-case roman numeral
+func roman(n int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+
+	var b strings.Builder
+	for i, value := range values {
+		for n >= value {
+			b.WriteString(symbols[i])
+			n -= value
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package hwpx
+
+import "testing"
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		format string
+		n      int
+		want   string
+	}{
+		{format: "DIGIT", n: 12, want: "12"},
+		{format: "CIRCLED_DIGIT", n: 3, want: "③"},
+		{format: "CIRCLED_DIGIT", n: 21, want: "21"},
+		{format: "ROMAN_CAPITAL", n: 1994, want: "MCMXCIV"},
+		{format: "ROMAN_SMALL", n: 14, want: "xiv"},
+		{format: "LATIN_CAPITAL", n: 1, want: "A"},
+		{format: "LATIN_SMALL", n: 28, want: "bb"},
+		{format: "CIRCLED_LATIN_SMALL", n: 27, want: "ⓐ"},
+		{format: "HANGUL_SYLLABLE", n: 3, want: "다"},
+		{format: "HANGUL_SYLLABLE", n: 15, want: "가가"},
+		{format: "CIRCLED_HANGUL_SYLLABLE", n: 2, want: "㉯"},
+		{format: "HANGUL_JAMO", n: 14, want: "ㅎ"},
+		{format: "CIRCLED_HANGUL_JAMO", n: 1, want: "㉠"},
+		{format: "HANGUL_PHONETIC", n: 10, want: "십"},
+		{format: "HANGUL_PHONETIC", n: 11, want: "11"},
+		{format: "IDEOGRAPH", n: 4, want: "四"},
+		{format: "CIRCLED_IDEOGRAPH", n: 10, want: "㊉"},
+		{format: "UNKNOWN", n: 5, want: "5"},
+		{format: "ROMAN_CAPITAL", n: 0, want: "0"},
+	}
+	for _, tt := range tests {
+		if got := FormatNumber(tt.format, tt.n); got != tt.want {
+			t.Errorf("FormatNumber(%s, %d) = %q, want %q", tt.format, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestNoteLabel(t *testing.T) {
+	tests := []struct {
+		def   FootnotesDefType
+		index int
+		want  string
+	}{
+		{def: FootnotesDefType{AutoNumFormat: "DIGIT"}, index: 1, want: "1"},
+		{def: FootnotesDefType{AutoNumFormat: "DIGIT", AutoNumStart: 5}, index: 2, want: "6"},
+		{def: FootnotesDefType{AutoNumFormat: "ROMAN_SMALL", PrefixChar: "(", SuffixChar: ")"}, index: 3, want: "(iii)"},
+	}
+	for _, tt := range tests {
+		if got := tt.def.Label(tt.index); got != tt.want {
+			t.Errorf("%+v.Label(%d) = %q, want %q", tt.def, tt.index, got, tt.want)
+		}
+	}
+	if got := (EndnotesDefType{AutoNumFormat: "LATIN_SMALL"}).Label(2); got != "b" {
+		t.Errorf("EndnotesDefType.Label(2) = %q, want %q", got, "b")
+	}
+}
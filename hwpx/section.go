@@ -32,12 +32,34 @@ type FootnotesDefType struct {
 	AutoNumFormat   string `xml:"auto-num-format,attr"`
 	AutoNumStart    int    `xml:"auto-num-start,attr"`
 	PlaceEndOfPage  bool   `xml:"place-end-of-page,attr"`
+	PrefixChar      string `xml:"prefix-char,attr,omitempty"`
+	SuffixChar      string `xml:"suffix-char,attr,omitempty"`
+}
+
+// Label returns the label of the footnote at the given position, starting from 1
+func (d FootnotesDefType) Label(index int) string {
+	return noteLabel(d.AutoNumFormat, d.AutoNumStart, d.PrefixChar, d.SuffixChar, index)
 }
 
 // EndnotesDefType represents endnotes definition
 type EndnotesDefType struct {
 	AutoNumFormat  string `xml:"auto-num-format,attr"`
 	AutoNumStart   int    `xml:"auto-num-start,attr"`
+	PrefixChar     string `xml:"prefix-char,attr,omitempty"`
+	SuffixChar     string `xml:"suffix-char,attr,omitempty"`
+}
+
+// Label returns the label of the endnote at the given position, starting from 1
+func (d EndnotesDefType) Label(index int) string {
+	return noteLabel(d.AutoNumFormat, d.AutoNumStart, d.PrefixChar, d.SuffixChar, index)
+}
+
+// noteLabel formats a note number, counting from start when it is set
+func noteLabel(format string, start int, prefix, suffix string, index int) string {
+	if start < 1 {
+		start = 1
+	}
+	return prefix + FormatNumber(format, start+index-1) + suffix
 }
 
 // AutoNumDefType represents auto numbering definition
@@ -0,0 +1,213 @@
+package interfaces
+
+import "strings"
+
+// BlockKind identifies the type of a content block
+type BlockKind string
+
+// Block kinds
+const (
+	BlockParagraph BlockKind = "paragraph"
+	BlockHeading   BlockKind = "heading"
+	BlockListItem  BlockKind = "list-item"
+	BlockTable     BlockKind = "table"
+	BlockImage     BlockKind = "image"
+	BlockPageBreak BlockKind = "page-break"
+)
+
+// Block represents a structural unit of document content
+type Block struct {
+	Kind BlockKind
+	// Level is the heading level or the list nesting level, starting from 1
+	Level int
+	// Ordered marks list items of numbered lists
+	Ordered bool
+	// Section marks page breaks that start a new document section
+	Section bool
+	// Align is the horizontal alignment: LEFT, CENTER, RIGHT, JUSTIFY or DISTRIBUTE
+	Align string
+	// ParaShape is the ID of the paragraph shape the block was read with
+	ParaShape string
+	// Style is the name of the paragraph style
+	Style string
+	Runs  []Run
+	Table *Table
+	Image *Image
+}
+
+// Run represents a span of text sharing the same formatting
+type Run struct {
+	Text      string
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Strike    bool
+	// Size is the font size in points, 0 when unknown
+	Size float64
+	// Color and Highlight are #RRGGBB colors, empty when unset
+	Color     string
+	Highlight string
+	Font      string
+	// CharShape is the ID of the character shape the run was read with
+	CharShape string
+}
+
+// Table represents a table as rows of cells. A merged cell appears once, in
+// the row and column where it starts, with its spans.
+type Table struct {
+	Rows [][]TableCell
+}
+
+// TableCell represents a table cell holding its own blocks
+type TableCell struct {
+	Row     int
+	Col     int
+	RowSpan int
+	ColSpan int
+	Blocks  []Block
+}
+
+// Image represents an embedded picture
+type Image struct {
+	// Name is the file name of the picture, e.g. image1.png
+	Name string
+	Data []byte
+	// Orientation is the EXIF orientation of the data, 0 or 1 when upright.
+	// Writers that embed the data unchanged apply it when placing the image.
+	Orientation int
+}
+
+// Text returns the plain text of the block's runs
+func (b Block) Text() string {
+	var text strings.Builder
+	for _, run := range b.Runs {
+		text.WriteString(run.Text)
+	}
+	return text.String()
+}
+
+// SameFormat reports whether two runs share the same formatting
+func (r Run) SameFormat(other Run) bool {
+	other.Text = r.Text
+	return r == other
+}
+
+// HasMergedCells reports whether any cell of the table spans several rows or columns
+func (t *Table) HasMergedCells() bool {
+	for _, row := range t.Rows {
+		for _, cell := range row {
+			if cell.RowSpan > 1 || cell.ColSpan > 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Columns returns the number of grid columns of the table
+func (t *Table) Columns() int {
+	columns := 0
+	for _, row := range t.Rows {
+		for _, cell := range row {
+			if end := cell.Col + max(cell.ColSpan, 1); end > columns {
+				columns = end
+			}
+		}
+	}
+	return columns
+}
+
+// BlockLines returns the plain text lines of blocks, one per non-empty
+// paragraph. Table cells contribute their paragraphs in reading order.
+func BlockLines(blocks []Block) []string {
+	var lines []string
+	for _, block := range blocks {
+		switch block.Kind {
+		case BlockTable:
+			if block.Table == nil {
+				continue
+			}
+			for _, row := range block.Table.Rows {
+				for _, cell := range row {
+					lines = append(lines, BlockLines(cell.Blocks)...)
+				}
+			}
+		case BlockImage, BlockPageBreak:
+		default:
+			if text := strings.TrimSpace(block.Text()); text != "" {
+				lines = append(lines, text)
+			}
+		}
+	}
+	return lines
+}
+
+// Tables returns the tables of blocks in reading order, each followed by
+// the tables nested in its cells
+func Tables(blocks []Block) []*Table {
+	var tables []*Table
+	for _, block := range blocks {
+		if block.Kind != BlockTable || block.Table == nil {
+			continue
+		}
+		tables = append(tables, block.Table)
+		for _, row := range block.Table.Rows {
+			for _, cell := range row {
+				tables = append(tables, Tables(cell.Blocks)...)
+			}
+		}
+	}
+	return tables
+}
+
+// Matrix returns the text of the table as rows of grid columns. Positions
+// covered by a merged cell hold its text when repeatMerged is set and are
+// empty otherwise.
+func (t *Table) Matrix(repeatMerged bool) [][]string {
+	columns := t.Columns()
+	rows := len(t.Rows)
+	for _, row := range t.Rows {
+		for _, cell := range row {
+			rows = max(rows, cell.Row+max(cell.RowSpan, 1))
+		}
+	}
+
+	matrix := make([][]string, rows)
+	for r := range matrix {
+		matrix[r] = make([]string, columns)
+	}
+	for _, row := range t.Rows {
+		for _, cell := range row {
+			text := strings.Join(BlockLines(cell.Blocks), "\n")
+			for r := cell.Row; r < min(cell.Row+max(cell.RowSpan, 1), rows); r++ {
+				for c := cell.Col; c < min(cell.Col+max(cell.ColSpan, 1), columns); c++ {
+					if (r == cell.Row && c == cell.Col) || repeatMerged {
+						matrix[r][c] = text
+					}
+				}
+			}
+		}
+	}
+	return matrix
+}
+
+// TextBlocks returns a paragraph block for each line of plain text
+func TextBlocks(text string) []Block {
+	var blocks []Block
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		blocks = append(blocks, Block{Kind: BlockParagraph, Runs: []Run{{Text: line}}})
+	}
+	return blocks
+}
+
+// ContentBlocks returns the blocks of the content, falling back to
+// paragraphs built from the text when the reader produced no structure
+func (c *PDFContent) ContentBlocks() []Block {
+	if len(c.Blocks) > 0 {
+		return c.Blocks
+	}
+	return TextBlocks(c.Text)
+}
@@ -0,0 +1,54 @@
+package interfaces
+
+import (
+	"reflect"
+	"testing"
+)
+
+// textCell returns a cell holding one paragraph
+func textCell(row, col, rowSpan, colSpan int, text string) TableCell {
+	return TableCell{Row: row, Col: col, RowSpan: rowSpan, ColSpan: colSpan,
+		Blocks: []Block{{Kind: BlockParagraph, Runs: []Run{{Text: text}}}}}
+}
+
+func TestTableMatrix(t *testing.T) {
+	// A header spanning two columns over a cell spanning two rows
+	table := &Table{Rows: [][]TableCell{
+		{textCell(0, 0, 1, 2, "head"), textCell(0, 2, 1, 1, "c")},
+		{textCell(1, 0, 2, 1, "tall"), textCell(1, 1, 1, 1, "x"), textCell(1, 2, 1, 1, "y")},
+		{textCell(2, 1, 1, 1, "z")},
+	}}
+
+	tests := []struct {
+		repeatMerged bool
+		want         [][]string
+	}{
+		{repeatMerged: false, want: [][]string{{"head", "", "c"}, {"tall", "x", "y"}, {"", "z", ""}}},
+		{repeatMerged: true, want: [][]string{{"head", "head", "c"}, {"tall", "x", "y"}, {"tall", "z", ""}}},
+	}
+	for _, tt := range tests {
+		if got := table.Matrix(tt.repeatMerged); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Matrix(%v) = %q, want %q", tt.repeatMerged, got, tt.want)
+		}
+	}
+	if !table.HasMergedCells() || table.Columns() != 3 {
+		t.Errorf("HasMergedCells() = %v, Columns() = %d, want true and 3", table.HasMergedCells(), table.Columns())
+	}
+}
+
+func TestTables(t *testing.T) {
+	inner := &Table{Rows: [][]TableCell{{textCell(0, 0, 1, 1, "inner")}}}
+	outer := &Table{Rows: [][]TableCell{{{Blocks: []Block{{Kind: BlockTable, Table: inner}}}}}}
+	other := &Table{Rows: [][]TableCell{{textCell(0, 0, 1, 1, "other")}}}
+	blocks := []Block{
+		{Kind: BlockTable, Table: outer},
+		{Kind: BlockParagraph, Runs: []Run{{Text: "between"}}},
+		{Kind: BlockTable, Table: other},
+	}
+	if got, want := Tables(blocks), []*Table{outer, inner, other}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tables() returned %d tables in the wrong order", len(got))
+	}
+	if got, want := BlockLines(blocks), []string{"inner", "between", "other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BlockLines() = %q, want %q", got, want)
+	}
+}
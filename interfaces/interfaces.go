@@ -1,6 +1,11 @@
 package interfaces
 
-import "io"
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 // FileReader is the interface that wraps the basic Read method.
 type FileReader interface {
@@ -12,6 +17,26 @@ type PDFContent struct {
 	Text     string
 	Pages    []PDFPage
 	Metadata map[string]string
+	Headers  []HeaderFooter
+	Footers  []HeaderFooter
+	Notes    []Note
+	Blocks   []Block
+	// PageSetup is the page size and margins of the source document, nil when unknown
+	PageSetup *PageSetup
+}
+
+// PageSetup describes the paper size and margins of a document in points
+type PageSetup struct {
+	Width        float64
+	Height       float64
+	MarginLeft   float64
+	MarginRight  float64
+	MarginTop    float64
+	MarginBottom float64
+	// MarginHeader and MarginFooter are the heights of the header and footer
+	// areas, which lie between the top and bottom margins and the body
+	MarginHeader float64
+	MarginFooter float64
 }
 
 // PDFPage represents a single page in a PDF document
@@ -21,6 +46,99 @@ type PDFPage struct {
 	Images [][]byte
 }
 
+// Field placeholders that may appear in header and footer text
+const (
+	PageNumberField = "{{PAGE}}"
+	TotalPagesField = "{{TOTAL_PAGES}}"
+)
+
+// HeaderFooter represents a header or footer repeated on document pages
+type HeaderFooter struct {
+	// Apply is the page type the header or footer applies to: BOTH, ODD or EVEN
+	Apply string
+	// Align is the horizontal alignment: LEFT, CENTER or RIGHT
+	Align string
+	Text  string
+	// PageNumber marks items generated from page number position controls
+	PageNumber bool
+}
+
+// AppliesTo reports whether the header or footer applies to the given page
+func (h HeaderFooter) AppliesTo(page int) bool {
+	switch strings.ToUpper(h.Apply) {
+	case "ODD":
+		return page%2 == 1
+	case "EVEN":
+		return page%2 == 0
+	default:
+		return true
+	}
+}
+
+// Resolve returns the text with page number fields replaced
+func (h HeaderFooter) Resolve(page, total int) string {
+	text := strings.ReplaceAll(h.Text, PageNumberField, strconv.Itoa(page))
+	return strings.ReplaceAll(text, TotalPagesField, strconv.Itoa(total))
+}
+
+// SelectHeaderFooters returns the items to draw on the given page.
+// Headers and footers for odd or even pages take precedence over those for
+// both, while page numbers are always drawn.
+func SelectHeaderFooters(items []HeaderFooter, page int) []HeaderFooter {
+	var both, specific, pageNumbers []HeaderFooter
+	for _, item := range items {
+		if !item.AppliesTo(page) {
+			continue
+		}
+		switch {
+		case item.PageNumber:
+			pageNumbers = append(pageNumbers, item)
+		case strings.ToUpper(item.Apply) == "ODD" || strings.ToUpper(item.Apply) == "EVEN":
+			specific = append(specific, item)
+		default:
+			both = append(both, item)
+		}
+	}
+	if len(specific) > 0 {
+		both = specific
+	}
+	return append(both, pageNumbers...)
+}
+
+// Note kinds
+const (
+	Footnote = "footnote"
+	Endnote  = "endnote"
+)
+
+// Note represents a footnote or endnote referenced from the text with NoteRef
+type Note struct {
+	Kind string
+	// ID is the unique key used in references, e.g. 1 for footnotes or e1 for endnotes
+	ID string
+	// Label is the number shown for the note, formatted per the document's numbering
+	Label string
+	Text  string
+}
+
+// NoteRef returns the reference to a note embedded in content text
+func NoteRef(id string) string {
+	return "[^" + id + "]"
+}
+
+// NoteRefPattern matches note references embedded in content text
+var NoteRefPattern = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+
+// FindNote returns the note with the given ID
+func (c *PDFContent) FindNote(id string) *Note {
+	for i := range c.Notes {
+		if c.Notes[i].ID == id {
+			return &c.Notes[i]
+		}
+	}
+	return nil
+}
+
 // PDFReader is the interface that wraps the basic PDF reading methods
 type PDFReader interface {
 	ReadPDF() (*PDFContent, error)
@@ -0,0 +1,42 @@
+package interfaces
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectHeaderFooters(t *testing.T) {
+	both := HeaderFooter{Apply: "BOTH", Text: "both"}
+	odd := HeaderFooter{Apply: "ODD", Text: "odd"}
+	even := HeaderFooter{Apply: "even", Text: "even"}
+	number := HeaderFooter{Apply: "BOTH", Text: PageNumberField, PageNumber: true}
+
+	tests := []struct {
+		name  string
+		items []HeaderFooter
+		page  int
+		want  []string
+	}{
+		{name: "both only", items: []HeaderFooter{both, number}, page: 2, want: []string{"both", PageNumberField}},
+		{name: "odd page", items: []HeaderFooter{both, odd, even, number}, page: 3, want: []string{"odd", PageNumberField}},
+		{name: "even page", items: []HeaderFooter{both, odd, even}, page: 4, want: []string{"even"}},
+		{name: "no item for the page", items: []HeaderFooter{both, odd}, page: 2, want: []string{"both"}},
+		{name: "none", items: nil, page: 1, want: nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, item := range SelectHeaderFooters(tt.items, tt.page) {
+			got = append(got, item.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SelectHeaderFooters() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHeaderFooterResolve(t *testing.T) {
+	item := HeaderFooter{Text: "- " + PageNumberField + " / " + TotalPagesField + " -"}
+	if got, want := item.Resolve(3, 12), "- 3 / 12 -"; got != want {
+		t.Errorf("Resolve() = %q, want %q", got, want)
+	}
+}
@@ -2,9 +2,13 @@ package main
 
 import (
 	"archive/zip"
+	"encoding/json"
 	"fmt"
+	"image"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"myconverter/interfaces"
@@ -18,7 +22,31 @@ func GetFileReader(filePath string) interfaces.FileReader {
 	switch ext {
 	case ".hwp":
 		return &readers.CFBReader{}
-	case ".hwpx", ".zip":
+	case ".docx":
+		return readers.NewDOCXReader(filePath)
+	case ".odt":
+		return readers.NewODTReader(filePath)
+	case ".rtf":
+		return readers.NewRTFReader(filePath)
+	case ".txt":
+		return readers.NewTextReader(filePath)
+	case ".md", ".markdown":
+		return readers.NewMarkdownReader(filePath)
+	case ".html", ".htm":
+		return readers.NewHTMLReader(filePath)
+	case ".png", ".jpg", ".jpeg", ".tif", ".tiff":
+		return readers.NewImageReader(filePath)
+	case ".zip":
+		// Office documents are recognized by their content types or mimetype
+		format, err := readers.DetectZipFormat(filePath)
+		if err == nil && format == "docx" {
+			return readers.NewDOCXReader(filePath)
+		}
+		if err == nil && format == "odt" {
+			return readers.NewODTReader(filePath)
+		}
+		return &readers.ZipReader{}
+	case ".hwpx":
 		return &readers.ZipReader{}
 	case ".pdf":
 		return readers.NewPDFReader(filePath)
@@ -36,6 +64,28 @@ func processReader(reader interfaces.FileReader, filePath string) error {
 
 	// Type assert to access specific reader methods
 	switch r := reader.(type) {
+	case *readers.DOCXReader:
+		printDocument(r.GetContent())
+
+	case *readers.ODTReader:
+		printDocument(r.GetContent())
+
+	case *readers.RTFReader:
+		printDocument(r.GetContent())
+
+	case *readers.TextReader:
+		printDocument(r.GetContent())
+
+	case *readers.MarkdownReader:
+		printDocument(r.GetContent())
+
+	case *readers.HTMLReader:
+		printDocument(r.GetContent())
+
+	case *readers.ImageReader:
+		printDocument(r.GetContent())
+		fmt.Printf("Pages: %d\n", len(r.GetContent().Pages))
+
 	case *readers.CFBReader:
 		// Display CFB entries
 		for _, entry := range r.GetEntries() {
@@ -65,17 +115,433 @@ func processReader(reader interfaces.FileReader, filePath string) error {
 	return nil
 }
 
+// printDocument displays document metadata and text
+func printDocument(content *interfaces.PDFContent) {
+	keys := make([]string, 0, len(content.Metadata))
+	for key := range content.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("%s: %s\n", key, content.Metadata[key])
+	}
+	fmt.Println(content.Text)
+}
+
+// readContent reads the content of an input file with the given reader
+func readContent(reader interfaces.FileReader, inputFile string) (*interfaces.PDFContent, error) {
+	switch r := reader.(type) {
+	case *readers.PDFReader:
+		// Read PDF content
+		content, err := r.ReadPDF()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PDF content: %v", err)
+		}
+		return content, nil
+
+	case *readers.DOCXReader:
+		// Read DOCX document content
+		if err := r.Read(inputFile); err != nil {
+			return nil, fmt.Errorf("failed to read DOCX content: %v", err)
+		}
+		return r.GetContent(), nil
+
+	case *readers.ODTReader:
+		// Read ODT document content
+		if err := r.Read(inputFile); err != nil {
+			return nil, fmt.Errorf("failed to read ODT content: %v", err)
+		}
+		return r.GetContent(), nil
+
+	case *readers.RTFReader:
+		// Read RTF document content
+		if err := r.Read(inputFile); err != nil {
+			return nil, fmt.Errorf("failed to read RTF content: %v", err)
+		}
+		return r.GetContent(), nil
+
+	case *readers.TextReader:
+		// Read plain text content
+		if err := r.Read(inputFile); err != nil {
+			return nil, fmt.Errorf("failed to read text content: %v", err)
+		}
+		return r.GetContent(), nil
+
+	case *readers.MarkdownReader:
+		// Read Markdown document content
+		if err := r.Read(inputFile); err != nil {
+			return nil, fmt.Errorf("failed to read Markdown content: %v", err)
+		}
+		return r.GetContent(), nil
+
+	case *readers.HTMLReader:
+		// Read HTML page content
+		if err := r.Read(inputFile); err != nil {
+			return nil, fmt.Errorf("failed to read HTML content: %v", err)
+		}
+		return r.GetContent(), nil
+
+	case *readers.ImageReader:
+		// Read image pages
+		if err := r.Read(inputFile); err != nil {
+			return nil, fmt.Errorf("failed to read image content: %v", err)
+		}
+		return r.GetContent(), nil
+
+	case *readers.CFBReader:
+		// Read HWP body text
+		if err := r.Read(inputFile); err != nil {
+			return nil, err
+		}
+		hwpContent, err := readers.ExtractHWPContent(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract HWP content: %v", err)
+		}
+		text := strings.Join(hwpContent.Text, "\n")
+		return &interfaces.PDFContent{
+			Text:    text,
+			Pages:   []interfaces.PDFPage{{Number: 1, Text: text}},
+			Blocks:  hwpContent.Blocks,
+			Headers: hwpContent.Headers,
+			Footers: hwpContent.Footers,
+			Notes:   hwpContent.Notes,
+
+			PageSetup: hwpContent.PageSetup,
+		}, nil
+
+	case *readers.ZipReader:
+		// For HWPX files
+		if !strings.HasSuffix(strings.ToLower(inputFile), ".hwpx") {
+			break
+		}
+
+		// Open HWPX file
+		zipReader, err := zip.OpenReader(inputFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open HWPX file: %v", err)
+		}
+		defer zipReader.Close()
+
+		// Extract text content from HWPX
+		hwpxContent, err := readers.ExtractHWPXContent(zipReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract HWPX content: %v", err)
+		}
+		// Convert HWPX content to PDFContent format
+		text := strings.Join(hwpxContent.Text, "\n")
+		return &interfaces.PDFContent{
+			Text:    text,
+			Pages:   []interfaces.PDFPage{{Number: 1, Text: text}},
+			Blocks:  hwpxContent.Blocks,
+			Headers: hwpxContent.Headers,
+			Footers: hwpxContent.Footers,
+			Notes:   hwpxContent.Notes,
+
+			PageSetup: hwpxContent.PageSetup,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported input file type")
+}
+
+// mergeSections returns the sections of a merged PDF, one per input file
+// titled with its name. PDF files are used as they are; other inputs are
+// read and written as PDF files in tempDir, sizing image pages at dpi, of
+// the PDF/A level pdfa unless it is empty.
+func mergeSections(inputs []string, tempDir string, dpi float64, pdfa string) ([]writers.MergeSection, error) {
+	sections := make([]writers.MergeSection, 0, len(inputs))
+	for i, input := range inputs {
+		section := writers.MergeSection{Path: input, Title: filepath.Base(input)}
+		if strings.ToLower(filepath.Ext(input)) != ".pdf" {
+			reader := GetFileReader(input)
+			if reader == nil {
+				return nil, fmt.Errorf("unsupported input file format: %s", input)
+			}
+			content, err := readContent(reader, input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", input, err)
+			}
+			pdfWriter := writers.NewPDFWriter(tempDir)
+			pdfWriter.ImageDPI = dpi
+			pdfWriter.PDFA = pdfa
+			section.Path = filepath.Join(tempDir, fmt.Sprintf("section-%d.pdf", i+1))
+			if err := pdfWriter.WriteContent(section.Path, content); err != nil {
+				return nil, fmt.Errorf("failed to convert %s: %v", input, err)
+			}
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// firstPageImage returns an image of the first page of an input file. HWP
+// and HWPX files use the preview they keep when present, PDF pages are
+// rendered and other documents are typeset like image output.
+func firstPageImage(inputFile string) (image.Image, error) {
+	reader := GetFileReader(inputFile)
+	if reader == nil {
+		return nil, fmt.Errorf("unsupported input file format")
+	}
+
+	switch r := reader.(type) {
+	case *readers.PDFReader:
+		return r.RenderPage(1, readers.DefaultRenderDPI)
+
+	case *readers.ImageReader:
+		if _, err := r.ReadImage(); err != nil {
+			return nil, err
+		}
+		return r.DecodePage(1)
+
+	case *readers.CFBReader:
+		if err := r.Read(inputFile); err != nil {
+			return nil, err
+		}
+		if preview, err := readers.HWPPreviewImage(r); err != nil || preview != nil {
+			return preview, err
+		}
+
+	case *readers.ZipReader:
+		if strings.HasSuffix(strings.ToLower(inputFile), ".hwpx") {
+			zipReader, err := zip.OpenReader(inputFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open HWPX file: %v", err)
+			}
+			preview, err := readers.HWPXPreviewImage(zipReader)
+			zipReader.Close()
+			if err != nil || preview != nil {
+				return preview, err
+			}
+		}
+	}
+
+	// Typeset the document text when there is no preview
+	content, err := readContent(GetFileReader(inputFile), inputFile)
+	if err != nil {
+		return nil, err
+	}
+	imageWriter, err := writers.NewImageWriter("")
+	if err != nil {
+		return nil, err
+	}
+	pages, err := imageWriter.RenderContent(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("document has no pages")
+	}
+	return pages[0], nil
+}
+
+// dpiFlag returns the value of the --dpi flag in args, 0 when absent
+func dpiFlag(args []string) (float64, error) {
+	value := flagValue(args, "--dpi")
+	if value == "" {
+		return 0, nil
+	}
+	dpi, err := strconv.ParseFloat(value, 64)
+	if err != nil || dpi <= 0 {
+		return 0, fmt.Errorf("invalid DPI: %s", value)
+	}
+	return dpi, nil
+}
+
+// imageFlags applies the --quality, --color, --hinting, --no-subpixel and
+// --background flags in args to an image writer
+func imageFlags(w *writers.ImageWriter, args []string) error {
+	if value := flagValue(args, "--quality"); value != "" {
+		quality, err := strconv.Atoi(value)
+		if err != nil || quality < 1 || quality > 100 {
+			return fmt.Errorf("invalid JPEG quality: %s", value)
+		}
+		w.Quality = quality
+	}
+	w.ColorMode = flagValue(args, "--color")
+	if value := flagValue(args, "--hinting"); value != "" {
+		if value != writers.HintingNone && value != writers.HintingFull {
+			return fmt.Errorf("invalid hinting: %s", value)
+		}
+		w.Hinting = value
+	}
+	if hasFlag(args, "--no-subpixel") {
+		w.SubPixel = false
+	}
+	if value := flagValue(args, "--background"); value != "" {
+		background, err := writers.ParseColor(value)
+		if err != nil {
+			return err
+		}
+		w.Background = background
+	}
+	return nil
+}
+
+// watermarkFlags returns the watermark set by the --watermark-text,
+// --watermark-image, --watermark-opacity, --watermark-rotation,
+// --watermark-position, --watermark-size and --watermark-color flags in
+// args, nil when neither text nor image is given
+func watermarkFlags(args []string) (*writers.Watermark, error) {
+	watermark := writers.NewWatermark(flagValue(args, "--watermark-text"))
+	if path := flagValue(args, "--watermark-image"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read watermark image: %v", err)
+		}
+		watermark.Image = data
+	}
+	if watermark.Text == "" && len(watermark.Image) == 0 {
+		return nil, nil
+	}
+
+	if value := flagValue(args, "--watermark-opacity"); value != "" {
+		opacity, err := strconv.ParseFloat(value, 64)
+		if err != nil || opacity <= 0 || opacity > 1 {
+			return nil, fmt.Errorf("invalid watermark opacity: %s", value)
+		}
+		watermark.Opacity = opacity
+	}
+	if value := flagValue(args, "--watermark-rotation"); value != "" {
+		rotation, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid watermark rotation: %s", value)
+		}
+		watermark.Rotation = rotation
+	}
+	if value := flagValue(args, "--watermark-position"); value != "" {
+		if !writers.IsWatermarkPosition(value) {
+			return nil, fmt.Errorf("invalid watermark position: %s", value)
+		}
+		watermark.Position = value
+	}
+	if value := flagValue(args, "--watermark-size"); value != "" {
+		size, err := strconv.ParseFloat(value, 64)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid watermark size: %s", value)
+		}
+		watermark.Size = size
+	}
+	if value := flagValue(args, "--watermark-color"); value != "" {
+		c, err := writers.ParseColor(value)
+		if err != nil {
+			return nil, err
+		}
+		watermark.Color = c
+	}
+	return watermark, nil
+}
+
+// pdfaFlag returns the PDF/A level of the --pdfa flag in args, "" when absent
+func pdfaFlag(args []string) (string, error) {
+	value := strings.ToLower(flagValue(args, "--pdfa"))
+	if value != "" && !writers.IsPDFALevel(value) {
+		return "", fmt.Errorf("invalid PDF/A level: %s", value)
+	}
+	return value, nil
+}
+
+// encryptionFlags returns the encryption set by the --user-password,
+// --owner-password, --encrypt-method and --allow flags in args
+func encryptionFlags(args []string) (*writers.Encryption, error) {
+	encryption := writers.NewEncryption(flagValue(args, "--user-password"), flagValue(args, "--owner-password"))
+	if value := strings.ToLower(flagValue(args, "--encrypt-method")); value != "" {
+		if !writers.IsEncryptionMethod(value) {
+			return nil, fmt.Errorf("invalid encryption method: %s", value)
+		}
+		encryption.Method = value
+	}
+	if value := flagValue(args, "--allow"); value != "" {
+		for _, permission := range strings.Split(value, ",") {
+			switch strings.ToLower(strings.TrimSpace(permission)) {
+			case "print":
+				encryption.AllowPrint = true
+			case "copy":
+				encryption.AllowCopy = true
+			case "modify":
+				encryption.AllowModify = true
+			case "annotate":
+				encryption.AllowAnnotate = true
+			case "":
+			default:
+				return nil, fmt.Errorf("invalid permission: %s", permission)
+			}
+		}
+	}
+	return encryption, nil
+}
+
+// pdfOutputFlags applies the --pdfa flag and, with --encrypt, the
+// encryption flags in args to a PDF writer
+func pdfOutputFlags(w *writers.PDFWriter, args []string) error {
+	var err error
+	if w.PDFA, err = pdfaFlag(args); err != nil {
+		return err
+	}
+	if hasFlag(args, "--encrypt") {
+		if w.Encryption, err = encryptionFlags(args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasFlag reports whether a command line flag is present in args
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == name {
+			return true
+		}
+	}
+	return false
+}
+
+// flagValue returns the value following a command line flag in args, "" when absent
+func flagValue(args []string, name string) string {
+	for i, arg := range args {
+		if arg == name && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
 func main() {
 	if len(os.Args) < 3 {
 		fmt.Println("Usage:")
-		fmt.Println("  Read:    myconverter read <filepath>")
+		fmt.Println("  Read:    myconverter read <filepath> [--json]")
 		fmt.Println("  Write:   myconverter write <output.zip> <file1> [file2] [dir1] ...")
-		fmt.Println("  Convert: myconverter convert <input_file> <output.(png|pdf)>")
+		fmt.Println("  Convert: myconverter convert <input_file> <output.(png|jpg|gif|bmp|tiff|pdf|txt|md|html|docx|odt|rtf|epub|json)> [--inline-images] [--font <font_file>]")
+		fmt.Println("           [--font <font_file>] draws PDF and image text in a TrueType font, the default being Malgun Gothic, NanumGothic or DejaVu Sans, and is embedded in EPUB output")
+		fmt.Println("           Text output: [--encoding utf-8|utf-8-bom|utf-16le|cp949|euc-kr] [--eol lf|crlf|cr] [--unmappable error|replace]")
+		fmt.Println("           PDF output: [--dpi <n>] sizes pages to image inputs instead of fitting them on A4")
+		fmt.Println("           Image output: [--quality <1-100>] [--color color|gray|mono]; TIFF holds all pages, other formats write out-1.png, out-2.png, ...")
+		fmt.Println("           Text to image: [--dpi <n>] (default 300) [--hinting none|full] [--no-subpixel] [--background #RRGGBB[AA]|transparent]")
+		fmt.Println("           PDF to image: [--dpi <n>] renders each page at n DPI (default 150)")
+		fmt.Println("           Watermark (PDF and image output): [--watermark-text <text>] [--watermark-image <file>] [--watermark-opacity <0-1>] (default 0.3)")
+		fmt.Println("           [--watermark-rotation <degrees>] (default 45) [--watermark-position center|top|bottom|left|right|top-left|top-right|bottom-left|bottom-right]")
+		fmt.Println("           [--watermark-size <points>] [--watermark-color #RRGGBB]")
+		fmt.Println("           PDF/A (PDF output, merge, split, reorder, rotate, delete and stamp): [--pdfa 1b|2b] writes archival PDF/A files")
+		fmt.Println("           Encryption (same commands): [--encrypt] [--user-password <password>] [--owner-password <password>]")
+		fmt.Println("           [--encrypt-method aes128|aes256] (default aes256) [--allow print,copy,modify,annotate] (default none)")
+		fmt.Println("  Merge:   myconverter merge <file1> [file2] ... -o <output.pdf> [--dpi <n>] [watermark options]")
+		fmt.Println("           Inputs of any type; each starts on a new page under a bookmark with its file name, PDF pages are copied as they are")
+		fmt.Println("  Split:   myconverter split <input.pdf> [<output.pdf>|-o <output.pdf>] --every <n>|--ranges <1-3,4-9> writes output-1.pdf, output-2.pdf, ... (input-1.pdf, ... without an output)")
+		fmt.Println("  Reorder: myconverter reorder <input.pdf> <output.pdf> --pages <3,1,2,4-> (pages not listed follow in their order)")
+		fmt.Println("  Rotate:  myconverter rotate <input.pdf> <output.pdf> --angle <90|180|270> [--pages <ranges>] turns pages clockwise")
+		fmt.Println("  Delete:  myconverter delete-pages <input.pdf> <output.pdf> --pages <ranges>")
+		fmt.Println("  Stamp:   myconverter stamp <input.pdf> <output.pdf> --watermark-text <text>|--watermark-image <file> [watermark options]")
+		fmt.Println("  Protect: myconverter protect <input.pdf> <output.pdf> [--user-password <password>] [--owner-password <password>] [encryption options]")
+		fmt.Println("  PDF/A:   myconverter validate-pdfa <input.pdf> [--level 1b|2b] reports PDF/A violations, against the claimed level by default")
+		fmt.Println("  Thumbnail: myconverter thumbnail <input_file> <output.(png|jpg|gif|bmp|tiff)> [--size <pixels>] [--quality <1-100>]")
+		fmt.Println("  Tables:  myconverter extract-tables <input_file> <output.(csv|xlsx)> [--merged blank|repeat]; PDF input needs a unipdf license")
 		return
 	}
 
 	command := strings.ToLower(os.Args[1])
 
+	// PDF and image text of every command is drawn in the --font file when given
+	writers.FontPath = flagValue(os.Args[2:], "--font")
+
 	switch command {
 	case "read":
 		filePath := os.Args[2]
@@ -85,6 +551,22 @@ func main() {
 			return
 		}
 
+		// Print the extracted structure as JSON instead of the text
+		if hasFlag(os.Args[3:], "--json") {
+			content, err := readContent(reader, filePath)
+			if err != nil {
+				fmt.Printf("Error reading file: %v\n", err)
+				return
+			}
+			data, err := json.MarshalIndent(writers.NewJSONDocument(content, filepath.Base(filePath)), "", "  ")
+			if err != nil {
+				fmt.Printf("Error encoding JSON: %v\n", err)
+				return
+			}
+			fmt.Println(string(data))
+			return
+		}
+
 		err := processReader(reader, filePath)
 		if err != nil {
 			fmt.Printf("Error reading file: %v\n", err)
@@ -120,14 +602,6 @@ func main() {
 		outputDir := filepath.Dir(outputFile)
 		ext := strings.ToLower(filepath.Ext(outputFile))
 
-		// Open HWPX file
-		zipReader, err := zip.OpenReader(inputFile)
-		if err != nil {
-			fmt.Printf("Error opening HWPX file: %v\n", err)
-			return
-		}
-		defer zipReader.Close()
-
 		// Get appropriate reader based on input file extension
 		reader := GetFileReader(inputFile)
 		if reader == nil {
@@ -135,53 +609,70 @@ func main() {
 			return
 		}
 
-		// Read content based on file type
-		var content *interfaces.PDFContent
-
-		switch r := reader.(type) {
-		case *readers.PDFReader:
-			// Read PDF content
-			content, err = r.ReadPDF()
+		// PDF pages are rasterized as drawn instead of re-typing their text
+		if pdfReader, ok := reader.(*readers.PDFReader); ok && writers.IsImageExt(ext) {
+			dpi, err := dpiFlag(os.Args[4:])
 			if err != nil {
-				fmt.Printf("Error reading PDF content: %v\n", err)
+				fmt.Printf("Error: %v\n", err)
 				return
 			}
-
-		case *readers.ZipReader:
-			// For HWPX files
-			if strings.HasSuffix(strings.ToLower(inputFile), ".hwpx") {
-				// Extract text content from HWPX
-				hwpxContent, err := readers.ExtractHWPXContent(zipReader)
-				if err != nil {
-					fmt.Printf("Error extracting HWPX content: %v\n", err)
-					return
-				}
-				// Convert HWPX content to PDFContent format
-				content = &interfaces.PDFContent{
-					Text: strings.Join(hwpxContent.Text, "\n"),
-					Pages: []interfaces.PDFPage{{
-						Number: 1,
-						Text:   strings.Join(hwpxContent.Text, "\n"),
-					}},
-				}
+			pages, err := pdfReader.RenderPages(dpi)
+			if err != nil {
+				fmt.Printf("Error rendering PDF: %v\n", err)
+				return
+			}
+			imageWriter := &writers.ImageWriter{OutputDir: outputDir, DPI: dpi}
+			if imageWriter.DPI == 0 {
+				imageWriter.DPI = readers.DefaultRenderDPI
+			}
+			if err := imageFlags(imageWriter, os.Args[4:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
 			}
+			if imageWriter.Watermark, err = watermarkFlags(os.Args[4:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if err := imageWriter.WritePages(outputFile, pages); err != nil {
+				fmt.Printf("Error creating image: %v\n", err)
+				return
+			}
+			fmt.Printf("Successfully rendered %d page(s) to: %s\n", len(pages), outputFile)
+			return
+		}
 
-		default:
-			fmt.Printf("Unsupported input file type\n")
+		// Read content based on file type
+		content, err := readContent(reader, inputFile)
+		if err != nil {
+			fmt.Printf("Error reading input file: %v\n", err)
 			return
 		}
 
 		switch ext {
-		case ".png":
+		case ".png", ".jpg", ".jpeg", ".gif", ".bmp", ".tif", ".tiff":
 			// Create image writer
 			imageWriter, err := writers.NewImageWriter(outputDir)
 			if err != nil {
 				fmt.Printf("Error creating image writer: %v\n", err)
 				return
 			}
+			if err := imageFlags(imageWriter, os.Args[4:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if dpi, err := dpiFlag(os.Args[4:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			} else if dpi > 0 {
+				imageWriter.DPI = dpi
+			}
+			if imageWriter.Watermark, err = watermarkFlags(os.Args[4:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
 
 			// Create image with text
-			err = imageWriter.WriteTexts(outputFile, content.Text)
+			err = imageWriter.WriteContent(outputFile, content)
 			if err != nil {
 				fmt.Printf("Error creating image: %v\n", err)
 				return
@@ -192,9 +683,21 @@ func main() {
 		case ".pdf":
 			// Create PDF writer
 			pdfWriter := writers.NewPDFWriter(outputDir)
+			if pdfWriter.ImageDPI, err = dpiFlag(os.Args[4:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if pdfWriter.Watermark, err = watermarkFlags(os.Args[4:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if err = pdfOutputFlags(pdfWriter, os.Args[4:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
 
 			// Create PDF with text
-			err := pdfWriter.WriteTexts(outputFile, content.Text)
+			err := pdfWriter.WriteContent(outputFile, content)
 			if err != nil {
 				fmt.Printf("Error creating PDF: %v\n", err)
 				return
@@ -219,7 +722,16 @@ func main() {
 		case ".txt":
 			// Create text writer
 			textWriter := writers.NewTextWriter(outputDir)
-			err = textWriter.WriteTexts(outputFile, content.Text)
+			if encoding := flagValue(os.Args[4:], "--encoding"); encoding != "" {
+				textWriter.Encoding = encoding
+			}
+			if eol := flagValue(os.Args[4:], "--eol"); eol != "" {
+				textWriter.EOL = eol
+			}
+			if unmappable := flagValue(os.Args[4:], "--unmappable"); unmappable != "" {
+				textWriter.Unmappable = unmappable
+			}
+			err = textWriter.WriteContent(outputFile, content)
 			if err != nil {
 				fmt.Printf("Error creating text file: %v\n", err)
 				return
@@ -227,10 +739,413 @@ func main() {
 
 			fmt.Printf("Successfully created text file: %s\n", outputFile)
 
+		case ".md":
+			// Create Markdown writer
+			markdownWriter := writers.NewMarkdownWriter(outputDir)
+			err = markdownWriter.WriteContent(outputFile, content)
+			if err != nil {
+				fmt.Printf("Error creating Markdown file: %v\n", err)
+				return
+			}
+
+			fmt.Printf("Successfully created Markdown file: %s\n", outputFile)
+
+		case ".html", ".htm":
+			// Create HTML writer
+			htmlWriter := writers.NewHTMLWriter(outputDir)
+			htmlWriter.InlineImages = hasFlag(os.Args[4:], "--inline-images")
+			err = htmlWriter.WriteContent(outputFile, content)
+			if err != nil {
+				fmt.Printf("Error creating HTML file: %v\n", err)
+				return
+			}
+
+			fmt.Printf("Successfully created HTML file: %s\n", outputFile)
+
+		case ".docx":
+			// Create DOCX writer
+			docxWriter := writers.NewDOCXWriter(outputDir)
+			err = docxWriter.WriteContent(outputFile, content)
+			if err != nil {
+				fmt.Printf("Error creating DOCX file: %v\n", err)
+				return
+			}
+
+			fmt.Printf("Successfully created DOCX file: %s\n", outputFile)
+
+		case ".odt":
+			// Create ODT writer
+			odtWriter := writers.NewODTWriter(outputDir)
+			err = odtWriter.WriteContent(outputFile, content)
+			if err != nil {
+				fmt.Printf("Error creating ODT file: %v\n", err)
+				return
+			}
+
+			fmt.Printf("Successfully created ODT file: %s\n", outputFile)
+
+		case ".rtf":
+			// Create RTF writer
+			rtfWriter := writers.NewRTFWriter(outputDir)
+			err = rtfWriter.WriteContent(outputFile, content)
+			if err != nil {
+				fmt.Printf("Error creating RTF file: %v\n", err)
+				return
+			}
+
+			fmt.Printf("Successfully created RTF file: %s\n", outputFile)
+
+		case ".epub":
+			// Create EPUB writer
+			epubWriter := writers.NewEPUBWriter(outputDir)
+			epubWriter.FontPath = flagValue(os.Args[4:], "--font")
+			err = epubWriter.WriteContent(outputFile, content)
+			if err != nil {
+				fmt.Printf("Error creating EPUB file: %v\n", err)
+				return
+			}
+
+			fmt.Printf("Successfully created EPUB file: %s\n", outputFile)
+
+		case ".json":
+			// Create JSON writer
+			jsonWriter := writers.NewJSONWriter(outputDir)
+			jsonWriter.Source = filepath.Base(inputFile)
+			err = jsonWriter.WriteContent(outputFile, content)
+			if err != nil {
+				fmt.Printf("Error creating JSON file: %v\n", err)
+				return
+			}
+
+			fmt.Printf("Successfully created JSON file: %s\n", outputFile)
+
 		default:
 			fmt.Printf("Unsupported output format: %s\n", ext)
 		}
 
+	case "merge":
+		// Inputs are followed or preceded by -o <output.pdf>
+		args := os.Args[2:]
+		outputFile := flagValue(args, "-o")
+		var inputs []string
+		for i := 0; i < len(args); i++ {
+			switch args[i] {
+			case "-o", "--dpi", "--watermark-text", "--watermark-image", "--watermark-opacity",
+				"--watermark-rotation", "--watermark-position", "--watermark-size", "--watermark-color", "--pdfa",
+				"--user-password", "--owner-password", "--encrypt-method", "--allow":
+				i++
+			case "--encrypt":
+			default:
+				inputs = append(inputs, args[i])
+			}
+		}
+		if outputFile == "" || len(inputs) == 0 {
+			fmt.Println("Error: Please provide input files and -o <output.pdf>")
+			return
+		}
+		if strings.ToLower(filepath.Ext(outputFile)) != ".pdf" {
+			fmt.Printf("Unsupported output format: %s\n", filepath.Ext(outputFile))
+			return
+		}
+
+		// Each input is a section of its own, starting on a new page with
+		// a bookmark holding the bookmarks of PDF inputs
+		pdfWriter := writers.NewPDFWriter(filepath.Dir(outputFile))
+		dpi, err := dpiFlag(args)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if pdfWriter.Watermark, err = watermarkFlags(args); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err = pdfOutputFlags(pdfWriter, args); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		tempDir, err := os.MkdirTemp("", "myconverter-merge")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer os.RemoveAll(tempDir)
+
+		sections, err := mergeSections(inputs, tempDir, dpi, pdfWriter.PDFA)
+		if err != nil {
+			fmt.Printf("Error reading input file: %v\n", err)
+			return
+		}
+		if err := pdfWriter.MergePDFs(outputFile, sections); err != nil {
+			fmt.Printf("Error merging files: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Successfully merged %d files into: %s\n", len(inputs), outputFile)
+
+	case "thumbnail":
+		if len(os.Args) < 4 {
+			fmt.Println("Error: Please provide input file and output path")
+			return
+		}
+		inputFile := os.Args[2]
+		outputFile := os.Args[3]
+		if !writers.IsImageExt(strings.ToLower(filepath.Ext(outputFile))) {
+			fmt.Printf("Unsupported output format: %s\n", filepath.Ext(outputFile))
+			return
+		}
+		size := 256
+		if value := flagValue(os.Args[4:], "--size"); value != "" {
+			var err error
+			if size, err = strconv.Atoi(value); err != nil || size <= 0 {
+				fmt.Printf("Error: invalid thumbnail size: %s\n", value)
+				return
+			}
+		}
+
+		page, err := firstPageImage(inputFile)
+		if err != nil {
+			fmt.Printf("Error rendering first page: %v\n", err)
+			return
+		}
+
+		imageWriter := &writers.ImageWriter{OutputDir: filepath.Dir(outputFile)}
+		if err := imageFlags(imageWriter, os.Args[4:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := imageWriter.SaveImage(writers.Thumbnail(page, size), filepath.Base(outputFile)); err != nil {
+			fmt.Printf("Error creating thumbnail: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Successfully created thumbnail: %s\n", outputFile)
+
+	case "stamp":
+		if len(os.Args) < 4 {
+			fmt.Println("Error: Please provide input PDF and output path")
+			return
+		}
+		inputFile := os.Args[2]
+		outputFile := os.Args[3]
+		if strings.ToLower(filepath.Ext(inputFile)) != ".pdf" || strings.ToLower(filepath.Ext(outputFile)) != ".pdf" {
+			fmt.Println("Error: stamp reads and writes PDF files")
+			return
+		}
+
+		watermark, err := watermarkFlags(os.Args[4:])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if watermark == nil {
+			fmt.Println("Error: Please provide --watermark-text or --watermark-image")
+			return
+		}
+
+		pdfWriter := writers.NewPDFWriter(filepath.Dir(outputFile))
+		pdfWriter.Watermark = watermark
+		if err = pdfOutputFlags(pdfWriter, os.Args[4:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := pdfWriter.Stamp(inputFile, outputFile); err != nil {
+			fmt.Printf("Error stamping PDF: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Successfully stamped: %s\n", outputFile)
+
+	case "protect":
+		if len(os.Args) < 4 {
+			fmt.Println("Error: Please provide input PDF and output path")
+			return
+		}
+		inputFile := os.Args[2]
+		outputFile := os.Args[3]
+		if strings.ToLower(filepath.Ext(inputFile)) != ".pdf" || strings.ToLower(filepath.Ext(outputFile)) != ".pdf" {
+			fmt.Println("Error: protect reads and writes PDF files")
+			return
+		}
+
+		pdfWriter := writers.NewPDFWriter(filepath.Dir(outputFile))
+		var err error
+		if pdfWriter.Encryption, err = encryptionFlags(os.Args[4:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := pdfWriter.Protect(inputFile, outputFile); err != nil {
+			fmt.Printf("Error protecting PDF: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Successfully protected: %s\n", outputFile)
+
+	case "split":
+		// The output is given after the input or with -o; parts are named
+		// after the input when it is left out
+		inputFile := os.Args[2]
+		args := os.Args[3:]
+		outputFile := flagValue(args, "-o")
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			outputFile, args = args[0], args[1:]
+		}
+		if outputFile == "" {
+			outputFile = inputFile
+		}
+		if strings.ToLower(filepath.Ext(outputFile)) != ".pdf" {
+			fmt.Printf("Unsupported output format: %s\n", filepath.Ext(outputFile))
+			return
+		}
+		pdfWriter := writers.NewPDFWriter(filepath.Dir(outputFile))
+		var err error
+		if err = pdfOutputFlags(pdfWriter, args); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		var paths []string
+		if value := flagValue(args, "--every"); value != "" {
+			count, convErr := strconv.Atoi(value)
+			if convErr != nil || count < 1 {
+				fmt.Printf("Error: invalid page count: %s\n", value)
+				return
+			}
+			paths, err = pdfWriter.SplitPDFEvery(inputFile, outputFile, count)
+		} else if value := flagValue(args, "--ranges"); value != "" {
+			ranges, parseErr := writers.ParsePageRanges(value)
+			if parseErr != nil {
+				fmt.Printf("Error: %v\n", parseErr)
+				return
+			}
+			paths, err = pdfWriter.SplitPDF(inputFile, outputFile, ranges)
+		} else {
+			fmt.Println("Error: Please provide --every <n> or --ranges <ranges>")
+			return
+		}
+		if err != nil {
+			fmt.Printf("Error splitting PDF: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Successfully split into %d files: %s\n", len(paths), strings.Join(paths, ", "))
+
+	case "reorder", "rotate", "delete-pages":
+		if len(os.Args) < 4 {
+			fmt.Println("Error: Please provide input PDF and output path")
+			return
+		}
+		command := os.Args[1]
+		inputFile := os.Args[2]
+		outputFile := os.Args[3]
+		pdfWriter := writers.NewPDFWriter(filepath.Dir(outputFile))
+		var err error
+		if err = pdfOutputFlags(pdfWriter, os.Args[4:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		var ranges []writers.PageRange
+		if value := flagValue(os.Args[4:], "--pages"); value != "" {
+			if ranges, err = writers.ParsePageRanges(value); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		} else if command != "rotate" {
+			fmt.Println("Error: Please provide --pages <ranges>")
+			return
+		}
+
+		switch command {
+		case "reorder":
+			err = pdfWriter.ReorderPDF(inputFile, outputFile, ranges)
+		case "rotate":
+			angle, convErr := strconv.Atoi(flagValue(os.Args[4:], "--angle"))
+			if convErr != nil {
+				fmt.Println("Error: Please provide --angle <90|180|270>")
+				return
+			}
+			err = pdfWriter.RotatePDF(inputFile, outputFile, angle, ranges)
+		case "delete-pages":
+			err = pdfWriter.DeletePDFPages(inputFile, outputFile, ranges)
+		}
+		if err != nil {
+			fmt.Printf("Error writing PDF: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Successfully wrote: %s\n", outputFile)
+
+	case "validate-pdfa":
+		inputFile := os.Args[2]
+		level := strings.ToLower(flagValue(os.Args[3:], "--level"))
+		if level != "" && !writers.IsPDFALevel(level) {
+			fmt.Printf("Error: invalid PDF/A level: %s\n", level)
+			return
+		}
+
+		report, err := writers.ValidatePDFA(inputFile, level)
+		if err != nil {
+			fmt.Printf("Error reading input file: %v\n", err)
+			return
+		}
+		claimed := "none"
+		if report.Claimed != "" {
+			claimed = "PDF/A-" + strings.ToUpper(report.Claimed)
+		}
+		fmt.Printf("%s: claims %s, checked against PDF/A-%s\n", inputFile, claimed, strings.ToUpper(report.Level))
+		if len(report.Violations) == 0 {
+			fmt.Println("No violations found")
+			return
+		}
+		fmt.Printf("%d violations:\n", len(report.Violations))
+		for _, violation := range report.Violations {
+			fmt.Printf("  - %s\n", violation)
+		}
+
+	case "extract-tables":
+		if len(os.Args) < 4 {
+			fmt.Println("Error: Please provide input file and output path")
+			return
+		}
+
+		inputFile := os.Args[2]
+		outputFile := os.Args[3]
+
+		reader := GetFileReader(inputFile)
+		if reader == nil {
+			fmt.Println("Unsupported input file format")
+			return
+		}
+
+		// PDF tables are detected from the page layout, other formats keep them as blocks
+		var tables []*interfaces.Table
+		if pdfReader, ok := reader.(*readers.PDFReader); ok {
+			var err error
+			if tables, err = pdfReader.ReadTables(); err != nil {
+				fmt.Printf("Error reading input file: %v\n", err)
+				return
+			}
+		} else {
+			content, err := readContent(reader, inputFile)
+			if err != nil {
+				fmt.Printf("Error reading input file: %v\n", err)
+				return
+			}
+			tables = interfaces.Tables(content.ContentBlocks())
+		}
+
+		tableWriter := writers.NewTableWriter(filepath.Dir(outputFile))
+		if merged := flagValue(os.Args[4:], "--merged"); merged != "" {
+			tableWriter.Merged = merged
+		}
+		if err := tableWriter.WriteTables(outputFile, tables); err != nil {
+			fmt.Printf("Error extracting tables: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Successfully extracted %d tables to: %s\n", len(tables), outputFile)
+
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 	}
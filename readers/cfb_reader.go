@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/richardlehane/mscfb"
 )
@@ -17,6 +18,7 @@ type CFBReader struct {
 // CFBEntry represents an entry in the CFB file
 type CFBEntry struct {
 	Name     string
+	Path     string // full path of the entry, e.g. BodyText/Section0
 	Size     int64
 	Content  []byte
 	Children []*CFBEntry
@@ -51,6 +53,7 @@ func (r *CFBReader) Read(filePath string) error {
 
 		r.Entries = append(r.Entries, CFBEntry{
 			Name:    entry.Name,
+			Path:    strings.Join(append(entry.Path, entry.Name), "/"),
 			Size:    entry.Size,
 			Content: content,
 		})
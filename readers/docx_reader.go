@@ -0,0 +1,730 @@
+package readers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"myconverter/hwpx"
+	"myconverter/interfaces"
+)
+
+// DOCX relationship types used by the reader
+const (
+	docxRelOfficeDocument = "/officeDocument"
+	docxRelCoreProperties = "/core-properties"
+	docxRelStyles         = "/styles"
+	docxRelNumbering      = "/numbering"
+	docxRelSettings       = "/settings"
+	docxRelFootnotes      = "/footnotes"
+	docxRelEndnotes       = "/endnotes"
+)
+
+// DOCXReader implements FileReader for Office Open XML documents
+type DOCXReader struct {
+	filePath string
+	content  *interfaces.PDFContent
+
+	files     map[string][]byte
+	styles    *docxStyles
+	numbering map[string]map[int]string
+	// noteTexts holds the footnote and endnote texts by their DOCX note IDs
+	noteTexts map[string]map[string]string
+	// sections counts the sectPr elements read, only the first one sets
+	// the page setup, headers and footers
+	sections         int
+	headerFooterRefs []docxHeaderFooterRef
+}
+
+// docxHeaderFooterRef is a header or footer reference of a section
+type docxHeaderFooterRef struct {
+	element  string
+	pageType string
+	id       string
+}
+
+// docxRelationship is an entry of a .rels part
+type docxRelationship struct {
+	Type   string
+	Target string
+}
+
+// docxCollector gathers the blocks of the body, a table cell or a note
+type docxCollector struct {
+	blocks []interfaces.Block
+	// note is the kind and DOCX ID of the note being read in a notes part
+	noteKind string
+	noteID   string
+}
+
+// docxParagraph buffers a paragraph until it is complete
+type docxParagraph struct {
+	block     interfaces.Block
+	style     string
+	numID     string
+	level     int
+	align     string
+	collector *docxCollector
+	// sectionBreak marks paragraphs ending a section
+	sectionBreak bool
+}
+
+// docxTable builds a table from tr/tc elements
+type docxTable struct {
+	table *interfaces.Table
+	col   int
+	cell  interfaces.TableCell
+	// vMerge is the vertical merge state of the cell being read: restart or continue
+	vMerge string
+	// merges maps grid columns to the row and index of the cells vertically merged into
+	merges map[int][2]int
+}
+
+// NewDOCXReader creates a new DOCXReader
+func NewDOCXReader(filePath string) *DOCXReader {
+	return &DOCXReader{
+		filePath: filePath,
+		content:  &interfaces.PDFContent{},
+	}
+}
+
+// Read reads the DOCX package and extracts its content
+func (r *DOCXReader) Read(filePath string) error {
+	r.filePath = filePath
+	_, err := r.ReadDOCX()
+	return err
+}
+
+// GetContent returns the content extracted by Read
+func (r *DOCXReader) GetContent() *interfaces.PDFContent {
+	return r.content
+}
+
+// ReadDOCX reads document.xml, styles, numbering, notes, headers, footers,
+// images and core properties into the content structure
+func (r *DOCXReader) ReadDOCX() (*interfaces.PDFContent, error) {
+	zipReader := &ZipReader{}
+	if err := zipReader.Read(r.filePath); err != nil {
+		return nil, err
+	}
+	r.files = make(map[string][]byte)
+	for _, file := range zipReader.GetFiles() {
+		r.files[file.Name] = file.Content
+	}
+
+	// The main document part is found through the package relationships
+	documentPart := "word/document.xml"
+	var corePart string
+	for _, rel := range r.relationships("") {
+		switch {
+		case strings.HasSuffix(rel.Type, docxRelOfficeDocument):
+			documentPart = rel.Target
+		case strings.HasSuffix(rel.Type, docxRelCoreProperties):
+			corePart = rel.Target
+		}
+	}
+	if _, ok := r.files[documentPart]; !ok {
+		return nil, fmt.Errorf("%s not found", documentPart)
+	}
+
+	var err error
+	documentRels := r.relationships(documentPart)
+	r.styles, err = parseDOCXStyles(r.files[r.relTarget(documentRels, docxRelStyles)])
+	if err != nil {
+		return nil, err
+	}
+	r.numbering, err = parseDOCXNumbering(r.files[r.relTarget(documentRels, docxRelNumbering)])
+	if err != nil {
+		return nil, err
+	}
+
+	// Notes are read first so references in the body can take their text
+	r.noteTexts = map[string]map[string]string{
+		interfaces.Footnote: make(map[string]string),
+		interfaces.Endnote:  make(map[string]string),
+	}
+	for _, relType := range []string{docxRelFootnotes, docxRelEndnotes} {
+		if part := r.relTarget(documentRels, relType); part != "" {
+			if _, err := r.parsePart(part); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	content := &interfaces.PDFContent{Metadata: make(map[string]string)}
+	r.content = content
+	blocks, err := r.parsePart(documentPart)
+	if err != nil {
+		return nil, err
+	}
+	content.Blocks = blocks
+	content.Text = strings.Join(interfaces.BlockLines(content.Blocks), "\n")
+	content.Pages = []interfaces.PDFPage{{Number: 1, Text: content.Text}}
+
+	if err := r.readHeaderFooters(documentRels, r.relTarget(documentRels, docxRelSettings)); err != nil {
+		return nil, err
+	}
+
+	if data, ok := r.files[corePart]; ok {
+		if content.Metadata, err = parseDOCXCoreProperties(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return content, nil
+}
+
+// relationships returns the relationships of a part by ID, or the package
+// relationships for an empty part name
+func (r *DOCXReader) relationships(part string) map[string]docxRelationship {
+	relsPart := path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+	if part == "" {
+		relsPart = "_rels/.rels"
+	}
+
+	rels := make(map[string]docxRelationship)
+	decoder := xml.NewDecoder(strings.NewReader(string(r.files[relsPart])))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		t, ok := token.(xml.StartElement)
+		if !ok || t.Name.Local != "Relationship" || attrValue(t, "TargetMode") == "External" {
+			continue
+		}
+
+		// Targets are relative to the part's directory unless absolute
+		target := attrValue(t, "Target")
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(path.Dir(part), target)
+		}
+		rels[attrValue(t, "Id")] = docxRelationship{Type: attrValue(t, "Type"), Target: target}
+	}
+	return rels
+}
+
+// relTarget returns the target of the first relationship with the given type suffix
+func (r *DOCXReader) relTarget(rels map[string]docxRelationship, relType string) string {
+	for _, rel := range rels {
+		if strings.HasSuffix(rel.Type, relType) {
+			return rel.Target
+		}
+	}
+	return ""
+}
+
+// readHeaderFooters reads the header and footer parts referenced by the first section
+func (r *DOCXReader) readHeaderFooters(rels map[string]docxRelationship, settingsPart string) error {
+	evenAndOdd := false
+	decoder := xml.NewDecoder(strings.NewReader(string(r.files[settingsPart])))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		if t, ok := token.(xml.StartElement); ok && t.Name.Local == "evenAndOddHeaders" {
+			evenAndOdd = docxOn(attrValue(t, "val"))
+		}
+	}
+
+	for _, ref := range r.headerFooterRefs {
+		rel, ok := rels[ref.id]
+		if !ok {
+			continue
+		}
+
+		// First page headers have no equivalent in the content model
+		var apply string
+		switch ref.pageType {
+		case "even":
+			if !evenAndOdd {
+				continue
+			}
+			apply = "EVEN"
+		case "first":
+			continue
+		default:
+			apply = "BOTH"
+			if evenAndOdd {
+				apply = "ODD"
+			}
+		}
+
+		blocks, err := r.parsePart(rel.Target)
+		if err != nil {
+			return err
+		}
+		item := interfaces.HeaderFooter{Apply: apply, Text: strings.Join(interfaces.BlockLines(blocks), "\n")}
+		for _, block := range blocks {
+			if block.Align != "" {
+				item.Align = block.Align
+				break
+			}
+		}
+		if ref.element == "headerReference" {
+			r.content.Headers = append(r.content.Headers, item)
+		} else {
+			r.content.Footers = append(r.content.Footers, item)
+		}
+	}
+	return nil
+}
+
+// parsePart walks a document, header, footer or notes part and returns its blocks
+func (r *DOCXReader) parsePart(part string) ([]interfaces.Block, error) {
+	data, ok := r.files[part]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", part)
+	}
+	rels := r.relationships(part)
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+
+	collectors := []*docxCollector{{}}
+	var paragraphs []*docxParagraph
+	var tables []*docxTable
+	// Formatting of the run being read
+	var format interfaces.Run
+	var inPPr, inRPr, inText, inInstr, inSectPr bool
+	// Field instructions and whether the field result is being skipped,
+	// fieldDepth tracks the elements inside a skipped simple field
+	var instr strings.Builder
+	var skipField bool
+	var fieldDepth int
+	// customMark is the index of the note whose custom mark is being read
+	customMark := -1
+
+	current := func() *docxCollector {
+		return collectors[len(collectors)-1]
+	}
+	paragraph := func() *docxParagraph {
+		if len(paragraphs) == 0 {
+			return nil
+		}
+		return paragraphs[len(paragraphs)-1]
+	}
+	write := func(text string) {
+		if p := paragraph(); p != nil && !skipField {
+			p.block.Runs = appendRun(p.block.Runs, text, format)
+		}
+	}
+	// addBlock places a table, picture or break after the text of the enclosing paragraph
+	addBlock := func(block interfaces.Block) {
+		collector := current()
+		if p := paragraph(); p != nil {
+			r.flushParagraph(p)
+			collector = p.collector
+		}
+		collector.blocks = append(collector.blocks, block)
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", part, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case inSectPr:
+				r.setSectionProperty(t)
+
+			case t.Name.Local == "footnote" || t.Name.Local == "endnote":
+				kind := interfaces.Footnote
+				if t.Name.Local == "endnote" {
+					kind = interfaces.Endnote
+				}
+				collectors = append(collectors, &docxCollector{noteKind: kind, noteID: attrValue(t, "id")})
+
+			case t.Name.Local == "p":
+				// Text before a nested paragraph, such as a text box, belongs in its own block
+				if p := paragraph(); p != nil && p.collector == current() {
+					r.flushParagraph(p)
+				}
+				p := &docxParagraph{
+					block:     interfaces.Block{Kind: interfaces.BlockParagraph},
+					style:     r.styles.DefaultParagraph,
+					level:     -1,
+					collector: current(),
+				}
+				paragraphs = append(paragraphs, p)
+				r.setParagraphStyle(p)
+
+			case t.Name.Local == "pPr":
+				inPPr = true
+			case t.Name.Local == "sectPr":
+				// A sectPr in a paragraph ends a section, the last one follows the body
+				if p := paragraph(); p != nil && inPPr {
+					p.sectionBreak = true
+				}
+				inSectPr = true
+				r.sections++
+			case inPPr && t.Name.Local == "rPr":
+				// Paragraph mark formatting does not apply to the text
+				inRPr = false
+			case inPPr:
+				if p := paragraph(); p != nil {
+					switch t.Name.Local {
+					case "pStyle":
+						p.style = attrValue(t, "val")
+						r.setParagraphStyle(p)
+					case "numId":
+						p.numID = attrValue(t, "val")
+					case "ilvl":
+						p.level, _ = strconv.Atoi(attrValue(t, "val"))
+					case "jc":
+						p.align = attrValue(t, "val")
+					}
+				}
+
+			case t.Name.Local == "r":
+				format = interfaces.Run{}
+				if p := paragraph(); p != nil {
+					format = r.styles.runFormat(p.style)
+				}
+			case t.Name.Local == "rPr":
+				inRPr = true
+			case inRPr:
+				if t.Name.Local == "rStyle" {
+					for _, property := range r.styles.resolve(attrValue(t, "val")).Run {
+						applyDOCXRunProperty(&format, property)
+					}
+					format.CharShape = attrValue(t, "val")
+				} else {
+					applyDOCXRunProperty(&format, t)
+				}
+
+			case t.Name.Local == "t":
+				inText = true
+			case t.Name.Local == "tab":
+				write("\t")
+			case t.Name.Local == "br":
+				if attrValue(t, "type") == "page" {
+					addBlock(interfaces.Block{Kind: interfaces.BlockPageBreak})
+				} else {
+					write("\n")
+				}
+			case t.Name.Local == "cr":
+				write("\n")
+
+			case t.Name.Local == "footnoteReference" || t.Name.Local == "endnoteReference":
+				kind := interfaces.Footnote
+				if t.Name.Local == "endnoteReference" {
+					kind = interfaces.Endnote
+				}
+				index := r.addNote(kind, attrValue(t, "id"))
+				write(interfaces.NoteRef(r.content.Notes[index].ID))
+				// A custom mark in the following text replaces the automatic number
+				if value := attrValue(t, "customMarkFollows"); value != "" && docxOn(value) {
+					customMark = index
+					r.content.Notes[index].Label = ""
+				}
+
+			case t.Name.Local == "fldSimple":
+				if field := docxField(attrValue(t, "instr")); field != "" && fieldDepth == 0 {
+					write(field)
+					skipField = true
+					fieldDepth = 1
+					continue
+				}
+			case t.Name.Local == "fldChar":
+				switch attrValue(t, "fldCharType") {
+				case "begin":
+					instr.Reset()
+				case "separate":
+					if field := docxField(instr.String()); field != "" {
+						write(field)
+						skipField = true
+					}
+				case "end":
+					skipField = false
+				}
+			case t.Name.Local == "instrText":
+				inInstr = true
+
+			case t.Name.Local == "blip" || t.Name.Local == "imagedata":
+				id := attrValue(t, "embed")
+				if id == "" {
+					id = attrValue(t, "id")
+				}
+				if rel, ok := rels[id]; ok {
+					if data, ok := r.files[rel.Target]; ok {
+						addBlock(interfaces.Block{
+							Kind:  interfaces.BlockImage,
+							Image: &interfaces.Image{Name: path.Base(rel.Target), Data: data},
+						})
+					}
+				}
+
+			case t.Name.Local == "tbl":
+				tables = append(tables, &docxTable{
+					table:  &interfaces.Table{},
+					merges: make(map[int][2]int),
+				})
+			case t.Name.Local == "tr":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					table.table.Rows = append(table.table.Rows, nil)
+					table.col = 0
+				}
+			case t.Name.Local == "tc":
+				if len(tables) > 0 && len(tables[len(tables)-1].table.Rows) > 0 {
+					table := tables[len(tables)-1]
+					row := len(table.table.Rows) - 1
+					table.cell = interfaces.TableCell{Row: row, Col: table.col, RowSpan: 1, ColSpan: 1}
+					table.vMerge = ""
+					collectors = append(collectors, &docxCollector{})
+				}
+			case t.Name.Local == "gridSpan":
+				if len(tables) > 0 {
+					span, _ := strconv.Atoi(attrValue(t, "val"))
+					tables[len(tables)-1].cell.ColSpan = max(span, 1)
+				}
+			case t.Name.Local == "vMerge":
+				if len(tables) > 0 {
+					// A vMerge without value continues the merge above
+					tables[len(tables)-1].vMerge = attrValue(t, "val")
+					if tables[len(tables)-1].vMerge == "" {
+						tables[len(tables)-1].vMerge = "continue"
+					}
+				}
+			}
+
+			if fieldDepth > 0 {
+				fieldDepth++
+			}
+
+		case xml.CharData:
+			switch {
+			case inText && current().noteKind != "" && r.noteMark(format.CharShape):
+				// The note's own mark is shown from its label
+			case inText && customMark >= 0:
+				r.content.Notes[customMark].Label += string(t)
+			case inText:
+				write(string(t))
+			case inInstr:
+				instr.Write(t)
+			}
+
+		case xml.EndElement:
+			if fieldDepth > 0 {
+				fieldDepth--
+				if fieldDepth == 0 {
+					skipField = false
+				}
+			}
+
+			switch t.Name.Local {
+			case "sectPr":
+				inSectPr = false
+			case "pPr":
+				inPPr = false
+				if p := paragraph(); p != nil {
+					r.setParagraphKind(p)
+				}
+			case "rPr":
+				inRPr = false
+			case "t":
+				inText = false
+			case "instrText":
+				inInstr = false
+			case "r":
+				customMark = -1
+			case "p":
+				if p := paragraph(); p != nil {
+					r.flushParagraph(p)
+					paragraphs = paragraphs[:len(paragraphs)-1]
+					if p.sectionBreak {
+						p.collector.blocks = append(p.collector.blocks, interfaces.Block{Kind: interfaces.BlockPageBreak, Section: true})
+					}
+				}
+			case "tc":
+				if len(tables) > 0 && len(collectors) > 1 {
+					table := tables[len(tables)-1]
+					table.cell.Blocks = current().blocks
+					collectors = collectors[:len(collectors)-1]
+					table.addCell()
+				}
+			case "tbl":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					tables = tables[:len(tables)-1]
+					addBlock(interfaces.Block{Kind: interfaces.BlockTable, Table: table.table})
+				}
+			case "footnote", "endnote":
+				if len(collectors) > 1 {
+					collector := current()
+					collectors = collectors[:len(collectors)-1]
+					r.noteTexts[collector.noteKind][collector.noteID] = strings.Join(interfaces.BlockLines(collector.blocks), "\n")
+				}
+			}
+		}
+	}
+
+	return collectors[0].blocks, nil
+}
+
+// addCell adds the cell being read to the table, extending the cell above
+// when it continues a vertical merge
+func (t *docxTable) addCell() {
+	row := len(t.table.Rows) - 1
+	span := t.cell.ColSpan
+	if origin, ok := t.merges[t.cell.Col]; ok && t.vMerge == "continue" {
+		cell := &t.table.Rows[origin[0]][origin[1]]
+		cell.RowSpan = row - cell.Row + 1
+		t.col += span
+		return
+	}
+	delete(t.merges, t.cell.Col)
+
+	t.table.Rows[row] = append(t.table.Rows[row], t.cell)
+	if t.vMerge == "restart" {
+		t.merges[t.cell.Col] = [2]int{row, len(t.table.Rows[row]) - 1}
+	}
+	t.col += span
+}
+
+// setParagraphStyle applies the alignment and numbering of the paragraph style
+func (r *DOCXReader) setParagraphStyle(p *docxParagraph) {
+	style := r.styles.resolve(p.style)
+	p.block.Style = style.Name
+	p.block.ParaShape = p.style
+	if style.Align != "" {
+		p.align = style.Align
+	}
+	if style.NumID != "" {
+		p.numID = style.NumID
+	}
+	if style.Level >= 0 {
+		p.level = style.Level
+	}
+}
+
+// setParagraphKind decides the block kind from the style, outline level and numbering
+func (r *DOCXReader) setParagraphKind(p *docxParagraph) {
+	style := r.styles.resolve(p.style)
+	p.block.Align = docxAlign(p.align)
+
+	if match := docxHeadingPattern.FindStringSubmatch(style.Name); match != nil {
+		p.block.Kind = interfaces.BlockHeading
+		p.block.Level, _ = strconv.Atoi(match[1])
+		return
+	}
+	if strings.EqualFold(style.Name, "Title") {
+		p.block.Kind, p.block.Level = interfaces.BlockHeading, 1
+		return
+	}
+	if style.Outline >= 0 && style.Outline < 9 {
+		p.block.Kind, p.block.Level = interfaces.BlockHeading, style.Outline+1
+		return
+	}
+
+	if p.numID != "" && p.numID != "0" {
+		level := max(p.level, 0)
+		format := r.numbering[p.numID][level]
+		p.block.Kind = interfaces.BlockListItem
+		p.block.Level = level + 1
+		p.block.Ordered = format != "" && format != "bullet" && format != "none"
+	}
+}
+
+// flushParagraph moves the buffered paragraph to its collector
+func (r *DOCXReader) flushParagraph(p *docxParagraph) {
+	if strings.TrimSpace(p.block.Text()) != "" {
+		block := p.block
+		block.Runs = trimRuns(block.Runs)
+		p.collector.blocks = append(p.collector.blocks, block)
+	}
+	p.block.Runs = nil
+}
+
+// addNote adds a note referenced from the text, numbered in reference
+// order, and returns its index
+func (r *DOCXReader) addNote(kind, docxID string) int {
+	count := 0
+	for _, note := range r.content.Notes {
+		if note.Kind == kind {
+			count++
+		}
+	}
+
+	note := interfaces.Note{Kind: kind, ID: strconv.Itoa(count + 1), Text: r.noteTexts[kind][docxID]}
+	note.Label = hwpx.FormatNumber("DIGIT", count+1)
+	if kind == interfaces.Endnote {
+		note.ID = "e" + note.ID
+		note.Label = hwpx.FormatNumber("ROMAN_SMALL", count+1)
+	}
+	r.content.Notes = append(r.content.Notes, note)
+	return len(r.content.Notes) - 1
+}
+
+// setSectionProperty reads the page setup and header/footer references of the first section
+func (r *DOCXReader) setSectionProperty(element xml.StartElement) {
+	if r.sections != 1 {
+		return
+	}
+
+	// Sizes are in twentieths of a point
+	value := func(name string) float64 {
+		v, _ := strconv.Atoi(attrValue(element, name))
+		return float64(v) / 20
+	}
+	if r.content.PageSetup == nil {
+		r.content.PageSetup = &interfaces.PageSetup{}
+	}
+	setup := r.content.PageSetup
+
+	switch element.Name.Local {
+	case "pgSz":
+		setup.Width, setup.Height = value("w"), value("h")
+	case "pgMar":
+		// Word places the header at its distance from the paper edge and the
+		// body at the top margin, the content model places the header
+		// area between the top margin and the body
+		setup.MarginLeft, setup.MarginRight = value("left"), value("right")
+		setup.MarginTop = value("header")
+		setup.MarginHeader = max(value("top")-value("header"), 0)
+		setup.MarginBottom = value("footer")
+		setup.MarginFooter = max(value("bottom")-value("footer"), 0)
+	case "headerReference", "footerReference":
+		r.headerFooterRefs = append(r.headerFooterRefs, docxHeaderFooterRef{
+			element:  element.Name.Local,
+			pageType: attrValue(element, "type"),
+			id:       attrValue(element, "id"),
+		})
+	}
+}
+
+// noteMark reports whether a character style is the footnote or endnote reference style
+func (r *DOCXReader) noteMark(styleID string) bool {
+	if styleID == "" {
+		return false
+	}
+	name := strings.ToLower(r.styles.resolve(styleID).Name)
+	return name == "footnote reference" || name == "endnote reference"
+}
+
+// docxField returns the placeholder for a PAGE or NUMPAGES field instruction
+func docxField(instr string) string {
+	fields := strings.Fields(instr)
+	if len(fields) == 0 {
+		return ""
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "PAGE":
+		return interfaces.PageNumberField
+	case "NUMPAGES", "SECTIONPAGES":
+		return interfaces.TotalPagesField
+	default:
+		return ""
+	}
+}
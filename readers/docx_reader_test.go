@@ -0,0 +1,152 @@
+package readers
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"myconverter/interfaces"
+)
+
+// writeTestZip writes a ZIP package of files and returns its path. A
+// mimetype entry is written first, as OpenDocument packages need.
+func writeTestZip(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] == "mimetype" || names[j] != "mimetype" && names[i] < names[j]
+	})
+	archive := zip.NewWriter(f)
+	for _, name := range names {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const docxTestNamespaces = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+func TestReadDOCX(t *testing.T) {
+	path := writeTestZip(t, "test.docx", map[string]string{
+		"_rels/.rels": `<?xml version="1.0"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>
+</Relationships>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering" Target="numbering.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footnotes" Target="footnotes.xml"/>
+<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>
+</Relationships>`,
+		"word/styles.xml": `<?xml version="1.0"?>
+<w:styles ` + docxTestNamespaces + `>
+<w:docDefaults><w:rPrDefault><w:rPr><w:sz w:val="20"/></w:rPr></w:rPrDefault></w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Centered"><w:name w:val="Centered"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/></w:pPr></w:style>
+<w:style w:type="character" w:styleId="Emphasis"><w:name w:val="Emphasis"/><w:rPr><w:i/><w:color w:val="FF0000"/></w:rPr></w:style>
+</w:styles>`,
+		"word/numbering.xml": `<?xml version="1.0"?>
+<w:numbering ` + docxTestNamespaces + `>
+<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+</w:numbering>`,
+		"word/footnotes.xml": `<?xml version="1.0"?>
+<w:footnotes ` + docxTestNamespaces + `>
+<w:footnote w:id="1"><w:p><w:r><w:t>the note</w:t></w:r></w:p></w:footnote>
+</w:footnotes>`,
+		"word/footer1.xml": `<?xml version="1.0"?>
+<w:ftr ` + docxTestNamespaces + `>
+<w:p><w:pPr><w:jc w:val="right"/></w:pPr><w:r><w:t xml:space="preserve">Page </w:t></w:r><w:fldSimple w:instr=" PAGE "><w:r><w:t>1</w:t></w:r></w:fldSimple></w:p>
+</w:ftr>`,
+		"word/document.xml": `<?xml version="1.0"?>
+<w:document ` + docxTestNamespaces + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Title</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Centered"/></w:pPr><w:r><w:t xml:space="preserve">plain </w:t></w:r><w:r><w:rPr><w:rStyle w:val="Emphasis"/><w:b/></w:rPr><w:t>styled</w:t></w:r><w:r><w:footnoteReference w:id="1"/></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>bullet</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>number</w:t></w:r></w:p>
+<w:tbl>
+<w:tr><w:tc><w:tcPr><w:vMerge w:val="restart"/></w:tcPr><w:p><w:r><w:t>tall</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>b</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:tcPr><w:vMerge/></w:tcPr><w:p/></w:tc><w:tc><w:p><w:r><w:t>d</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:r><w:t>wide</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+<w:p><w:r><w:br w:type="page"/></w:r><w:r><w:t>after</w:t></w:r></w:p>
+<w:sectPr><w:footerReference w:type="default" r:id="rId4"/><w:pgSz w:w="11906" w:h="16838"/></w:sectPr>
+</w:body></w:document>`,
+		"docProps/core.xml": `<?xml version="1.0"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:title>Report</dc:title><dc:creator>Kim</dc:creator>
+</cp:coreProperties>`,
+	})
+
+	content, err := NewDOCXReader(path).ReadDOCX()
+	if err != nil {
+		t.Fatalf("ReadDOCX() error = %v", err)
+	}
+	want := []string{
+		`heading1 [heading 1] "Title"`,
+		`paragraph [Centered] "plain styled[^1]"`,
+		`list-item1 [Normal] "bullet"`,
+		`list-item2 ordered [Normal] "number"`,
+		`table tall|b / d / wide`,
+		`page-break ""`,
+		`paragraph [Normal] "after"`,
+	}
+	if got := describeBlocks(content.Blocks); !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks = %q, want %q", got, want)
+	}
+
+	if got := content.Blocks[1].Align; got != "CENTER" {
+		t.Errorf("align = %q, want CENTER", got)
+	}
+	styled := content.Blocks[1].Runs[1]
+	if !styled.Bold || !styled.Italic || styled.Color != "#FF0000" || styled.Size != 10 || styled.CharShape != "Emphasis" {
+		t.Errorf("styled run = %+v, want bold italic #FF0000 10pt Emphasis", styled)
+	}
+	if size := content.Blocks[0].Runs[0].Size; size != 16 {
+		t.Errorf("heading size = %v, want 16", size)
+	}
+	table := content.Blocks[4].Table
+	if cell := table.Rows[0][0]; cell.RowSpan != 2 {
+		t.Errorf("merged cell row span = %d, want 2", cell.RowSpan)
+	}
+	if cell := table.Rows[2][0]; cell.ColSpan != 2 {
+		t.Errorf("merged cell column span = %d, want 2", cell.ColSpan)
+	}
+	if len(content.Notes) != 1 || content.Notes[0].Text != "the note" {
+		t.Errorf("notes = %+v, want the note", content.Notes)
+	}
+	if len(content.Footers) != 1 || content.Footers[0].Text != "Page "+interfaces.PageNumberField || content.Footers[0].Align != "RIGHT" {
+		t.Errorf("footers = %+v", content.Footers)
+	}
+	if setup := content.PageSetup; setup == nil || setup.Width != 595.3 || setup.Height != 841.9 {
+		t.Errorf("page setup = %+v, want A4", setup)
+	}
+	if content.Metadata["Title"] != "Report" || content.Metadata["Author"] != "Kim" {
+		t.Errorf("metadata = %v", content.Metadata)
+	}
+}
@@ -0,0 +1,336 @@
+package readers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"myconverter/interfaces"
+)
+
+// docxHeadingPattern matches the names of the built-in heading styles
+var docxHeadingPattern = regexp.MustCompile(`(?i)^heading\s*(\d)$`)
+
+// docxHighlights maps highlight color names to their RGB values
+var docxHighlights = map[string]string{
+	"black": "#000000", "blue": "#0000FF", "cyan": "#00FFFF", "green": "#00FF00",
+	"magenta": "#FF00FF", "red": "#FF0000", "yellow": "#FFFF00", "white": "#FFFFFF",
+	"darkBlue": "#000080", "darkCyan": "#008080", "darkGreen": "#008000", "darkMagenta": "#800080",
+	"darkRed": "#800000", "darkYellow": "#808000", "darkGray": "#808080", "lightGray": "#C0C0C0",
+}
+
+// docxStyle is a paragraph or character style of styles.xml
+type docxStyle struct {
+	Name    string
+	BasedOn string
+	// Run holds the rPr property elements of the style
+	Run []xml.StartElement
+	// Paragraph properties, empty or -1 when the style does not set them
+	Align   string
+	NumID   string
+	Level   int
+	Outline int
+}
+
+// docxStyles holds the style definitions of a DOCX package
+type docxStyles struct {
+	// Defaults holds the rPr property elements of the document defaults
+	Defaults []xml.StartElement
+	Styles   map[string]*docxStyle
+	// DefaultParagraph is the ID of the default paragraph style
+	DefaultParagraph string
+}
+
+// parseDOCXStyles parses the document defaults and styles of styles.xml
+func parseDOCXStyles(data []byte) (*docxStyles, error) {
+	styles := &docxStyles{Styles: make(map[string]*docxStyle)}
+	if len(data) == 0 {
+		return styles, nil
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	var style *docxStyle
+	var inDefaults, inRPr, inPPr bool
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse styles XML: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "rPrDefault":
+				inDefaults = true
+			case t.Name.Local == "style":
+				style = &docxStyle{Level: -1, Outline: -1}
+				styles.Styles[attrValue(t, "styleId")] = style
+				if attrValue(t, "type") == "paragraph" && docxOn(attrValue(t, "default")) && attrValue(t, "default") != "" {
+					styles.DefaultParagraph = attrValue(t, "styleId")
+				}
+			case t.Name.Local == "rPr":
+				inRPr = true
+			case t.Name.Local == "pPr":
+				inPPr = true
+			case inRPr && inDefaults:
+				styles.Defaults = append(styles.Defaults, t.Copy())
+			case inRPr && style != nil:
+				style.Run = append(style.Run, t.Copy())
+			case style != nil && t.Name.Local == "name":
+				style.Name = attrValue(t, "val")
+			case style != nil && t.Name.Local == "basedOn":
+				style.BasedOn = attrValue(t, "val")
+			case style != nil && inPPr:
+				style.setParagraphProperty(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "rPrDefault":
+				inDefaults = false
+			case "style":
+				style = nil
+			case "rPr":
+				inRPr = false
+			case "pPr":
+				inPPr = false
+			}
+		}
+	}
+
+	return styles, nil
+}
+
+// setParagraphProperty applies a pPr property element to the style
+func (s *docxStyle) setParagraphProperty(element xml.StartElement) {
+	switch element.Name.Local {
+	case "jc":
+		s.Align = attrValue(element, "val")
+	case "numId":
+		s.NumID = attrValue(element, "val")
+	case "ilvl":
+		s.Level, _ = strconv.Atoi(attrValue(element, "val"))
+	case "outlineLvl":
+		s.Outline, _ = strconv.Atoi(attrValue(element, "val"))
+	}
+}
+
+// resolve returns the style with the properties inherited through basedOn
+func (s *docxStyles) resolve(id string) docxStyle {
+	resolved := docxStyle{Level: -1, Outline: -1}
+	// Walk up the chain, the nearest style wins
+	var chain []*docxStyle
+	for seen := 0; id != "" && seen < 16; seen++ {
+		style, ok := s.Styles[id]
+		if !ok {
+			break
+		}
+		chain = append(chain, style)
+		id = style.BasedOn
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		style := chain[i]
+		resolved.Run = append(resolved.Run, style.Run...)
+		if style.Align != "" {
+			resolved.Align = style.Align
+		}
+		if style.NumID != "" {
+			resolved.NumID = style.NumID
+		}
+		if style.Level >= 0 {
+			resolved.Level = style.Level
+		}
+		if style.Outline >= 0 {
+			resolved.Outline = style.Outline
+		}
+	}
+	if len(chain) > 0 {
+		resolved.Name = chain[0].Name
+	}
+	return resolved
+}
+
+// runFormat returns the run formatting of the document defaults and a style
+func (s *docxStyles) runFormat(styleID string) interfaces.Run {
+	var run interfaces.Run
+	for _, property := range s.Defaults {
+		applyDOCXRunProperty(&run, property)
+	}
+	for _, property := range s.resolve(styleID).Run {
+		applyDOCXRunProperty(&run, property)
+	}
+	return run
+}
+
+// applyDOCXRunProperty applies an rPr property element to the run formatting
+func applyDOCXRunProperty(run *interfaces.Run, element xml.StartElement) {
+	val := attrValue(element, "val")
+	switch element.Name.Local {
+	case "b":
+		run.Bold = docxOn(val)
+	case "i":
+		run.Italic = docxOn(val)
+	case "u":
+		run.Underline = val != "" && val != "none"
+	case "strike", "dstrike":
+		run.Strike = docxOn(val)
+	case "sz":
+		if size, err := strconv.Atoi(val); err == nil {
+			// Sizes are in half points
+			run.Size = float64(size) / 2
+		}
+	case "color":
+		run.Color = docxColor(val)
+	case "highlight":
+		run.Highlight = docxHighlights[val]
+	case "shd":
+		if fill := docxColor(attrValue(element, "fill")); fill != "" {
+			run.Highlight = fill
+		}
+	case "rFonts":
+		if font := attrValue(element, "eastAsia"); font != "" {
+			run.Font = font
+		} else if font := attrValue(element, "ascii"); font != "" {
+			run.Font = font
+		}
+	}
+}
+
+// docxOn reports whether an on/off property value is on. A missing value means on.
+func docxOn(val string) bool {
+	switch strings.ToLower(val) {
+	case "0", "false", "off", "none":
+		return false
+	default:
+		return true
+	}
+}
+
+// docxColor converts an RRGGBB color value to #RRGGBB, returning "" for auto
+func docxColor(val string) string {
+	if len(val) != 6 || strings.EqualFold(val, "auto") {
+		return ""
+	}
+	return "#" + strings.ToUpper(val)
+}
+
+// docxAlign converts a w:jc value to the alignment names of the content model
+func docxAlign(val string) string {
+	switch val {
+	case "left", "start":
+		return "LEFT"
+	case "right", "end":
+		return "RIGHT"
+	case "center":
+		return "CENTER"
+	case "both":
+		return "JUSTIFY"
+	case "distribute":
+		return "DISTRIBUTE"
+	default:
+		return ""
+	}
+}
+
+// parseDOCXNumbering returns the number format of each level of each
+// numbering instance in numbering.xml
+func parseDOCXNumbering(data []byte) (map[string]map[int]string, error) {
+	numbering := make(map[string]map[int]string)
+	if len(data) == 0 {
+		return numbering, nil
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	abstracts := make(map[string]map[int]string)
+	instances := make(map[string]string)
+	var abstract, num string
+	level := 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse numbering XML: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "abstractNum":
+				abstract = attrValue(t, "abstractNumId")
+				abstracts[abstract] = make(map[int]string)
+			case "lvl":
+				level, _ = strconv.Atoi(attrValue(t, "ilvl"))
+			case "numFmt":
+				if abstract != "" {
+					abstracts[abstract][level] = attrValue(t, "val")
+				}
+			case "num":
+				num = attrValue(t, "numId")
+			case "abstractNumId":
+				if num != "" {
+					instances[num] = attrValue(t, "val")
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "abstractNum":
+				abstract = ""
+			case "num":
+				num = ""
+			}
+		}
+	}
+
+	for num, abstract := range instances {
+		numbering[num] = abstracts[abstract]
+	}
+	return numbering, nil
+}
+
+// parseDOCXCoreProperties reads the document metadata of docProps/core.xml
+func parseDOCXCoreProperties(data []byte) (map[string]string, error) {
+	keys := map[string]string{
+		"title":          "Title",
+		"creator":        "Author",
+		"subject":        "Subject",
+		"keywords":       "Keywords",
+		"description":    "Description",
+		"lastModifiedBy": "LastModifiedBy",
+		"created":        "CreationDate",
+		"modified":       "ModDate",
+	}
+
+	metadata := make(map[string]string)
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	var key string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse core properties XML: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			key = keys[t.Name.Local]
+		case xml.CharData:
+			if key != "" && strings.TrimSpace(string(t)) != "" {
+				metadata[key] += strings.TrimSpace(string(t))
+			}
+		case xml.EndElement:
+			key = ""
+		}
+	}
+	return metadata, nil
+}
@@ -0,0 +1,668 @@
+package readers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+
+	"myconverter/hwpx"
+	"myconverter/interfaces"
+)
+
+// Patterns of HTML documents
+var (
+	htmlMetaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset`)
+	htmlSpaces      = regexp.MustCompile(`[ \t\n\r\f]+`)
+	htmlEndnoteID   = regexp.MustCompile(`(?i)edn|^note-e\d+$`)
+)
+
+// htmlMetadata maps meta element names to metadata keys
+var htmlMetadata = map[string]string{
+	"author":      "Author",
+	"description": "Description",
+	"keywords":    "Keywords",
+	"subject":     "Subject",
+}
+
+// htmlSkipped lists the elements whose content is not document text
+var htmlSkipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Svg: true, atom.Math: true, atom.Select: true, atom.Textarea: true,
+}
+
+// htmlBlocks lists the elements that start and end a paragraph
+var htmlBlocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Main: true, atom.Header: true, atom.Footer: true, atom.Nav: true,
+	atom.Aside: true, atom.Address: true, atom.Center: true, atom.Figure: true,
+	atom.Figcaption: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Form: true, atom.Fieldset: true, atom.Details: true, atom.Summary: true,
+	atom.Hr: true, atom.Caption: true, atom.Body: true,
+}
+
+// HTMLReader implements FileReader for HTML pages and HTML mail bodies
+type HTMLReader struct {
+	filePath string
+	content  *interfaces.PDFContent
+
+	// classes holds the declarations of the page's style sheets by selector
+	classes map[string]string
+	// noteRefs maps note reference links to the elements defining the notes
+	noteRefs map[*html.Node]*html.Node
+	// noteDefs holds the note definitions, which are not part of the body
+	noteDefs map[*html.Node]bool
+	// noteIDs maps note definitions to the IDs of the notes already referenced
+	noteIDs map[*html.Node]string
+	images  int
+}
+
+// htmlContext accumulates the blocks of the body or of a table cell
+type htmlContext struct {
+	blocks []interfaces.Block
+	// block is the template of the paragraph being read
+	block interfaces.Block
+	runs  []interfaces.Run
+	// images are placed after the paragraph they appear in
+	images []interfaces.Block
+	// lists holds whether each open list is ordered
+	lists []bool
+	// item marks a list item with no text yet
+	item  bool
+	quote bool
+}
+
+// NewHTMLReader creates a new HTMLReader
+func NewHTMLReader(filePath string) *HTMLReader {
+	return &HTMLReader{
+		filePath: filePath,
+		content:  &interfaces.PDFContent{},
+	}
+}
+
+// Read reads the HTML file and extracts its content
+func (r *HTMLReader) Read(filePath string) error {
+	r.filePath = filePath
+	_, err := r.ReadHTML()
+	return err
+}
+
+// GetContent returns the content extracted by Read
+func (r *HTMLReader) GetContent() *interfaces.PDFContent {
+	return r.content
+}
+
+// ReadHTML parses the HTML file into the content structure
+func (r *HTMLReader) ReadHTML() (*interfaces.PDFContent, error) {
+	data, err := os.ReadFile(r.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	text, encoding := decodeHTML(data)
+	doc, err := html.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %v", err)
+	}
+
+	r.content = &interfaces.PDFContent{Metadata: map[string]string{"Encoding": encoding}}
+	r.classes = make(map[string]string)
+	r.noteRefs = make(map[*html.Node]*html.Node)
+	r.noteDefs = make(map[*html.Node]bool)
+	r.noteIDs = make(map[*html.Node]string)
+	r.images = 0
+
+	r.readHead(doc)
+	r.findNotes(doc)
+
+	ctx := &htmlContext{block: interfaces.Block{Kind: interfaces.BlockParagraph}}
+	if body := findElement(doc, atom.Body); body != nil {
+		r.walk(body, ctx, htmlFormat{})
+	}
+	ctx.endParagraph()
+
+	// Page breaks only separate content
+	blocks := ctx.blocks
+	for len(blocks) > 0 && blocks[len(blocks)-1].Kind == interfaces.BlockPageBreak {
+		blocks = blocks[:len(blocks)-1]
+	}
+	for len(blocks) > 0 && blocks[0].Kind == interfaces.BlockPageBreak {
+		blocks = blocks[1:]
+	}
+	r.content.Blocks = blocks
+
+	r.content.Text = strings.Join(interfaces.BlockLines(r.content.Blocks), "\n")
+	r.content.Pages = []interfaces.PDFPage{{Number: 1, Text: r.content.Text}}
+	return r.content, nil
+}
+
+// decodeHTML converts an HTML page to UTF-8 and returns the name of its
+// encoding. Byte order marks and meta charset declarations are trusted,
+// other pages are detected like text files.
+func decodeHTML(data []byte) (string, string) {
+	enc, name, certain := charset.DetermineEncoding(data, "")
+	if !certain && !htmlMetaCharset.Match(data[:min(len(data), 1024)]) {
+		return decodeText(data)
+	}
+	text, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return decodeText(data)
+	}
+	// The decoder keeps the byte order mark
+	return string(bytes.TrimPrefix(text, []byte("\uFEFF"))), name
+}
+
+// readHead reads the title, meta elements and style sheets of the page
+func (r *HTMLReader) readHead(doc *html.Node) {
+	if root := findElement(doc, atom.Html); root != nil {
+		if lang := attr(root, "lang"); lang != "" {
+			r.content.Metadata["Language"] = lang
+		}
+	}
+
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Title:
+				if title := strings.TrimSpace(nodeText(n, nil)); title != "" && r.content.Metadata["Title"] == "" {
+					r.content.Metadata["Title"] = title
+				}
+				return
+			case atom.Meta:
+				if key, ok := htmlMetadata[strings.ToLower(attr(n, "name"))]; ok && attr(n, "content") != "" {
+					r.content.Metadata[key] = attr(n, "content")
+				}
+			case atom.Style:
+				for selector, declarations := range parseStyleSheet(nodeText(n, nil)) {
+					r.classes[selector] += declarations
+				}
+				return
+			case atom.Svg, atom.Script:
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	visit(doc)
+}
+
+// findNotes finds note references and the elements defining the notes.
+// A note reference is a link inside a superscript, or one to a footnote
+// anchor of a word processor, whose target lies in a paragraph that links
+// back to the reference.
+func (r *HTMLReader) findNotes(doc *html.Node) {
+	targets := make(map[string]*html.Node)
+	var links []*html.Node
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if id := attr(n, "id"); id != "" {
+				targets[id] = n
+			}
+			if n.DataAtom == atom.A {
+				if name := attr(n, "name"); name != "" && targets[name] == nil {
+					targets[name] = n
+				}
+				if strings.HasPrefix(attr(n, "href"), "#") {
+					links = append(links, n)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	visit(doc)
+
+	for _, link := range links {
+		target := strings.TrimPrefix(attr(link, "href"), "#")
+		inSup := link.Parent != nil && link.Parent.DataAtom == atom.Sup
+		if !inSup && !strings.Contains(target, "ftn") && !strings.Contains(target, "edn") {
+			continue
+		}
+		node := targets[target]
+		if node == nil {
+			continue
+		}
+		def := blockAncestor(node)
+		if def == nil || def.DataAtom == atom.Body || isAncestor(def, link) || r.inNote(link) || !hasBackLink(def, anchorNames(link)) {
+			continue
+		}
+		r.noteRefs[link] = def
+		r.noteDefs[def] = true
+	}
+}
+
+// walk reads the content of an element with the inherited formatting
+func (r *HTMLReader) walk(n *html.Node, ctx *htmlContext, inherited htmlFormat) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch child.Type {
+		case html.TextNode:
+			ctx.text(child.Data, inherited)
+		case html.ElementNode:
+			r.element(child, ctx, inherited)
+		}
+	}
+}
+
+// element reads an element and its content
+func (r *HTMLReader) element(n *html.Node, ctx *htmlContext, inherited htmlFormat) {
+	if htmlSkipped[n.DataAtom] || r.noteDefs[n] {
+		return
+	}
+	if def, ok := r.noteRefs[n]; ok {
+		ctx.appendText(interfaces.NoteRef(r.noteID(n, def)), inherited.run)
+		return
+	}
+
+	format, box := r.elementFormat(n, inherited)
+	if box.hidden {
+		return
+	}
+	if box.breakBefore {
+		ctx.pageBreak()
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		ctx.appendText("\n", format.run)
+
+	case atom.Img:
+		r.images++
+		if image := loadImageSource(filepath.Dir(r.filePath), strings.TrimSpace(attr(n, "src")), r.images); image != nil {
+			ctx.images = append(ctx.images, interfaces.Block{Kind: interfaces.BlockImage, Image: image})
+		} else if alt := attr(n, "alt"); alt != "" {
+			// Images that cannot be loaded keep their description
+			ctx.text(alt, format)
+		}
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(n.Data[1:])
+		ctx.startBlock(interfaces.Block{Kind: interfaces.BlockHeading, Level: level, Align: format.align})
+		r.walk(n, ctx, format)
+		ctx.endParagraph()
+
+	case atom.Ul, atom.Ol, atom.Menu:
+		ctx.endParagraph()
+		ctx.lists = append(ctx.lists, n.DataAtom == atom.Ol)
+		r.walk(n, ctx, format)
+		ctx.endParagraph()
+		ctx.lists = ctx.lists[:len(ctx.lists)-1]
+
+	case atom.Li:
+		level := max(len(ctx.lists), 1)
+		ordered := len(ctx.lists) > 0 && ctx.lists[len(ctx.lists)-1]
+		ctx.startBlock(interfaces.Block{Kind: interfaces.BlockListItem, Level: level, Ordered: ordered, Align: format.align})
+		ctx.item = true
+		r.walk(n, ctx, format)
+		ctx.endParagraph()
+
+	case atom.Blockquote:
+		ctx.endParagraph()
+		quote := ctx.quote
+		ctx.quote = true
+		ctx.block = ctx.paragraph(format.align)
+		r.walk(n, ctx, format)
+		ctx.endParagraph()
+		ctx.quote = quote
+		ctx.block = ctx.paragraph("")
+
+	case atom.Pre:
+		block := ctx.paragraph(format.align)
+		block.Style = "Code"
+		ctx.startBlock(block)
+		r.walk(n, ctx, format)
+		ctx.endParagraph()
+
+	case atom.Table:
+		ctx.endParagraph()
+		r.table(n, ctx, format)
+
+	default:
+		if htmlBlocks[n.DataAtom] {
+			ctx.startBlock(ctx.paragraph(format.align))
+			r.walk(n, ctx, format)
+			ctx.endParagraph()
+		} else {
+			r.walk(n, ctx, format)
+		}
+	}
+
+	if box.breakAfter {
+		ctx.pageBreak()
+	}
+}
+
+// elementFormat returns the formatting of an element: the defaults of its
+// type, then the rules of its type and classes, then its style attribute
+func (r *HTMLReader) elementFormat(n *html.Node, inherited htmlFormat) (htmlFormat, htmlBox) {
+	format := inherited
+	var box htmlBox
+
+	switch n.DataAtom {
+	case atom.B, atom.Strong, atom.Th:
+		format.run.Bold = true
+	case atom.I, atom.Em, atom.Cite, atom.Var, atom.Dfn:
+		format.run.Italic = true
+	case atom.U, atom.Ins:
+		format.run.Underline = true
+	case atom.S, atom.Strike, atom.Del:
+		format.run.Strike = true
+	case atom.Code, atom.Tt, atom.Kbd, atom.Samp:
+		format.run.Font = markdownCodeFont
+	case atom.Pre:
+		format.run.Font = markdownCodeFont
+		format.preserve = true
+	case atom.Mark:
+		format.run.Highlight = "#FFFF00"
+	case atom.Center:
+		format.align = "CENTER"
+	case atom.Font:
+		if color, ok := cssColor(strings.ToLower(attr(n, "color"))); ok {
+			format.run.Color = color
+		}
+		if size, err := strconv.Atoi(strings.TrimSpace(attr(n, "size"))); err == nil {
+			format.run.Size = htmlFontSizes[min(max(size, 1), 7)-1]
+		}
+		if face := cssFontFamily(attr(n, "face")); face != "" {
+			format.run.Font = face
+		}
+	}
+	if align := cssAlign(strings.ToLower(attr(n, "align"))); align != "" {
+		format.align = align
+	}
+	if _, ok := attrValueOK(n, "hidden"); ok {
+		box.hidden = true
+	}
+
+	tag := strings.ToLower(n.Data)
+	applyDeclarations(r.classes[tag], &format, &box)
+	for _, class := range strings.Fields(attr(n, "class")) {
+		class = strings.ToLower(class)
+		applyDeclarations(r.classes["."+class], &format, &box)
+		applyDeclarations(r.classes[tag+"."+class], &format, &box)
+	}
+	applyDeclarations(attr(n, "style"), &format, &box)
+	return format, box
+}
+
+// table reads a table, keeping merged cells as spans. Cells hold the
+// blocks read from their content.
+func (r *HTMLReader) table(n *html.Node, ctx *htmlContext, format htmlFormat) {
+	var rows []*html.Node
+	var caption *html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch child.DataAtom {
+		case atom.Tr:
+			rows = append(rows, child)
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			for row := child.FirstChild; row != nil; row = row.NextSibling {
+				if row.DataAtom == atom.Tr {
+					rows = append(rows, row)
+				}
+			}
+		case atom.Caption:
+			caption = child
+		}
+	}
+	if caption != nil {
+		r.element(caption, ctx, format)
+	}
+
+	table := &interfaces.Table{}
+	occupied := make(map[[2]int]bool)
+	for rowIndex, row := range rows {
+		var cells []interfaces.TableCell
+		col := 0
+		for cellNode := row.FirstChild; cellNode != nil; cellNode = cellNode.NextSibling {
+			if cellNode.DataAtom != atom.Td && cellNode.DataAtom != atom.Th {
+				continue
+			}
+			for occupied[[2]int{rowIndex, col}] {
+				col++
+			}
+			cell := interfaces.TableCell{Row: rowIndex, Col: col, RowSpan: 1, ColSpan: 1}
+			if span, err := strconv.Atoi(attr(cellNode, "colspan")); err == nil && span > 1 {
+				cell.ColSpan = span
+			}
+			if span, err := strconv.Atoi(attr(cellNode, "rowspan")); err == nil && span > 1 {
+				cell.RowSpan = min(span, len(rows)-rowIndex)
+			}
+			for y := rowIndex; y < rowIndex+cell.RowSpan; y++ {
+				for x := col; x < col+cell.ColSpan; x++ {
+					occupied[[2]int{y, x}] = true
+				}
+			}
+
+			cellFormat, box := r.elementFormat(cellNode, format)
+			if !box.hidden {
+				cellCtx := &htmlContext{block: interfaces.Block{Kind: interfaces.BlockParagraph, Align: cellFormat.align}}
+				r.walk(cellNode, cellCtx, cellFormat)
+				cellCtx.endParagraph()
+				cell.Blocks = cellCtx.blocks
+			}
+			cells = append(cells, cell)
+			col += cell.ColSpan
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	if len(table.Rows) > 0 {
+		ctx.blocks = append(ctx.blocks, interfaces.Block{Kind: interfaces.BlockTable, Table: table})
+	}
+}
+
+// noteID returns the ID of the note a reference link points to, adding the
+// note on its first reference
+func (r *HTMLReader) noteID(link, def *html.Node) string {
+	if id, ok := r.noteIDs[def]; ok {
+		return id
+	}
+
+	kind := interfaces.Footnote
+	if htmlEndnoteID.MatchString(strings.TrimPrefix(attr(link, "href"), "#")) {
+		kind = interfaces.Endnote
+	}
+	count := 0
+	for _, note := range r.content.Notes {
+		if note.Kind == kind {
+			count++
+		}
+	}
+
+	// The note text leaves out the links back to the reference
+	text := nodeText(def, func(n *html.Node) bool {
+		return n.DataAtom == atom.A && strings.HasPrefix(attr(n, "href"), "#")
+	})
+	label := strings.Trim(strings.TrimSpace(nodeText(link, nil)), "[]()")
+
+	note := interfaces.Note{Kind: kind, ID: strconv.Itoa(count + 1), Label: label, Text: strings.TrimSpace(htmlSpaces.ReplaceAllString(text, " "))}
+	if kind == interfaces.Endnote {
+		note.ID = "e" + note.ID
+		if note.Label == "" {
+			note.Label = hwpx.FormatNumber("ROMAN_SMALL", count+1)
+		}
+	}
+	if note.Label == "" {
+		note.Label = note.ID
+	}
+	r.noteIDs[def] = note.ID
+	r.content.Notes = append(r.content.Notes, note)
+	return note.ID
+}
+
+// paragraph returns the template of a paragraph in the context
+func (ctx *htmlContext) paragraph(align string) interfaces.Block {
+	block := interfaces.Block{Kind: interfaces.BlockParagraph, Align: align}
+	if ctx.quote {
+		block.Style = "Quote"
+	}
+	return block
+}
+
+// startBlock ends the paragraph being read and starts one with the
+// template. The first paragraph inside a list item continues the item.
+func (ctx *htmlContext) startBlock(block interfaces.Block) {
+	if ctx.item && len(ctx.runs) == 0 && block.Kind == interfaces.BlockParagraph {
+		if block.Align != "" {
+			ctx.block.Align = block.Align
+		}
+		return
+	}
+	ctx.endParagraph()
+	ctx.block = block
+}
+
+// endParagraph adds the paragraph being read, followed by its images
+func (ctx *htmlContext) endParagraph() {
+	block := ctx.block
+	block.Runs = trimRuns(ctx.runs)
+	if len(block.Runs) > 0 {
+		ctx.blocks = append(ctx.blocks, block)
+		ctx.item = false
+	}
+	ctx.blocks = append(ctx.blocks, ctx.images...)
+	ctx.runs, ctx.images = nil, nil
+	ctx.block = ctx.paragraph("")
+}
+
+// pageBreak ends the paragraph being read and adds a page break
+func (ctx *htmlContext) pageBreak() {
+	ctx.endParagraph()
+	if n := len(ctx.blocks); n == 0 || ctx.blocks[n-1].Kind != interfaces.BlockPageBreak {
+		ctx.blocks = append(ctx.blocks, interfaces.Block{Kind: interfaces.BlockPageBreak})
+	}
+}
+
+// text adds the text of a text node. Unless white space is preserved, runs
+// of white space collapse to a single space, dropped at line starts.
+func (ctx *htmlContext) text(text string, format htmlFormat) {
+	if format.preserve {
+		ctx.appendText(strings.ReplaceAll(text, "\r\n", "\n"), format.run)
+		return
+	}
+	text = htmlSpaces.ReplaceAllString(text, " ")
+	if strings.HasPrefix(text, " ") {
+		if n := len(ctx.runs); n == 0 || strings.HasSuffix(ctx.runs[n-1].Text, " ") || strings.HasSuffix(ctx.runs[n-1].Text, "\n") {
+			text = text[1:]
+		}
+	}
+	ctx.appendText(text, format.run)
+}
+
+// appendText adds text with the formatting of a run
+func (ctx *htmlContext) appendText(text string, format interfaces.Run) {
+	if text != "" {
+		ctx.runs = appendRun(ctx.runs, text, format)
+	}
+}
+
+// findElement returns the first element of a type in document order
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nodeText returns the text of a node, leaving out the nodes skip reports
+func nodeText(n *html.Node, skip func(*html.Node) bool) string {
+	if skip != nil && skip(n) {
+		return ""
+	}
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var text strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		text.WriteString(nodeText(child, skip))
+	}
+	return text.String()
+}
+
+// attr returns the value of an element's attribute, "" when absent
+func attr(n *html.Node, name string) string {
+	value, _ := attrValueOK(n, name)
+	return value
+}
+
+// attrValueOK returns the value of an element's attribute and whether it is present
+func attrValueOK(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && strings.EqualFold(a.Key, name) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// blockAncestor returns the nearest paragraph-like element containing n, n included
+func blockAncestor(n *html.Node) *html.Node {
+	for ; n != nil; n = n.Parent {
+		if n.Type == html.ElementNode && (htmlBlocks[n.DataAtom] || n.DataAtom == atom.Li) {
+			return n
+		}
+	}
+	return nil
+}
+
+// isAncestor reports whether ancestor contains n
+func isAncestor(ancestor, n *html.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// inNote reports whether a node lies in a note definition
+func (r *HTMLReader) inNote(n *html.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if r.noteDefs[n] {
+			return true
+		}
+	}
+	return false
+}
+
+// anchorNames returns the names a link back to a reference may use: the
+// IDs and names of the reference link and of its inline ancestors
+func anchorNames(link *html.Node) map[string]bool {
+	names := make(map[string]bool)
+	for n := link; n != nil && n.Type == html.ElementNode && !htmlBlocks[n.DataAtom]; n = n.Parent {
+		for _, key := range []string{"id", "name"} {
+			if value := attr(n, key); value != "" {
+				names[value] = true
+			}
+		}
+	}
+	return names
+}
+
+// hasBackLink reports whether an element contains a link to one of the anchors
+func hasBackLink(n *html.Node, names map[string]bool) bool {
+	if n.Type == html.ElementNode && n.DataAtom == atom.A && names[strings.TrimPrefix(attr(n, "href"), "#")] {
+		return true
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if hasBackLink(child, names) {
+			return true
+		}
+	}
+	return false
+}
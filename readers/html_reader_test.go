@@ -0,0 +1,57 @@
+package readers
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"myconverter/interfaces"
+)
+
+func TestHTMLImageSources(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	dir := filepath.Join(root, "doc")
+	for path, data := range map[string][]byte{
+		filepath.Join(root, "secret.png"):         buf.Bytes(),
+		filepath.Join(dir, "images", "local.png"): buf.Bytes(),
+		filepath.Join(dir, "notes.txt"):           []byte("not a picture"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source := `<img src="/etc/hostname"><img src="../../etc/passwd">` +
+		`<img src="../secret.png"><img src="images/../../secret.png">` +
+		`<img src="` + filepath.ToSlash(filepath.Join(root, "secret.png")) + `">` +
+		`<img src="file://` + filepath.ToSlash(filepath.Join(root, "secret.png")) + `">` +
+		`<img src="notes.txt"><img src="images/local.png"><img src="images/%6Cocal.png">`
+	path := filepath.Join(dir, "test.html")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content, err := NewHTMLReader(path).ReadHTML()
+	if err != nil {
+		t.Fatalf("ReadHTML() error = %v", err)
+	}
+	var got []string
+	for _, block := range content.Blocks {
+		if block.Kind == interfaces.BlockImage {
+			got = append(got, block.Image.Name)
+		}
+	}
+	if want := []string{"local.png", "local.png"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("images = %q, want %q", got, want)
+	}
+}
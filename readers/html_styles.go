@@ -0,0 +1,272 @@
+package readers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"myconverter/interfaces"
+)
+
+// Patterns of CSS syntax
+var (
+	cssComment  = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssSelector = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*)?(?:\.([A-Za-z0-9_-]+))?$`)
+	cssRGB      = regexp.MustCompile(`(?i)^rgba?\(\s*([\d.]+%?)[\s,]+([\d.]+%?)[\s,]+([\d.]+%?)(?:[\s,/]+([\d.]+%?))?\s*\)$`)
+)
+
+// cssColors maps CSS color names to #RRGGBB values
+var cssColors = map[string]string{
+	"black": "#000000", "white": "#FFFFFF", "red": "#FF0000", "green": "#008000",
+	"blue": "#0000FF", "yellow": "#FFFF00", "gray": "#808080", "grey": "#808080",
+	"silver": "#C0C0C0", "maroon": "#800000", "navy": "#000080", "purple": "#800080",
+	"teal": "#008080", "olive": "#808000", "orange": "#FFA500", "fuchsia": "#FF00FF",
+	"magenta": "#FF00FF", "aqua": "#00FFFF", "cyan": "#00FFFF", "lime": "#00FF00",
+	"brown": "#A52A2A", "pink": "#FFC0CB", "gold": "#FFD700", "darkred": "#8B0000",
+	"darkblue": "#00008B", "darkgreen": "#006400", "darkgray": "#A9A9A9", "lightgray": "#D3D3D3",
+}
+
+// cssFontSizes maps CSS absolute size keywords to points
+var cssFontSizes = map[string]float64{
+	"xx-small": 7, "x-small": 7.5, "small": 10, "medium": 12,
+	"large": 13.5, "x-large": 18, "xx-large": 24, "xxx-large": 36,
+}
+
+// htmlFontSizes maps the size attribute of font elements to points
+var htmlFontSizes = []float64{7.5, 10, 12, 13.5, 18, 24, 36}
+
+// htmlFormat is the inherited formatting of an element's text
+type htmlFormat struct {
+	run   interfaces.Run
+	align string
+	// preserve keeps the white space and line breaks of text
+	preserve bool
+}
+
+// htmlBox holds the properties of an element that are not inherited
+type htmlBox struct {
+	hidden      bool
+	breakBefore bool
+	breakAfter  bool
+}
+
+// parseStyleSheet returns the declarations of the type, class and
+// type.class selectors of a style sheet. Other selectors and at-rules are
+// ignored.
+func parseStyleSheet(css string) map[string]string {
+	rules := make(map[string]string)
+	css = cssComment.ReplaceAllString(css, "")
+	for i := 0; i < len(css); {
+		open := strings.IndexByte(css[i:], '{')
+		if open < 0 {
+			break
+		}
+		selectors := strings.TrimSpace(css[i : i+open])
+		i += open + 1
+
+		if strings.HasPrefix(selectors, "@") {
+			// At-rules like @media nest their own blocks
+			depth := 1
+			for ; i < len(css) && depth > 0; i++ {
+				switch css[i] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+				}
+			}
+			continue
+		}
+
+		end := strings.IndexByte(css[i:], '}')
+		if end < 0 {
+			end = len(css) - i
+		}
+		declarations := css[i : i+end]
+		i += end + 1
+
+		for _, selector := range strings.Split(selectors, ",") {
+			selector = strings.TrimSpace(selector)
+			if selector != "" && cssSelector.MatchString(selector) {
+				key := strings.ToLower(selector)
+				rules[key] += declarations + ";"
+			}
+		}
+	}
+	return rules
+}
+
+// applyDeclarations applies CSS declarations to the formatting of an element
+func applyDeclarations(declarations string, format *htmlFormat, box *htmlBox) {
+	for _, declaration := range strings.Split(declarations, ";") {
+		name, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		lower := strings.ToLower(value)
+
+		switch name {
+		case "font-weight":
+			if n, err := strconv.Atoi(lower); err == nil {
+				format.run.Bold = n >= 600
+			} else {
+				format.run.Bold = lower == "bold" || lower == "bolder"
+			}
+		case "font-style":
+			format.run.Italic = lower == "italic" || lower == "oblique"
+		case "text-decoration", "text-decoration-line":
+			if lower == "none" {
+				format.run.Underline, format.run.Strike = false, false
+				break
+			}
+			if strings.Contains(lower, "underline") {
+				format.run.Underline = true
+			}
+			if strings.Contains(lower, "line-through") {
+				format.run.Strike = true
+			}
+		case "color":
+			if color, ok := cssColor(lower); ok {
+				format.run.Color = color
+			}
+		case "background-color", "background":
+			for _, part := range strings.Fields(lower) {
+				if color, ok := cssColor(part); ok {
+					format.run.Highlight = color
+					break
+				}
+			}
+		case "font-size":
+			if size := cssFontSize(lower, format.run.Size); size > 0 {
+				format.run.Size = size
+			}
+		case "font-family":
+			if font := cssFontFamily(value); font != "" {
+				format.run.Font = font
+			}
+		case "text-align":
+			format.align = cssAlign(lower)
+		case "white-space":
+			format.preserve = strings.HasPrefix(lower, "pre") || lower == "break-spaces"
+		case "display":
+			box.hidden = lower == "none"
+		case "visibility":
+			box.hidden = lower == "hidden"
+		case "break-before", "page-break-before":
+			box.breakBefore = lower == "page" || lower == "always"
+		case "break-after", "page-break-after":
+			box.breakAfter = lower == "page" || lower == "always"
+		}
+	}
+}
+
+// cssColor returns a CSS color as #RRGGBB. Transparent colors are reported
+// as found but empty.
+func cssColor(value string) (string, bool) {
+	switch {
+	case value == "transparent":
+		return "", true
+	case strings.HasPrefix(value, "#") && len(value) == 4:
+		return strings.ToUpper("#" + strings.Repeat(value[1:2], 2) + strings.Repeat(value[2:3], 2) + strings.Repeat(value[3:4], 2)), true
+	case strings.HasPrefix(value, "#") && len(value) == 7:
+		if _, err := strconv.ParseUint(value[1:], 16, 32); err == nil {
+			return strings.ToUpper(value), true
+		}
+	case cssRGB.MatchString(value):
+		match := cssRGB.FindStringSubmatch(value)
+		if match[4] != "" && cssComponent(match[4], 1) == 0 {
+			return "", true
+		}
+		return fmt.Sprintf("#%02X%02X%02X",
+			int(math.Round(cssComponent(match[1], 255))),
+			int(math.Round(cssComponent(match[2], 255))),
+			int(math.Round(cssComponent(match[3], 255)))), true
+	}
+	color, ok := cssColors[value]
+	return color, ok
+}
+
+// cssComponent returns a color component, percentages being relative to full
+func cssComponent(value string, full float64) float64 {
+	if strings.HasSuffix(value, "%") {
+		n, _ := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		return math.Min(n/100*full, full)
+	}
+	n, _ := strconv.ParseFloat(value, 64)
+	return math.Min(n, full)
+}
+
+// cssFontSize returns a CSS font size in points, relative sizes being
+// relative to the parent size (12pt when unknown). It returns 0 for sizes
+// it does not understand.
+func cssFontSize(value string, parent float64) float64 {
+	if parent == 0 {
+		parent = 12
+	}
+	if size, ok := cssFontSizes[value]; ok {
+		return size
+	}
+	switch value {
+	case "smaller":
+		return roundHalf(parent / 1.2)
+	case "larger":
+		return roundHalf(parent * 1.2)
+	}
+
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"pt", 1}, {"px", 0.75}, {"rem", 12}, {"em", parent}, {"%", parent / 100},
+		{"pc", 12}, {"mm", 72 / 25.4}, {"cm", 72 / 2.54}, {"in", 72},
+	}
+	for _, unit := range units {
+		if n, err := strconv.ParseFloat(strings.TrimSuffix(value, unit.suffix), 64); strings.HasSuffix(value, unit.suffix) && err == nil && n > 0 {
+			return roundHalf(n * unit.scale)
+		}
+	}
+	return 0
+}
+
+// roundHalf rounds a font size to half points
+func roundHalf(size float64) float64 {
+	return math.Round(size*2) / 2
+}
+
+// cssFontFamily returns the first specific font of a font-family list.
+// Generic families name no font, except monospace for code.
+func cssFontFamily(value string) string {
+	for _, family := range strings.Split(value, ",") {
+		family = strings.Trim(strings.TrimSpace(family), `"'`)
+		switch strings.ToLower(family) {
+		case "":
+			continue
+		case "monospace":
+			return markdownCodeFont
+		case "serif", "sans-serif", "cursive", "fantasy", "system-ui", "inherit", "initial":
+			return ""
+		}
+		return family
+	}
+	return ""
+}
+
+// cssAlign returns the paragraph alignment of a CSS text alignment
+func cssAlign(value string) string {
+	switch value {
+	case "left", "start":
+		return "LEFT"
+	case "right", "end":
+		return "RIGHT"
+	case "center":
+		return "CENTER"
+	case "justify":
+		return "JUSTIFY"
+	default:
+		return ""
+	}
+}
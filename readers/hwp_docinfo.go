@@ -0,0 +1,213 @@
+package readers
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"myconverter/interfaces"
+)
+
+// HWP DocInfo record tag IDs
+const (
+	hwpTagBinData   = hwpTagBegin + 2
+	hwpTagFaceName  = hwpTagBegin + 3
+	hwpTagCharShape = hwpTagBegin + 5
+	hwpTagParaShape = hwpTagBegin + 9
+	hwpTagStyle     = hwpTagBegin + 10
+)
+
+// hwpHeadingTypes maps paragraph shape heading codes to their HWPX names
+var hwpHeadingTypes = []string{"NONE", "OUTLINE", "NUMBER", "BULLET"}
+
+// hwpAlignments maps paragraph shape alignment codes to their HWPX names
+var hwpAlignments = []string{"JUSTIFY", "LEFT", "RIGHT", "CENTER", "DISTRIBUTE", "DISTRIBUTE_SPACE", "JUSTIFY", "JUSTIFY"}
+
+// hwpDocInfo holds the shape, style and binary data definitions of the DocInfo stream
+type hwpDocInfo struct {
+	// FaceNames lists the font faces, Hangul faces first
+	FaceNames  []string
+	CharShapes []interfaces.Run
+	ParaShapes []paraShape
+	Styles     []paraStyle
+	BinData    []hwpBinData
+}
+
+// hwpBinData describes a binary data item stored in the BinData storage
+type hwpBinData struct {
+	ID  int
+	Ext string
+	// Compression is 0 to follow the document, 1 for compressed and 2 for uncompressed
+	Compression int
+}
+
+// StreamName returns the name of the stream holding the binary data
+func (b hwpBinData) StreamName() string {
+	return fmt.Sprintf("BIN%04X.%s", b.ID, b.Ext)
+}
+
+// parseHWPDocInfo parses the records of the DocInfo stream
+func parseHWPDocInfo(data []byte) (*hwpDocInfo, error) {
+	records, err := parseHWPRecords(data)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &hwpDocInfo{}
+	var visit func(records []*hwpRecord)
+	visit = func(records []*hwpRecord) {
+		for _, record := range records {
+			switch record.TagID {
+			case hwpTagFaceName:
+				name := ""
+				if len(record.Data) > 1 {
+					name, _ = hwpString(record.Data[1:])
+				}
+				info.FaceNames = append(info.FaceNames, name)
+			case hwpTagCharShape:
+				info.CharShapes = append(info.CharShapes, info.charShape(record.Data))
+			case hwpTagParaShape:
+				info.ParaShapes = append(info.ParaShapes, hwpParaShape(record.Data))
+			case hwpTagStyle:
+				info.Styles = append(info.Styles, hwpStyle(record.Data))
+			case hwpTagBinData:
+				info.BinData = append(info.BinData, hwpBinDataItem(record.Data))
+			}
+			visit(record.Children)
+		}
+	}
+	visit(records)
+
+	return info, nil
+}
+
+// charShape reads the run formatting of a CHAR_SHAPE record
+func (info *hwpDocInfo) charShape(data []byte) interfaces.Run {
+	run := interfaces.Run{CharShape: strconv.Itoa(len(info.CharShapes))}
+	// Face names, ratios, spacings, relative sizes and offsets precede the base size
+	if len(data) < 50 {
+		return run
+	}
+
+	if face := int(binary.LittleEndian.Uint16(data)); face < len(info.FaceNames) {
+		run.Font = info.FaceNames[face]
+	}
+	run.Size = float64(int32(binary.LittleEndian.Uint32(data[42:]))) / 100
+
+	properties := binary.LittleEndian.Uint32(data[46:])
+	run.Italic = properties&0x01 != 0
+	run.Bold = properties&0x02 != 0
+	run.Underline = (properties>>2)&0x03 != 0
+	run.Strike = (properties>>18)&0x07 != 0
+
+	// Text, underline, shade and shadow colors follow the shadow gaps
+	if len(data) >= 64 {
+		run.Color = hwpColor(binary.LittleEndian.Uint32(data[52:]))
+		if shade := binary.LittleEndian.Uint32(data[60:]); shade != 0xFFFFFFFF && shade != 0x00FFFFFF {
+			run.Highlight = hwpColor(shade)
+		}
+	}
+	return run
+}
+
+// hwpColor converts a COLORREF (0x00BBGGRR) to a #RRGGBB color
+func hwpColor(value uint32) string {
+	return fmt.Sprintf("#%02X%02X%02X", value&0xFF, (value>>8)&0xFF, (value>>16)&0xFF)
+}
+
+// hwpParaShape reads the heading settings of a PARA_SHAPE record
+func hwpParaShape(data []byte) paraShape {
+	if len(data) < 4 {
+		return paraShape{HeadingType: "NONE"}
+	}
+
+	properties := binary.LittleEndian.Uint32(data)
+	return paraShape{
+		HeadingType:  hwpHeadingTypes[(properties>>23)&0x03],
+		HeadingLevel: int(properties>>25) & 0x07,
+		Align:        hwpAlignments[(properties>>2)&0x07],
+	}
+}
+
+// hwpStyle reads the names of a STYLE record
+func hwpStyle(data []byte) paraStyle {
+	name, rest := hwpString(data)
+	engName, _ := hwpString(rest)
+	return paraStyle{Name: name, EngName: engName}
+}
+
+// hwpBinDataItem reads a BIN_DATA record
+func hwpBinDataItem(data []byte) hwpBinData {
+	if len(data) < 2 {
+		return hwpBinData{}
+	}
+
+	properties := binary.LittleEndian.Uint16(data)
+	item := hwpBinData{Compression: int(properties>>4) & 0x03}
+	// Linked files (type 0) are not stored in the document
+	if properties&0x0F == 0 || len(data) < 4 {
+		return item
+	}
+	item.ID = int(binary.LittleEndian.Uint16(data[2:]))
+	item.Ext, _ = hwpString(data[4:])
+	return item
+}
+
+// hwpString reads a length-prefixed UTF-16 string and returns the remaining data
+func hwpString(data []byte) (string, []byte) {
+	if len(data) < 2 {
+		return "", nil
+	}
+	length := int(binary.LittleEndian.Uint16(data))
+	data = data[2:]
+	if len(data) < length*2 {
+		return "", nil
+	}
+
+	units := make([]uint16, length)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(units)), data[length*2:]
+}
+
+// hwpStream returns the content of a stream, decompressing it when needed
+func hwpStream(entry CFBEntry, compressed bool) ([]byte, error) {
+	if !compressed {
+		return entry.Content, nil
+	}
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(entry.Content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %v", entry.Path, err)
+	}
+	return data, nil
+}
+
+// hwpImage loads the picture stored for a 1-based binary data item ID
+func hwpImage(entries []CFBEntry, info *hwpDocInfo, itemID int, compressed bool) *interfaces.Image {
+	if info == nil || itemID < 1 || itemID > len(info.BinData) {
+		return nil
+	}
+	item := info.BinData[itemID-1]
+	if item.ID == 0 {
+		return nil
+	}
+
+	name := item.StreamName()
+	for _, entry := range entries {
+		if !strings.EqualFold(entry.Path, "BinData/"+name) {
+			continue
+		}
+		data, err := hwpStream(entry, item.Compression == 1 || (item.Compression == 0 && compressed))
+		if err != nil {
+			return nil
+		}
+		return &interfaces.Image{Name: strings.ToLower(name), Data: data}
+	}
+	return nil
+}
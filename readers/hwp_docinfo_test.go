@@ -0,0 +1,159 @@
+package readers
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+
+	"myconverter/interfaces"
+)
+
+// hwpTestString returns a string as a length-prefixed UTF-16 string
+func hwpTestString(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	data := binary.LittleEndian.AppendUint16(nil, uint16(len(units)))
+	for _, unit := range units {
+		data = binary.LittleEndian.AppendUint16(data, unit)
+	}
+	return data
+}
+
+// hwpTestRecord returns a record with its header
+func hwpTestRecord(tag uint16, level int, data []byte) []byte {
+	header := uint32(tag) | uint32(level)<<10 | uint32(len(data))<<20
+	return append(binary.LittleEndian.AppendUint32(nil, header), data...)
+}
+
+// hwpTestCharShape returns a CHAR_SHAPE record of a bold 12pt run in the
+// second face, red on yellow
+func hwpTestCharShape() []byte {
+	data := make([]byte, 72)
+	binary.LittleEndian.PutUint16(data, 1)
+	binary.LittleEndian.PutUint32(data[42:], 1200)
+	binary.LittleEndian.PutUint32(data[46:], 0x02)
+	binary.LittleEndian.PutUint32(data[52:], 0x0000FF)
+	binary.LittleEndian.PutUint32(data[60:], 0x00FFFF)
+	return data
+}
+
+func TestHWPString(t *testing.T) {
+	encoded := hwpTestString("본문")
+	tests := []struct {
+		name string
+		data []byte
+		want string
+		rest []byte
+	}{
+		{name: "string", data: append(append([]byte{}, encoded...), 0xAA), want: "본문", rest: []byte{0xAA}},
+		{name: "empty string", data: []byte{0, 0, 0xAA}, want: "", rest: []byte{0xAA}},
+		{name: "no data", data: nil},
+		{name: "half a length", data: []byte{2}},
+		{name: "length only", data: encoded[:2]},
+		{name: "missing a unit", data: encoded[:4]},
+		{name: "odd byte", data: encoded[:5]},
+	}
+	for _, tt := range tests {
+		got, rest := hwpString(tt.data)
+		if got != tt.want || len(rest) != len(tt.rest) || (len(rest) > 0 && !reflect.DeepEqual(rest, tt.rest)) {
+			t.Errorf("%s: hwpString(% X) = %q, % X, want %q, % X", tt.name, tt.data, got, rest, tt.want, tt.rest)
+		}
+	}
+}
+
+func TestHWPCharShape(t *testing.T) {
+	info := &hwpDocInfo{FaceNames: []string{"바탕", "돋움"}}
+	full := hwpTestCharShape()
+	tests := []struct {
+		name string
+		data []byte
+		want interfaces.Run
+	}{
+		{name: "full", data: full, want: interfaces.Run{CharShape: "0", Font: "돋움", Size: 12, Bold: true, Color: "#FF0000", Highlight: "#FFFF00"}},
+		{name: "without colors", data: full[:63], want: interfaces.Run{CharShape: "0", Font: "돋움", Size: 12, Bold: true}},
+		{name: "without properties", data: full[:49], want: interfaces.Run{CharShape: "0"}},
+		{name: "face only", data: full[:2], want: interfaces.Run{CharShape: "0"}},
+		{name: "empty", data: nil, want: interfaces.Run{CharShape: "0"}},
+		{name: "unknown face", data: append([]byte{9, 0}, full[2:]...), want: interfaces.Run{CharShape: "0", Size: 12, Bold: true, Color: "#FF0000", Highlight: "#FFFF00"}},
+	}
+	for _, tt := range tests {
+		if got := info.charShape(tt.data); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: charShape() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHWPDocInfoRecords(t *testing.T) {
+	style := append(hwpTestString("바탕글"), hwpTestString("Normal")...)
+	binData := append([]byte{0x01, 0x00, 0x01, 0x00}, hwpTestString("png")...)
+	tests := []struct {
+		name string
+		data []byte
+		want *hwpDocInfo
+	}{
+		{
+			name: "records",
+			data: concat(
+				hwpTestRecord(hwpTagFaceName, 1, append([]byte{0}, hwpTestString("바탕")...)),
+				hwpTestRecord(hwpTagFaceName, 1, append([]byte{0}, hwpTestString("돋움")...)),
+				hwpTestRecord(hwpTagCharShape, 1, hwpTestCharShape()),
+				hwpTestRecord(hwpTagStyle, 1, style),
+				hwpTestRecord(hwpTagBinData, 1, binData),
+			),
+			want: &hwpDocInfo{
+				FaceNames:  []string{"바탕", "돋움"},
+				CharShapes: []interfaces.Run{{CharShape: "0", Font: "돋움", Size: 12, Bold: true, Color: "#FF0000", Highlight: "#FFFF00"}},
+				Styles:     []paraStyle{{Name: "바탕글", EngName: "Normal"}},
+				BinData:    []hwpBinData{{ID: 1, Ext: "png"}},
+			},
+		},
+		{
+			// Records cut short inside their data keep what they hold
+			name: "truncated records",
+			data: concat(
+				hwpTestRecord(hwpTagFaceName, 1, []byte{0}),
+				hwpTestRecord(hwpTagFaceName, 1, append([]byte{0}, hwpTestString("돋움")[:3]...)),
+				hwpTestRecord(hwpTagCharShape, 1, hwpTestCharShape()[:30]),
+				hwpTestRecord(hwpTagParaShape, 1, []byte{1, 2}),
+				hwpTestRecord(hwpTagStyle, 1, style[:9]),
+				hwpTestRecord(hwpTagBinData, 1, binData[:3]),
+				hwpTestRecord(hwpTagBinData, 1, binData[:1]),
+			),
+			want: &hwpDocInfo{
+				FaceNames:  []string{"", ""},
+				CharShapes: []interfaces.Run{{CharShape: "0"}},
+				ParaShapes: []paraShape{{HeadingType: "NONE"}},
+				Styles:     []paraStyle{{Name: "바탕글"}},
+				BinData:    []hwpBinData{{}, {}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseHWPDocInfo(tt.data)
+		if err != nil {
+			t.Errorf("%s: parseHWPDocInfo() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseHWPDocInfo() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// A stream cut inside a record is an error; a partial header is ignored
+	data := hwpTestRecord(hwpTagCharShape, 1, hwpTestCharShape())
+	if _, err := parseHWPDocInfo(data[:10]); err == nil {
+		t.Error("parseHWPDocInfo() accepted a truncated record")
+	}
+	if info, err := parseHWPDocInfo(data[:2]); err != nil || len(info.CharShapes) > 0 {
+		t.Errorf("parseHWPDocInfo() of a partial header = %+v, %v", info, err)
+	}
+}
+
+// concat joins byte slices
+func concat(parts ...[]byte) []byte {
+	var data []byte
+	for _, part := range parts {
+		data = append(data, part...)
+	}
+	return data
+}
@@ -0,0 +1,652 @@
+package readers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"myconverter/hwpx"
+	"myconverter/interfaces"
+)
+
+// HWP record tag IDs used by the body text parser
+const (
+	hwpTagBegin      = 0x10
+	hwpTagParaHeader = hwpTagBegin + 50
+	hwpTagParaText   = hwpTagBegin + 51
+	hwpTagCtrlHeader = hwpTagBegin + 55
+	hwpTagListHeader = hwpTagBegin + 56
+	hwpTagPageDef    = hwpTagBegin + 57
+	hwpTagNoteShape  = hwpTagBegin + 58
+	hwpTagTable      = hwpTagBegin + 61
+	hwpTagPicture    = hwpTagBegin + 69
+	hwpTagCharShapes = hwpTagBegin + 52
+)
+
+// HWP control IDs
+const (
+	hwpCtrlHeader     = 'h'<<24 | 'e'<<16 | 'a'<<8 | 'd'
+	hwpCtrlFooter     = 'f'<<24 | 'o'<<16 | 'o'<<8 | 't'
+	hwpCtrlAutoNum    = 'a'<<24 | 't'<<16 | 'n'<<8 | 'o'
+	hwpCtrlPageNumPos = 'p'<<24 | 'g'<<16 | 'n'<<8 | 'p'
+	hwpCtrlSectionDef = 's'<<24 | 'e'<<16 | 'c'<<8 | 'd'
+	hwpCtrlFootnote   = 'f'<<24 | 'n'<<16 | ' '<<8 | ' '
+	hwpCtrlEndnote    = 'e'<<24 | 'n'<<16 | ' '<<8 | ' '
+	hwpCtrlTable      = 't'<<24 | 'b'<<16 | 'l'<<8 | ' '
+	hwpCtrlShape      = 'g'<<24 | 's'<<16 | 'o'<<8 | ' '
+)
+
+// HWPContent represents the text content extracted from the BodyText streams of an HWP file
+type HWPContent struct {
+	Text    []string
+	Blocks  []interfaces.Block
+	Headers []interfaces.HeaderFooter
+	Footers []interfaces.HeaderFooter
+	Notes   []interfaces.Note
+	// Numbering of footnotes and endnotes from the section definition
+	FootnotesDef hwpx.FootnotesDefType
+	EndnotesDef  hwpx.EndnotesDefType
+	// PageSetup is the page definition of the first section
+	PageSetup *interfaces.PageSetup
+
+	entries    []CFBEntry
+	info       *hwpDocInfo
+	compressed bool
+}
+
+// hwpRecord is a single record of an HWP stream with its nested records
+type hwpRecord struct {
+	TagID    uint16
+	Level    int
+	Data     []byte
+	Children []*hwpRecord
+}
+
+// ExtractHWPContent extracts text, headers and footers from the entries of a read HWP file
+func ExtractHWPContent(r *CFBReader) (*HWPContent, error) {
+	content := HWPContent{entries: r.Entries, info: &hwpDocInfo{}}
+
+	compressed, err := hwpCompressed(r.Entries)
+	if err != nil {
+		return nil, err
+	}
+	content.compressed = compressed
+
+	// Collect BodyText/SectionN streams in section order
+	var sections []CFBEntry
+	for _, entry := range r.Entries {
+		if strings.HasPrefix(entry.Path, "BodyText/Section") {
+			sections = append(sections, entry)
+		}
+		if entry.Path == "DocInfo" {
+			data, err := hwpStream(entry, compressed)
+			if err != nil {
+				return nil, err
+			}
+			if content.info, err = parseHWPDocInfo(data); err != nil {
+				return nil, fmt.Errorf("failed to parse DocInfo: %v", err)
+			}
+		}
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		return hwpSectionIndex(sections[i].Name) < hwpSectionIndex(sections[j].Name)
+	})
+
+	for _, section := range sections {
+		data, err := hwpStream(section, compressed)
+		if err != nil {
+			return nil, err
+		}
+
+		records, err := parseHWPRecords(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", section.Path, err)
+		}
+
+		// Sections start on a new page
+		if len(content.Blocks) > 0 {
+			content.Blocks = append(content.Blocks, interfaces.Block{Kind: interfaces.BlockPageBreak, Section: true})
+		}
+		for _, record := range records {
+			if record.TagID != hwpTagParaHeader {
+				continue
+			}
+			if hwpPageBreak(record) && len(content.Blocks) > 0 {
+				content.Blocks = append(content.Blocks, interfaces.Block{Kind: interfaces.BlockPageBreak})
+			}
+			content.Blocks = append(content.Blocks, content.paragraph(record)...)
+		}
+	}
+
+	content.Text = interfaces.BlockLines(content.Blocks)
+	content.labelNotes()
+	return &content, nil
+}
+
+// hwpCompressed reads the FileHeader stream and reports whether streams are compressed
+func hwpCompressed(entries []CFBEntry) (bool, error) {
+	for _, entry := range entries {
+		if entry.Path != "FileHeader" {
+			continue
+		}
+		if len(entry.Content) < 40 || !strings.HasPrefix(string(entry.Content), "HWP Document File") {
+			return false, fmt.Errorf("invalid HWP file header")
+		}
+
+		properties := binary.LittleEndian.Uint32(entry.Content[36:40])
+		if properties&0x02 != 0 {
+			return false, fmt.Errorf("password protected HWP files are not supported")
+		}
+		if properties&0x04 != 0 {
+			return false, fmt.Errorf("distribution HWP files are not supported")
+		}
+		return properties&0x01 != 0, nil
+	}
+	return false, fmt.Errorf("HWP file header not found")
+}
+
+// hwpSectionIndex returns the number of a SectionN stream name
+func hwpSectionIndex(name string) int {
+	index, err := strconv.Atoi(strings.TrimPrefix(name, "Section"))
+	if err != nil {
+		return -1
+	}
+	return index
+}
+
+// parseHWPRecords parses a record stream into a tree based on record levels
+func parseHWPRecords(data []byte) ([]*hwpRecord, error) {
+	var roots []*hwpRecord
+	var stack []*hwpRecord
+
+	for offset := 0; offset+4 <= len(data); {
+		header := binary.LittleEndian.Uint32(data[offset:])
+		offset += 4
+
+		record := &hwpRecord{
+			TagID: uint16(header & 0x3FF),
+			Level: int((header >> 10) & 0x3FF),
+		}
+		size := int(header >> 20)
+		if size == 0xFFF {
+			if offset+4 > len(data) {
+				return nil, fmt.Errorf("truncated record header")
+			}
+			size = int(binary.LittleEndian.Uint32(data[offset:]))
+			offset += 4
+		}
+		if offset+size > len(data) {
+			return nil, fmt.Errorf("truncated record data")
+		}
+		record.Data = data[offset : offset+size]
+		offset += size
+
+		// Attach the record to the latest record one level above it
+		for len(stack) > 0 && stack[len(stack)-1].Level >= record.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, record)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, record)
+		}
+		stack = append(stack, record)
+	}
+
+	return roots, nil
+}
+
+// paragraphs returns the blocks of the paragraph records in a list
+func (c *HWPContent) paragraphs(records []*hwpRecord) []interfaces.Block {
+	var blocks []interfaces.Block
+	for _, record := range records {
+		if record.TagID == hwpTagParaHeader {
+			blocks = append(blocks, c.paragraph(record)...)
+		}
+	}
+	return blocks
+}
+
+// hwpPageBreak reports whether a paragraph starts a new page
+func hwpPageBreak(para *hwpRecord) bool {
+	return len(para.Data) >= 12 && para.Data[11]&0x04 != 0
+}
+
+// paragraph decodes a paragraph's runs, handling the controls embedded in it.
+// Tables, pictures and paragraphs nested in other controls follow the
+// paragraph text as separate blocks.
+func (c *HWPContent) paragraph(para *hwpRecord) []interfaces.Block {
+	var text, shapes []byte
+	var ctrls []*hwpRecord
+	for _, child := range para.Children {
+		switch child.TagID {
+		case hwpTagParaText:
+			text = child.Data
+		case hwpTagCharShapes:
+			shapes = child.Data
+		case hwpTagCtrlHeader:
+			ctrls = append(ctrls, child)
+		}
+	}
+
+	// The paragraph shape and style decide the block kind
+	var shape paraShape
+	var style paraStyle
+	shapeID := ""
+	if len(para.Data) >= 11 {
+		if id := int(binary.LittleEndian.Uint16(para.Data[8:])); id < len(c.info.ParaShapes) {
+			shape = c.info.ParaShapes[id]
+			shapeID = strconv.Itoa(id)
+		}
+		if id := int(para.Data[10]); id < len(c.info.Styles) {
+			style = c.info.Styles[id]
+		}
+	}
+	kind, level, ordered := blockKind(shape, style)
+	block := interfaces.Block{
+		Kind:      kind,
+		Level:     level,
+		Ordered:   ordered,
+		Align:     shape.Align,
+		ParaShape: shapeID,
+		Style:     style.Name,
+	}
+
+	var blocks []interfaces.Block
+	var units []uint16
+	var format interfaces.Run
+	flushRun := func() {
+		if len(units) > 0 {
+			block.Runs = appendRun(block.Runs, string(utf16.Decode(units)), format)
+			units = units[:0]
+		}
+	}
+	flush := func() {
+		flushRun()
+		if strings.TrimSpace(block.Text()) != "" {
+			paragraph := block
+			paragraph.Runs = trimRuns(block.Runs)
+			blocks = append(blocks, paragraph)
+		}
+		block.Runs = nil
+	}
+	appendString := func(s string) {
+		units = append(units, utf16.Encode([]rune(s))...)
+	}
+
+	nextCtrl := 0
+	nextShape := 0
+	for i := 0; i+1 < len(text); i += 2 {
+		// Character shapes change at code unit positions
+		for nextShape+8 <= len(shapes) && int(binary.LittleEndian.Uint32(shapes[nextShape:])) <= i/2 {
+			flushRun()
+			if id := int(binary.LittleEndian.Uint32(shapes[nextShape+4:])); id < len(c.info.CharShapes) {
+				format = c.info.CharShapes[id]
+			}
+			nextShape += 8
+		}
+
+		ch := binary.LittleEndian.Uint16(text[i:])
+		if ch >= 32 {
+			units = append(units, ch)
+			continue
+		}
+
+		switch ch {
+		case 9:
+			units = append(units, '\t')
+		case 10:
+			units = append(units, '\n')
+		case 24:
+			units = append(units, '-')
+		case 30, 31:
+			units = append(units, ' ')
+		case 1, 2, 3, 11, 12, 14, 15, 16, 17, 18, 21, 22, 23:
+			// Extended controls refer to the control headers in order
+			if nextCtrl < len(ctrls) {
+				ctrl := ctrls[nextCtrl]
+				nextCtrl++
+				if s, ok := c.inlineCtrl(ctrl); ok {
+					appendString(s)
+				} else if nested := c.ctrl(ctrl); len(nested) > 0 {
+					flush()
+					blocks = append(blocks, nested...)
+				}
+			}
+		}
+
+		// Inline and extended controls occupy eight code units
+		if hwpWideControl(ch) {
+			i += 14
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// appendRun appends text to runs, extending the last run when it has the same formatting
+func appendRun(runs []interfaces.Run, text string, format interfaces.Run) []interfaces.Run {
+	if len(runs) > 0 && runs[len(runs)-1].SameFormat(format) {
+		runs[len(runs)-1].Text += text
+		return runs
+	}
+	format.Text = text
+	return append(runs, format)
+}
+
+// hwpWideControl reports whether a control character occupies eight code units
+func hwpWideControl(ch uint16) bool {
+	switch ch {
+	case 0, 10, 13, 24, 25, 26, 27, 28, 29, 30, 31:
+		return false
+	default:
+		return true
+	}
+}
+
+// inlineCtrl returns the text that replaces a control inside a paragraph.
+// Footnotes and endnotes are collected and replaced by their references.
+func (c *HWPContent) inlineCtrl(ctrl *hwpRecord) (string, bool) {
+	if len(ctrl.Data) < 4 {
+		return "", false
+	}
+
+	switch binary.LittleEndian.Uint32(ctrl.Data) {
+	case hwpCtrlAutoNum:
+		if len(ctrl.Data) < 8 {
+			return "", false
+		}
+		switch binary.LittleEndian.Uint32(ctrl.Data[4:]) & 0x0F {
+		case 0:
+			return interfaces.PageNumberField, true
+		case 6:
+			return interfaces.TotalPagesField, true
+		}
+
+	case hwpCtrlFootnote, hwpCtrlEndnote:
+		note := interfaces.Note{Kind: interfaces.Footnote}
+		if binary.LittleEndian.Uint32(ctrl.Data) == hwpCtrlEndnote {
+			note.Kind = interfaces.Endnote
+		}
+		note.ID = strconv.Itoa(c.countNotes(note.Kind) + 1)
+		if note.Kind == interfaces.Endnote {
+			note.ID = "e" + note.ID
+		}
+
+		// Reserve the note's place before reading its body, which may hold notes too
+		c.Notes = append(c.Notes, note)
+		index := len(c.Notes) - 1
+		c.Notes[index].Text = strings.Join(interfaces.BlockLines(c.paragraphs(ctrl.Children)), "\n")
+		return interfaces.NoteRef(note.ID), true
+	}
+
+	return "", false
+}
+
+// countNotes returns the number of notes of the given kind
+func (c *HWPContent) countNotes(kind string) int {
+	count := 0
+	for _, note := range c.Notes {
+		if note.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// labelNotes numbers the notes using the footnote and endnote shapes
+func (c *HWPContent) labelNotes() {
+	footnotes, endnotes := 0, 0
+	for i := range c.Notes {
+		if c.Notes[i].Kind == interfaces.Endnote {
+			endnotes++
+			c.Notes[i].Label = c.EndnotesDef.Label(endnotes)
+		} else {
+			footnotes++
+			c.Notes[i].Label = c.FootnotesDef.Label(footnotes)
+		}
+	}
+}
+
+// setNoteShapes reads the footnote and endnote shapes of a section definition
+func (c *HWPContent) setNoteShapes(records []*hwpRecord) {
+	shapes := 0
+	for _, record := range records {
+		if record.TagID != hwpTagNoteShape || len(record.Data) < 12 {
+			continue
+		}
+
+		format := "DIGIT"
+		if code := int(record.Data[0]); code < len(hwpx.NumberFormats) {
+			format = hwpx.NumberFormats[code]
+		}
+		prefix := hwpChar(binary.LittleEndian.Uint16(record.Data[6:]))
+		suffix := hwpChar(binary.LittleEndian.Uint16(record.Data[8:]))
+		start := int(binary.LittleEndian.Uint16(record.Data[10:]))
+
+		// The first shape is for footnotes, the second for endnotes
+		if shapes == 0 {
+			c.FootnotesDef = hwpx.FootnotesDefType{
+				AutoNumFormat:  format,
+				AutoNumStart:   start,
+				PlaceEndOfPage: true,
+				PrefixChar:     prefix,
+				SuffixChar:     suffix,
+			}
+		} else {
+			c.EndnotesDef = hwpx.EndnotesDefType{
+				AutoNumFormat: format,
+				AutoNumStart:  start,
+				PrefixChar:    prefix,
+				SuffixChar:    suffix,
+			}
+		}
+		shapes++
+	}
+}
+
+// hwpPageSetup reads the paper size and margins of a PAGE_DEF record
+func hwpPageSetup(data []byte) *interfaces.PageSetup {
+	if len(data) < 40 {
+		return nil
+	}
+
+	// Sizes are in HWPUNIT, 1/7200 inch
+	value := func(offset int) float64 {
+		return float64(binary.LittleEndian.Uint32(data[offset:])) / 100
+	}
+	setup := &interfaces.PageSetup{
+		Width:        value(0),
+		Height:       value(4),
+		MarginLeft:   value(8),
+		MarginRight:  value(12),
+		MarginTop:    value(16),
+		MarginBottom: value(20),
+		MarginHeader: value(24),
+		MarginFooter: value(28),
+	}
+	// Landscape pages keep the portrait paper size
+	if binary.LittleEndian.Uint32(data[36:])&0x01 != 0 {
+		setup.Width, setup.Height = setup.Height, setup.Width
+	}
+	return setup
+}
+
+// hwpChar returns a decoration character, or an empty string when it is unset
+func hwpChar(ch uint16) string {
+	if ch == 0 {
+		return ""
+	}
+	return string(rune(ch))
+}
+
+// ctrl handles a control header and returns the body blocks it contributes
+func (c *HWPContent) ctrl(ctrl *hwpRecord) []interfaces.Block {
+	if len(ctrl.Data) < 4 {
+		return nil
+	}
+
+	switch binary.LittleEndian.Uint32(ctrl.Data) {
+	case hwpCtrlHeader, hwpCtrlFooter:
+		item := interfaces.HeaderFooter{
+			Apply: hwpApplyPageType(ctrl.Data),
+			Text:  strings.Join(interfaces.BlockLines(c.paragraphs(ctrl.Children)), "\n"),
+		}
+		if binary.LittleEndian.Uint32(ctrl.Data) == hwpCtrlHeader {
+			c.Headers = append(c.Headers, item)
+		} else {
+			c.Footers = append(c.Footers, item)
+		}
+		return nil
+
+	case hwpCtrlPageNumPos:
+		c.addPageNumber(ctrl.Data)
+		return nil
+
+	case hwpCtrlSectionDef:
+		c.setNoteShapes(ctrl.Children)
+		if c.PageSetup == nil {
+			if pageDef := findHWPRecord(ctrl.Children, hwpTagPageDef); pageDef != nil {
+				c.PageSetup = hwpPageSetup(pageDef.Data)
+			}
+		}
+		return nil
+
+	case hwpCtrlTable:
+		return []interfaces.Block{{Kind: interfaces.BlockTable, Table: c.table(ctrl)}}
+
+	case hwpCtrlShape:
+		// Pictures are drawing objects holding a picture component
+		if picture := findHWPRecord(ctrl.Children, hwpTagPicture); picture != nil && len(picture.Data) >= 73 {
+			itemID := int(binary.LittleEndian.Uint16(picture.Data[71:]))
+			if image := hwpImage(c.entries, c.info, itemID, c.compressed); image != nil {
+				return []interfaces.Block{{Kind: interfaces.BlockImage, Image: image}}
+			}
+			return nil
+		}
+		return c.nestedParagraphs(ctrl.Children)
+
+	default:
+		// Text boxes and other controls hold paragraph lists
+		return c.nestedParagraphs(ctrl.Children)
+	}
+}
+
+// table builds a table from the cell list headers and paragraphs of a table control
+func (c *HWPContent) table(ctrl *hwpRecord) *interfaces.Table {
+	table := &interfaces.Table{}
+	if record := findHWPRecord(ctrl.Children, hwpTagTable); record != nil && len(record.Data) >= 6 {
+		table.Rows = make([][]interfaces.TableCell, binary.LittleEndian.Uint16(record.Data[4:]))
+	}
+
+	var cells []interfaces.TableCell
+	for _, child := range ctrl.Children {
+		switch child.TagID {
+		case hwpTagListHeader:
+			// Cell address and spans follow the common list header
+			cell := interfaces.TableCell{RowSpan: 1, ColSpan: 1}
+			if len(child.Data) >= 16 {
+				cell.Col = int(binary.LittleEndian.Uint16(child.Data[8:]))
+				cell.Row = int(binary.LittleEndian.Uint16(child.Data[10:]))
+				cell.ColSpan = int(binary.LittleEndian.Uint16(child.Data[12:]))
+				cell.RowSpan = int(binary.LittleEndian.Uint16(child.Data[14:]))
+			}
+			cells = append(cells, cell)
+		case hwpTagParaHeader:
+			if len(cells) > 0 {
+				cell := &cells[len(cells)-1]
+				cell.Blocks = append(cell.Blocks, c.paragraph(child)...)
+			}
+		}
+	}
+
+	for _, cell := range cells {
+		for cell.Row >= len(table.Rows) {
+			table.Rows = append(table.Rows, nil)
+		}
+		table.Rows[cell.Row] = append(table.Rows[cell.Row], cell)
+	}
+	return table
+}
+
+// findHWPRecord returns the first record with the given tag at any depth below records
+func findHWPRecord(records []*hwpRecord, tagID uint16) *hwpRecord {
+	for _, record := range records {
+		if record.TagID == tagID {
+			return record
+		}
+		if found := findHWPRecord(record.Children, tagID); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nestedParagraphs returns the blocks of paragraphs found at any depth below records
+func (c *HWPContent) nestedParagraphs(records []*hwpRecord) []interfaces.Block {
+	var blocks []interfaces.Block
+	for _, record := range records {
+		if record.TagID == hwpTagParaHeader {
+			blocks = append(blocks, c.paragraph(record)...)
+		} else {
+			blocks = append(blocks, c.nestedParagraphs(record.Children)...)
+		}
+	}
+	return blocks
+}
+
+// hwpApplyPageType returns the page type of a header or footer control
+func hwpApplyPageType(data []byte) string {
+	if len(data) < 8 {
+		return "BOTH"
+	}
+	switch binary.LittleEndian.Uint32(data[4:]) & 0x03 {
+	case 1:
+		return "EVEN"
+	case 2:
+		return "ODD"
+	default:
+		return "BOTH"
+	}
+}
+
+// hwpPageNumPositions maps page number position codes to their HWPX names
+var hwpPageNumPositions = []string{
+	"NONE", "TOP_LEFT", "TOP_CENTER", "TOP_RIGHT",
+	"BOTTOM_LEFT", "BOTTOM_CENTER", "BOTTOM_RIGHT",
+	"OUTSIDE_TOP", "OUTSIDE_BOTTOM", "INSIDE_TOP", "INSIDE_BOTTOM",
+}
+
+// addPageNumber adds a page number defined by a page number position control
+func (c *HWPContent) addPageNumber(data []byte) {
+	if len(data) < 8 {
+		return
+	}
+
+	properties := binary.LittleEndian.Uint32(data[4:])
+	position := int(properties>>8) & 0x0F
+	if position >= len(hwpPageNumPositions) {
+		return
+	}
+	pos := hwpPageNumPositions[position]
+
+	// User, prefix and suffix characters follow the properties
+	text := interfaces.PageNumberField
+	if len(data) >= 14 {
+		prefix := hwpChar(binary.LittleEndian.Uint16(data[10:]))
+		suffix := hwpChar(binary.LittleEndian.Uint16(data[12:]))
+		text = prefix + text + suffix
+	}
+
+	items := pageNumberItems(pos, text)
+	if strings.Contains(pos, "TOP") {
+		c.Headers = append(c.Headers, items...)
+	} else {
+		c.Footers = append(c.Footers, items...)
+	}
+}
@@ -0,0 +1,169 @@
+package readers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"myconverter/interfaces"
+)
+
+// hwpxHeader holds the shape and style definitions of an HWPX header.xml
+type hwpxHeader struct {
+	// CharShapes maps charPr IDs to the run formatting they define
+	CharShapes map[string]interfaces.Run
+	// ParaShapes maps paraPr IDs to their definitions
+	ParaShapes map[string]paraShape
+	// Styles maps style IDs to their definitions
+	Styles map[string]paraStyle
+}
+
+// paraShape is the part of a paragraph shape that decides the block kind
+type paraShape struct {
+	// HeadingType is NONE, OUTLINE, NUMBER or BULLET
+	HeadingType string
+	// HeadingLevel is the outline or list level, starting from 0
+	HeadingLevel int
+	// Align is the horizontal alignment of the paragraph
+	Align string
+}
+
+// paraStyle is a named paragraph style
+type paraStyle struct {
+	Name    string
+	EngName string
+}
+
+// outlineStylePattern matches the names of the built-in outline styles
+var outlineStylePattern = regexp.MustCompile(`^(?:Outline|개요)\s*(\d+)$`)
+
+// parseHWPXHeader parses the char/para shapes and styles of header.xml
+func parseHWPXHeader(xmlContent []byte) (*hwpxHeader, error) {
+	header := &hwpxHeader{
+		CharShapes: make(map[string]interfaces.Run),
+		ParaShapes: make(map[string]paraShape),
+		Styles:     make(map[string]paraStyle),
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(string(xmlContent)))
+	var charID, paraID string
+	// Hangul font faces by ID, referenced by the charPr fontRef
+	fonts := make(map[string]string)
+	var fontLang string
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse header XML: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "fontface":
+				fontLang = attrValue(t, "lang")
+			case "font":
+				if fontLang == "HANGUL" {
+					fonts[attrValue(t, "id")] = attrValue(t, "face")
+				}
+			case "charPr":
+				charID = attrValue(t, "id")
+				run := interfaces.Run{CharShape: charID, Color: hwpxColor(attrValue(t, "textColor"))}
+				if height, err := strconv.Atoi(attrValue(t, "height")); err == nil {
+					run.Size = float64(height) / 100
+				}
+				if shade := hwpxColor(attrValue(t, "shadeColor")); shade != "#FFFFFF" {
+					run.Highlight = shade
+				}
+				header.CharShapes[charID] = run
+			case "fontRef", "bold", "italic", "underline", "strikeout":
+				if charID == "" {
+					continue
+				}
+				run := header.CharShapes[charID]
+				switch t.Name.Local {
+				case "fontRef":
+					run.Font = fonts[attrValue(t, "hangul")]
+				case "bold":
+					run.Bold = true
+				case "italic":
+					run.Italic = true
+				case "underline":
+					run.Underline = attrValue(t, "type") != "" && attrValue(t, "type") != "NONE"
+				case "strikeout":
+					run.Strike = attrValue(t, "shape") != "" && attrValue(t, "shape") != "NONE"
+				}
+				header.CharShapes[charID] = run
+			case "paraPr":
+				paraID = attrValue(t, "id")
+				header.ParaShapes[paraID] = paraShape{HeadingType: "NONE"}
+			case "heading":
+				if paraID == "" {
+					continue
+				}
+				shape := header.ParaShapes[paraID]
+				shape.HeadingType = attrValue(t, "type")
+				shape.HeadingLevel, _ = strconv.Atoi(attrValue(t, "level"))
+				header.ParaShapes[paraID] = shape
+			case "align":
+				if paraID == "" {
+					continue
+				}
+				shape := header.ParaShapes[paraID]
+				shape.Align = attrValue(t, "horizontal")
+				header.ParaShapes[paraID] = shape
+			case "style":
+				header.Styles[attrValue(t, "id")] = paraStyle{
+					Name:    attrValue(t, "name"),
+					EngName: attrValue(t, "engName"),
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "fontface":
+				fontLang = ""
+			case "charPr":
+				charID = ""
+			case "paraPr":
+				paraID = ""
+			}
+		}
+	}
+
+	return header, nil
+}
+
+// hwpxColor normalizes a #RRGGBB color attribute, returning "" for none
+func hwpxColor(value string) string {
+	if len(value) != 7 || !strings.HasPrefix(value, "#") {
+		return ""
+	}
+	return strings.ToUpper(value)
+}
+
+// blockKind returns the kind, level and ordering of a paragraph from its shape and style
+func blockKind(shape paraShape, style paraStyle) (interfaces.BlockKind, int, bool) {
+	for _, name := range []string{style.EngName, style.Name} {
+		if match := outlineStylePattern.FindStringSubmatch(name); match != nil {
+			level, _ := strconv.Atoi(match[1])
+			return interfaces.BlockHeading, level, false
+		}
+	}
+
+	switch shape.HeadingType {
+	case "OUTLINE":
+		return interfaces.BlockHeading, shape.HeadingLevel + 1, false
+	case "NUMBER":
+		return interfaces.BlockListItem, shape.HeadingLevel + 1, true
+	case "BULLET":
+		return interfaces.BlockListItem, shape.HeadingLevel + 1, false
+	default:
+		return interfaces.BlockParagraph, 0, false
+	}
+}
@@ -5,70 +5,531 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+
+	"myconverter/hwpx"
+	"myconverter/interfaces"
 )
 
 // HWPXContent represents the extracted text content from HWPX
 type HWPXContent struct {
-	Text []string
+	Text    []string
+	Blocks  []interfaces.Block
+	Headers []interfaces.HeaderFooter
+	Footers []interfaces.HeaderFooter
+	Notes   []interfaces.Note
+	// Numbering of footnotes and endnotes from the section properties
+	FootnotesDef hwpx.FootnotesDefType
+	EndnotesDef  hwpx.EndnotesDefType
+	// PageSetup is the page definition of the section properties
+	PageSetup *interfaces.PageSetup
+
+	header *hwpxHeader
+	files  map[string]*zip.File
 }
 
-// ExtractHWPXContent extracts text content from section0.xml in HWPX file
-func ExtractHWPXContent(zipReader *zip.ReadCloser) (*HWPXContent, error) {
-	var content HWPXContent
+// hwpxCollector gathers paragraph blocks for the body, a table cell, a
+// header/footer or a note
+type hwpxCollector struct {
+	element string
+	apply   string
+	blocks  []interfaces.Block
+	// note is the index of the note a footnote or endnote collector fills
+	note int
+}
+
+// hwpxParagraph buffers the runs of a paragraph until it is complete
+type hwpxParagraph struct {
+	block     interfaces.Block
+	collector *hwpxCollector
+}
+
+// write appends text to the paragraph using the given run formatting
+func (p *hwpxParagraph) write(text string, format interfaces.Run) {
+	p.block.Runs = appendRun(p.block.Runs, text, format)
+}
 
-	// Find and read section0.xml
+// flush moves the buffered paragraph to its collector
+func (p *hwpxParagraph) flush() {
+	if strings.TrimSpace(p.block.Text()) != "" {
+		block := p.block
+		block.Runs = trimRuns(block.Runs)
+		p.collector.blocks = append(p.collector.blocks, block)
+	}
+	p.block.Runs = nil
+}
+
+// hwpxTable builds a table from tr/tc elements
+type hwpxTable struct {
+	table *interfaces.Table
+	cell  interfaces.TableCell
+}
+
+// ExtractHWPXContent extracts text content from the section files in HWPX file
+func ExtractHWPXContent(zipReader *zip.ReadCloser) (*HWPXContent, error) {
+	content := HWPXContent{files: make(map[string]*zip.File)}
 	for _, file := range zipReader.File {
-		if strings.HasSuffix(file.Name, "section0.xml") {
-			rc, err := file.Open()
-			if err != nil {
-				return nil, fmt.Errorf("failed to open section0.xml: %v", err)
-			}
-			defer rc.Close()
+		content.files[file.Name] = file
+	}
 
-			// Read the XML content
-			xmlContent, err := io.ReadAll(rc)
+	// Shapes and styles decide run formatting and paragraph kinds
+	content.header = &hwpxHeader{}
+	for name := range content.files {
+		if strings.HasSuffix(name, "header.xml") {
+			xmlContent, err := content.readFile(name)
 			if err != nil {
-				return nil, fmt.Errorf("failed to read XML content: %v", err)
+				return nil, err
 			}
+			if content.header, err = parseHWPXHeader(xmlContent); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// Read the section files in order, section0.xml first
+	var sections []string
+	for name := range content.files {
+		if hwpxSectionIndex(name) >= 0 {
+			sections = append(sections, name)
+		}
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		return hwpxSectionIndex(sections[i]) < hwpxSectionIndex(sections[j])
+	})
+	for _, name := range sections {
+		xmlContent, err := content.readFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := content.parseSection(xmlContent); err != nil {
+			return nil, err
+		}
+	}
+
+	return &content, nil
+}
+
+// hwpxSectionIndex returns the number of a Contents/sectionN.xml file name, -1 for other files
+func hwpxSectionIndex(name string) int {
+	base := path.Base(name)
+	if !strings.HasPrefix(base, "section") || path.Ext(base) != ".xml" {
+		return -1
+	}
+	index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(base, "section"), ".xml"))
+	if err != nil {
+		return -1
+	}
+	return index
+}
+
+// readFile reads a file of the HWPX package
+func (c *HWPXContent) readFile(name string) ([]byte, error) {
+	file, ok := c.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", name, err)
+	}
+	defer rc.Close()
+
+	// Read the XML content
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	return data, nil
+}
+
+// parseSection walks the section XML collecting paragraphs, headers and footers
+func (c *HWPXContent) parseSection(xmlContent []byte) error {
+	decoder := xml.NewDecoder(strings.NewReader(string(xmlContent)))
+
+	// Body blocks go to the bottom collector, cell, header/footer and note
+	// blocks to the collector on top
+	collectors := []*hwpxCollector{{element: "body"}}
+	// Paragraphs nest inside table cells and header/footer controls
+	var paragraphs []*hwpxParagraph
+	var tables []*hwpxTable
+	var isHPT bool
+	// Footnote or endnote properties element being read
+	var notePr string
+	// Whether the first page properties element is being read
+	var inPagePr bool
+	// Formatting of the run being read
+	var format interfaces.Run
+
+	current := func() *hwpxCollector {
+		return collectors[len(collectors)-1]
+	}
+	write := func(text string) {
+		if len(paragraphs) > 0 {
+			paragraphs[len(paragraphs)-1].write(text, format)
+		}
+	}
+	// addBlock places a table or picture after the text of the enclosing paragraph
+	addBlock := func(block interfaces.Block) {
+		collector := current()
+		if len(paragraphs) > 0 {
+			p := paragraphs[len(paragraphs)-1]
+			p.flush()
+			collector = p.collector
+		}
+		collector.blocks = append(collector.blocks, block)
+	}
 
-			// Parse XML to extract text from hp:t elements
-			decoder := xml.NewDecoder(strings.NewReader(string(xmlContent)))
-			var texts []string
-			var isHPT bool
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse XML: %v", err)
+		}
 
-			for {
-				token, err := decoder.Token()
-				if err == io.EOF {
-					break
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				// Text before a nested paragraph of the same collector, such
+				// as a text box, belongs in its own block
+				if len(paragraphs) > 0 && paragraphs[len(paragraphs)-1].collector == current() {
+					paragraphs[len(paragraphs)-1].flush()
 				}
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse XML: %v", err)
+				if attrValue(t, "pageBreak") == "1" && len(collectors) == 1 && len(current().blocks) > 0 {
+					current().blocks = append(current().blocks, interfaces.Block{Kind: interfaces.BlockPageBreak})
 				}
 
-				switch t := token.(type) {
-				case xml.StartElement:
-					if t.Name.Local == "t" {
-						isHPT = true
+				shapeID := attrValue(t, "paraPrIDRef")
+				shape := c.header.ParaShapes[shapeID]
+				style := c.header.Styles[attrValue(t, "styleIDRef")]
+				kind, level, ordered := blockKind(shape, style)
+				paragraphs = append(paragraphs, &hwpxParagraph{
+					block: interfaces.Block{
+						Kind:      kind,
+						Level:     level,
+						Ordered:   ordered,
+						Align:     shape.Align,
+						ParaShape: shapeID,
+						Style:     style.Name,
+					},
+					collector: current(),
+				})
+			case "run":
+				format = c.header.CharShapes[attrValue(t, "charPrIDRef")]
+			case "t":
+				isHPT = true
+			case "tab":
+				if isHPT {
+					write("\t")
+				}
+			case "lineBreak":
+				if isHPT {
+					write("\n")
+				}
+			case "header", "footer":
+				collectors = append(collectors, &hwpxCollector{
+					element: t.Name.Local,
+					apply:   attrValue(t, "applyPageType"),
+				})
+			case "autoNum":
+				switch attrValue(t, "numType") {
+				case "PAGE":
+					write(interfaces.PageNumberField)
+				case "TOTAL_PAGE":
+					write(interfaces.TotalPagesField)
+				}
+			case "pageNum":
+				c.addPageNumber(attrValue(t, "pos"), attrValue(t, "sideChar"))
+			case "pagePr":
+				inPagePr = c.PageSetup == nil
+				c.setPageSize(t)
+			case "margin":
+				if inPagePr {
+					c.setPageMargins(t)
+				}
+			case "footNotePr", "endNotePr":
+				notePr = t.Name.Local
+			case "autoNumFormat":
+				c.setNoteFormat(notePr, t)
+			case "numbering":
+				if start, err := strconv.Atoi(attrValue(t, "newNum")); err == nil {
+					if notePr == "footNotePr" {
+						c.FootnotesDef.AutoNumStart = start
+					} else if notePr == "endNotePr" {
+						c.EndnotesDef.AutoNumStart = start
 					}
-				case xml.CharData:
-					if isHPT {
-						text := strings.TrimSpace(string(t))
-						if text != "" {
-							texts = append(texts, text)
-						}
+				}
+			case "footNote", "endNote":
+				// The reference stays in the text, the note body is collected separately
+				kind, id := interfaces.Footnote, strconv.Itoa(c.countNotes(interfaces.Footnote)+1)
+				if t.Name.Local == "endNote" {
+					kind, id = interfaces.Endnote, "e"+strconv.Itoa(c.countNotes(interfaces.Endnote)+1)
+				}
+				write(interfaces.NoteRef(id))
+				c.Notes = append(c.Notes, interfaces.Note{Kind: kind, ID: id})
+				collectors = append(collectors, &hwpxCollector{element: t.Name.Local, note: len(c.Notes) - 1})
+			case "tbl":
+				tables = append(tables, &hwpxTable{table: &interfaces.Table{}})
+			case "tr":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1].table
+					table.Rows = append(table.Rows, nil)
+				}
+			case "tc":
+				if len(tables) > 0 && len(tables[len(tables)-1].table.Rows) > 0 {
+					table := tables[len(tables)-1]
+					row := len(table.table.Rows) - 1
+					// Cell addresses follow in cellAddr, default to the row order
+					table.cell = interfaces.TableCell{Row: row, Col: len(table.table.Rows[row]), RowSpan: 1, ColSpan: 1}
+					collectors = append(collectors, &hwpxCollector{element: "tc"})
+				}
+			case "cellAddr":
+				if len(tables) > 0 {
+					cell := &tables[len(tables)-1].cell
+					cell.Col, _ = strconv.Atoi(attrValue(t, "colAddr"))
+					cell.Row, _ = strconv.Atoi(attrValue(t, "rowAddr"))
+				}
+			case "cellSpan":
+				if len(tables) > 0 {
+					cell := &tables[len(tables)-1].cell
+					cell.ColSpan, _ = strconv.Atoi(attrValue(t, "colSpan"))
+					cell.RowSpan, _ = strconv.Atoi(attrValue(t, "rowSpan"))
+				}
+			case "img":
+				if image := c.binaryItem(attrValue(t, "binaryItemIDRef")); image != nil {
+					addBlock(interfaces.Block{Kind: interfaces.BlockImage, Image: image})
+				}
+			}
+		case xml.CharData:
+			if isHPT {
+				write(string(t))
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				isHPT = false
+			case "pagePr":
+				inPagePr = false
+			case "footNotePr", "endNotePr":
+				notePr = ""
+			case "footNote", "endNote":
+				if len(collectors) > 1 {
+					collector := current()
+					collectors = collectors[:len(collectors)-1]
+					c.Notes[collector.note].Text = strings.Join(interfaces.BlockLines(collector.blocks), "\n")
+				}
+			case "p":
+				if len(paragraphs) > 0 {
+					paragraphs[len(paragraphs)-1].flush()
+					paragraphs = paragraphs[:len(paragraphs)-1]
+				}
+			case "header", "footer":
+				if len(collectors) > 1 {
+					collector := current()
+					collectors = collectors[:len(collectors)-1]
+					item := interfaces.HeaderFooter{
+						Apply: collector.apply,
+						Text:  strings.Join(interfaces.BlockLines(collector.blocks), "\n"),
 					}
-				case xml.EndElement:
-					if t.Name.Local == "t" {
-						isHPT = false
+					if collector.element == "header" {
+						c.Headers = append(c.Headers, item)
+					} else {
+						c.Footers = append(c.Footers, item)
 					}
 				}
+			case "tc":
+				if len(tables) > 0 && current().element == "tc" {
+					table := tables[len(tables)-1]
+					table.cell.Blocks = current().blocks
+					collectors = collectors[:len(collectors)-1]
+					row := len(table.table.Rows) - 1
+					table.table.Rows[row] = append(table.table.Rows[row], table.cell)
+				}
+			case "tbl":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					tables = tables[:len(tables)-1]
+					addBlock(interfaces.Block{Kind: interfaces.BlockTable, Table: table.table})
+				}
 			}
+		}
+	}
 
-			content.Text = texts
-			break
+	// Sections start on a new page
+	if len(c.Blocks) > 0 && len(collectors[0].blocks) > 0 {
+		c.Blocks = append(c.Blocks, interfaces.Block{Kind: interfaces.BlockPageBreak, Section: true})
+	}
+	c.Blocks = append(c.Blocks, collectors[0].blocks...)
+	c.Text = interfaces.BlockLines(c.Blocks)
+	c.labelNotes()
+	return nil
+}
+
+// binaryItem loads a picture referenced by its binary item ID
+func (c *HWPXContent) binaryItem(id string) *interfaces.Image {
+	if id == "" {
+		return nil
+	}
+
+	// Pictures are stored as BinData/<id>.<ext>
+	for name := range c.files {
+		base := path.Base(name)
+		if strings.HasPrefix(name, "BinData/") && strings.TrimSuffix(base, path.Ext(base)) == id {
+			data, err := c.readFile(name)
+			if err != nil {
+				return nil
+			}
+			return &interfaces.Image{Name: base, Data: data}
 		}
 	}
+	return nil
+}
 
-	return &content, nil
+// trimRuns removes the leading and trailing whitespace of a paragraph's runs
+func trimRuns(runs []interfaces.Run) []interfaces.Run {
+	for len(runs) > 0 && strings.TrimSpace(runs[0].Text) == "" {
+		runs = runs[1:]
+	}
+	for len(runs) > 0 && strings.TrimSpace(runs[len(runs)-1].Text) == "" {
+		runs = runs[:len(runs)-1]
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+
+	trimmed := append([]interfaces.Run(nil), runs...)
+	trimmed[0].Text = strings.TrimLeft(trimmed[0].Text, " \t\n")
+	last := len(trimmed) - 1
+	trimmed[last].Text = strings.TrimRight(trimmed[last].Text, " \t\n")
+	return trimmed
+}
+
+// setPageSize reads the paper size of a pagePr element
+func (c *HWPXContent) setPageSize(element xml.StartElement) {
+	if c.PageSetup != nil {
+		return
+	}
+
+	width, _ := strconv.Atoi(attrValue(element, "width"))
+	height, _ := strconv.Atoi(attrValue(element, "height"))
+	// Sizes are in HWPUNIT, 1/7200 inch. NARROWLY marks landscape pages,
+	// which keep the portrait paper size.
+	c.PageSetup = &interfaces.PageSetup{Width: float64(width) / 100, Height: float64(height) / 100}
+	if attrValue(element, "landscape") == "NARROWLY" {
+		c.PageSetup.Width, c.PageSetup.Height = c.PageSetup.Height, c.PageSetup.Width
+	}
+}
+
+// setPageMargins reads the page margins of the margin element inside pagePr
+func (c *HWPXContent) setPageMargins(element xml.StartElement) {
+	value := func(name string) float64 {
+		v, _ := strconv.Atoi(attrValue(element, name))
+		return float64(v) / 100
+	}
+	c.PageSetup.MarginLeft = value("left")
+	c.PageSetup.MarginRight = value("right")
+	c.PageSetup.MarginTop = value("top")
+	c.PageSetup.MarginBottom = value("bottom")
+	c.PageSetup.MarginHeader = value("header")
+	c.PageSetup.MarginFooter = value("footer")
+}
+
+// setNoteFormat applies an autoNumFormat element of the footnote or endnote properties
+func (c *HWPXContent) setNoteFormat(notePr string, element xml.StartElement) {
+	format := attrValue(element, "type")
+	prefix := attrValue(element, "prefixChar")
+	suffix := attrValue(element, "suffixChar")
+
+	switch notePr {
+	case "footNotePr":
+		c.FootnotesDef.AutoNumFormat = format
+		c.FootnotesDef.PrefixChar = prefix
+		c.FootnotesDef.SuffixChar = suffix
+	case "endNotePr":
+		c.EndnotesDef.AutoNumFormat = format
+		c.EndnotesDef.PrefixChar = prefix
+		c.EndnotesDef.SuffixChar = suffix
+	}
+}
+
+// countNotes returns the number of notes of the given kind
+func (c *HWPXContent) countNotes(kind string) int {
+	count := 0
+	for _, note := range c.Notes {
+		if note.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// labelNotes numbers the notes using the footnote and endnote definitions
+func (c *HWPXContent) labelNotes() {
+	footnotes, endnotes := 0, 0
+	for i := range c.Notes {
+		if c.Notes[i].Kind == interfaces.Endnote {
+			endnotes++
+			c.Notes[i].Label = c.EndnotesDef.Label(endnotes)
+		} else {
+			footnotes++
+			c.Notes[i].Label = c.FootnotesDef.Label(footnotes)
+		}
+	}
+}
+
+// addPageNumber adds a page number defined by a pageNum control as a header or footer
+func (c *HWPXContent) addPageNumber(pos, sideChar string) {
+	text := interfaces.PageNumberField
+	if sideChar != "" {
+		text = sideChar + " " + text + " " + sideChar
+	}
+
+	items := pageNumberItems(pos, text)
+	if strings.Contains(pos, "TOP") {
+		c.Headers = append(c.Headers, items...)
+	} else {
+		c.Footers = append(c.Footers, items...)
+	}
+}
+
+// pageNumberItems builds header/footer items for a page number position such
+// as BOTTOM_CENTER or OUTSIDE_TOP
+func pageNumberItems(pos, text string) []interfaces.HeaderFooter {
+	item := func(apply, align string) interfaces.HeaderFooter {
+		return interfaces.HeaderFooter{Apply: apply, Align: align, Text: text, PageNumber: true}
+	}
+
+	switch {
+	case pos == "" || pos == "NONE":
+		return nil
+	case strings.HasPrefix(pos, "OUTSIDE"):
+		// Outside positions sit on the right of odd and the left of even pages
+		return []interfaces.HeaderFooter{item("ODD", "RIGHT"), item("EVEN", "LEFT")}
+	case strings.HasPrefix(pos, "INSIDE"):
+		return []interfaces.HeaderFooter{item("ODD", "LEFT"), item("EVEN", "RIGHT")}
+	case strings.HasSuffix(pos, "LEFT"):
+		return []interfaces.HeaderFooter{item("BOTH", "LEFT")}
+	case strings.HasSuffix(pos, "RIGHT"):
+		return []interfaces.HeaderFooter{item("BOTH", "RIGHT")}
+	default:
+		return []interfaces.HeaderFooter{item("BOTH", "CENTER")}
+	}
+}
+
+// attrValue returns the value of the named attribute, ignoring its namespace
+func attrValue(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
 }
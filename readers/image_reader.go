@@ -0,0 +1,136 @@
+package readers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// Register the decoders of supported image files
+	_ "image/jpeg"
+
+	"golang.org/x/image/tiff"
+
+	"myconverter/interfaces"
+)
+
+// ImageReader implements FileReader for PNG, JPEG and TIFF images, such as
+// scanned pages. Each image, or each page of a multi-page TIFF, becomes an
+// image block on its own page.
+type ImageReader struct {
+	filePath string
+	content  *interfaces.PDFContent
+}
+
+// NewImageReader creates a new ImageReader
+func NewImageReader(filePath string) *ImageReader {
+	return &ImageReader{
+		filePath: filePath,
+		content:  &interfaces.PDFContent{},
+	}
+}
+
+// Read reads the image file and extracts its pages
+func (r *ImageReader) Read(filePath string) error {
+	r.filePath = filePath
+	_, err := r.ReadImage()
+	return err
+}
+
+// GetContent returns the content extracted by Read
+func (r *ImageReader) GetContent() *interfaces.PDFContent {
+	return r.content
+}
+
+// ReadImage reads the image file into image blocks separated by page
+// breaks. PNG and JPEG data is kept unchanged, with the EXIF orientation
+// of JPEG images recorded for writers to apply. TIFF pages are converted
+// to upright PNG images.
+func (r *ImageReader) ReadImage() (*interfaces.PDFContent, error) {
+	data, err := os.ReadFile(r.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	name := filepath.Base(r.filePath)
+	var images []*interfaces.Image
+	switch format {
+	case "jpeg":
+		images = append(images, &interfaces.Image{Name: name, Data: data, Orientation: jpegOrientation(data)})
+	case "tiff":
+		if images, err = tiffImages(data, strings.TrimSuffix(name, filepath.Ext(name))); err != nil {
+			return nil, err
+		}
+	default:
+		images = append(images, &interfaces.Image{Name: name, Data: data})
+	}
+
+	r.content = &interfaces.PDFContent{Metadata: map[string]string{"Format": strings.ToUpper(format)}}
+	for i, img := range images {
+		if i > 0 {
+			r.content.Blocks = append(r.content.Blocks, interfaces.Block{Kind: interfaces.BlockPageBreak})
+		}
+		r.content.Blocks = append(r.content.Blocks, interfaces.Block{Kind: interfaces.BlockImage, Image: img})
+		r.content.Pages = append(r.content.Pages, interfaces.PDFPage{Number: i + 1, Images: [][]byte{img.Data}})
+	}
+	return r.content, nil
+}
+
+// DecodePage decodes an upright image of a page, numbered from 1, of the
+// content read by ReadImage
+func (r *ImageReader) DecodePage(number int) (image.Image, error) {
+	var images []*interfaces.Image
+	for _, block := range r.content.Blocks {
+		if block.Kind == interfaces.BlockImage && block.Image != nil {
+			images = append(images, block.Image)
+		}
+	}
+	if number < 1 || number > len(images) {
+		return nil, fmt.Errorf("page %d out of range", number)
+	}
+	img, _, err := image.Decode(bytes.NewReader(images[number-1].Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+	return orientImage(img, images[number-1].Orientation), nil
+}
+
+// tiffImages decodes each page of TIFF data to an upright PNG image
+func tiffImages(data []byte, base string) ([]*interfaces.Image, error) {
+	order, _, err := tiffHeader(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TIFF: %v", err)
+	}
+	offsets, err := tiffDirectories(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TIFF: %v", err)
+	}
+
+	var images []*interfaces.Image
+	for i, offset := range offsets {
+		page, err := tiff.Decode(bytes.NewReader(tiffPage(data, order, offset)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode TIFF page %d: %v", i+1, err)
+		}
+		orientation, _ := tiffTagValue(data, order, offset, tiffTagOrientation)
+		page = orientImage(page, int(orientation))
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, page); err != nil {
+			return nil, fmt.Errorf("failed to encode TIFF page %d: %v", i+1, err)
+		}
+		name := base + ".png"
+		if len(offsets) > 1 {
+			name = fmt.Sprintf("%s-%d.png", base, i+1)
+		}
+		images = append(images, &interfaces.Image{Name: name, Data: buf.Bytes()})
+	}
+	return images, nil
+}
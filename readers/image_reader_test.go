@@ -0,0 +1,225 @@
+package readers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testImage returns a 3x2 gray image whose pixels are 10*y+x
+func testImage() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(10*y + x)})
+		}
+	}
+	return img
+}
+
+// grayRows returns the gray levels of an image by row
+func grayRows(img image.Image) [][]uint8 {
+	bounds := img.Bounds()
+	rows := make([][]uint8, bounds.Dy())
+	for y := range rows {
+		for x := 0; x < bounds.Dx(); x++ {
+			rows[y] = append(rows[y], color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y)
+		}
+	}
+	return rows
+}
+
+// testTIFF returns an uncompressed little-endian TIFF file with a page for
+// each image, recording the orientations that are not 0
+func testTIFF(pages []*image.Gray, orientations []int) []byte {
+	order := binary.LittleEndian
+	data := []byte{'I', 'I', 42, 0, 0, 0, 0, 0}
+	next := 4
+	for i, page := range pages {
+		pixels := len(data)
+		data = append(data, page.Pix...)
+		if len(data)%2 == 1 {
+			data = append(data, 0)
+		}
+		order.PutUint32(data[next:], uint32(len(data)))
+
+		type entry struct{ tag, value uint16 }
+		width, height := uint16(page.Rect.Dx()), uint16(page.Rect.Dy())
+		entries := []entry{{256, width}, {257, height}, {258, 8}, {259, 1}, {262, 1}, {273, 0}}
+		if orientations[i] != 0 {
+			entries = append(entries, entry{274, uint16(orientations[i])})
+		}
+		entries = append(entries, entry{277, 1}, entry{278, height}, entry{279, width * height})
+
+		data = order.AppendUint16(data, uint16(len(entries)))
+		for _, e := range entries {
+			data = order.AppendUint16(data, e.tag)
+			if e.tag == 273 {
+				// Strip offsets are longs
+				data = order.AppendUint16(data, 4)
+				data = order.AppendUint32(data, 1)
+				data = order.AppendUint32(data, uint32(pixels))
+				continue
+			}
+			data = order.AppendUint16(data, 3)
+			data = order.AppendUint32(data, 1)
+			data = order.AppendUint16(data, e.value)
+			data = order.AppendUint16(data, 0)
+		}
+		next = len(data)
+		data = order.AppendUint32(data, 0)
+	}
+	return data
+}
+
+// testEXIFJPEG returns a JPEG file of img with an EXIF orientation in the
+// byte order of order
+func testEXIFJPEG(t *testing.T, img image.Image, orientation int, order binary.AppendByteOrder) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	exif := []byte("Exif\x00\x00MM\x00\x2A")
+	if order == binary.AppendByteOrder(binary.LittleEndian) {
+		exif = []byte("Exif\x00\x00II\x2A\x00")
+	}
+	exif = order.AppendUint32(exif, 8)
+	exif = order.AppendUint16(exif, 1)
+	exif = order.AppendUint16(exif, tiffTagOrientation)
+	exif = order.AppendUint16(exif, 3)
+	exif = order.AppendUint32(exif, 1)
+	exif = order.AppendUint16(exif, uint16(orientation))
+	exif = order.AppendUint16(exif, 0)
+	exif = order.AppendUint32(exif, 0)
+
+	segment := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(exif)+2))
+	data := append([]byte{0xFF, 0xD8}, segment...)
+	data = append(data, exif...)
+	return append(data, buf.Bytes()[2:]...)
+}
+
+func TestOrientImage(t *testing.T) {
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{orientation: 0, want: [][]uint8{{0, 1, 2}, {10, 11, 12}}},
+		{orientation: 1, want: [][]uint8{{0, 1, 2}, {10, 11, 12}}},
+		{orientation: 2, want: [][]uint8{{2, 1, 0}, {12, 11, 10}}},
+		{orientation: 3, want: [][]uint8{{12, 11, 10}, {2, 1, 0}}},
+		{orientation: 4, want: [][]uint8{{10, 11, 12}, {0, 1, 2}}},
+		{orientation: 5, want: [][]uint8{{0, 10}, {1, 11}, {2, 12}}},
+		{orientation: 6, want: [][]uint8{{10, 0}, {11, 1}, {12, 2}}},
+		{orientation: 7, want: [][]uint8{{12, 2}, {11, 1}, {10, 0}}},
+		{orientation: 8, want: [][]uint8{{2, 12}, {1, 11}, {0, 10}}},
+	}
+	for _, tt := range tests {
+		if got := grayRows(orientImage(testImage(), tt.orientation)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("orientImage(%d) = %v, want %v", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	img := testImage()
+	var plain bytes.Buffer
+	if err := jpeg.Encode(&plain, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "big endian", data: testEXIFJPEG(t, img, 6, binary.BigEndian), want: 6},
+		{name: "little endian", data: testEXIFJPEG(t, img, 8, binary.LittleEndian), want: 8},
+		{name: "out of range", data: testEXIFJPEG(t, img, 9, binary.BigEndian), want: 1},
+		{name: "no EXIF", data: plain.Bytes(), want: 1},
+		{name: "not a JPEG", data: []byte("GIF89a"), want: 1},
+	}
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: jpegOrientation() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadImage(t *testing.T) {
+	dir := t.TempDir()
+	jpegData := testEXIFJPEG(t, testImage(), 6, binary.BigEndian)
+	files := map[string][]byte{
+		"scan.jpg": jpegData,
+		"scan.tif": testTIFF([]*image.Gray{testImage(), testImage()}, []int{0, 6}),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// JPEG data is kept with its orientation, pages decode upright
+	reader := NewImageReader(filepath.Join(dir, "scan.jpg"))
+	content, err := reader.ReadImage()
+	if err != nil {
+		t.Fatalf("ReadImage() error = %v", err)
+	}
+	if len(content.Blocks) != 1 || !bytes.Equal(content.Blocks[0].Image.Data, jpegData) || content.Blocks[0].Image.Orientation != 6 {
+		t.Fatalf("JPEG blocks = %+v, want the data with orientation 6", content.Blocks)
+	}
+	page, err := reader.DecodePage(1)
+	if err != nil {
+		t.Fatalf("DecodePage() error = %v", err)
+	}
+	if size := page.Bounds().Size(); size != image.Pt(2, 3) {
+		t.Errorf("JPEG page size = %v, want 2x3", size)
+	}
+
+	// Each TIFF page becomes an upright PNG on its own page
+	reader = NewImageReader(filepath.Join(dir, "scan.tif"))
+	content, err = reader.ReadImage()
+	if err != nil {
+		t.Fatalf("ReadImage() error = %v", err)
+	}
+	var kinds, names []string
+	for _, block := range content.Blocks {
+		kinds = append(kinds, string(block.Kind))
+		if block.Image != nil {
+			names = append(names, block.Image.Name)
+			if block.Image.Orientation > 1 {
+				t.Errorf("TIFF page %s keeps orientation %d", block.Image.Name, block.Image.Orientation)
+			}
+		}
+	}
+	if want := []string{"image", "page-break", "image"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("TIFF blocks = %q, want %q", kinds, want)
+	}
+	if want := []string{"scan-1.png", "scan-2.png"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("TIFF images = %q, want %q", names, want)
+	}
+	if content.Metadata["Format"] != "TIFF" || len(content.Pages) != 2 {
+		t.Errorf("TIFF format %q with %d pages, want TIFF with 2", content.Metadata["Format"], len(content.Pages))
+	}
+	for number, want := range map[int][][]uint8{
+		1: {{0, 1, 2}, {10, 11, 12}},
+		2: {{10, 0}, {11, 1}, {12, 2}},
+	} {
+		page, err := reader.DecodePage(number)
+		if err != nil {
+			t.Fatalf("DecodePage(%d) error = %v", number, err)
+		}
+		if got := grayRows(page); !reflect.DeepEqual(got, want) {
+			t.Errorf("TIFF page %d = %v, want %v", number, got, want)
+		}
+	}
+	if _, err := reader.DecodePage(3); err == nil {
+		t.Errorf("DecodePage(3) succeeded, want an error")
+	}
+}
@@ -0,0 +1,487 @@
+package readers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+
+	"myconverter/interfaces"
+)
+
+// Patterns of Markdown inline structure
+var (
+	mdEntity       = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	mdAutolink     = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^<>\s]*|[A-Za-z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[A-Za-z0-9.-]+)>`)
+	mdInlineTag    = regexp.MustCompile(`^<(/?)([A-Za-z][A-Za-z0-9]*)(?:\s[^<>]*)?/?>`)
+	dataURIPattern = regexp.MustCompile(`(?is)^data:image/([a-z+.-]+);base64,(.*)$`)
+)
+
+// markdownNode is a piece of inline text, or a run of emphasis delimiters
+// that is matched after the whole text is scanned
+type markdownNode struct {
+	text   string
+	format interfaces.Run
+	// delim is the delimiter character, 0 for text
+	delim    byte
+	count    int
+	canOpen  bool
+	canClose bool
+}
+
+// inline parses inline Markdown into runs and the image blocks it contains
+func (r *MarkdownReader) inline(text string) ([]interfaces.Run, []interfaces.Block) {
+	var images []interfaces.Block
+	nodes := r.inlineNodes(text, interfaces.Run{}, &images)
+	matchEmphasis(nodes)
+
+	var runs []interfaces.Run
+	for _, node := range nodes {
+		if node.delim != 0 {
+			node.text = strings.Repeat(string(node.delim), node.count)
+		}
+		if node.text != "" {
+			runs = appendRun(runs, node.text, node.format)
+		}
+	}
+	return runs, images
+}
+
+// inlineNodes scans text into nodes. HTML formatting tags change the format
+// of the text that follows them.
+func (r *MarkdownReader) inlineNodes(text string, format interfaces.Run, images *[]interfaces.Block) []markdownNode {
+	var nodes []markdownNode
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			nodes = append(nodes, markdownNode{text: literal.String(), format: format})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && text[i+1] == '\n':
+			flush()
+			nodes = append(nodes, markdownNode{text: "\n", format: format})
+			i += 2
+
+		case c == '\\' && i+1 < len(text) && isASCIIPunct(text[i+1]):
+			literal.WriteByte(text[i+1])
+			i += 2
+
+		case c == '\n':
+			// Two trailing spaces make a hard break, otherwise lines join with a space
+			flush()
+			hard := false
+			if n := len(nodes); n > 0 && nodes[n-1].delim == 0 {
+				hard = strings.HasSuffix(nodes[n-1].text, "  ")
+				nodes[n-1].text = strings.TrimRight(nodes[n-1].text, " ")
+			}
+			if hard {
+				nodes = append(nodes, markdownNode{text: "\n", format: format})
+			} else {
+				nodes = append(nodes, markdownNode{text: " ", format: format})
+			}
+			i++
+			for i < len(text) && text[i] == ' ' {
+				i++
+			}
+
+		case c == '`':
+			n := countRun(text, i, '`')
+			end := findBacktickRun(text, i+n, n)
+			if end < 0 {
+				literal.WriteString(text[i : i+n])
+				i += n
+				break
+			}
+			flush()
+			code := strings.ReplaceAll(text[i+n:end], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			codeFormat := format
+			codeFormat.Font = markdownCodeFont
+			nodes = append(nodes, markdownNode{text: code, format: codeFormat})
+			i = end + n
+
+		case c == '*' || c == '_' || c == '~':
+			flush()
+			n := countRun(text, i, c)
+			before, _ := utf8.DecodeLastRuneInString(text[:i])
+			after, _ := utf8.DecodeRuneInString(text[i+n:])
+			if i == 0 {
+				before = ' '
+			}
+			if i+n == len(text) {
+				after = ' '
+			}
+			left := !unicode.IsSpace(after) && (!isPunctRune(after) || unicode.IsSpace(before) || isPunctRune(before))
+			right := !unicode.IsSpace(before) && (!isPunctRune(before) || unicode.IsSpace(after) || isPunctRune(after))
+			node := markdownNode{delim: c, count: n, format: format, canOpen: left, canClose: right}
+			if c == '_' {
+				node.canOpen = left && (!right || isPunctRune(before))
+				node.canClose = right && (!left || isPunctRune(after))
+			}
+			nodes = append(nodes, node)
+			i += n
+
+		case c == '!' && i+1 < len(text) && text[i+1] == '[':
+			alt, dest, end, ok := r.parseLink(text, i+1)
+			if !ok {
+				literal.WriteByte(c)
+				i++
+				break
+			}
+			flush()
+			if image := r.loadImage(dest); image != nil {
+				*images = append(*images, interfaces.Block{Kind: interfaces.BlockImage, Image: image})
+			} else {
+				// Images that cannot be loaded keep their description
+				nodes = append(nodes, r.inlineNodes(alt, format, images)...)
+			}
+			i = end
+
+		case c == '[':
+			if end := strings.IndexByte(text[i:], ']'); strings.HasPrefix(text[i:], "[^") && end > 2 {
+				if _, ok := r.notes[text[i+2:i+end]]; ok {
+					flush()
+					nodes = append(nodes, markdownNode{text: interfaces.NoteRef(r.noteID(text[i+2 : i+end])), format: format})
+					i += end + 1
+					break
+				}
+			}
+			label, _, end, ok := r.parseLink(text, i)
+			if !ok {
+				literal.WriteByte(c)
+				i++
+				break
+			}
+			// Links keep their text only
+			flush()
+			nodes = append(nodes, r.inlineNodes(label, format, images)...)
+			i = end
+
+		case c == '<':
+			if match := mdAutolink.FindStringSubmatch(text[i:]); match != nil {
+				literal.WriteString(match[1])
+				i += len(match[0])
+				break
+			}
+			match := mdInlineTag.FindStringSubmatch(text[i:])
+			if match == nil {
+				literal.WriteByte(c)
+				i++
+				break
+			}
+			flush()
+			on := match[1] == ""
+			switch strings.ToLower(match[2]) {
+			case "b", "strong":
+				format.Bold = on
+			case "i", "em":
+				format.Italic = on
+			case "u", "ins":
+				format.Underline = on
+			case "s", "del", "strike":
+				format.Strike = on
+			case "code":
+				if on {
+					format.Font = markdownCodeFont
+				} else {
+					format.Font = ""
+				}
+			case "br":
+				nodes = append(nodes, markdownNode{text: "\n", format: format})
+			}
+			i += len(match[0])
+
+		case c == '&':
+			if match := mdEntity.FindString(text[i:]); match != "" {
+				literal.WriteString(html.UnescapeString(match))
+				i += len(match)
+				break
+			}
+			literal.WriteByte(c)
+			i++
+
+		default:
+			literal.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return nodes
+}
+
+// matchEmphasis pairs delimiter runs and applies their formatting to the
+// nodes between them. One delimiter on each side makes italic, two make
+// bold, and two tildes strike through.
+func matchEmphasis(nodes []markdownNode) {
+	for closer := 0; closer < len(nodes); closer++ {
+		c := &nodes[closer]
+		if c.delim == 0 || !c.canClose || c.count == 0 {
+			continue
+		}
+		for opener := closer - 1; opener >= 0; opener-- {
+			o := &nodes[opener]
+			if o.delim != c.delim || !o.canOpen || o.count == 0 {
+				continue
+			}
+			// Runs that both open and close cannot pair when their lengths add
+			// up to a multiple of three
+			if c.delim != '~' && (o.canClose || c.canOpen) && (o.count+c.count)%3 == 0 && (o.count%3 != 0 || c.count%3 != 0) {
+				continue
+			}
+			use := 1
+			if o.count >= 2 && c.count >= 2 {
+				use = 2
+			}
+			if c.delim == '~' && use != 2 {
+				continue
+			}
+
+			for k := opener + 1; k < closer; k++ {
+				switch {
+				case c.delim == '~':
+					nodes[k].format.Strike = true
+				case use == 2:
+					nodes[k].format.Bold = true
+				default:
+					nodes[k].format.Italic = true
+				}
+				// Delimiters inside a matched pair cannot pair with ones outside
+				if nodes[k].delim != 0 {
+					nodes[k].canOpen, nodes[k].canClose = false, false
+				}
+			}
+			o.count -= use
+			c.count -= use
+			if c.count > 0 {
+				// The rest of the closer may match another opener
+				closer--
+			}
+			break
+		}
+	}
+}
+
+// parseLink parses a link or image starting at the opening bracket and
+// returns its text, destination and end. Reference links use the link
+// definitions of the document.
+func (r *MarkdownReader) parseLink(text string, start int) (string, string, int, bool) {
+	depth := 0
+	close := -1
+	for i := start; i < len(text) && close < 0; i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '`':
+			// Brackets inside code spans do not count
+			n := countRun(text, i, '`')
+			if end := findBacktickRun(text, i+n, n); end >= 0 {
+				i = end + n - 1
+			} else {
+				i += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				close = i
+			}
+		}
+	}
+	if close < 0 {
+		return "", "", 0, false
+	}
+	label := text[start+1 : close]
+	rest := text[close+1:]
+
+	if strings.HasPrefix(rest, "(") {
+		dest, n, ok := parseLinkDestination(rest)
+		if ok {
+			return label, dest, close + 1 + n, true
+		}
+	}
+	if strings.HasPrefix(rest, "[") {
+		if end := strings.IndexByte(rest, ']'); end >= 0 {
+			ref := rest[1:end]
+			if ref == "" {
+				ref = label
+			}
+			if dest, ok := r.links[strings.ToLower(ref)]; ok {
+				return label, dest, close + 1 + end + 1, true
+			}
+		}
+	}
+	if dest, ok := r.links[strings.ToLower(label)]; ok {
+		return label, dest, close + 1, true
+	}
+	return "", "", 0, false
+}
+
+// parseLinkDestination parses the parenthesized destination and optional
+// title after a link's text and returns the destination and its length
+func parseLinkDestination(text string) (string, int, bool) {
+	i := 1
+	for i < len(text) && (text[i] == ' ' || text[i] == '\n') {
+		i++
+	}
+
+	var dest strings.Builder
+	if i < len(text) && text[i] == '<' {
+		end := strings.IndexByte(text[i:], '>')
+		if end < 0 {
+			return "", 0, false
+		}
+		dest.WriteString(text[i+1 : i+end])
+		i += end + 1
+	} else {
+		depth := 0
+	scan:
+		for ; i < len(text); i++ {
+			switch c := text[i]; {
+			case c == '\\' && i+1 < len(text) && isASCIIPunct(text[i+1]):
+				i++
+				dest.WriteByte(text[i])
+			case c == '(':
+				depth++
+				dest.WriteByte(c)
+			case c == ')' && depth == 0, c == ' ', c == '\n':
+				break scan
+			case c == ')':
+				depth--
+				dest.WriteByte(c)
+			default:
+				dest.WriteByte(c)
+			}
+		}
+	}
+
+	// A title follows the destination in quotes or parentheses
+	for i < len(text) && (text[i] == ' ' || text[i] == '\n') {
+		i++
+	}
+	if i < len(text) && (text[i] == '"' || text[i] == '\'' || text[i] == '(') {
+		closing := text[i]
+		if closing == '(' {
+			closing = ')'
+		}
+		end := strings.IndexByte(text[i+1:], closing)
+		if end < 0 {
+			return "", 0, false
+		}
+		i += end + 2
+		for i < len(text) && (text[i] == ' ' || text[i] == '\n') {
+			i++
+		}
+	}
+	if i >= len(text) || text[i] != ')' {
+		return "", 0, false
+	}
+	return html.UnescapeString(dest.String()), i + 1, true
+}
+
+// loadImage loads an image from a data URI or a file relative to the
+// Markdown file
+func (r *MarkdownReader) loadImage(source string) *interfaces.Image {
+	r.images++
+	return loadImageSource(filepath.Dir(r.filePath), source, r.images)
+}
+
+// loadImageSource loads an image from a data URI or a file under dir,
+// relative paths being resolved against it. Images of data URIs are named
+// after their index. Remote images are not fetched, and absolute paths,
+// file URLs and paths leading out of dir are not read, so a document
+// cannot pull other files of the machine into the output. Data that is not
+// a picture is skipped.
+func loadImageSource(dir, source string, index int) *interfaces.Image {
+	if match := dataURIPattern.FindStringSubmatch(source); match != nil {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(match[2]), ""))
+		if err != nil || !isImageData(data) {
+			return nil
+		}
+		ext := strings.TrimSuffix(strings.ToLower(match[1]), "+xml")
+		if ext == "jpeg" {
+			ext = "jpg"
+		}
+		return &interfaces.Image{Name: fmt.Sprintf("image%d.%s", index, ext), Data: data}
+	}
+
+	// Remote, cid:, file: and drive letter sources
+	if strings.Contains(source, ":") {
+		return nil
+	}
+	path, err := url.PathUnescape(source)
+	if err != nil || path == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "/") {
+		return nil
+	}
+	path = filepath.Join(dir, filepath.FromSlash(path))
+	if rel, err := filepath.Rel(filepath.Clean(dir), path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !isImageData(data) {
+		return nil
+	}
+	return &interfaces.Image{Name: filepath.Base(path), Data: data}
+}
+
+// isImageData reports whether data is a picture in a format that can be
+// decoded
+func isImageData(data []byte) bool {
+	_, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return err == nil
+}
+
+// countRun returns the number of consecutive c bytes at position i
+func countRun(text string, i int, c byte) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// findBacktickRun returns the position of the next run of exactly n
+// backticks at or after start, or -1
+func findBacktickRun(text string, start, n int) int {
+	for i := start; i < len(text); {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+		m := countRun(text, i, '`')
+		if m == n {
+			return i
+		}
+		i += m
+	}
+	return -1
+}
+
+// isASCIIPunct reports whether c is an ASCII punctuation character, which
+// a backslash escapes
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && c > ' ' && c < 0x7F && !(c >= '0' && c <= '9' || isLetter(c))
+}
+
+// isPunctRune reports whether r is punctuation for the flanking rules of emphasis
+func isPunctRune(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,599 @@
+package readers
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"myconverter/hwpx"
+	"myconverter/interfaces"
+)
+
+// markdownCodeFont is the font of code spans and code blocks
+const markdownCodeFont = "Courier New"
+
+// Patterns of Markdown block structure
+var (
+	mdATXHeading    = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdSetextLine    = regexp.MustCompile(`^(=+|-+)[ \t]*$`)
+	mdThematicBreak = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdFence         = regexp.MustCompile("^(`{3,}|~{3,})[ \t]*([^`]*)$")
+	mdListMarker    = regexp.MustCompile(`^([-+*]|\d{1,9}[.)])(?:([ \t]+)|$)`)
+	mdNoteDef       = regexp.MustCompile(`^\[\^([^\]\s]+)\]:[ \t]?(.*)$`)
+	mdLinkDef       = regexp.MustCompile(`^\[([^\]]+)\]:[ \t]*<?([^\s>]+)>?(?:[ \t]+.*)?$`)
+	mdTableDelim    = regexp.MustCompile(`^\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	mdFrontMatter   = regexp.MustCompile(`^([A-Za-z_]+):[ \t]*(.*)$`)
+	mdHTMLRow       = regexp.MustCompile(`(?is)<tr[^>]*>(.*?)</tr>`)
+	mdHTMLCell      = regexp.MustCompile(`(?is)<t([dh])([^>]*)>(.*?)</t[dh]>`)
+	mdHTMLSpan      = regexp.MustCompile(`(?i)(colspan|rowspan)\s*=\s*"?(\d+)`)
+	mdHTMLImage     = regexp.MustCompile(`(?i)<img[^>]*\ssrc\s*=\s*"([^"]*)"[^>]*>`)
+	mdHTMLBreak     = regexp.MustCompile(`(?i)<br\s*/?>`)
+	mdHTMLTag       = regexp.MustCompile(`<[^>]*>`)
+)
+
+// mdMetadata maps front matter keys to metadata keys
+var mdMetadata = map[string]string{
+	"title":       "Title",
+	"author":      "Author",
+	"subject":     "Subject",
+	"description": "Description",
+	"keywords":    "Keywords",
+	"tags":        "Keywords",
+	"date":        "CreationDate",
+	"lang":        "Language",
+	"language":    "Language",
+}
+
+// MarkdownReader implements FileReader for CommonMark documents, with
+// GitHub flavored tables, strikethrough and footnotes
+type MarkdownReader struct {
+	filePath string
+	content  *interfaces.PDFContent
+
+	// links holds link reference definitions by lower-case label
+	links map[string]string
+	// notes holds footnote definitions by label, in definition order
+	notes     map[string]string
+	noteOrder []string
+	// noteIDs maps footnote labels to the IDs of the notes already referenced
+	noteIDs map[string]string
+	images  int
+}
+
+// markdownList is an open list item
+type markdownList struct {
+	// indent is the column where the item's content starts
+	indent  int
+	ordered bool
+}
+
+// NewMarkdownReader creates a new MarkdownReader
+func NewMarkdownReader(filePath string) *MarkdownReader {
+	return &MarkdownReader{
+		filePath: filePath,
+		content:  &interfaces.PDFContent{},
+	}
+}
+
+// Read reads the Markdown file and extracts its content
+func (r *MarkdownReader) Read(filePath string) error {
+	r.filePath = filePath
+	_, err := r.ReadMarkdown()
+	return err
+}
+
+// GetContent returns the content extracted by Read
+func (r *MarkdownReader) GetContent() *interfaces.PDFContent {
+	return r.content
+}
+
+// ReadMarkdown parses the Markdown file into the content structure
+func (r *MarkdownReader) ReadMarkdown() (*interfaces.PDFContent, error) {
+	data, err := os.ReadFile(r.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	text, encoding := decodeText(data)
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(text, "\n")
+
+	r.content = &interfaces.PDFContent{Metadata: map[string]string{"Encoding": encoding}}
+	r.links = make(map[string]string)
+	r.notes = make(map[string]string)
+	r.noteOrder = nil
+	r.noteIDs = make(map[string]string)
+	r.images = 0
+
+	lines = r.frontMatter(lines)
+	lines = r.definitions(lines)
+	r.content.Blocks = r.parseBlocks(lines)
+
+	// Notes defined without references are kept after the referenced ones
+	for _, label := range r.noteOrder {
+		r.noteID(label)
+	}
+
+	r.content.Text = strings.Join(interfaces.BlockLines(r.content.Blocks), "\n")
+	r.content.Pages = []interfaces.PDFPage{{Number: 1, Text: r.content.Text}}
+	return r.content, nil
+}
+
+// frontMatter reads a YAML front matter block of simple key: value lines
+// into the metadata and returns the remaining lines
+func (r *MarkdownReader) frontMatter(lines []string) []string {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "---" || line == "..." {
+			return lines[i+1:]
+		}
+		match := mdFrontMatter.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if key, ok := mdMetadata[strings.ToLower(match[1])]; ok {
+			value := strings.Trim(strings.TrimSpace(match[2]), `"'`)
+			value = strings.Trim(value, "[]")
+			r.content.Metadata[key] = value
+		}
+	}
+	// Without a closing line the dashes are a thematic break
+	return lines
+}
+
+// definitions collects footnote and link reference definitions outside
+// code blocks and returns the other lines
+func (r *MarkdownReader) definitions(lines []string) []string {
+	var rest []string
+	fence := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			rest = append(rest, line)
+			continue
+		}
+		if match := mdFence.FindStringSubmatch(trimmed); match != nil && len(line)-len(trimmed) < 4 {
+			fence = match[1]
+			rest = append(rest, line)
+			continue
+		}
+
+		if match := mdNoteDef.FindStringSubmatch(trimmed); match != nil && len(line)-len(trimmed) < 4 {
+			// Indented lines continue the note
+			text := []string{match[2]}
+			for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], "    ") || strings.HasPrefix(lines[i+1], "\t")) {
+				i++
+				text = append(text, strings.TrimSpace(lines[i]))
+			}
+			label := match[1]
+			if _, ok := r.notes[label]; !ok {
+				r.noteOrder = append(r.noteOrder, label)
+			}
+			r.notes[label] = strings.Join(text, "\n")
+			continue
+		}
+		if match := mdLinkDef.FindStringSubmatch(trimmed); match != nil && len(line)-len(trimmed) < 4 && !strings.HasPrefix(match[1], "^") {
+			r.links[strings.ToLower(match[1])] = match[2]
+			continue
+		}
+		rest = append(rest, line)
+	}
+	return rest
+}
+
+// parseBlocks parses lines into blocks
+func (r *MarkdownReader) parseBlocks(lines []string) []interfaces.Block {
+	var blocks []interfaces.Block
+	var paragraph []string
+	var lists []*markdownList
+	// item is the list item started by the paragraph being read
+	var item *interfaces.Block
+	sawBlank := false
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			if item != nil && item.Kind == interfaces.BlockListItem {
+				// Empty list items still count as items
+				blocks = append(blocks, *item)
+			}
+			item = nil
+			return
+		}
+		template := interfaces.Block{Kind: interfaces.BlockParagraph}
+		if item != nil {
+			template = *item
+		}
+		blocks = append(blocks, r.paragraphBlocks(template, strings.Join(paragraph, "\n"))...)
+		paragraph, item = nil, nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := expandTabs(lines[i])
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+
+		if trimmed == "" {
+			flush()
+			sawBlank = true
+			continue
+		}
+
+		// Setext underlines turn the paragraph into a heading
+		if len(paragraph) > 0 && indent < 4 && mdSetextLine.MatchString(trimmed) {
+			level := 1
+			if trimmed[0] == '-' {
+				level = 2
+			}
+			item = &interfaces.Block{Kind: interfaces.BlockHeading, Level: level}
+			flush()
+			continue
+		}
+
+		// Lazy continuation lines extend the paragraph, and any list item
+		// ends the paragraph of the item before it
+		if len(paragraph) > 0 && !sawBlank && !r.startsBlock(trimmed) && !(item != nil && mdListMarker.MatchString(trimmed)) {
+			paragraph = append(paragraph, trimmed)
+			continue
+		}
+
+		// Lines indented less than the content of a list item end it
+		for len(lists) > 0 && indent < lists[len(lists)-1].indent {
+			lists = lists[:len(lists)-1]
+		}
+		base := 0
+		if len(lists) > 0 {
+			base = lists[len(lists)-1].indent
+		}
+		relative := indent - base
+		sawBlank = false
+
+		switch {
+		case relative >= 4 && len(paragraph) == 0:
+			// Indented code runs until a line indented less
+			code := []string{line[base+4:]}
+			for i+1 < len(lines) {
+				next := expandTabs(lines[i+1])
+				if strings.TrimSpace(next) != "" && !strings.HasPrefix(next, strings.Repeat(" ", base+4)) {
+					break
+				}
+				i++
+				code = append(code, strings.TrimPrefix(next, strings.Repeat(" ", base+4)))
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, markdownCodeBlock(code))
+
+		case mdFence.MatchString(trimmed) && relative < 4:
+			flush()
+			fence := mdFence.FindStringSubmatch(trimmed)[1]
+			var code []string
+			for i+1 < len(lines) {
+				i++
+				next := expandTabs(lines[i])
+				if closing := strings.TrimSpace(next); strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+					break
+				}
+				// Content lines lose up to the indentation of the opening fence
+				for n := 0; n < indent && strings.HasPrefix(next, " "); n++ {
+					next = next[1:]
+				}
+				code = append(code, next)
+			}
+			blocks = append(blocks, markdownCodeBlock(code))
+
+		case mdATXHeading.MatchString(trimmed) && relative < 4:
+			flush()
+			match := mdATXHeading.FindStringSubmatch(trimmed)
+			blocks = append(blocks, r.paragraphBlocks(interfaces.Block{Kind: interfaces.BlockHeading, Level: len(match[1])}, match[2])...)
+
+		case mdThematicBreak.MatchString(trimmed) && relative < 4:
+			flush()
+
+		case strings.HasPrefix(trimmed, ">") && relative < 4:
+			flush()
+			var quote []string
+			for ; i < len(lines); i++ {
+				next := strings.TrimLeft(expandTabs(lines[i]), " ")
+				if !strings.HasPrefix(next, ">") {
+					// Paragraph text continues the quote until a blank line
+					if strings.TrimSpace(next) == "" || r.startsBlock(next) {
+						break
+					}
+					quote = append(quote, next)
+					continue
+				}
+				next = strings.TrimPrefix(next, ">")
+				quote = append(quote, strings.TrimPrefix(next, " "))
+			}
+			i--
+			for _, block := range r.parseBlocks(quote) {
+				if block.Kind == interfaces.BlockParagraph && block.Style == "" {
+					block.Style = "Quote"
+				}
+				blocks = append(blocks, block)
+			}
+
+		case strings.HasPrefix(strings.ToLower(trimmed), "<table"):
+			flush()
+			var table []string
+			for ; i < len(lines); i++ {
+				table = append(table, lines[i])
+				if strings.Contains(strings.ToLower(lines[i]), "</table>") {
+					break
+				}
+			}
+			if block, ok := r.htmlTable(strings.Join(table, "\n")); ok {
+				blocks = append(blocks, block)
+			}
+
+		case strings.HasPrefix(trimmed, "<!--"):
+			flush()
+			for ; i < len(lines) && !strings.Contains(lines[i], "-->"); i++ {
+			}
+
+		case len(paragraph) == 0 && strings.Contains(trimmed, "|") && i+1 < len(lines) && mdTableDelim.MatchString(strings.TrimSpace(lines[i+1])):
+			var rows []string
+			for ; i < len(lines) && strings.Contains(lines[i], "|"); i++ {
+				rows = append(rows, strings.TrimSpace(lines[i]))
+			}
+			i--
+			blocks = append(blocks, r.pipeTable(rows))
+
+		case mdListMarker.MatchString(trimmed) && !(len(paragraph) > 0 && item == nil && !r.interruptsParagraph(trimmed)):
+			flush()
+			match := mdListMarker.FindStringSubmatch(trimmed)
+			marker := match[1]
+			// Content starts after the marker and at most four spaces
+			spaces := len(match[2])
+			if spaces > 4 || spaces == 0 {
+				spaces = 1
+			}
+			list := &markdownList{indent: indent + len(marker) + spaces, ordered: marker[len(marker)-1] == '.' || marker[len(marker)-1] == ')'}
+			lists = append(lists, list)
+			item = &interfaces.Block{Kind: interfaces.BlockListItem, Level: len(lists), Ordered: list.ordered}
+			if text := strings.TrimSpace(trimmed[len(match[0]):]); text != "" {
+				paragraph = append(paragraph, strings.TrimLeft(trimmed[len(match[0]):], " "))
+			}
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// startsBlock reports whether a line starts a block that interrupts a paragraph
+func (r *MarkdownReader) startsBlock(trimmed string) bool {
+	switch {
+	case mdATXHeading.MatchString(trimmed), mdFence.MatchString(trimmed), mdThematicBreak.MatchString(trimmed):
+		return true
+	case strings.HasPrefix(trimmed, ">"), strings.HasPrefix(strings.ToLower(trimmed), "<table"):
+		return true
+	case mdListMarker.MatchString(trimmed):
+		return r.interruptsParagraph(trimmed)
+	}
+	return false
+}
+
+// interruptsParagraph reports whether a list item may interrupt a
+// paragraph: it must have content, and ordered lists must start at 1
+func (r *MarkdownReader) interruptsParagraph(trimmed string) bool {
+	match := mdListMarker.FindStringSubmatch(trimmed)
+	if match == nil || strings.TrimSpace(trimmed[len(match[0]):]) == "" {
+		return false
+	}
+	marker := match[1]
+	if marker == "-" || marker == "+" || marker == "*" {
+		return true
+	}
+	return strings.TrimLeft(marker[:len(marker)-1], "0") == "1"
+}
+
+// paragraphBlocks returns the block of a paragraph's inline text followed
+// by the images it contains
+func (r *MarkdownReader) paragraphBlocks(template interfaces.Block, text string) []interfaces.Block {
+	runs, images := r.inline(text)
+	var blocks []interfaces.Block
+	block := template
+	block.Runs = trimRuns(runs)
+	if strings.TrimSpace(block.Text()) != "" || (block.Kind == interfaces.BlockListItem && len(images) == 0) {
+		blocks = append(blocks, block)
+	}
+	return append(blocks, images...)
+}
+
+// markdownCodeBlock returns a code block as a paragraph in the code font
+func markdownCodeBlock(lines []string) interfaces.Block {
+	return interfaces.Block{
+		Kind:  interfaces.BlockParagraph,
+		Style: "Code",
+		Runs:  []interfaces.Run{{Text: strings.Join(lines, "\n"), Font: markdownCodeFont}},
+	}
+}
+
+// pipeTable returns the table of GFM pipe table rows, the second row being
+// the delimiter row with the column alignments
+func (r *MarkdownReader) pipeTable(rows []string) interfaces.Block {
+	var aligns []string
+	for _, spec := range splitTableRow(rows[1]) {
+		spec = strings.TrimSpace(spec)
+		switch {
+		case strings.HasPrefix(spec, ":") && strings.HasSuffix(spec, ":"):
+			aligns = append(aligns, "CENTER")
+		case strings.HasSuffix(spec, ":"):
+			aligns = append(aligns, "RIGHT")
+		case strings.HasPrefix(spec, ":"):
+			aligns = append(aligns, "LEFT")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+
+	table := &interfaces.Table{}
+	for _, row := range append(rows[:1], rows[2:]...) {
+		cells := splitTableRow(row)
+		var tableRow []interfaces.TableCell
+		// Rows have as many cells as the delimiter row
+		for col := range aligns {
+			cell := interfaces.TableCell{Row: len(table.Rows), Col: col, RowSpan: 1, ColSpan: 1}
+			if col < len(cells) {
+				// Line breaks in a cell separate its paragraphs
+				for _, part := range mdHTMLBreak.Split(strings.TrimSpace(cells[col]), -1) {
+					cell.Blocks = append(cell.Blocks, r.paragraphBlocks(interfaces.Block{Kind: interfaces.BlockParagraph, Align: aligns[col]}, part)...)
+				}
+			}
+			tableRow = append(tableRow, cell)
+		}
+		table.Rows = append(table.Rows, tableRow)
+	}
+	return interfaces.Block{Kind: interfaces.BlockTable, Table: table}
+}
+
+// splitTableRow splits a pipe table row at unescaped pipes outside code spans
+func splitTableRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(row); i++ {
+		switch c := row[i]; {
+		case c == '\\' && i+1 < len(row) && row[i+1] == '|':
+			// The escaped pipe is kept as text of the cell
+			cell.WriteString(`\|`)
+			i++
+		case c == '`':
+			inCode = !inCode
+			cell.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	return append(cells, cell.String())
+}
+
+// htmlTable returns the table of an HTML table block, as written for
+// tables with merged cells
+func (r *MarkdownReader) htmlTable(source string) (interfaces.Block, bool) {
+	table := &interfaces.Table{}
+	occupied := make(map[[2]int]bool)
+	for row, rowMatch := range mdHTMLRow.FindAllStringSubmatch(source, -1) {
+		var cells []interfaces.TableCell
+		col := 0
+		for _, cellMatch := range mdHTMLCell.FindAllStringSubmatch(rowMatch[1], -1) {
+			for occupied[[2]int{row, col}] {
+				col++
+			}
+			cell := interfaces.TableCell{Row: row, Col: col, RowSpan: 1, ColSpan: 1}
+			for _, span := range mdHTMLSpan.FindAllStringSubmatch(cellMatch[2], -1) {
+				n, _ := strconv.Atoi(span[2])
+				if strings.EqualFold(span[1], "colspan") {
+					cell.ColSpan = max(n, 1)
+				} else {
+					cell.RowSpan = max(n, 1)
+				}
+			}
+			for y := row; y < row+cell.RowSpan; y++ {
+				for x := col; x < col+cell.ColSpan; x++ {
+					occupied[[2]int{y, x}] = true
+				}
+			}
+
+			for _, part := range mdHTMLBreak.Split(cellMatch[3], -1) {
+				if match := mdHTMLImage.FindStringSubmatch(part); match != nil {
+					if image := r.loadImage(html.UnescapeString(match[1])); image != nil {
+						cell.Blocks = append(cell.Blocks, interfaces.Block{Kind: interfaces.BlockImage, Image: image})
+					}
+				}
+				text := strings.TrimSpace(html.UnescapeString(mdHTMLTag.ReplaceAllString(part, "")))
+				if text != "" {
+					cell.Blocks = append(cell.Blocks, interfaces.Block{Kind: interfaces.BlockParagraph, Runs: []interfaces.Run{{Text: text}}})
+				}
+			}
+			cells = append(cells, cell)
+			col += cell.ColSpan
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	if len(table.Rows) == 0 {
+		return interfaces.Block{}, false
+	}
+	return interfaces.Block{Kind: interfaces.BlockTable, Table: table}, true
+}
+
+// noteID returns the ID of the note defined with a label, adding the note
+// on its first reference. Labels like e1 mark endnotes.
+func (r *MarkdownReader) noteID(label string) string {
+	if id, ok := r.noteIDs[label]; ok {
+		return id
+	}
+
+	kind := interfaces.Footnote
+	if len(label) > 1 && label[0] == 'e' && strings.Trim(label[1:], "0123456789") == "" {
+		kind = interfaces.Endnote
+	}
+	count := 0
+	for _, note := range r.content.Notes {
+		if note.Kind == kind {
+			count++
+		}
+	}
+
+	// Note text is inline Markdown without structure
+	runs, _ := r.inline(r.notes[label])
+	var text strings.Builder
+	for _, run := range runs {
+		text.WriteString(run.Text)
+	}
+
+	note := interfaces.Note{Kind: kind, ID: strconv.Itoa(count + 1), Label: label, Text: strings.TrimSpace(text.String())}
+	if kind == interfaces.Endnote {
+		note.ID = "e" + note.ID
+		note.Label = hwpx.FormatNumber("ROMAN_SMALL", count+1)
+	}
+	r.noteIDs[label] = note.ID
+	r.content.Notes = append(r.content.Notes, note)
+	return note.ID
+}
+
+// expandTabs replaces leading tabs by spaces to the next multiple of four columns
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var out strings.Builder
+	column := 0
+	for i, c := range line {
+		switch c {
+		case '\t':
+			n := 4 - column%4
+			out.WriteString(strings.Repeat(" ", n))
+			column += n
+		case ' ':
+			out.WriteByte(' ')
+			column++
+		default:
+			out.WriteString(line[i:])
+			return out.String()
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,182 @@
+package readers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"myconverter/interfaces"
+)
+
+// readTestMarkdown reads Markdown source through a file
+func readTestMarkdown(t *testing.T, source string) *interfaces.PDFContent {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.md")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content, err := NewMarkdownReader(path).ReadMarkdown()
+	if err != nil {
+		t.Fatalf("ReadMarkdown() error = %v", err)
+	}
+	return content
+}
+
+// describeBlocks returns a line for each block with its kind, level, list
+// type, style and text
+func describeBlocks(blocks []interfaces.Block) []string {
+	var lines []string
+	for _, block := range blocks {
+		line := string(block.Kind)
+		if block.Level > 0 {
+			line += fmt.Sprint(block.Level)
+		}
+		if block.Ordered {
+			line += " ordered"
+		}
+		if block.Style != "" {
+			line += " [" + block.Style + "]"
+		}
+		switch {
+		case block.Table != nil:
+			var rows []string
+			for _, row := range block.Table.Rows {
+				var cells []string
+				for _, cell := range row {
+					cells = append(cells, strings.Join(interfaces.BlockLines(cell.Blocks), " "))
+				}
+				rows = append(rows, strings.Join(cells, "|"))
+			}
+			line += " " + strings.Join(rows, " / ")
+		case block.Image != nil:
+			line += " " + block.Image.Name
+		default:
+			line += fmt.Sprintf(" %q", block.Text())
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestMarkdownBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "headings",
+			source: "# One #\n\nTwo\n---\n\n###### Six",
+			want:   []string{`heading1 "One"`, `heading2 "Two"`, `heading6 "Six"`},
+		},
+		{
+			name:   "soft line breaks",
+			source: "first\nsecond\n\nthird",
+			want:   []string{`paragraph "first second"`, `paragraph "third"`},
+		},
+		{
+			name:   "nested lists",
+			source: "- a\n  1. b\n  2. c\n- d",
+			want:   []string{`list-item1 "a"`, `list-item2 ordered "b"`, `list-item2 ordered "c"`, `list-item1 "d"`},
+		},
+		{
+			name:   "ordered list not starting at one does not interrupt a paragraph",
+			source: "text\n2. more",
+			want:   []string{`paragraph "text 2. more"`},
+		},
+		{
+			name:   "fenced code keeps indentation",
+			source: "```go\nfunc f() {\n\treturn\n}\n```",
+			want:   []string{`paragraph [Code] "func f() {\n    return\n}"`},
+		},
+		{
+			name:   "indented code",
+			source: "para\n\n    a\n      b\n\nafter",
+			want:   []string{`paragraph "para"`, `paragraph [Code] "a\n  b"`, `paragraph "after"`},
+		},
+		{
+			name:   "quote",
+			source: "> quoted\nlazy\n\nafter",
+			want:   []string{`paragraph [Quote] "quoted lazy"`, `paragraph "after"`},
+		},
+		{
+			name:   "pipe table",
+			source: "| a | b |\n|:--|--:|\n| 1 | 2 |",
+			want:   []string{`table a|b / 1|2`},
+		},
+		{
+			name:   "thematic break and comment",
+			source: "a\n\n***\n\n<!-- hidden\n-->\nb",
+			want:   []string{`paragraph "a"`, `paragraph "b"`},
+		},
+		{
+			name:   "front matter",
+			source: "---\ntitle: Doc\n---\nbody",
+			want:   []string{`paragraph "body"`},
+		},
+	}
+	for _, tt := range tests {
+		got := describeBlocks(readTestMarkdown(t, tt.source).Blocks)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: blocks = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownInline(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []interfaces.Run
+	}{
+		{
+			name:   "emphasis",
+			source: "a *b* **c** ~~d~~",
+			want: []interfaces.Run{
+				{Text: "a "}, {Text: "b", Italic: true}, {Text: " "}, {Text: "c", Bold: true}, {Text: " "}, {Text: "d", Strike: true},
+			},
+		},
+		{
+			name:   "nested emphasis",
+			source: "***both***",
+			want:   []interfaces.Run{{Text: "both", Bold: true, Italic: true}},
+		},
+		{
+			name:   "unmatched delimiters",
+			source: "2 * 3 and a_b_c",
+			want:   []interfaces.Run{{Text: "2 * 3 and a_b_c"}},
+		},
+		{
+			name:   "code span",
+			source: "use `*x*` here",
+			want:   []interfaces.Run{{Text: "use "}, {Text: "*x*", Font: markdownCodeFont}, {Text: " here"}},
+		},
+		{
+			name:   "escapes and entities",
+			source: `\*not\* &amp; &#65;`,
+			want:   []interfaces.Run{{Text: "*not* & A"}},
+		},
+	}
+	for _, tt := range tests {
+		blocks := readTestMarkdown(t, tt.source).Blocks
+		if len(blocks) != 1 {
+			t.Errorf("%s: %d blocks, want 1", tt.name, len(blocks))
+			continue
+		}
+		if got := blocks[0].Runs; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: runs = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownMetadata(t *testing.T) {
+	content := readTestMarkdown(t, "---\ntitle: Doc\nauthor: \"Kim\"\ntags: [a, b]\n---\n# Heading")
+	for key, want := range map[string]string{"Title": "Doc", "Author": "Kim", "Keywords": "a, b"} {
+		if got := content.Metadata[key]; got != want {
+			t.Errorf("metadata %s = %q, want %q", key, got, want)
+		}
+	}
+}
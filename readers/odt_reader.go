@@ -0,0 +1,483 @@
+package readers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"myconverter/interfaces"
+)
+
+// ODTReader implements FileReader for OpenDocument text documents
+type ODTReader struct {
+	filePath string
+	content  *interfaces.PDFContent
+
+	files  map[string][]byte
+	styles *odtStyles
+}
+
+// odtCollector gathers the blocks of the body, a table cell, a note or a
+// header/footer
+type odtCollector struct {
+	element string
+	blocks  []interfaces.Block
+	// note is the index of the note a note body collector fills
+	note int
+}
+
+// odtParagraph buffers a paragraph until it is complete
+type odtParagraph struct {
+	block     interfaces.Block
+	collector *odtCollector
+}
+
+// odtTable builds a table from table-row/table-cell elements
+type odtTable struct {
+	table *interfaces.Table
+	col   int
+	cell  interfaces.TableCell
+	// repeat is the number of times the cell being read is repeated
+	repeat int
+}
+
+// NewODTReader creates a new ODTReader
+func NewODTReader(filePath string) *ODTReader {
+	return &ODTReader{
+		filePath: filePath,
+		content:  &interfaces.PDFContent{},
+	}
+}
+
+// Read reads the ODT package and extracts its content
+func (r *ODTReader) Read(filePath string) error {
+	r.filePath = filePath
+	_, err := r.ReadODT()
+	return err
+}
+
+// GetContent returns the content extracted by Read
+func (r *ODTReader) GetContent() *interfaces.PDFContent {
+	return r.content
+}
+
+// ReadODT reads content.xml, styles.xml, meta.xml and the pictures of the
+// package into the content structure
+func (r *ODTReader) ReadODT() (*interfaces.PDFContent, error) {
+	zipReader := &ZipReader{}
+	if err := zipReader.Read(r.filePath); err != nil {
+		return nil, err
+	}
+	r.files = make(map[string][]byte)
+	for _, file := range zipReader.GetFiles() {
+		r.files[file.Name] = file.Content
+	}
+	if _, ok := r.files["content.xml"]; !ok {
+		return nil, fmt.Errorf("content.xml not found")
+	}
+
+	// Automatic styles of content.xml refer to the common styles of styles.xml
+	r.styles = newODTStyles()
+	for _, name := range []string{"styles.xml", "content.xml"} {
+		if data, ok := r.files[name]; ok {
+			if err := r.styles.parse(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	r.content = &interfaces.PDFContent{Metadata: make(map[string]string)}
+	if layout, ok := r.styles.PageLayouts[r.styles.MasterPageLayout]; ok && layout.Width > 0 {
+		r.content.PageSetup = layout
+	}
+
+	// Master pages in styles.xml hold the headers and footers
+	if data, ok := r.files["styles.xml"]; ok {
+		if _, err := r.parse(data); err != nil {
+			return nil, err
+		}
+	}
+
+	blocks, err := r.parse(r.files["content.xml"])
+	if err != nil {
+		return nil, err
+	}
+	r.content.Blocks = blocks
+	r.content.Text = strings.Join(interfaces.BlockLines(blocks), "\n")
+	r.content.Pages = []interfaces.PDFPage{{Number: 1, Text: r.content.Text}}
+
+	if data, ok := r.files["meta.xml"]; ok {
+		if r.content.Metadata, err = parseODTMeta(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.content, nil
+}
+
+// parse walks content.xml or the master pages of styles.xml and returns the body blocks
+func (r *ODTReader) parse(data []byte) ([]interfaces.Block, error) {
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+
+	collectors := []*odtCollector{{element: "body"}}
+	var paragraphs []*odtParagraph
+	var tables []*odtTable
+	// Formats of the paragraph and nested spans being read
+	var formats []interfaces.Run
+	// Whether each open list is numbered, by nesting level
+	var lists []string
+	// skip counts the elements whose text is not content, such as fields
+	// and note citations
+	var skip int
+	var inCitation, lastSpace bool
+	// Left headers and footers apply to even pages
+	hasLeft := map[string]bool{}
+
+	current := func() *odtCollector {
+		return collectors[len(collectors)-1]
+	}
+	paragraph := func() *odtParagraph {
+		if len(paragraphs) == 0 {
+			return nil
+		}
+		return paragraphs[len(paragraphs)-1]
+	}
+	write := func(text string) {
+		p := paragraph()
+		if p == nil || len(formats) == 0 {
+			return
+		}
+		p.block.Runs = appendRun(p.block.Runs, text, formats[len(formats)-1])
+		lastSpace = strings.HasSuffix(text, " ")
+	}
+	// addBlock places a table or picture after the text of the enclosing paragraph
+	addBlock := func(block interfaces.Block) {
+		collector := current()
+		if p := paragraph(); p != nil {
+			r.flushParagraph(p)
+			collector = p.collector
+		}
+		collector.blocks = append(collector.blocks, block)
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+
+			switch t.Name.Local {
+			case "header", "footer", "header-left", "footer-left":
+				if t.Name.Space != "" && attrValue(t, "display") != "false" {
+					collectors = append(collectors, &odtCollector{element: t.Name.Local})
+					if strings.HasSuffix(t.Name.Local, "-left") {
+						hasLeft[strings.TrimSuffix(t.Name.Local, "-left")] = true
+					}
+				}
+
+			case "p", "h":
+				// Text before a nested paragraph, such as a text box, belongs in its own block
+				if p := paragraph(); p != nil && p.collector == current() {
+					r.flushParagraph(p)
+				}
+				styleName := attrValue(t, "style-name")
+				block := interfaces.Block{
+					Kind:      interfaces.BlockParagraph,
+					Align:     odtAlign(r.styles.paragraphProperty(styleName, "text-align")),
+					ParaShape: styleName,
+					Style:     r.styleDisplayName(styleName),
+				}
+				if t.Name.Local == "h" || r.styles.outlineLevel(styleName) > 0 {
+					block.Kind = interfaces.BlockHeading
+					block.Level, _ = strconv.Atoi(attrValue(t, "outline-level"))
+					if block.Level == 0 {
+						block.Level = max(r.styles.outlineLevel(styleName), 1)
+					}
+				} else if len(lists) > 0 {
+					block.Kind = interfaces.BlockListItem
+					block.Level = len(lists)
+					block.Ordered = r.styles.Lists[lists[len(lists)-1]][len(lists)]
+				}
+
+				if r.styles.paragraphProperty(styleName, "break-before") == "page" &&
+					len(collectors) == 1 && len(current().blocks) > 0 {
+					current().blocks = append(current().blocks, interfaces.Block{Kind: interfaces.BlockPageBreak})
+				}
+				paragraphs = append(paragraphs, &odtParagraph{block: block, collector: current()})
+				formats = append(formats, r.styles.runFormat(styleName))
+				lastSpace = true
+
+			case "span":
+				format := interfaces.Run{}
+				if len(formats) > 0 {
+					format = formats[len(formats)-1]
+				}
+				if name := attrValue(t, "style-name"); name != "" {
+					r.styles.applyText(&format, name)
+					format.CharShape = name
+				}
+				formats = append(formats, format)
+
+			case "s":
+				count, err := strconv.Atoi(attrValue(t, "c"))
+				if err != nil {
+					count = 1
+				}
+				write(strings.Repeat(" ", count))
+			case "tab":
+				write("\t")
+			case "line-break":
+				write("\n")
+				lastSpace = true
+
+			case "page-number":
+				write(interfaces.PageNumberField)
+				skip = 1
+			case "page-count":
+				write(interfaces.TotalPagesField)
+				skip = 1
+
+			case "list":
+				// Nested lists without a style continue the style of the outer list
+				name := attrValue(t, "style-name")
+				if name == "" && len(lists) > 0 {
+					name = lists[len(lists)-1]
+				}
+				lists = append(lists, name)
+
+			case "note":
+				kind, id := interfaces.Footnote, strconv.Itoa(r.countNotes(interfaces.Footnote)+1)
+				if attrValue(t, "note-class") == "endnote" {
+					kind, id = interfaces.Endnote, "e"+strconv.Itoa(r.countNotes(interfaces.Endnote)+1)
+				}
+				write(interfaces.NoteRef(id))
+				r.content.Notes = append(r.content.Notes, interfaces.Note{Kind: kind, ID: id})
+			case "note-citation":
+				inCitation = true
+			case "note-body":
+				collectors = append(collectors, &odtCollector{element: "note", note: len(r.content.Notes) - 1})
+
+			case "table":
+				if t.Name.Space != "" {
+					tables = append(tables, &odtTable{table: &interfaces.Table{}})
+				}
+			case "table-row":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					table.table.Rows = append(table.table.Rows, nil)
+					table.col = 0
+				}
+			case "table-cell":
+				if len(tables) > 0 && len(tables[len(tables)-1].table.Rows) > 0 {
+					table := tables[len(tables)-1]
+					cell := interfaces.TableCell{Row: len(table.table.Rows) - 1, Col: table.col, RowSpan: 1, ColSpan: 1}
+					if span, err := strconv.Atoi(attrValue(t, "number-columns-spanned")); err == nil {
+						cell.ColSpan = max(span, 1)
+					}
+					if span, err := strconv.Atoi(attrValue(t, "number-rows-spanned")); err == nil {
+						cell.RowSpan = max(span, 1)
+					}
+					table.cell = cell
+					table.repeat = 1
+					if repeat, err := strconv.Atoi(attrValue(t, "number-columns-repeated")); err == nil {
+						table.repeat = max(repeat, 1)
+					}
+					collectors = append(collectors, &odtCollector{element: "cell"})
+				}
+			case "covered-table-cell":
+				if len(tables) > 0 {
+					repeat, err := strconv.Atoi(attrValue(t, "number-columns-repeated"))
+					if err != nil {
+						repeat = 1
+					}
+					tables[len(tables)-1].col += max(repeat, 1)
+				}
+
+			case "image":
+				href := strings.TrimPrefix(attrValue(t, "href"), "./")
+				if imageData, ok := r.files[href]; ok {
+					addBlock(interfaces.Block{
+						Kind:  interfaces.BlockImage,
+						Image: &interfaces.Image{Name: path.Base(href), Data: imageData},
+					})
+				}
+			}
+
+		case xml.CharData:
+			if skip > 0 {
+				continue
+			}
+			if inCitation {
+				r.content.Notes[len(r.content.Notes)-1].Label += string(t)
+				continue
+			}
+			if p := paragraph(); p != nil {
+				// Whitespace in text content collapses to single spaces
+				text := strings.Join(strings.Fields(string(t)), " ")
+				if text == "" && strings.TrimSpace(string(t)) == "" && len(t) > 0 {
+					if !lastSpace {
+						write(" ")
+					}
+					continue
+				}
+				if startsWithSpace(string(t)) && !lastSpace {
+					text = " " + text
+				}
+				if endsWithSpace(string(t)) {
+					text += " "
+				}
+				write(text)
+			}
+
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+
+			switch t.Name.Local {
+			case "header", "footer", "header-left", "footer-left":
+				if t.Name.Space != "" && len(collectors) > 1 && current().element == t.Name.Local {
+					collector := current()
+					collectors = collectors[:len(collectors)-1]
+					r.addHeaderFooter(collector, hasLeft)
+				}
+			case "p", "h":
+				if p := paragraph(); p != nil {
+					r.flushParagraph(p)
+					paragraphs = paragraphs[:len(paragraphs)-1]
+				}
+				if len(formats) > 0 {
+					formats = formats[:len(formats)-1]
+				}
+			case "span":
+				if len(formats) > 0 {
+					formats = formats[:len(formats)-1]
+				}
+			case "list":
+				if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+				}
+			case "note-citation":
+				inCitation = false
+			case "note-body":
+				if len(collectors) > 1 && current().element == "note" {
+					collector := current()
+					collectors = collectors[:len(collectors)-1]
+					r.content.Notes[collector.note].Text = strings.Join(interfaces.BlockLines(collector.blocks), "\n")
+				}
+			case "table-cell":
+				if len(tables) > 0 && len(collectors) > 1 && current().element == "cell" {
+					table := tables[len(tables)-1]
+					table.cell.Blocks = current().blocks
+					collectors = collectors[:len(collectors)-1]
+					row := len(table.table.Rows) - 1
+					for i := 0; i < table.repeat; i++ {
+						cell := table.cell
+						cell.Col = table.col
+						table.table.Rows[row] = append(table.table.Rows[row], cell)
+						table.col += cell.ColSpan
+					}
+				}
+			case "table":
+				if t.Name.Space != "" && len(tables) > 0 {
+					table := tables[len(tables)-1]
+					tables = tables[:len(tables)-1]
+					addBlock(interfaces.Block{Kind: interfaces.BlockTable, Table: table.table})
+				}
+			}
+		}
+	}
+
+	return collectors[0].blocks, nil
+}
+
+// addHeaderFooter adds the text of a master page header or footer
+func (r *ODTReader) addHeaderFooter(collector *odtCollector, hasLeft map[string]bool) {
+	kind := strings.TrimSuffix(collector.element, "-left")
+	apply := "BOTH"
+	if strings.HasSuffix(collector.element, "-left") {
+		apply = "EVEN"
+	} else if hasLeft[kind] {
+		apply = "ODD"
+	}
+
+	item := interfaces.HeaderFooter{Apply: apply, Text: strings.Join(interfaces.BlockLines(collector.blocks), "\n")}
+	for _, block := range collector.blocks {
+		if block.Align != "" {
+			item.Align = block.Align
+			break
+		}
+	}
+	if item.Text == "" {
+		return
+	}
+
+	// Headers read before the matching left header still apply to both
+	// kinds of pages, fix them up when the left one appears
+	items := &r.content.Headers
+	if kind == "footer" {
+		items = &r.content.Footers
+	}
+	if apply == "EVEN" {
+		for i := range *items {
+			if (*items)[i].Apply == "BOTH" {
+				(*items)[i].Apply = "ODD"
+			}
+		}
+	}
+	*items = append(*items, item)
+}
+
+// styleDisplayName returns the name of a paragraph style as shown to users
+func (r *ODTReader) styleDisplayName(name string) string {
+	// Automatic styles derive from the common style the user picked
+	if style, ok := r.styles.Styles[name]; ok && style.Parent != "" && strings.HasPrefix(name, "P") {
+		name = style.Parent
+	}
+	return strings.ReplaceAll(name, "_20_", " ")
+}
+
+// flushParagraph moves the buffered paragraph to its collector
+func (r *ODTReader) flushParagraph(p *odtParagraph) {
+	if strings.TrimSpace(p.block.Text()) != "" {
+		block := p.block
+		block.Runs = trimRuns(block.Runs)
+		p.collector.blocks = append(p.collector.blocks, block)
+	}
+	p.block.Runs = nil
+}
+
+// countNotes returns the number of notes of the given kind
+func (r *ODTReader) countNotes(kind string) int {
+	count := 0
+	for _, note := range r.content.Notes {
+		if note.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// startsWithSpace reports whether text starts with XML whitespace
+func startsWithSpace(text string) bool {
+	return text != "" && strings.ContainsRune(" \t\r\n", rune(text[0]))
+}
+
+// endsWithSpace reports whether text ends with XML whitespace
+func endsWithSpace(text string) bool {
+	return text != "" && strings.ContainsRune(" \t\r\n", rune(text[len(text)-1]))
+}
@@ -0,0 +1,121 @@
+package readers
+
+import (
+	"reflect"
+	"testing"
+
+	"myconverter/interfaces"
+)
+
+const odtTestNamespaces = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+	`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" ` +
+	`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+	`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+	`xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" ` +
+	`xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" ` +
+	`xmlns:xlink="http://www.w3.org/1999/xlink" ` +
+	`xmlns:dc="http://purl.org/dc/elements/1.1/" ` +
+	`xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0"`
+
+func TestReadODT(t *testing.T) {
+	path := writeTestZip(t, "test.odt", map[string]string{
+		"mimetype": "application/vnd.oasis.opendocument.text",
+		"styles.xml": `<?xml version="1.0"?>
+<office:document-styles ` + odtTestNamespaces + `>
+<office:styles>
+<style:default-style style:family="paragraph"><style:text-properties fo:font-size="10pt"/></style:default-style>
+<style:style style:name="Heading_20_1" style:display-name="Heading 1" style:family="paragraph" style:default-outline-level="1"><style:text-properties fo:font-size="18pt" fo:font-weight="bold"/></style:style>
+<style:style style:name="Text_20_body" style:display-name="Text body" style:family="paragraph"/>
+</office:styles>
+<office:automatic-styles>
+<style:page-layout style:name="pm1"><style:page-layout-properties fo:page-width="21cm" fo:page-height="29.7cm" fo:margin-left="2cm" fo:margin-right="2cm" fo:margin-top="2cm" fo:margin-bottom="2cm"/></style:page-layout>
+</office:automatic-styles>
+<office:master-styles>
+<style:master-page style:name="Standard" style:page-layout-name="pm1">
+<style:footer><text:p>Page <text:page-number>1</text:page-number></text:p></style:footer>
+</style:master-page>
+</office:master-styles>
+</office:document-styles>`,
+		"content.xml": `<?xml version="1.0"?>
+<office:document-content ` + odtTestNamespaces + `>
+<office:automatic-styles>
+<style:style style:name="P1" style:family="paragraph" style:parent-style-name="Text_20_body"><style:paragraph-properties fo:text-align="center"/></style:style>
+<style:style style:name="P2" style:family="paragraph" style:parent-style-name="Text_20_body"><style:paragraph-properties fo:break-before="page"/></style:style>
+<style:style style:name="T1" style:family="text"><style:text-properties fo:font-style="italic" fo:color="#ff0000"/></style:style>
+<text:list-style style:name="L1"><text:list-level-style-bullet text:level="1"/><text:list-level-style-number text:level="2"/></text:list-style>
+</office:automatic-styles>
+<office:body><office:text>
+<text:h text:style-name="Heading_20_1" text:outline-level="1">Title</text:h>
+<text:p text:style-name="P1">plain<text:s text:c="2"/><text:span text:style-name="T1">styled</text:span><text:note text:note-class="footnote"><text:note-citation>1</text:note-citation><text:note-body><text:p>the note</text:p></text:note-body></text:note></text:p>
+<text:list text:style-name="L1"><text:list-item><text:p>bullet</text:p>
+<text:list><text:list-item><text:p>number</text:p></text:list-item></text:list>
+</text:list-item></text:list>
+<table:table>
+<table:table-row><table:table-cell table:number-rows-spanned="2"><text:p>tall</text:p></table:table-cell><table:table-cell><text:p>b</text:p></table:table-cell></table:table-row>
+<table:table-row><table:covered-table-cell/><table:table-cell><text:p>d</text:p></table:table-cell></table:table-row>
+<table:table-row><table:table-cell table:number-columns-spanned="2"><text:p>wide</text:p></table:table-cell><table:covered-table-cell/></table:table-row>
+</table:table>
+<text:p><draw:frame><draw:image xlink:href="Pictures/1.png"/></draw:frame></text:p>
+<text:p text:style-name="P2">after</text:p>
+</office:text></office:body>
+</office:document-content>`,
+		"meta.xml": `<?xml version="1.0"?>
+<office:document-meta ` + odtTestNamespaces + `><office:meta>
+<dc:title>Report</dc:title><meta:initial-creator>Kim</meta:initial-creator><meta:keyword>a</meta:keyword><meta:keyword>b</meta:keyword>
+</office:meta></office:document-meta>`,
+		"Pictures/1.png": "picture",
+	})
+
+	content, err := NewODTReader(path).ReadODT()
+	if err != nil {
+		t.Fatalf("ReadODT() error = %v", err)
+	}
+	want := []string{
+		`heading1 [Heading 1] "Title"`,
+		`paragraph [Text body] "plain  styled[^1]"`,
+		`list-item1 "bullet"`,
+		`list-item2 ordered "number"`,
+		`table tall|b / d / wide`,
+		`image 1.png`,
+		`page-break ""`,
+		`paragraph [Text body] "after"`,
+	}
+	if got := describeBlocks(content.Blocks); !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks = %q, want %q", got, want)
+	}
+
+	if got := content.Blocks[1].Align; got != "CENTER" {
+		t.Errorf("align = %q, want CENTER", got)
+	}
+	styled := content.Blocks[1].Runs[1]
+	if !styled.Italic || styled.Color != "#FF0000" || styled.Size != 10 || styled.CharShape != "T1" {
+		t.Errorf("styled run = %+v, want italic #FF0000 10pt T1", styled)
+	}
+	if heading := content.Blocks[0].Runs[0]; !heading.Bold || heading.Size != 18 {
+		t.Errorf("heading run = %+v, want bold 18pt", heading)
+	}
+	table := content.Blocks[4].Table
+	if cell := table.Rows[0][0]; cell.RowSpan != 2 {
+		t.Errorf("merged cell row span = %d, want 2", cell.RowSpan)
+	}
+	if cell := table.Rows[1][0]; cell.Col != 1 {
+		t.Errorf("cell after a covered cell is in column %d, want 1", cell.Col)
+	}
+	if cell := table.Rows[2][0]; cell.ColSpan != 2 {
+		t.Errorf("merged cell column span = %d, want 2", cell.ColSpan)
+	}
+	if len(content.Notes) != 1 || content.Notes[0].Text != "the note" || content.Notes[0].Label != "1" {
+		t.Errorf("notes = %+v, want the note", content.Notes)
+	}
+	if len(content.Footers) != 1 || content.Footers[0].Text != "Page "+interfaces.PageNumberField {
+		t.Errorf("footers = %+v", content.Footers)
+	}
+	if setup := content.PageSetup; setup == nil || setup.Width < 595 || setup.Width > 596 {
+		t.Errorf("page setup = %+v, want A4", setup)
+	}
+	for key, value := range map[string]string{"Title": "Report", "Author": "Kim", "Keywords": "a, b"} {
+		if got := content.Metadata[key]; got != value {
+			t.Errorf("metadata %s = %q, want %q", key, got, value)
+		}
+	}
+}
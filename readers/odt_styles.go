@@ -0,0 +1,299 @@
+package readers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"myconverter/interfaces"
+)
+
+// odtStyle is a paragraph or text style of an OpenDocument package
+type odtStyle struct {
+	Family string
+	Parent string
+	// Text holds the attributes of the style's text-properties
+	Text []xml.Attr
+	// Paragraph holds the attributes of the style's paragraph-properties
+	Paragraph []xml.Attr
+}
+
+// odtStyles holds the styles, list styles and page layout of a package
+type odtStyles struct {
+	// Defaults holds the text-properties of the default paragraph style
+	Defaults []xml.Attr
+	Styles   map[string]*odtStyle
+	// Lists maps list style names to whether each level is numbered
+	Lists map[string]map[int]bool
+	// PageLayouts maps page layout names to their page setup
+	PageLayouts map[string]*interfaces.PageSetup
+	// MasterPageLayout is the page layout of the first master page
+	MasterPageLayout string
+}
+
+// newODTStyles creates an empty style set
+func newODTStyles() *odtStyles {
+	return &odtStyles{
+		Styles:      make(map[string]*odtStyle),
+		Lists:       make(map[string]map[int]bool),
+		PageLayouts: make(map[string]*interfaces.PageSetup),
+	}
+}
+
+// parse reads the style definitions of styles.xml or the automatic styles of content.xml
+func (s *odtStyles) parse(data []byte) error {
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	var style *odtStyle
+	var isDefault bool
+	var list map[int]bool
+	var layout *interfaces.PageSetup
+	// Header and footer heights are added to the page margins
+	var headerFooter string
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse styles XML: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "style":
+				style = &odtStyle{Family: attrValue(t, "family"), Parent: attrValue(t, "parent-style-name")}
+				s.Styles[attrValue(t, "name")] = style
+			case "default-style":
+				isDefault = attrValue(t, "family") == "paragraph"
+			case "text-properties":
+				if style != nil {
+					style.Text = append(style.Text, t.Attr...)
+				} else if isDefault {
+					s.Defaults = append(s.Defaults, t.Attr...)
+				}
+			case "paragraph-properties":
+				if style != nil {
+					style.Paragraph = append(style.Paragraph, t.Attr...)
+				}
+			case "list-style":
+				list = make(map[int]bool)
+				s.Lists[attrValue(t, "name")] = list
+			case "list-level-style-number", "list-level-style-bullet", "list-level-style-image":
+				if list != nil {
+					level, _ := strconv.Atoi(attrValue(t, "level"))
+					list[level] = t.Name.Local == "list-level-style-number"
+				}
+			case "page-layout":
+				layout = &interfaces.PageSetup{}
+				s.PageLayouts[attrValue(t, "name")] = layout
+			case "page-layout-properties":
+				if layout != nil {
+					layout.Width = odtLength(attrValue(t, "page-width"))
+					layout.Height = odtLength(attrValue(t, "page-height"))
+					layout.MarginLeft = odtLength(attrValue(t, "margin-left"))
+					layout.MarginRight = odtLength(attrValue(t, "margin-right"))
+					layout.MarginTop = odtLength(attrValue(t, "margin-top"))
+					layout.MarginBottom = odtLength(attrValue(t, "margin-bottom"))
+				}
+			case "header-style", "footer-style":
+				headerFooter = t.Name.Local
+			case "header-footer-properties":
+				if layout != nil {
+					height := odtLength(attrValue(t, "min-height")) + odtLength(attrValue(t, "margin-bottom")) + odtLength(attrValue(t, "margin-top"))
+					if headerFooter == "header-style" {
+						layout.MarginHeader = height
+					} else {
+						layout.MarginFooter = height
+					}
+				}
+			case "master-page":
+				if s.MasterPageLayout == "" {
+					s.MasterPageLayout = attrValue(t, "page-layout-name")
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "style":
+				style = nil
+			case "default-style":
+				isDefault = false
+			case "list-style":
+				list = nil
+			case "page-layout":
+				layout = nil
+			case "header-style", "footer-style":
+				headerFooter = ""
+			}
+		}
+	}
+	return nil
+}
+
+// chain returns a style and its parents, the style itself first
+func (s *odtStyles) chain(name string) []*odtStyle {
+	var chain []*odtStyle
+	for seen := 0; name != "" && seen < 16; seen++ {
+		style, ok := s.Styles[name]
+		if !ok {
+			break
+		}
+		chain = append(chain, style)
+		name = style.Parent
+	}
+	return chain
+}
+
+// applyText applies the text properties of a style and its parents to a run
+func (s *odtStyles) applyText(run *interfaces.Run, name string) {
+	chain := s.chain(name)
+	for i := len(chain) - 1; i >= 0; i-- {
+		applyODTTextProperties(run, chain[i].Text)
+	}
+}
+
+// runFormat returns the run formatting of the defaults and a paragraph style
+func (s *odtStyles) runFormat(name string) interfaces.Run {
+	var run interfaces.Run
+	applyODTTextProperties(&run, s.Defaults)
+	s.applyText(&run, name)
+	return run
+}
+
+// paragraphProperty returns a paragraph property of a style or its parents
+func (s *odtStyles) paragraphProperty(name, property string) string {
+	for _, style := range s.chain(name) {
+		for _, attr := range style.Paragraph {
+			if attr.Name.Local == property {
+				return attr.Value
+			}
+		}
+	}
+	return ""
+}
+
+// outlineLevel returns the default outline level of a paragraph style, 0 for none
+func (s *odtStyles) outlineLevel(name string) int {
+	for seen := 0; name != "" && seen < 16; seen++ {
+		// Heading styles are named Heading_20_1 and so on
+		if strings.HasPrefix(name, "Heading_20_") {
+			level, _ := strconv.Atoi(strings.TrimPrefix(name, "Heading_20_"))
+			return level
+		}
+		style, ok := s.Styles[name]
+		if !ok {
+			break
+		}
+		name = style.Parent
+	}
+	return 0
+}
+
+// applyODTTextProperties applies text-properties attributes to a run
+func applyODTTextProperties(run *interfaces.Run, attrs []xml.Attr) {
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "font-weight":
+			run.Bold = attr.Value == "bold" || attr.Value >= "600" && attr.Value <= "900"
+		case "font-style":
+			run.Italic = attr.Value == "italic" || attr.Value == "oblique"
+		case "text-underline-style":
+			run.Underline = attr.Value != "none"
+		case "text-line-through-style":
+			run.Strike = attr.Value != "none"
+		case "font-size":
+			if size := odtLength(attr.Value); size > 0 {
+				run.Size = size
+			}
+		case "color":
+			run.Color = hwpxColor(attr.Value)
+		case "background-color":
+			run.Highlight = hwpxColor(attr.Value)
+		case "font-name", "font-name-asian", "font-family", "font-family-asian":
+			run.Font = strings.Trim(attr.Value, `'"`)
+		}
+	}
+}
+
+// odtAlign converts a fo:text-align value to the alignment names of the content model
+func odtAlign(value string) string {
+	switch value {
+	case "start", "left":
+		return "LEFT"
+	case "end", "right":
+		return "RIGHT"
+	case "center":
+		return "CENTER"
+	case "justify":
+		return "JUSTIFY"
+	default:
+		return ""
+	}
+}
+
+// odtLength converts a length such as 2cm, 20mm, 1in or 12pt to points
+func odtLength(value string) float64 {
+	units := map[string]float64{"pt": 1, "in": 72, "inch": 72, "cm": 72 / 2.54, "mm": 72 / 25.4, "pc": 12, "px": 0.75}
+	for _, unit := range []string{"inch", "pt", "in", "cm", "mm", "pc", "px"} {
+		if strings.HasSuffix(value, unit) {
+			number, err := strconv.ParseFloat(strings.TrimSuffix(value, unit), 64)
+			if err != nil {
+				return 0
+			}
+			return number * units[unit]
+		}
+	}
+	return 0
+}
+
+// parseODTMeta reads the document metadata of meta.xml
+func parseODTMeta(data []byte) (map[string]string, error) {
+	keys := map[string]string{
+		"title":           "Title",
+		"initial-creator": "Author",
+		"creator":         "LastModifiedBy",
+		"subject":         "Subject",
+		"keyword":         "Keywords",
+		"description":     "Description",
+		"creation-date":   "CreationDate",
+		"date":            "ModDate",
+		"generator":       "Creator",
+	}
+
+	metadata := make(map[string]string)
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	var key string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse meta XML: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			key = keys[t.Name.Local]
+		case xml.CharData:
+			if key == "" || strings.TrimSpace(string(t)) == "" {
+				continue
+			}
+			if key == "Keywords" && metadata[key] != "" {
+				metadata[key] += ", "
+			}
+			metadata[key] += strings.TrimSpace(string(t))
+		case xml.EndElement:
+			key = ""
+		}
+	}
+
+	// The document author is the initial creator, or the last one when unknown
+	if metadata["Author"] == "" && metadata["LastModifiedBy"] != "" {
+		metadata["Author"] = metadata["LastModifiedBy"]
+	}
+	return metadata, nil
+}
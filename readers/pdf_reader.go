@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/unidoc/unipdf/v3/common/license"
 	"github.com/unidoc/unipdf/v3/extractor"
 	"github.com/unidoc/unipdf/v3/model"
 	"myconverter/interfaces"
@@ -102,6 +103,7 @@ func (r *PDFReader) ReadPDF() (*interfaces.PDFContent, error) {
 
 	// 페이지별 텍스트 추출
 	r.content.Pages = make([]interfaces.PDFPage, numPages)
+	r.content.Blocks = nil
 	var textBuilder strings.Builder
 
 	for i := 0; i < numPages; i++ {
@@ -129,12 +131,73 @@ func (r *PDFReader) ReadPDF() (*interfaces.PDFContent, error) {
 			Text:   text,
 			Images: nil,
 		}
+
+		// 페이지 구분과 함께 문단 블록 추가
+		if i > 0 {
+			r.content.Blocks = append(r.content.Blocks, interfaces.Block{Kind: interfaces.BlockPageBreak})
+		}
+		r.content.Blocks = append(r.content.Blocks, interfaces.TextBlocks(text)...)
 	}
 
 	r.content.Text = textBuilder.String()
 	return r.content, nil
 }
 
+// ReadTables detects the tables laid out on the pages of the PDF file.
+// Detection uses the unipdf text extractor, which needs a unipdf license.
+func (r *PDFReader) ReadTables() ([]*interfaces.Table, error) {
+	pdfReader, file, err := r.openPDF()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	numPages, err := pdfReader.GetNumPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page count: %v", err)
+	}
+	if !license.GetLicenseKey().IsLicensed() {
+		return nil, fmt.Errorf("detecting PDF tables needs a unipdf license")
+	}
+
+	var tables []*interfaces.Table
+	for i := 0; i < numPages; i++ {
+		page, err := pdfReader.GetPage(i + 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get page %d: %v", i+1, err)
+		}
+		extractor, err := extractor.New(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create text extractor for page %d: %v", i+1, err)
+		}
+		pageText, _, _, err := extractor.ExtractPageText()
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract text from page %d: %v", i+1, err)
+		}
+		for _, textTable := range pageText.Tables() {
+			tables = append(tables, pdfTable(textTable))
+		}
+	}
+	return tables, nil
+}
+
+// pdfTable converts a detected table to cells, one per grid position as PDF
+// tables carry no merge information
+func pdfTable(textTable extractor.TextTable) *interfaces.Table {
+	table := &interfaces.Table{}
+	for y, row := range textTable.Cells {
+		var cells []interfaces.TableCell
+		for x, cell := range row {
+			cells = append(cells, interfaces.TableCell{
+				Row: y, Col: x, RowSpan: 1, ColSpan: 1,
+				Blocks: interfaces.TextBlocks(cell.Text),
+			})
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	return table
+}
+
 func (r *PDFReader) ReadStream() (io.ReadCloser, error) {
 	return os.Open(r.filePath)
 }
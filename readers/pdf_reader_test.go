@@ -0,0 +1,31 @@
+package readers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/unidoc/unipdf/v3/extractor"
+
+	"myconverter/interfaces"
+)
+
+func TestPDFTable(t *testing.T) {
+	textTable := extractor.TextTable{W: 2, H: 2, Cells: [][]extractor.TableCell{
+		{{Text: "Name"}, {Text: "Total"}},
+		{{Text: "a\nb"}, {Text: ""}},
+	}}
+	got := pdfTable(textTable)
+	want := &interfaces.Table{Rows: [][]interfaces.TableCell{
+		{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Blocks: interfaces.TextBlocks("Name")},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Blocks: interfaces.TextBlocks("Total")},
+		},
+		{
+			{Row: 1, Col: 0, RowSpan: 1, ColSpan: 1, Blocks: interfaces.TextBlocks("a\nb")},
+			{Row: 1, Col: 1, RowSpan: 1, ColSpan: 1, Blocks: interfaces.TextBlocks("")},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pdfTable() = %+v, want %+v", got, want)
+	}
+}
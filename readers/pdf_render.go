@@ -0,0 +1,118 @@
+package readers
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/render"
+)
+
+// DefaultRenderDPI is the resolution RenderPages uses when none is given
+const DefaultRenderDPI = 150
+
+// RenderPages rasterizes each page of the PDF file at dpi, drawing its
+// content stream with the text, paths and images it holds
+func (r *PDFReader) RenderPages(dpi float64) ([]image.Image, error) {
+	if dpi <= 0 {
+		dpi = DefaultRenderDPI
+	}
+
+	pdfReader, file, err := r.openPDF()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	numPages, err := pdfReader.GetNumPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page count: %v", err)
+	}
+
+	pages := make([]image.Image, 0, numPages)
+	for i := 0; i < numPages; i++ {
+		img, err := renderPage(pdfReader, i+1, dpi)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, img)
+	}
+	return pages, nil
+}
+
+// RenderPage rasterizes one page of the PDF file, numbered from 1, at dpi
+func (r *PDFReader) RenderPage(number int, dpi float64) (image.Image, error) {
+	pdfReader, file, err := r.openPDF()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	numPages, err := pdfReader.GetNumPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page count: %v", err)
+	}
+	if number < 1 || number > numPages {
+		return nil, fmt.Errorf("page %d out of range", number)
+	}
+	if dpi <= 0 {
+		dpi = DefaultRenderDPI
+	}
+	return renderPage(pdfReader, number, dpi)
+}
+
+// renderPage rasterizes a page of an open PDF document at dpi
+func renderPage(pdfReader *model.PdfReader, number int, dpi float64) (image.Image, error) {
+	page, err := pdfReader.GetPage(number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page %d: %v", number, err)
+	}
+
+	// 표시되는 페이지 너비(포인트)를 해상도에 맞는 픽셀 수로 변환
+	box, err := page.GetMediaBox()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get size of page %d: %v", number, err)
+	}
+	if page.CropBox != nil {
+		box = page.CropBox
+	}
+	width := box.Width()
+	if page.Rotate != nil && *page.Rotate%180 != 0 {
+		width = box.Height()
+	}
+	device := render.NewImageDevice()
+	device.OutputWidth = int(math.Round(math.Abs(width) * dpi / 72))
+
+	img, err := device.Render(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render page %d: %v", number, err)
+	}
+	return img, nil
+}
+
+// openPDF opens the PDF file for rendering and table detection,
+// decrypting documents that open with an empty password
+func (r *PDFReader) openPDF() (*model.PdfReader, *os.File, error) {
+	// PDF 파일 열기
+	file, err := os.Open(r.filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open PDF file: %v", err)
+	}
+
+	pdfReader, err := model.NewPdfReader(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to create PDF reader: %v", err)
+	}
+
+	// 암호 없이 열 수 있는 문서는 빈 암호로 해제
+	if encrypted, err := pdfReader.IsEncrypted(); err == nil && encrypted {
+		if ok, err := pdfReader.Decrypt([]byte("")); err != nil || !ok {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to decrypt PDF: password required")
+		}
+	}
+	return pdfReader, file, nil
+}
@@ -0,0 +1,96 @@
+package readers
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestPDFPages writes a PDF file with a page for each content stream,
+// with pages of 72x36 points rotated by rotate degrees, and returns its path
+func writeTestPDFPages(t *testing.T, rotate int, contents ...string) string {
+	t.Helper()
+	var objects []string
+	kids := make([]string, len(contents))
+	for i, content := range contents {
+		page := 3 + 2*i
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 72 36] /Rotate %d /Contents %d 0 R /Resources << >> >>", rotate, page+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents)),
+	}, objects...)
+
+	var pdf strings.Builder
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	path := filepath.Join(t.TempDir(), "test.pdf")
+	if err := os.WriteFile(path, []byte(pdf.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// isRed reports whether a pixel is close to pure red
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xE000 && g < 0x2000 && b < 0x2000
+}
+
+func TestRenderPages(t *testing.T) {
+	// The left half of the page is red
+	leftHalf := "1 0 0 rg 0 0 36 36 re f"
+	path := writeTestPDFPages(t, 0, leftHalf, "0 0 1 rg 0 0 72 36 re f")
+
+	pages, err := NewPDFReader(path).RenderPages(144)
+	if err != nil {
+		t.Fatalf("RenderPages() error = %v", err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("%d pages, want 2", len(pages))
+	}
+	if size := pages[0].Bounds().Size(); size != image.Pt(144, 72) {
+		t.Errorf("page size = %v, want 144x72", size)
+	}
+	if !isRed(pages[0].At(20, 36)) || isRed(pages[0].At(120, 36)) {
+		t.Errorf("pixels = %v, %v, want red on the left only", pages[0].At(20, 36), pages[0].At(120, 36))
+	}
+	if r, g, b, _ := pages[1].At(72, 36).RGBA(); r > 0x2000 || g > 0x2000 || b < 0xE000 {
+		t.Errorf("page 2 pixel = %v, want blue", pages[1].At(72, 36))
+	}
+
+	// Rotated pages render upright
+	page, err := NewPDFReader(writeTestPDFPages(t, 90, leftHalf)).RenderPage(1, 72)
+	if err != nil {
+		t.Fatalf("RenderPage() error = %v", err)
+	}
+	// Turning the page clockwise brings its left half to the top
+	if size := page.Bounds().Size(); size.X != 36 || size.Y < 71 || size.Y > 72 {
+		t.Errorf("rotated page size = %v, want 36x72", size)
+	}
+	if !isRed(page.At(18, 10)) || isRed(page.At(18, 60)) {
+		t.Errorf("rotated pixels = %v, %v, want red at the top only", page.At(18, 10), page.At(18, 60))
+	}
+
+	if _, err := NewPDFReader(path).RenderPage(3, 72); err == nil {
+		t.Errorf("RenderPage(3) succeeded, want an error")
+	}
+}
@@ -0,0 +1,57 @@
+package readers
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+
+	// Register the decoders of preview images
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+)
+
+// HWPPreviewImage decodes the first page preview an HWP file keeps in its
+// PrvImage stream. It returns nil when the file has no preview.
+func HWPPreviewImage(r *CFBReader) (image.Image, error) {
+	for _, entry := range r.Entries {
+		if entry.Path != "PrvImage" || len(entry.Content) == 0 {
+			continue
+		}
+		img, _, err := image.Decode(bytes.NewReader(entry.Content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode PrvImage: %v", err)
+		}
+		return img, nil
+	}
+	return nil, nil
+}
+
+// HWPXPreviewImage decodes the first page preview an HWPX file keeps in
+// Preview/PrvImage.png. It returns nil when the file has no preview.
+func HWPXPreviewImage(zipReader *zip.ReadCloser) (image.Image, error) {
+	for _, file := range zipReader.File {
+		if !strings.EqualFold(file.Name, "Preview/PrvImage.png") {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %v", file.Name, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file.Name, err)
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", file.Name, err)
+		}
+		return img, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,56 @@
+package readers
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestPreviewImages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal(err)
+	}
+	preview := buf.String()
+
+	// HWP files keep the preview in the PrvImage stream
+	img, err := HWPPreviewImage(&CFBReader{Entries: []CFBEntry{
+		{Path: "BodyText/Section0", Content: []byte("body")},
+		{Path: "PrvImage", Content: buf.Bytes()},
+	}})
+	if err != nil || img == nil || img.Bounds().Size() != image.Pt(4, 3) {
+		t.Errorf("HWPPreviewImage() = %v, %v, want a 4x3 image", img, err)
+	}
+	if img, err := HWPPreviewImage(&CFBReader{}); img != nil || err != nil {
+		t.Errorf("HWPPreviewImage() without preview = %v, %v, want nil", img, err)
+	}
+	if _, err := HWPPreviewImage(&CFBReader{Entries: []CFBEntry{{Path: "PrvImage", Content: []byte("bad")}}}); err == nil {
+		t.Errorf("HWPPreviewImage() of bad data succeeded, want an error")
+	}
+
+	// HWPX files keep it in Preview/PrvImage.png, matched in any case
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{name: "preview", files: map[string]string{"Contents/section0.xml": "<sec/>", "Preview/PrvImage.png": preview}, want: true},
+		{name: "case", files: map[string]string{"preview/prvimage.PNG": preview}, want: true},
+		{name: "no preview", files: map[string]string{"Contents/section0.xml": "<sec/>"}},
+	}
+	for _, tt := range tests {
+		archive, err := zip.OpenReader(writeTestZip(t, "test.hwpx", tt.files))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := HWPXPreviewImage(archive)
+		archive.Close()
+		if err != nil {
+			t.Errorf("%s: HWPXPreviewImage() error = %v", tt.name, err)
+		} else if (img != nil) != tt.want {
+			t.Errorf("%s: HWPXPreviewImage() = %v, want an image %v", tt.name, img, tt.want)
+		}
+	}
+}
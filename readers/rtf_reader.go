@@ -0,0 +1,883 @@
+package readers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"myconverter/hwpx"
+	"myconverter/interfaces"
+)
+
+// rtfMetadata maps \info destinations to metadata keys
+var rtfMetadata = map[string]string{
+	"title":    "Title",
+	"subject":  "Subject",
+	"author":   "Author",
+	"operator": "LastModifiedBy",
+	"keywords": "Keywords",
+	"doccomm":  "Description",
+	"creatim":  "CreationDate",
+	"revtim":   "ModDate",
+}
+
+// rtfSkipped lists destinations whose text is not content
+var rtfSkipped = map[string]bool{
+	"nonshppict": true, "listtext": true, "pntext": true, "pntxta": true, "pntxtb": true,
+	"leveltext": true, "levelnumbers": true, "listname": true, "headerf": true, "footerf": true,
+	"themedata": true, "colorschememapping": true, "datastore": true, "latentstyles": true,
+	"xmlnstbl": true, "rsidtbl": true, "generator": true, "bkmkstart": true, "bkmkend": true,
+	"object": true, "objdata": true, "private": true, "txe": true, "xe": true, "tc": true,
+}
+
+// rtfSpecialChars maps control words to the characters they stand for
+var rtfSpecialChars = map[string]string{
+	"tab": "\t", "line": "\n", "emdash": "—", "endash": "–", "bullet": "•",
+	"lquote": "‘", "rquote": "’", "ldblquote": "“", "rdblquote": "”",
+	"emspace": " ", "enspace": " ", "qmspace": " ", "~": " ", "_": "-",
+}
+
+// RTFReader implements FileReader for Rich Text Format documents
+type RTFReader struct {
+	filePath string
+	content  *interfaces.PDFContent
+
+	// codePage is the ANSI code page declared with \ansicpg
+	codePage    int
+	defaultFont int
+	fonts       map[int]*rtfFont
+	colors      []string
+	styles      map[int]string
+	// lists holds whether each level of a list is numbered, by list ID
+	lists map[int][]bool
+	// overrides maps the \ls indexes used by paragraphs to list IDs
+	overrides map[int]int
+	// page holds the page size and margins in twips
+	page map[string]int
+
+	// Definitions being read in the font, color, style and list tables
+	font       int
+	color      []int
+	style      int
+	styleName  string
+	listLevels []bool
+	overrideID int
+	date       map[string]int
+
+	// pending holds text bytes awaiting decoding in the current code page
+	pending []byte
+	// skip is the number of fallback characters left after a \u control
+	skip      int
+	surrogate rune
+	images    int
+}
+
+// rtfFont is an entry of the font table
+type rtfFont struct {
+	name     string
+	codePage int
+	// complete marks names ended by a semicolon
+	complete bool
+}
+
+// rtfParagraph holds the paragraph properties of a group
+type rtfParagraph struct {
+	align   string
+	style   int
+	outline int
+	list    int
+	level   int
+	inTable bool
+}
+
+// rtfState is the formatting and destination of a group
+type rtfState struct {
+	format interfaces.Run
+	font   int
+	para   rtfParagraph
+	uc     int
+	// dest is the destination receiving the text, empty for content
+	dest      string
+	collector *rtfCollector
+	field     *rtfField
+	pict      *rtfPicture
+	// starts is the destination that began in this group and ends with it
+	starts string
+	// ignorable marks groups starting with \*
+	ignorable bool
+}
+
+// rtfCollector gathers the blocks of the body, a header, a footer or a note
+type rtfCollector struct {
+	kind    string
+	blocks  []interfaces.Block
+	para    interfaces.Block
+	table   *rtfTable
+	endnote bool
+}
+
+// rtfField is a field whose result may be replaced by a placeholder
+type rtfField struct {
+	instr string
+}
+
+// rtfPicture is a \pict destination
+type rtfPicture struct {
+	ext  string
+	data []byte
+	hex  strings.Builder
+}
+
+// rtfTable builds a table from \trowd, \cell and \row controls
+type rtfTable struct {
+	rows  [][]rtfCell
+	row   []rtfCell
+	cell  []interfaces.Block
+	defs  []rtfCellDef
+	merge rtfCellDef
+}
+
+// rtfCell is a cell read with the definition of its position in the row
+type rtfCell struct {
+	def    rtfCellDef
+	blocks []interfaces.Block
+}
+
+// rtfCellDef is a cell definition: its right boundary in twips and its
+// horizontal and vertical merge state, first or continue
+type rtfCellDef struct {
+	right  int
+	hMerge string
+	vMerge string
+}
+
+// NewRTFReader creates a new RTFReader
+func NewRTFReader(filePath string) *RTFReader {
+	return &RTFReader{
+		filePath: filePath,
+		content:  &interfaces.PDFContent{},
+	}
+}
+
+// Read reads the RTF file and extracts its content
+func (r *RTFReader) Read(filePath string) error {
+	r.filePath = filePath
+	_, err := r.ReadRTF()
+	return err
+}
+
+// GetContent returns the content extracted by Read
+func (r *RTFReader) GetContent() *interfaces.PDFContent {
+	return r.content
+}
+
+// ReadRTF parses the RTF file into the content structure
+func (r *RTFReader) ReadRTF() (*interfaces.PDFContent, error) {
+	data, err := os.ReadFile(r.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "{\\rtf") {
+		return nil, fmt.Errorf("not an RTF file")
+	}
+
+	r.content = &interfaces.PDFContent{Metadata: make(map[string]string)}
+	r.codePage = 1252
+	r.fonts = make(map[int]*rtfFont)
+	r.styles = make(map[int]string)
+	r.lists = make(map[int][]bool)
+	r.overrides = make(map[int]int)
+	r.page = make(map[string]int)
+	r.images = 0
+
+	body := &rtfCollector{kind: "body"}
+	states := []rtfState{{uc: 1, collector: body, para: rtfParagraph{style: -1, outline: -1}}}
+	// last is the state of the document group, whose last paragraph may
+	// end without \par
+	last := states[0]
+	for _, token := range tokenizeRTF(data) {
+		if len(states) == 0 {
+			break
+		}
+		state := &states[len(states)-1]
+		if token.kind != rtfText {
+			r.flushText(state)
+		}
+
+		switch token.kind {
+		case rtfGroupStart:
+			group := *state
+			group.starts, group.ignorable = "", false
+			states = append(states, group)
+			if state.dest == "stylesheet" {
+				// Styles without \s are style 0
+				r.style, r.styleName = 0, ""
+			}
+			r.skip = 0
+		case rtfGroupEnd:
+			ended := states[len(states)-1]
+			states = states[:len(states)-1]
+			if len(states) > 0 {
+				r.endGroup(ended, &states[len(states)-1])
+			} else {
+				last = ended
+			}
+			r.skip = 0
+		case rtfText:
+			text := token.data
+			if r.skip > 0 {
+				n := min(r.skip, len(text))
+				text, r.skip = text[n:], r.skip-n
+			}
+			r.pending = append(r.pending, text...)
+		case rtfBinary:
+			if state.pict != nil && state.dest == "pict" {
+				state.pict.data = append(state.pict.data, token.data...)
+			}
+		case rtfControl:
+			r.control(state, token)
+		}
+	}
+
+	r.endParagraph(&last)
+	r.closeTable(body)
+	// A trailing section break does not start another section
+	for len(body.blocks) > 0 && body.blocks[len(body.blocks)-1].Kind == interfaces.BlockPageBreak {
+		body.blocks = body.blocks[:len(body.blocks)-1]
+	}
+
+	r.content.Blocks = body.blocks
+	r.content.Text = strings.Join(interfaces.BlockLines(body.blocks), "\n")
+	r.content.Pages = []interfaces.PDFPage{{Number: 1, Text: r.content.Text}}
+	r.setPageSetup()
+	return r.content, nil
+}
+
+// control applies a control word or symbol to the group state
+func (r *RTFReader) control(state *rtfState, token rtfToken) {
+	word, param := token.word, token.param
+	if word == "*" {
+		state.ignorable = true
+		return
+	}
+	ignorable := state.ignorable
+	state.ignorable = false
+	if state.dest == "skip" {
+		return
+	}
+	switch word {
+	case "u":
+		r.writeUnicode(state, param)
+		return
+	case "uc":
+		state.uc = param
+		return
+	}
+	r.skip = 0
+
+	// Tables of the document header
+	switch state.dest {
+	case "fonttbl":
+		switch word {
+		case "f":
+			r.font, state.font = param, param
+			r.fonts[param] = &rtfFont{}
+		case "fcharset":
+			if font, ok := r.fonts[r.font]; ok && font.codePage == 0 {
+				font.codePage = rtfCharsetCodePages[param]
+			}
+		case "cpg":
+			if font, ok := r.fonts[r.font]; ok {
+				font.codePage = param
+			}
+		default:
+			if ignorable {
+				state.dest = "skip"
+			}
+		}
+		return
+	case "colortbl":
+		switch word {
+		case "red":
+			r.color = []int{param, 0, 0}
+		case "green", "blue":
+			if r.color == nil {
+				r.color = []int{0, 0, 0}
+			}
+			if word == "green" {
+				r.color[1] = param
+			} else {
+				r.color[2] = param
+			}
+		}
+		return
+	case "stylesheet":
+		switch word {
+		case "s":
+			r.style = param
+			r.styleName = ""
+		case "cs", "ds", "ts", "tsrowd":
+			r.style = -1
+		default:
+			if ignorable {
+				state.dest = "skip"
+			}
+		}
+		return
+	case "listtable":
+		switch word {
+		case "list":
+			r.listLevels = nil
+		case "listlevel":
+			r.listLevels = append(r.listLevels, true)
+		case "levelnfc", "levelnfcn":
+			if len(r.listLevels) > 0 {
+				// 23 is a bullet and 255 no number
+				r.listLevels[len(r.listLevels)-1] = param != 23 && param != 255
+			}
+		case "listid":
+			r.lists[param] = r.listLevels
+		default:
+			if rtfSkipped[word] || ignorable {
+				state.dest = "skip"
+			}
+		}
+		return
+	case "listoverridetable":
+		switch word {
+		case "listid":
+			r.overrideID = param
+		case "ls":
+			r.overrides[param] = r.overrideID
+		}
+		return
+	case "creatim", "revtim":
+		r.date[word] = param
+		return
+	case "info":
+		if key, ok := rtfMetadata[word]; ok {
+			state.dest, state.starts = word, word
+			if word == "creatim" || word == "revtim" {
+				r.date = make(map[string]int)
+			} else {
+				r.content.Metadata[key] = ""
+			}
+		} else if ignorable {
+			state.dest = "skip"
+		}
+		return
+	case "pict":
+		switch word {
+		case "pngblip":
+			state.pict.ext = ".png"
+		case "jpegblip":
+			state.pict.ext = ".jpg"
+		case "emfblip", "wmetafile", "macpict", "dibitmap", "wbitmap":
+			state.pict.ext = ""
+		default:
+			if ignorable {
+				state.dest = "skip"
+			}
+		}
+		return
+	}
+
+	switch word {
+	// Destinations
+	case "fonttbl", "colortbl", "stylesheet", "info", "listtable", "listoverridetable":
+		state.dest = word
+	case "pict":
+		state.dest, state.starts = "pict", "pict"
+		state.pict = &rtfPicture{}
+	case "header", "headerl", "headerr", "footer", "footerl", "footerr", "footnote":
+		state.collector = &rtfCollector{kind: word}
+		state.dest, state.starts = "", "collector"
+		state.para = rtfParagraph{style: -1, outline: -1}
+	case "ftnalt":
+		if state.collector.kind == "footnote" {
+			state.collector.endnote = true
+		}
+	case "field":
+		state.field = &rtfField{}
+	case "fldinst":
+		state.dest = "fldinst"
+	case "fldrslt":
+		if state.field != nil {
+			if placeholder := docxField(state.field.instr); placeholder != "" {
+				r.write(state, placeholder)
+				state.dest = "skip"
+			}
+		}
+	case "shppict":
+
+	// Document properties
+	case "ansicpg":
+		r.codePage = param
+	case "deff":
+		r.defaultFont = param
+		state.font = param
+	case "paperw", "paperh", "margl", "margr", "margt", "margb", "headery", "footery":
+		r.page[word] = param
+
+	// Character formatting
+	case "plain":
+		state.format = interfaces.Run{}
+		state.font = r.defaultFont
+		if font, ok := r.fonts[r.defaultFont]; ok {
+			state.format.Font = font.name
+		}
+	case "b":
+		state.format.Bold = !token.hasParam || param != 0
+	case "i":
+		state.format.Italic = !token.hasParam || param != 0
+	case "ul", "uld", "uldb", "uldash", "uldashd", "uldashdd", "ulth", "ulw", "ulwave", "uldbwave":
+		state.format.Underline = !token.hasParam || param != 0
+	case "ulnone":
+		state.format.Underline = false
+	case "strike", "striked":
+		state.format.Strike = !token.hasParam || param != 0
+	case "fs":
+		state.format.Size = float64(param) / 2
+	case "f":
+		state.font = param
+		if font, ok := r.fonts[param]; ok {
+			state.format.Font = font.name
+		}
+	case "cf":
+		state.format.Color = r.colorAt(param)
+	case "highlight", "cb", "chcbpat":
+		state.format.Highlight = r.colorAt(param)
+
+	// Paragraphs
+	case "par", "nestcell", "nestrow":
+		r.endParagraph(state)
+	case "pard":
+		state.para = rtfParagraph{style: -1, outline: -1}
+	case "ql":
+		state.para.align = "LEFT"
+	case "qc":
+		state.para.align = "CENTER"
+	case "qr":
+		state.para.align = "RIGHT"
+	case "qj":
+		state.para.align = "JUSTIFY"
+	case "qd":
+		state.para.align = "DISTRIBUTE"
+	case "s":
+		state.para.style = param
+	case "outlinelevel":
+		state.para.outline = param
+	case "ls":
+		state.para.list = param
+	case "ilvl":
+		state.para.level = param
+	case "intbl":
+		state.para.inTable = true
+	case "page":
+		r.addBlock(state, interfaces.Block{Kind: interfaces.BlockPageBreak})
+	case "sect":
+		r.addBlock(state, interfaces.Block{Kind: interfaces.BlockPageBreak, Section: true})
+
+	// Tables
+	case "trowd":
+		table := r.table(state.collector)
+		table.defs, table.merge = nil, rtfCellDef{}
+	case "clmgf", "clmrg", "clvmgf", "clvmrg":
+		table := r.table(state.collector)
+		merge := "first"
+		if strings.HasSuffix(word, "mrg") {
+			merge = "continue"
+		}
+		if strings.HasPrefix(word, "clv") {
+			table.merge.vMerge = merge
+		} else {
+			table.merge.hMerge = merge
+		}
+	case "cellx":
+		table := r.table(state.collector)
+		table.merge.right = param
+		table.defs = append(table.defs, table.merge)
+		table.merge = rtfCellDef{}
+	case "cell":
+		state.para.inTable = true
+		r.endParagraph(state)
+		table := r.table(state.collector)
+		table.row = append(table.row, rtfCell{blocks: table.cell})
+		table.cell = nil
+	case "row":
+		table := r.table(state.collector)
+		for i := range table.row {
+			if i < len(table.defs) {
+				table.row[i].def = table.defs[i]
+			} else {
+				table.row[i].def.right = -1
+			}
+		}
+		table.rows = append(table.rows, table.row)
+		table.row = nil
+
+	default:
+		if text, ok := rtfSpecialChars[word]; ok {
+			r.write(state, text)
+		} else if rtfSkipped[word] || ignorable {
+			state.dest = "skip"
+		}
+	}
+}
+
+// endGroup finishes the destination that began in a group
+func (r *RTFReader) endGroup(ended rtfState, parent *rtfState) {
+	switch ended.starts {
+	case "collector":
+		collector := ended.collector
+		r.endParagraph(&ended)
+		r.closeTable(collector)
+		text := strings.Join(interfaces.BlockLines(collector.blocks), "\n")
+		if collector.kind == "footnote" {
+			r.addNote(parent, collector.endnote, text)
+		} else {
+			r.addHeaderFooter(collector, text)
+		}
+	case "pict":
+		pict := ended.pict
+		data := pict.data
+		if len(data) == 0 {
+			data, _ = hex.DecodeString(strings.Map(func(c rune) rune {
+				if c == ' ' || c == '\t' {
+					return -1
+				}
+				return c
+			}, pict.hex.String()))
+		}
+		if pict.ext != "" && len(data) > 0 {
+			r.images++
+			name := fmt.Sprintf("image%d%s", r.images, pict.ext)
+			r.addBlock(parent, interfaces.Block{Kind: interfaces.BlockImage, Image: &interfaces.Image{Name: name, Data: data}})
+		}
+	case "creatim", "revtim":
+		if r.date["yr"] > 0 {
+			date := time.Date(r.date["yr"], time.Month(max(r.date["mo"], 1)), max(r.date["dy"], 1), r.date["hr"], r.date["min"], r.date["sec"], 0, time.UTC)
+			r.content.Metadata[rtfMetadata[ended.starts]] = date.Format(time.RFC3339)
+		}
+	}
+}
+
+// flushText decodes the pending bytes and sends them to the destination
+func (r *RTFReader) flushText(state *rtfState) {
+	if len(r.pending) == 0 {
+		return
+	}
+	// Content and font names use the charset of their font, other
+	// destinations the ANSI code page
+	codePage := r.codePage
+	if font, ok := r.fonts[state.font]; ok && font.codePage != 0 && (state.dest == "" || state.dest == "fonttbl") {
+		codePage = font.codePage
+	}
+	text := decodeCodePage(codePage, r.pending)
+	r.pending = nil
+	r.emit(state, text)
+}
+
+// emit sends decoded text to the destination of the group
+func (r *RTFReader) emit(state *rtfState, text string) {
+	switch state.dest {
+	case "":
+		r.write(state, text)
+	case "fonttbl":
+		if font, ok := r.fonts[r.font]; ok && !font.complete {
+			name, _, found := strings.Cut(font.name+text, ";")
+			font.name, font.complete = name, found
+			if found {
+				font.name = strings.TrimSpace(name)
+			}
+		}
+	case "colortbl":
+		for _, c := range text {
+			if c != ';' {
+				continue
+			}
+			color := ""
+			if r.color != nil {
+				color = fmt.Sprintf("#%02X%02X%02X", r.color[0], r.color[1], r.color[2])
+			}
+			r.colors = append(r.colors, color)
+			r.color = nil
+		}
+	case "stylesheet":
+		if r.style < 0 {
+			return
+		}
+		r.styleName += text
+		if name, _, found := strings.Cut(r.styleName, ";"); found {
+			r.styles[r.style] = strings.TrimSpace(name)
+			r.style = -1
+		}
+	case "fldinst":
+		if state.field != nil {
+			state.field.instr += text
+		}
+	case "pict":
+		state.pict.hex.WriteString(text)
+	default:
+		if key, ok := rtfMetadata[state.dest]; ok {
+			r.content.Metadata[key] += text
+		}
+	}
+}
+
+// write appends text to the paragraph being read with the group's format
+func (r *RTFReader) write(state *rtfState, text string) {
+	if state.dest != "" {
+		r.emit(state, text)
+		return
+	}
+	p := &state.collector.para
+	p.Runs = appendRun(p.Runs, text, state.format)
+}
+
+// writeUnicode writes the character of a \u control and skips its
+// fallback characters. Negative values stand for code units above 32767.
+func (r *RTFReader) writeUnicode(state *rtfState, value int) {
+	if value < 0 {
+		value += 65536
+	}
+	c := rune(value)
+	switch {
+	case utf16.IsSurrogate(c) && c < 0xDC00:
+		r.surrogate = c
+	case utf16.IsSurrogate(c):
+		if r.surrogate != 0 {
+			r.emit(state, string(utf16.DecodeRune(r.surrogate, c)))
+		}
+		r.surrogate = 0
+	default:
+		r.emit(state, string(c))
+	}
+	r.skip = state.uc
+}
+
+// colorAt returns the color table entry at index, empty for the automatic color
+func (r *RTFReader) colorAt(index int) string {
+	if index < 0 || index >= len(r.colors) {
+		return ""
+	}
+	return r.colors[index]
+}
+
+// table returns the table being read in a collector, starting one if needed
+func (r *RTFReader) table(collector *rtfCollector) *rtfTable {
+	if collector.table == nil {
+		collector.table = &rtfTable{}
+	}
+	return collector.table
+}
+
+// endParagraph finishes the paragraph being read in the group's collector
+func (r *RTFReader) endParagraph(state *rtfState) {
+	collector := state.collector
+	block := collector.para
+	collector.para = interfaces.Block{}
+
+	p := state.para
+	block.Kind = interfaces.BlockParagraph
+	block.Align = p.align
+	if name, ok := r.styles[p.style]; ok {
+		block.Style = name
+		if match := docxHeadingPattern.FindStringSubmatch(name); match != nil {
+			block.Kind = interfaces.BlockHeading
+			block.Level, _ = strconv.Atoi(match[1])
+		}
+	}
+	switch {
+	case p.outline >= 0 && p.outline < 9:
+		block.Kind, block.Level = interfaces.BlockHeading, p.outline+1
+	case p.list > 0 && block.Kind != interfaces.BlockHeading:
+		block.Kind, block.Level = interfaces.BlockListItem, p.level+1
+		if levels := r.lists[r.overrides[p.list]]; p.level < len(levels) {
+			block.Ordered = levels[p.level]
+		}
+	}
+
+	hasText := strings.TrimSpace(block.Text()) != ""
+	if hasText {
+		block.Runs = trimRuns(block.Runs)
+	}
+	if p.inTable {
+		if hasText {
+			table := r.table(collector)
+			table.cell = append(table.cell, block)
+		}
+		return
+	}
+	r.closeTable(collector)
+	if hasText {
+		collector.blocks = append(collector.blocks, block)
+	}
+}
+
+// addBlock places a picture or page break after the text of the paragraph being read
+func (r *RTFReader) addBlock(state *rtfState, block interfaces.Block) {
+	collector := state.collector
+	if strings.TrimSpace(collector.para.Text()) != "" {
+		r.endParagraph(state)
+	}
+	if state.para.inTable && block.Kind != interfaces.BlockPageBreak {
+		table := r.table(collector)
+		table.cell = append(table.cell, block)
+		return
+	}
+	r.closeTable(collector)
+	collector.blocks = append(collector.blocks, block)
+}
+
+// closeTable adds the table being read in a collector once its rows are complete
+func (r *RTFReader) closeTable(collector *rtfCollector) {
+	table := collector.table
+	collector.table = nil
+	if table == nil || len(table.rows) == 0 {
+		return
+	}
+
+	// Columns are the distinct cell boundaries of all rows
+	var edges []int
+	seen := make(map[int]bool)
+	for _, row := range table.rows {
+		for i := range row {
+			if right := row[i].def.right; right >= 0 && !seen[right] {
+				seen[right] = true
+				edges = append(edges, right)
+			}
+		}
+	}
+	sort.Ints(edges)
+	column := func(right int) int {
+		return sort.SearchInts(edges, right) + 1
+	}
+
+	result := &interfaces.Table{}
+	// vertical maps a start column to the cell that began a vertical merge there
+	type position struct{ row, index int }
+	vertical := make(map[int]position)
+	for rowIndex, row := range table.rows {
+		var cells []interfaces.TableCell
+		start := 0
+		for _, cell := range row {
+			end := start + 1
+			if cell.def.right >= 0 {
+				end = max(column(cell.def.right), start+1)
+			}
+			span := end - start
+			pos, merged := vertical[start]
+
+			switch {
+			case cell.def.hMerge == "continue" && len(cells) > 0:
+				cells[len(cells)-1].ColSpan += span
+				cells[len(cells)-1].Blocks = append(cells[len(cells)-1].Blocks, cell.blocks...)
+			case cell.def.vMerge == "continue" && merged:
+				result.Rows[pos.row][pos.index].RowSpan++
+			default:
+				cells = append(cells, interfaces.TableCell{Row: rowIndex, Col: start, RowSpan: 1, ColSpan: span, Blocks: cell.blocks})
+				if cell.def.vMerge == "first" {
+					vertical[start] = position{rowIndex, len(cells) - 1}
+				} else {
+					delete(vertical, start)
+				}
+			}
+			start = end
+		}
+		result.Rows = append(result.Rows, cells)
+	}
+	collector.blocks = append(collector.blocks, interfaces.Block{Kind: interfaces.BlockTable, Table: result})
+}
+
+// addNote adds a footnote or endnote and writes its reference in the parent group
+func (r *RTFReader) addNote(parent *rtfState, endnote bool, text string) {
+	kind := interfaces.Footnote
+	if endnote {
+		kind = interfaces.Endnote
+	}
+	count := 0
+	for _, note := range r.content.Notes {
+		if note.Kind == kind {
+			count++
+		}
+	}
+
+	note := interfaces.Note{Kind: kind, ID: strconv.Itoa(count + 1), Text: text}
+	note.Label = hwpx.FormatNumber("DIGIT", count+1)
+	if endnote {
+		note.ID = "e" + note.ID
+		note.Label = hwpx.FormatNumber("ROMAN_SMALL", count+1)
+	}
+	r.content.Notes = append(r.content.Notes, note)
+
+	// The reference takes the format of the text it follows
+	if parent.dest != "" {
+		return
+	}
+	p := &parent.collector.para
+	format := parent.format
+	if len(p.Runs) > 0 {
+		format = p.Runs[len(p.Runs)-1]
+	}
+	p.Runs = appendRun(p.Runs, interfaces.NoteRef(note.ID), format)
+}
+
+// addHeaderFooter adds the text of a header or footer destination
+func (r *RTFReader) addHeaderFooter(collector *rtfCollector, text string) {
+	if text == "" {
+		return
+	}
+	item := interfaces.HeaderFooter{Apply: "BOTH", Text: text}
+	// Left pages are even and right pages odd
+	switch collector.kind {
+	case "headerl", "footerl":
+		item.Apply = "EVEN"
+	case "headerr", "footerr":
+		item.Apply = "ODD"
+	}
+	for _, block := range collector.blocks {
+		if block.Align != "" {
+			item.Align = block.Align
+			break
+		}
+	}
+	if strings.HasPrefix(collector.kind, "header") {
+		r.content.Headers = append(r.content.Headers, item)
+	} else {
+		r.content.Footers = append(r.content.Footers, item)
+	}
+}
+
+// setPageSetup sets the page size and margins read from the document properties
+func (r *RTFReader) setPageSetup() {
+	if r.page["paperw"] == 0 || r.page["paperh"] == 0 {
+		return
+	}
+	// Sizes are in twentieths of a point. RTF places the header at its
+	// distance from the paper edge and the body at the top margin, the
+	// content model places the header area between the top margin and the body.
+	value := func(name string, fallback int) float64 {
+		if v, ok := r.page[name]; ok {
+			return float64(v) / 20
+		}
+		return float64(fallback) / 20
+	}
+	top, bottom := value("margt", 1440), value("margb", 1440)
+	header, footer := value("headery", 720), value("footery", 720)
+	r.content.PageSetup = &interfaces.PageSetup{
+		Width:        value("paperw", 0),
+		Height:       value("paperh", 0),
+		MarginLeft:   value("margl", 1800),
+		MarginRight:  value("margr", 1800),
+		MarginTop:    min(header, top),
+		MarginHeader: max(top-header, 0),
+		MarginBottom: min(footer, bottom),
+		MarginFooter: max(bottom-footer, 0),
+	}
+}
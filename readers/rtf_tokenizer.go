@@ -0,0 +1,237 @@
+package readers
+
+import (
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// RTF token kinds
+const (
+	rtfGroupStart = iota
+	rtfGroupEnd
+	rtfControl
+	// rtfText holds literal bytes, including \'hh escapes, in the code page
+	// of the current font
+	rtfText
+	// rtfBinary holds the raw data of a \bin control
+	rtfBinary
+)
+
+// rtfToken is a group delimiter, a control word or symbol, or text
+type rtfToken struct {
+	kind int
+	// word is the name of a control word, or the character of a control symbol
+	word     string
+	param    int
+	hasParam bool
+	data     []byte
+	// hex marks text that came from a \'hh escape
+	hex bool
+}
+
+// rtfCharsetCodePages maps \fcharset values to Windows code pages
+var rtfCharsetCodePages = map[int]int{
+	0:   1252,
+	128: 932,
+	129: 949,
+	134: 936,
+	136: 950,
+	161: 1253,
+	162: 1254,
+	177: 1255,
+	178: 1256,
+	186: 1257,
+	204: 1251,
+	238: 1250,
+}
+
+// tokenizeRTF splits RTF data into tokens
+func tokenizeRTF(data []byte) []rtfToken {
+	var tokens []rtfToken
+	var text []byte
+	flush := func() {
+		if len(text) > 0 {
+			tokens = append(tokens, rtfToken{kind: rtfText, data: text})
+			text = nil
+		}
+	}
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch c {
+		case '{':
+			flush()
+			tokens = append(tokens, rtfToken{kind: rtfGroupStart})
+			i++
+		case '}':
+			flush()
+			tokens = append(tokens, rtfToken{kind: rtfGroupEnd})
+			i++
+		case '\r', '\n':
+			// Line breaks of the file are not part of the text
+			i++
+		case '\\':
+			i++
+			if i >= len(data) {
+				break
+			}
+			c = data[i]
+			switch {
+			case c == '\\' || c == '{' || c == '}':
+				text = append(text, c)
+				i++
+			case c == '\'':
+				if i+2 < len(data) {
+					if b, ok := hexByte(data[i+1], data[i+2]); ok {
+						flush()
+						tokens = append(tokens, rtfToken{kind: rtfText, data: []byte{b}, hex: true})
+					}
+				}
+				i += 3
+			case c == '\r' || c == '\n':
+				// A backslash before a line break is a paragraph mark
+				flush()
+				tokens = append(tokens, rtfToken{kind: rtfControl, word: "par"})
+				i++
+			case isLetter(c):
+				flush()
+				start := i
+				for i < len(data) && isLetter(data[i]) {
+					i++
+				}
+				token := rtfToken{kind: rtfControl, word: string(data[start:i])}
+				numStart := i
+				if i < len(data) && data[i] == '-' {
+					i++
+				}
+				for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+					i++
+				}
+				if i > numStart && !(i == numStart+1 && data[numStart] == '-') {
+					token.hasParam = true
+					token.param = atoiSigned(data[numStart:i])
+				} else {
+					i = numStart
+				}
+				// A space delimiting the control word belongs to it
+				if i < len(data) && data[i] == ' ' {
+					i++
+				}
+				if token.word == "bin" && token.param > 0 {
+					end := min(i+token.param, len(data))
+					tokens = append(tokens, token, rtfToken{kind: rtfBinary, data: data[i:end]})
+					i = end
+					continue
+				}
+				tokens = append(tokens, token)
+			default:
+				flush()
+				tokens = append(tokens, rtfToken{kind: rtfControl, word: string(c)})
+				i++
+			}
+		default:
+			text = append(text, c)
+			i++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// isLetter reports whether c is an ASCII letter
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// hexByte returns the byte written as two hexadecimal digits
+func hexByte(hi, lo byte) (byte, bool) {
+	h, ok1 := hexDigit(hi)
+	l, ok2 := hexDigit(lo)
+	return h<<4 | l, ok1 && ok2
+}
+
+// hexDigit returns the value of a hexadecimal digit
+func hexDigit(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	default:
+		return 0, false
+	}
+}
+
+// atoiSigned parses an optionally negative decimal number
+func atoiSigned(digits []byte) int {
+	n, negative := 0, false
+	for i, c := range digits {
+		if i == 0 && c == '-' {
+			negative = true
+			continue
+		}
+		n = n*10 + int(c-'0')
+	}
+	if negative {
+		return -n
+	}
+	return n
+}
+
+// codePageEncoding returns the encoding of a Windows code page, nil when
+// the code page is unknown
+func codePageEncoding(codePage int) encoding.Encoding {
+	switch codePage {
+	case 949:
+		// The decoder accepts the unified Hangul code extensions of CP949
+		return korean.EUCKR
+	case 932:
+		return japanese.ShiftJIS
+	case 936:
+		return simplifiedchinese.GBK
+	case 950:
+		return traditionalchinese.Big5
+	case 874:
+		return charmap.Windows874
+	case 1250:
+		return charmap.Windows1250
+	case 1251:
+		return charmap.Windows1251
+	case 1252:
+		return charmap.Windows1252
+	case 1253:
+		return charmap.Windows1253
+	case 1254:
+		return charmap.Windows1254
+	case 1255:
+		return charmap.Windows1255
+	case 1256:
+		return charmap.Windows1256
+	case 1257:
+		return charmap.Windows1257
+	case 1258:
+		return charmap.Windows1258
+	default:
+		return nil
+	}
+}
+
+// decodeCodePage converts text in a Windows code page to UTF-8, falling
+// back to Windows-1252 for unknown code pages
+func decodeCodePage(codePage int, data []byte) string {
+	enc := codePageEncoding(codePage)
+	if enc == nil {
+		enc = charmap.Windows1252
+	}
+	text, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(text)
+}
@@ -0,0 +1,63 @@
+package readers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeRTF(t *testing.T) {
+	group := rtfToken{kind: rtfGroupStart}
+	end := rtfToken{kind: rtfGroupEnd}
+	control := func(word string) rtfToken { return rtfToken{kind: rtfControl, word: word} }
+	param := func(word string, value int) rtfToken {
+		return rtfToken{kind: rtfControl, word: word, param: value, hasParam: true}
+	}
+	text := func(s string) rtfToken { return rtfToken{kind: rtfText, data: []byte(s)} }
+	hex := func(b byte) rtfToken { return rtfToken{kind: rtfText, data: []byte{b}, hex: true} }
+
+	tests := []struct {
+		name string
+		rtf  string
+		want []rtfToken
+	}{
+		{name: "groups and words", rtf: `{\rtf1\ansi Hello}`, want: []rtfToken{group, param("rtf", 1), control("ansi"), text("Hello"), end}},
+		{name: "negative parameter", rtf: `\li-720\u-3`, want: []rtfToken{param("li", -720), param("u", -3)}},
+		{name: "lone minus", rtf: `\b-x`, want: []rtfToken{control("b"), text("-x")}},
+		{name: "delimiting space", rtf: `\b  bold`, want: []rtfToken{control("b"), text(" bold")}},
+		{name: "escaped delimiters", rtf: `a\{b\}c\\d`, want: []rtfToken{text(`a{b}c\d`)}},
+		{name: "hex escapes", rtf: `x\'c7\'D1y`, want: []rtfToken{text("x"), hex(0xC7), hex(0xD1), text("y")}},
+		{name: "bad hex escape", rtf: `\'zz!`, want: []rtfToken{text("!")}},
+		{name: "hex escape cut short", rtf: `a\'c`, want: []rtfToken{text("a")}},
+		{name: "control symbols", rtf: `\~\-\*`, want: []rtfToken{control("~"), control("-"), control("*")}},
+		{name: "line breaks", rtf: "a\r\nb\\\nc", want: []rtfToken{text("ab"), control("par"), text("c")}},
+		{name: "binary data", rtf: `\bin3 {}\x}`, want: []rtfToken{param("bin", 3), {kind: rtfBinary, data: []byte(`{}\`)}, text("x"), end}},
+		{name: "binary data cut short", rtf: `\bin9 ab`, want: []rtfToken{param("bin", 9), {kind: rtfBinary, data: []byte("ab")}}},
+		{name: "trailing backslash", rtf: `a\`, want: []rtfToken{text("a")}},
+		{name: "empty", rtf: ``, want: nil},
+	}
+	for _, tt := range tests {
+		if got := tokenizeRTF([]byte(tt.rtf)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: tokenizeRTF(%q) = %+v, want %+v", tt.name, tt.rtf, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeCodePage(t *testing.T) {
+	tests := []struct {
+		codePage int
+		data     []byte
+		want     string
+	}{
+		{codePage: 949, data: []byte{0xC7, 0xD1, 0xB1, 0xDB}, want: "한글"},
+		{codePage: 932, data: []byte{0x82, 0xA0}, want: "あ"},
+		{codePage: 1251, data: []byte{0xCF, 0xF0}, want: "Пр"},
+		{codePage: 1252, data: []byte{0x93, 'a', 0x94}, want: "“a”"},
+		// Unknown code pages fall back to Windows-1252
+		{codePage: 12345, data: []byte{0xE9}, want: "é"},
+	}
+	for _, tt := range tests {
+		if got := decodeCodePage(tt.codePage, tt.data); got != tt.want {
+			t.Errorf("decodeCodePage(%d, % X) = %q, want %q", tt.codePage, tt.data, got, tt.want)
+		}
+	}
+}
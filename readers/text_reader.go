@@ -0,0 +1,116 @@
+package readers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"myconverter/interfaces"
+)
+
+// TextReader implements FileReader for plain text files
+type TextReader struct {
+	filePath string
+	content  *interfaces.PDFContent
+}
+
+// NewTextReader creates a new TextReader
+func NewTextReader(filePath string) *TextReader {
+	return &TextReader{
+		filePath: filePath,
+		content:  &interfaces.PDFContent{},
+	}
+}
+
+// Read reads the text file and extracts its content
+func (r *TextReader) Read(filePath string) error {
+	r.filePath = filePath
+	_, err := r.ReadText()
+	return err
+}
+
+// GetContent returns the content extracted by Read
+func (r *TextReader) GetContent() *interfaces.PDFContent {
+	return r.content
+}
+
+// ReadText reads the text file in its detected encoding. Each non-empty
+// line becomes a paragraph and form feeds become page breaks.
+func (r *TextReader) ReadText() (*interfaces.PDFContent, error) {
+	data, err := os.ReadFile(r.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	text, encoding := decodeText(data)
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+
+	r.content = &interfaces.PDFContent{Metadata: map[string]string{"Encoding": encoding}}
+	for i, page := range strings.Split(text, "\f") {
+		if i > 0 {
+			r.content.Blocks = append(r.content.Blocks, interfaces.Block{Kind: interfaces.BlockPageBreak})
+		}
+		r.content.Blocks = append(r.content.Blocks, interfaces.TextBlocks(page)...)
+		r.content.Pages = append(r.content.Pages, interfaces.PDFPage{Number: i + 1, Text: strings.Trim(page, "\n")})
+	}
+	r.content.Text = strings.Join(interfaces.BlockLines(r.content.Blocks), "\n")
+	return r.content, nil
+}
+
+// decodeText converts text data to UTF-8 and returns the name of the
+// detected encoding. Byte order marks identify UTF-8 and UTF-16, otherwise
+// valid UTF-8 is kept, NUL bytes on every other position point to UTF-16
+// and the rest is read as CP949, or Windows-1252 when it is not valid CP949.
+func decodeText(data []byte) (string, string) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:]), "utf-8-bom"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUTF16(data[2:], false), "utf-16le"
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUTF16(data[2:], true), "utf-16be"
+	case utf8.Valid(data):
+		return string(data), "utf-8"
+	}
+
+	// ASCII characters in UTF-16 have a zero high byte
+	var evenZeros, oddZeros int
+	for i, b := range data {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	if oddZeros > len(data)/4 && evenZeros == 0 {
+		return decodeUTF16(data, false), "utf-16le"
+	}
+	if evenZeros > len(data)/4 && oddZeros == 0 {
+		return decodeUTF16(data, true), "utf-16be"
+	}
+
+	// Invalid sequences decode to replacement characters, which the
+	// original data cannot contain since it is not UTF-8
+	if text := decodeCodePage(949, data); !strings.ContainsRune(text, utf8.RuneError) {
+		return text, "cp949"
+	}
+	return decodeCodePage(1252, data), "windows-1252"
+}
+
+// decodeUTF16 converts UTF-16 data in the given byte order to UTF-8
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+		}
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,182 @@
+package readers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// TIFF tags read from image directories
+const (
+	tiffTagOrientation = 0x0112
+)
+
+// tiffHeader returns the byte order and first directory offset of TIFF data
+func tiffHeader(data []byte) (binary.ByteOrder, uint32, error) {
+	if len(data) < 8 {
+		return nil, 0, fmt.Errorf("TIFF header too short")
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, 0, fmt.Errorf("invalid TIFF byte order")
+	}
+	if order.Uint16(data[2:]) != 42 {
+		return nil, 0, fmt.Errorf("unsupported TIFF version")
+	}
+	return order, order.Uint32(data[4:]), nil
+}
+
+// tiffDirectories returns the offsets of the image directories of TIFF
+// data, one per page
+func tiffDirectories(data []byte) ([]uint32, error) {
+	order, offset, err := tiffHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var offsets []uint32
+	seen := make(map[uint32]bool)
+	for offset != 0 && !seen[offset] {
+		if int(offset)+2 > len(data) {
+			return nil, fmt.Errorf("TIFF directory offset %d out of range", offset)
+		}
+		seen[offset] = true
+		offsets = append(offsets, offset)
+		count := int(order.Uint16(data[offset:]))
+		next := int(offset) + 2 + count*12
+		if next+4 > len(data) {
+			break
+		}
+		offset = order.Uint32(data[next:])
+	}
+	return offsets, nil
+}
+
+// tiffTagValue returns the first value of a SHORT or LONG tag of the image
+// directory at offset
+func tiffTagValue(data []byte, order binary.ByteOrder, offset uint32, tag uint16) (uint32, bool) {
+	if int(offset)+2 > len(data) {
+		return 0, false
+	}
+	count := int(order.Uint16(data[offset:]))
+	for i := 0; i < count; i++ {
+		entry := int(offset) + 2 + i*12
+		if entry+12 > len(data) {
+			return 0, false
+		}
+		if order.Uint16(data[entry:]) != tag {
+			continue
+		}
+		switch order.Uint16(data[entry+2:]) {
+		case 3:
+			return uint32(order.Uint16(data[entry+8:])), true
+		case 4:
+			return order.Uint32(data[entry+8:]), true
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+// tiffPage returns TIFF data whose first directory is the one at offset,
+// so that decoders reading only the first page read that page
+func tiffPage(data []byte, order binary.ByteOrder, offset uint32) []byte {
+	page := append([]byte(nil), data...)
+	order.PutUint32(page[4:], offset)
+	return page
+}
+
+// jpegOrientation returns the EXIF orientation of JPEG data, 1 when absent
+func jpegOrientation(data []byte) int {
+	if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xD8 || marker >= 0xD0 && marker <= 0xD7 || marker == 0x01 || marker == 0xFF {
+			// Markers without a length
+			i += 2
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		// Image data starts after the scan header
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			exif := segment[6:]
+			order, offset, err := tiffHeader(exif)
+			if err != nil {
+				return 1
+			}
+			if value, ok := tiffTagValue(exif, order, offset, tiffTagOrientation); ok && value >= 1 && value <= 8 {
+				return int(value)
+			}
+			return 1
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// orientImage returns the upright image of pixels stored with an EXIF
+// orientation
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	// Gray scans stay gray
+	var dst interface {
+		image.Image
+		Set(x, y int, c color.Color)
+	}
+	if _, ok := img.(*image.Gray); ok {
+		dst = image.NewGray(image.Rect(0, 0, dw, dh))
+	} else {
+		dst = image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	}
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			// Source pixel of each displayed pixel
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return dst
+}
@@ -81,3 +81,50 @@ func (r *ZipReader) GetXMLFiles() []ZipEntry {
 	}
 	return xmlFiles
 }
+
+// DetectZipFormat returns the document format of a ZIP package: docx, xlsx
+// or pptx from the OOXML content types, the mimetype entry of ODF, EPUB and
+// HWPX packages, or "zip" for plain archives
+func DetectZipFormat(filePath string) (string, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open zip file: %v", err)
+	}
+	defer reader.Close()
+
+	readEntry := func(file *zip.File) string {
+		rc, err := file.Open()
+		if err != nil {
+			return ""
+		}
+		defer rc.Close()
+		data, _ := io.ReadAll(io.LimitReader(rc, 1<<20))
+		return string(data)
+	}
+
+	for _, file := range reader.File {
+		switch file.Name {
+		case "[Content_Types].xml":
+			contentTypes := readEntry(file)
+			switch {
+			case strings.Contains(contentTypes, "wordprocessingml.document.main+xml"),
+				strings.Contains(contentTypes, "wordprocessingml.template.main+xml"):
+				return "docx", nil
+			case strings.Contains(contentTypes, "spreadsheetml.sheet.main+xml"):
+				return "xlsx", nil
+			case strings.Contains(contentTypes, "presentationml.presentation.main+xml"):
+				return "pptx", nil
+			}
+		case "mimetype":
+			switch strings.TrimSpace(readEntry(file)) {
+			case "application/vnd.oasis.opendocument.text":
+				return "odt", nil
+			case "application/epub+zip":
+				return "epub", nil
+			case "application/hwp+zip":
+				return "hwpx", nil
+			}
+		}
+	}
+	return "zip", nil
+}
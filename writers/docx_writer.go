@@ -0,0 +1,746 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+
+	"myconverter/interfaces"
+)
+
+// DOCX namespaces and relationship types
+const (
+	docxNamespaceW   = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	docxNamespaceR   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	docxNamespaceWP  = "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+	docxNamespaceA   = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	docxNamespacePic = "http://schemas.openxmlformats.org/drawingml/2006/picture"
+
+	docxRelOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	docxRelCoreProperties = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties"
+	docxRelStyles         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
+	docxRelNumbering      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	docxRelSettings       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings"
+	docxRelFootnotes      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footnotes"
+	docxRelEndnotes       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/endnotes"
+	docxRelHeader         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header"
+	docxRelFooter         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer"
+	docxRelImage          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+)
+
+// Default page setup used when the source document has none: A4 with the
+// body 1 inch from the paper edges
+var docxDefaultPage = interfaces.PageSetup{
+	Width: 595.3, Height: 841.9,
+	MarginLeft: 72, MarginRight: 72, MarginTop: 36, MarginBottom: 36,
+	MarginHeader: 36, MarginFooter: 36,
+}
+
+// DOCXWriter handles writing content as an Office Open XML document
+type DOCXWriter struct {
+	// Output directory for DOCX files
+	OutputDir string
+}
+
+// NewDOCXWriter creates a new DOCXWriter
+func NewDOCXWriter(outputDir string) *DOCXWriter {
+	return &DOCXWriter{OutputDir: outputDir}
+}
+
+// WriteContent converts the content to a DOCX package with its styles,
+// numbering, images, headers, footers and notes.
+func (w *DOCXWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	page := docxDefaultPage
+	if content.PageSetup != nil && content.PageSetup.Width > 0 && content.PageSetup.Height > 0 {
+		page = *content.PageSetup
+	}
+
+	d := &docxBuilder{
+		content:    content,
+		page:       page,
+		media:      make(map[string]string),
+		mediaTypes: make(map[string]string),
+		footnotes:  make(map[string]int),
+		endnotes:   make(map[string]int),
+	}
+	d.body.WriteString(xml.Header)
+	d.body.WriteString(`<w:document xmlns:w="` + docxNamespaceW + `" xmlns:r="` + docxNamespaceR +
+		`" xmlns:wp="` + docxNamespaceWP + `" xmlns:a="` + docxNamespaceA + `" xmlns:pic="` + docxNamespacePic + `"><w:body>`)
+	d.blocks(&d.body, content.ContentBlocks())
+	d.sectionProperties()
+	d.body.WriteString(`</w:body></w:document>`)
+
+	zipWriter := &ZipWriter{TargetPath: filepath.Join(w.OutputDir, filepath.Base(outputPath))}
+	return zipWriter.WriteEntries(d.entries())
+}
+
+// Write writes sample text to the specified file.
+func (w *DOCXWriter) Write(outputPath string) error {
+	return w.WriteContent(outputPath, &interfaces.PDFContent{Text: "Sample Text"})
+}
+
+// docxRel is a relationship of the main document part
+type docxRel struct {
+	ID     string
+	Type   string
+	Target string
+}
+
+// docxBuilder accumulates the document body and the parts it references
+type docxBuilder struct {
+	content *interfaces.PDFContent
+	page    interfaces.PageSetup
+	body    strings.Builder
+	rels    []docxRel
+	parts   []ZipEntry
+	// media maps image names to their relationship IDs
+	media map[string]string
+	// mediaTypes maps image file extensions to their content types
+	mediaTypes map[string]string
+	// footnotes and endnotes map note IDs to their DOCX note IDs
+	footnotes map[string]int
+	endnotes  map[string]int
+	// orderedLists counts the numbering instances of ordered lists
+	orderedLists int
+	// listNum is the numbering instance of the ordered list being written
+	listNum  int
+	drawings int
+	// headerParts and footerParts name the header and footer parts
+	headerParts []string
+	footerParts []string
+	// evenAndOdd marks documents with different odd and even page headers
+	evenAndOdd bool
+}
+
+// addRel adds a relationship of the main document and returns its ID
+func (d *docxBuilder) addRel(relType, target string) string {
+	id := "rId" + strconv.Itoa(len(d.rels)+1)
+	d.rels = append(d.rels, docxRel{ID: id, Type: relType, Target: target})
+	return id
+}
+
+// blocks writes the WordprocessingML of blocks
+func (d *docxBuilder) blocks(out *strings.Builder, blocks []interfaces.Block) {
+	for i, block := range blocks {
+		switch block.Kind {
+		case interfaces.BlockTable:
+			if block.Table != nil {
+				d.table(out, block.Table)
+			}
+		case interfaces.BlockImage:
+			if drawing := d.image(block.Image); drawing != "" {
+				out.WriteString(`<w:p>` + docxParagraphProperties("", block.Align, "") + drawing + `</w:p>`)
+			}
+		case interfaces.BlockPageBreak:
+			out.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+		case interfaces.BlockHeading:
+			style := "Heading" + strconv.Itoa(min(max(block.Level, 1), 6))
+			out.WriteString(`<w:p>` + docxParagraphProperties(style, block.Align, "") + d.runs(block.Runs) + `</w:p>`)
+		case interfaces.BlockListItem:
+			// Each ordered list restarts its numbering
+			num := 1
+			if block.Ordered {
+				if i == 0 || blocks[i-1].Kind != interfaces.BlockListItem || d.listNum == 0 {
+					d.orderedLists++
+					d.listNum = 2 + d.orderedLists
+				}
+				num = d.listNum
+			}
+			numPr := fmt.Sprintf(`<w:numPr><w:ilvl w:val="%d"/><w:numId w:val="%d"/></w:numPr>`, min(max(block.Level, 1), 9)-1, num)
+			out.WriteString(`<w:p>` + docxParagraphProperties("ListParagraph", block.Align, numPr) + d.runs(block.Runs) + `</w:p>`)
+		default:
+			out.WriteString(`<w:p>` + docxParagraphProperties("", block.Align, "") + d.runs(block.Runs) + `</w:p>`)
+		}
+		if block.Kind != interfaces.BlockListItem {
+			d.listNum = 0
+		}
+	}
+}
+
+// docxParagraphProperties returns the pPr element of a paragraph
+func docxParagraphProperties(style, align, numPr string) string {
+	var pPr strings.Builder
+	if style != "" {
+		pPr.WriteString(`<w:pStyle w:val="` + style + `"/>`)
+	}
+	pPr.WriteString(numPr)
+	if jc := docxJustification(align); jc != "" {
+		pPr.WriteString(`<w:jc w:val="` + jc + `"/>`)
+	}
+	if pPr.Len() == 0 {
+		return ""
+	}
+	return `<w:pPr>` + pPr.String() + `</w:pPr>`
+}
+
+// docxJustification returns the w:jc value of a paragraph alignment
+func docxJustification(align string) string {
+	switch strings.ToUpper(align) {
+	case "LEFT":
+		return "left"
+	case "RIGHT":
+		return "right"
+	case "CENTER":
+		return "center"
+	case "JUSTIFY":
+		return "both"
+	case "DISTRIBUTE", "DISTRIBUTE_SPACE":
+		return "distribute"
+	default:
+		return ""
+	}
+}
+
+// runs returns the w:r elements of runs, turning note references into
+// footnote and endnote references
+func (d *docxBuilder) runs(runs []interfaces.Run) string {
+	var out strings.Builder
+	for _, run := range runs {
+		rPr := docxRunProperties(run, "")
+		last := 0
+		for _, match := range interfaces.NoteRefPattern.FindAllStringSubmatchIndex(run.Text, -1) {
+			out.WriteString(docxTextRun(run.Text[last:match[0]], rPr))
+			out.WriteString(d.noteReference(run.Text[match[2]:match[3]]))
+			last = match[1]
+		}
+		out.WriteString(docxTextRun(run.Text[last:], rPr))
+	}
+	return out.String()
+}
+
+// docxRunProperties returns the rPr element of a run's formatting
+func docxRunProperties(run interfaces.Run, style string) string {
+	var rPr strings.Builder
+	if style != "" {
+		rPr.WriteString(`<w:rStyle w:val="` + style + `"/>`)
+	}
+	if run.Font != "" {
+		font := xmlText(run.Font)
+		rPr.WriteString(`<w:rFonts w:ascii="` + font + `" w:hAnsi="` + font + `" w:eastAsia="` + font + `"/>`)
+	}
+	if run.Bold {
+		rPr.WriteString(`<w:b/>`)
+	}
+	if run.Italic {
+		rPr.WriteString(`<w:i/>`)
+	}
+	if run.Strike {
+		rPr.WriteString(`<w:strike/>`)
+	}
+	if run.Color != "" {
+		rPr.WriteString(`<w:color w:val="` + strings.TrimPrefix(run.Color, "#") + `"/>`)
+	}
+	if run.Size > 0 {
+		// Sizes are in half points
+		size := strconv.Itoa(int(run.Size*2 + 0.5))
+		rPr.WriteString(`<w:sz w:val="` + size + `"/><w:szCs w:val="` + size + `"/>`)
+	}
+	if run.Underline {
+		rPr.WriteString(`<w:u w:val="single"/>`)
+	}
+	if run.Highlight != "" {
+		rPr.WriteString(`<w:shd w:val="clear" w:color="auto" w:fill="` + strings.TrimPrefix(run.Highlight, "#") + `"/>`)
+	}
+	if rPr.Len() == 0 {
+		return ""
+	}
+	return `<w:rPr>` + rPr.String() + `</w:rPr>`
+}
+
+// docxTextRun returns a run of text, converting tabs and line breaks
+func docxTextRun(text, rPr string) string {
+	if text == "" {
+		return ""
+	}
+
+	var out strings.Builder
+	out.WriteString(`<w:r>` + rPr)
+	start := 0
+	for i, ch := range text {
+		if ch != '\t' && ch != '\n' {
+			continue
+		}
+		if start < i {
+			out.WriteString(`<w:t xml:space="preserve">` + xmlText(text[start:i]) + `</w:t>`)
+		}
+		if ch == '\t' {
+			out.WriteString(`<w:tab/>`)
+		} else {
+			out.WriteString(`<w:br/>`)
+		}
+		start = i + 1
+	}
+	if start < len(text) {
+		out.WriteString(`<w:t xml:space="preserve">` + xmlText(text[start:]) + `</w:t>`)
+	}
+	out.WriteString(`</w:r>`)
+	return out.String()
+}
+
+// noteReference returns the reference run of a note, shown with the note's
+// own label so the source numbering is kept
+func (d *docxBuilder) noteReference(id string) string {
+	note := d.content.FindNote(id)
+	if note == nil {
+		return docxTextRun(interfaces.NoteRef(id), "")
+	}
+
+	element, style, ids := "footnoteReference", "FootnoteReference", d.footnotes
+	if note.Kind == interfaces.Endnote {
+		element, style, ids = "endnoteReference", "EndnoteReference", d.endnotes
+	}
+	if _, ok := ids[id]; !ok {
+		// IDs -1 and 0 are taken by the separators
+		ids[id] = len(ids) + 1
+	}
+
+	label := note.Label
+	if label == "" {
+		label = note.ID
+	}
+	return fmt.Sprintf(`<w:r>%s<w:%s w:customMarkFollows="1" w:id="%d"/><w:t>%s</w:t></w:r>`,
+		docxRunProperties(interfaces.Run{}, style), element, ids[id], xmlText(label))
+}
+
+// table writes a table, expressing merged cells with gridSpan and vMerge
+func (d *docxBuilder) table(out *strings.Builder, table *interfaces.Table) {
+	columns := table.Columns()
+	if columns == 0 {
+		return
+	}
+
+	// Locate the cells starting at and covering each grid position
+	rows := len(table.Rows)
+	starts := make(map[[2]int]interfaces.TableCell)
+	covers := make(map[[2]int]interfaces.TableCell)
+	for _, row := range table.Rows {
+		for _, cell := range row {
+			cell.RowSpan, cell.ColSpan = max(cell.RowSpan, 1), max(cell.ColSpan, 1)
+			starts[[2]int{cell.Row, cell.Col}] = cell
+			for r := cell.Row; r < cell.Row+cell.RowSpan; r++ {
+				covers[[2]int{r, cell.Col}] = cell
+			}
+			rows = max(rows, cell.Row+cell.RowSpan)
+		}
+	}
+
+	contentWidth := int((d.page.Width - d.page.MarginLeft - d.page.MarginRight) * 20)
+	columnWidth := max(contentWidth/columns, 1)
+
+	out.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="0" w:type="auto"/></w:tblPr><w:tblGrid>`)
+	for i := 0; i < columns; i++ {
+		out.WriteString(fmt.Sprintf(`<w:gridCol w:w="%d"/>`, columnWidth))
+	}
+	out.WriteString(`</w:tblGrid>`)
+
+	for r := 0; r < rows; r++ {
+		out.WriteString(`<w:tr>`)
+		for c := 0; c < columns; {
+			cell, starting := starts[[2]int{r, c}]
+			vMerge := ""
+			if starting {
+				if cell.RowSpan > 1 {
+					vMerge = `<w:vMerge w:val="restart"/>`
+				}
+			} else if covering, ok := covers[[2]int{r, c}]; ok && covering.Row < r {
+				cell, vMerge = covering, `<w:vMerge/>`
+				cell.Blocks = nil
+			} else {
+				cell = interfaces.TableCell{ColSpan: 1}
+			}
+
+			span := max(cell.ColSpan, 1)
+			out.WriteString(fmt.Sprintf(`<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>`, columnWidth*span))
+			if span > 1 {
+				out.WriteString(fmt.Sprintf(`<w:gridSpan w:val="%d"/>`, span))
+			}
+			out.WriteString(vMerge + `</w:tcPr>`)
+			d.blocks(out, cell.Blocks)
+			// A cell must end with a paragraph
+			if len(cell.Blocks) == 0 || cell.Blocks[len(cell.Blocks)-1].Kind == interfaces.BlockTable {
+				out.WriteString(`<w:p/>`)
+			}
+			out.WriteString(`</w:tc>`)
+			c += span
+		}
+		out.WriteString(`</w:tr>`)
+	}
+	out.WriteString(`</w:tbl>`)
+}
+
+// image adds an image to the media parts and returns its inline drawing run
+func (d *docxBuilder) image(img *interfaces.Image) string {
+	if img == nil || len(img.Data) == 0 {
+		return ""
+	}
+
+	ext, contentType := imageType(img)
+	key := img.Name + "\x00" + strconv.Itoa(len(img.Data))
+	relID, ok := d.media[key]
+	if !ok {
+		name := fmt.Sprintf("media/image%d%s", len(d.media)+1, ext)
+		relID = d.addRel(docxRelImage, name)
+		d.media[key] = relID
+		d.mediaTypes[strings.TrimPrefix(ext, ".")] = contentType
+		d.parts = append(d.parts, ZipEntry{Name: "word/" + name, Data: img.Data})
+	}
+
+	// Pictures keep their pixel size at 96 DPI, scaled down to the text width
+	width, height := imagePixels(img.Data)
+	cx, cy := int64(width)*9525, int64(height)*9525
+	if maxWidth := int64((d.page.Width - d.page.MarginLeft - d.page.MarginRight) * 12700); maxWidth > 0 && cx > maxWidth {
+		cy = cy * maxWidth / cx
+		cx = maxWidth
+	}
+
+	d.drawings++
+	name := xmlText(img.Name)
+	return fmt.Sprintf(`<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0">`+
+		`<wp:extent cx="%d" cy="%d"/><wp:docPr id="%d" name="Picture %d" descr="%s"/>`+
+		`<wp:cNvGraphicFramePr><a:graphicFrameLocks noChangeAspect="1"/></wp:cNvGraphicFramePr>`+
+		`<a:graphic><a:graphicData uri="%s"><pic:pic><pic:nvPicPr><pic:cNvPr id="0" name="%s"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="%s"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r>`,
+		cx, cy, d.drawings, d.drawings, name, docxNamespacePic, name, relID, cx, cy)
+}
+
+// imageType returns the file extension and content type of an image
+func imageType(img *interfaces.Image) (string, string) {
+	contentType := http.DetectContentType(img.Data)
+	ext := strings.ToLower(path.Ext(img.Name))
+	if ext == "" {
+		ext = map[string]string{"image/jpeg": ".jpg", "image/gif": ".gif", "image/bmp": ".bmp"}[contentType]
+		if ext == "" {
+			ext = ".png"
+		}
+	}
+	if contentType == "application/octet-stream" {
+		contentType = "image/" + strings.TrimPrefix(ext, ".")
+	}
+	return ext, contentType
+}
+
+// imagePixels returns the pixel size of an image, 400x300 when it cannot be decoded
+func imagePixels(data []byte) (int, int) {
+	if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil && config.Width > 0 && config.Height > 0 {
+		return config.Width, config.Height
+	}
+	return 400, 300
+}
+
+// sectionProperties writes the final sectPr with headers, footers and page setup
+func (d *docxBuilder) sectionProperties() {
+	// Even pages get their own parts when some item applies to odd or even pages
+	for _, item := range append(append([]interfaces.HeaderFooter(nil), d.content.Headers...), d.content.Footers...) {
+		if apply := strings.ToUpper(item.Apply); apply == "ODD" || apply == "EVEN" {
+			d.evenAndOdd = true
+		}
+	}
+
+	var sectPr strings.Builder
+	sectPr.WriteString(`<w:sectPr>`)
+	sectPr.WriteString(d.headerFooterReferences("header", d.content.Headers))
+	sectPr.WriteString(d.headerFooterReferences("footer", d.content.Footers))
+
+	twips := func(points float64) int {
+		return int(points*20 + 0.5)
+	}
+	orient := ""
+	if d.page.Width > d.page.Height {
+		orient = ` w:orient="landscape"`
+	}
+	sectPr.WriteString(fmt.Sprintf(`<w:pgSz w:w="%d" w:h="%d"%s/>`, twips(d.page.Width), twips(d.page.Height), orient))
+
+	// Word places the body at the top margin and the header at its own
+	// distance from the paper edge, HWP places the header inside the top
+	// margin and the body below it
+	sectPr.WriteString(fmt.Sprintf(`<w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="%d" w:footer="%d" w:gutter="0"/>`,
+		twips(d.page.MarginTop+d.page.MarginHeader), twips(d.page.MarginRight),
+		twips(d.page.MarginBottom+d.page.MarginFooter), twips(d.page.MarginLeft),
+		twips(d.page.MarginTop), twips(d.page.MarginBottom)))
+	sectPr.WriteString(`</w:sectPr>`)
+	d.body.WriteString(sectPr.String())
+}
+
+// headerFooterReferences adds the header or footer parts for odd and even
+// pages and returns their references
+func (d *docxBuilder) headerFooterReferences(kind string, items []interfaces.HeaderFooter) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	// Odd pages use the default part
+	pageTypes := []string{"default"}
+	if d.evenAndOdd {
+		pageTypes = append(pageTypes, "even")
+	}
+
+	var refs strings.Builder
+	for i, pageType := range pageTypes {
+		var part strings.Builder
+		element, style := "w:hdr", "Header"
+		if kind == "footer" {
+			element, style = "w:ftr", "Footer"
+		}
+		part.WriteString(xml.Header + `<` + element + ` xmlns:w="` + docxNamespaceW + `" xmlns:r="` + docxNamespaceR + `">`)
+		selected := interfaces.SelectHeaderFooters(items, i+1)
+		for _, item := range selected {
+			for _, line := range strings.Split(item.Text, "\n") {
+				part.WriteString(`<w:p>` + docxParagraphProperties(style, item.Align, "") + docxFieldRuns(line) + `</w:p>`)
+			}
+		}
+		if len(selected) == 0 {
+			part.WriteString(`<w:p/>`)
+		}
+		part.WriteString(`</` + element + `>`)
+
+		var name string
+		if kind == "footer" {
+			d.footerParts = append(d.footerParts, fmt.Sprintf("footer%d.xml", len(d.footerParts)+1))
+			name = d.footerParts[len(d.footerParts)-1]
+		} else {
+			d.headerParts = append(d.headerParts, fmt.Sprintf("header%d.xml", len(d.headerParts)+1))
+			name = d.headerParts[len(d.headerParts)-1]
+		}
+		d.parts = append(d.parts, ZipEntry{Name: "word/" + name, Data: []byte(part.String())})
+
+		relType := docxRelHeader
+		if kind == "footer" {
+			relType = docxRelFooter
+		}
+		refs.WriteString(fmt.Sprintf(`<w:%sReference w:type="%s" r:id="%s"/>`, kind, pageType, d.addRel(relType, name)))
+	}
+	return refs.String()
+}
+
+// docxFieldRuns returns the runs of header or footer text, turning page
+// number placeholders into PAGE and NUMPAGES fields
+func docxFieldRuns(text string) string {
+	var out strings.Builder
+	for text != "" {
+		page := strings.Index(text, interfaces.PageNumberField)
+		total := strings.Index(text, interfaces.TotalPagesField)
+		if page < 0 && total < 0 {
+			out.WriteString(docxTextRun(text, ""))
+			break
+		}
+
+		field, placeholder, index := "PAGE", interfaces.PageNumberField, page
+		if page < 0 || (total >= 0 && total < page) {
+			field, placeholder, index = "NUMPAGES", interfaces.TotalPagesField, total
+		}
+		out.WriteString(docxTextRun(text[:index], ""))
+		out.WriteString(`<w:fldSimple w:instr=" ` + field + ` "><w:r><w:t>1</w:t></w:r></w:fldSimple>`)
+		text = text[index+len(placeholder):]
+	}
+	return out.String()
+}
+
+// notesPart returns the footnotes or endnotes part holding the referenced notes
+func (d *docxBuilder) notesPart(kind string, ids map[string]int) []byte {
+	element, style, textStyle := "footnote", "FootnoteReference", "FootnoteText"
+	if kind == interfaces.Endnote {
+		element, style, textStyle = "endnote", "EndnoteReference", "EndnoteText"
+	}
+
+	var part strings.Builder
+	part.WriteString(xml.Header + `<w:` + element + `s xmlns:w="` + docxNamespaceW + `" xmlns:r="` + docxNamespaceR + `">`)
+	part.WriteString(`<w:` + element + ` w:type="separator" w:id="-1"><w:p><w:r><w:separator/></w:r></w:p></w:` + element + `>`)
+	part.WriteString(`<w:` + element + ` w:type="continuationSeparator" w:id="0"><w:p><w:r><w:continuationSeparator/></w:r></w:p></w:` + element + `>`)
+	for _, note := range d.content.Notes {
+		id, ok := ids[note.ID]
+		if !ok {
+			continue
+		}
+		label := note.Label
+		if label == "" {
+			label = note.ID
+		}
+		part.WriteString(fmt.Sprintf(`<w:%s w:id="%d"><w:p>`, element, id))
+		part.WriteString(docxParagraphProperties(textStyle, "", ""))
+		part.WriteString(docxTextRun(label, docxRunProperties(interfaces.Run{}, style)))
+		part.WriteString(docxTextRun(" "+note.Text, ""))
+		part.WriteString(`</w:p></w:` + element + `>`)
+	}
+	part.WriteString(`</w:` + element + `s>`)
+	return []byte(part.String())
+}
+
+// entries returns the files of the DOCX package
+func (d *docxBuilder) entries() []ZipEntry {
+	d.addRel(docxRelStyles, "styles.xml")
+	d.addRel(docxRelNumbering, "numbering.xml")
+	d.addRel(docxRelSettings, "settings.xml")
+	overrides := []string{
+		`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>`,
+		`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>`,
+		`<Override PartName="/word/numbering.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"/>`,
+		`<Override PartName="/word/settings.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"/>`,
+		`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>`,
+	}
+	for _, name := range d.headerParts {
+		overrides = append(overrides, `<Override PartName="/word/`+name+`" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>`)
+	}
+	for _, name := range d.footerParts {
+		overrides = append(overrides, `<Override PartName="/word/`+name+`" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"/>`)
+	}
+	if len(d.footnotes) > 0 {
+		d.addRel(docxRelFootnotes, "footnotes.xml")
+		d.parts = append(d.parts, ZipEntry{Name: "word/footnotes.xml", Data: d.notesPart(interfaces.Footnote, d.footnotes)})
+		overrides = append(overrides, `<Override PartName="/word/footnotes.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footnotes+xml"/>`)
+	}
+	if len(d.endnotes) > 0 {
+		d.addRel(docxRelEndnotes, "endnotes.xml")
+		d.parts = append(d.parts, ZipEntry{Name: "word/endnotes.xml", Data: d.notesPart(interfaces.Endnote, d.endnotes)})
+		overrides = append(overrides, `<Override PartName="/word/endnotes.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.endnotes+xml"/>`)
+	}
+
+	var contentTypes strings.Builder
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	contentTypes.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	contentTypes.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	for ext, contentType := range d.mediaTypes {
+		contentTypes.WriteString(`<Default Extension="` + ext + `" ContentType="` + contentType + `"/>`)
+	}
+	contentTypes.WriteString(strings.Join(overrides, ""))
+	contentTypes.WriteString(`</Types>`)
+
+	var rels strings.Builder
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for _, rel := range d.rels {
+		rels.WriteString(`<Relationship Id="` + rel.ID + `" Type="` + rel.Type + `" Target="` + rel.Target + `"/>`)
+	}
+	rels.WriteString(`</Relationships>`)
+
+	entries := []ZipEntry{
+		{Name: "[Content_Types].xml", Data: []byte(contentTypes.String())},
+		{Name: "_rels/.rels", Data: []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + docxRelOfficeDocument + `" Target="word/document.xml"/>` +
+			`<Relationship Id="rId2" Type="` + docxRelCoreProperties + `" Target="docProps/core.xml"/></Relationships>`)},
+		{Name: "docProps/core.xml", Data: []byte(d.coreProperties())},
+		{Name: "word/document.xml", Data: []byte(d.body.String())},
+		{Name: "word/_rels/document.xml.rels", Data: []byte(rels.String())},
+		{Name: "word/styles.xml", Data: []byte(docxStyles)},
+		{Name: "word/numbering.xml", Data: []byte(d.numbering())},
+		{Name: "word/settings.xml", Data: []byte(d.settings())},
+	}
+	return append(entries, d.parts...)
+}
+
+// coreProperties returns the core properties part with the document
+// metadata
+func (d *docxBuilder) coreProperties() string {
+	var core strings.Builder
+	core.WriteString(xml.Header + `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">`)
+	elements := []struct{ key, element string }{
+		{"Title", "dc:title"},
+		{"Subject", "dc:subject"},
+		{"Author", "dc:creator"},
+		{"Keywords", "cp:keywords"},
+		{"Description", "dc:description"},
+		{"LastModifiedBy", "cp:lastModifiedBy"},
+	}
+	for _, e := range elements {
+		if value := strings.TrimSpace(d.content.Metadata[e.key]); value != "" {
+			core.WriteString(`<` + e.element + `>` + xmlText(value) + `</` + e.element + `>`)
+		}
+	}
+	for _, e := range []struct{ key, element string }{{"CreationDate", "dcterms:created"}, {"ModDate", "dcterms:modified"}} {
+		if value := docxDate(d.content.Metadata[e.key]); value != "" {
+			core.WriteString(`<` + e.element + ` xsi:type="dcterms:W3CDTF">` + value + `</` + e.element + `>`)
+		}
+	}
+	core.WriteString(`</cp:coreProperties>`)
+	return core.String()
+}
+
+// docxDate converts a date of the metadata to a UTC W3CDTF date, returning
+// "" for dates it cannot read. Dates without a time zone are taken as UTC.
+func docxDate(value string) string {
+	value = strings.TrimSpace(value)
+	// PDF dates look like D:20240131120000+09'00'
+	pdfDate := strings.ReplaceAll(strings.TrimPrefix(value, "D:"), "'", "")
+	for _, t := range []struct{ layout, value string }{{time.RFC3339, value}, {"20060102150405Z0700", pdfDate}} {
+		if parsed, err := time.Parse(t.layout, t.value); err == nil {
+			return parsed.UTC().Format("2006-01-02T15:04:05Z")
+		}
+	}
+	if date := metadataDate(value); date != "" {
+		return date + "Z"
+	}
+	return ""
+}
+
+// settings returns the settings part
+func (d *docxBuilder) settings() string {
+	var settings strings.Builder
+	settings.WriteString(xml.Header + `<w:settings xmlns:w="` + docxNamespaceW + `">`)
+	if d.evenAndOdd {
+		settings.WriteString(`<w:evenAndOddHeaders/>`)
+	}
+	if len(d.footnotes) > 0 {
+		settings.WriteString(`<w:footnotePr><w:footnote w:id="-1"/><w:footnote w:id="0"/></w:footnotePr>`)
+	}
+	if len(d.endnotes) > 0 {
+		settings.WriteString(`<w:endnotePr><w:endnote w:id="-1"/><w:endnote w:id="0"/></w:endnotePr>`)
+	}
+	settings.WriteString(`</w:settings>`)
+	return settings.String()
+}
+
+// numbering returns the numbering part: a bullet list, a numbered list and
+// one restarting instance of the numbered list per ordered list
+func (d *docxBuilder) numbering() string {
+	var numbering strings.Builder
+	numbering.WriteString(xml.Header + `<w:numbering xmlns:w="` + docxNamespaceW + `">`)
+
+	bullets := []string{"•", "◦", "▪"}
+	for abstract, format := range []string{"bullet", "decimal"} {
+		numbering.WriteString(fmt.Sprintf(`<w:abstractNum w:abstractNumId="%d"><w:multiLevelType w:val="hybridMultilevel"/>`, abstract))
+		for level := 0; level < 9; level++ {
+			text := bullets[level%len(bullets)]
+			if format == "decimal" {
+				text = "%" + strconv.Itoa(level+1) + "."
+			}
+			numbering.WriteString(fmt.Sprintf(`<w:lvl w:ilvl="%d"><w:start w:val="1"/><w:numFmt w:val="%s"/><w:lvlText w:val="%s"/><w:lvlJc w:val="left"/>`+
+				`<w:pPr><w:ind w:left="%d" w:hanging="360"/></w:pPr></w:lvl>`, level, format, text, 720*(level+1)))
+		}
+		numbering.WriteString(`</w:abstractNum>`)
+	}
+
+	numbering.WriteString(`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>`)
+	numbering.WriteString(`<w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num>`)
+	for i := 1; i <= d.orderedLists; i++ {
+		numbering.WriteString(fmt.Sprintf(`<w:num w:numId="%d"><w:abstractNumId w:val="1"/>`+
+			`<w:lvlOverride w:ilvl="0"><w:startOverride w:val="1"/></w:lvlOverride></w:num>`, 2+i))
+	}
+	numbering.WriteString(`</w:numbering>`)
+	return numbering.String()
+}
+
+// xmlText escapes text for XML character data and attribute values
+func xmlText(text string) string {
+	var out strings.Builder
+	xml.EscapeText(&out, []byte(text))
+	return out.String()
+}
@@ -0,0 +1,119 @@
+package writers
+
+import (
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"myconverter/interfaces"
+	"myconverter/readers"
+)
+
+func TestDOCXDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "2024-01-31T12:00:00+09:00", want: "2024-01-31T03:00:00Z"},
+		{value: "D:20240131120000+09'00'", want: "2024-01-31T03:00:00Z"},
+		{value: "D:20240131120000Z", want: "2024-01-31T12:00:00Z"},
+		{value: "2024-01-31", want: "2024-01-31T00:00:00Z"},
+		{value: "last week", want: ""},
+		{value: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := docxDate(tt.value); got != tt.want {
+			t.Errorf("docxDate(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDOCXCoreProperties(t *testing.T) {
+	metadata := map[string]string{
+		"Title":        "보고서 <초안>",
+		"Author":       "Kim & Lee",
+		"Subject":      "Budget",
+		"Keywords":     "a, b",
+		"CreationDate": "D:20240131120000+09'00'",
+		"ModDate":      "2024-02-01T08:30:00Z",
+	}
+	content := &interfaces.PDFContent{
+		Metadata: metadata,
+		Blocks:   []interfaces.Block{{Kind: interfaces.BlockParagraph, Runs: []interfaces.Run{{Text: "body"}}}},
+	}
+	dir := t.TempDir()
+	if err := NewDOCXWriter(dir).WriteContent("out.docx", content); err != nil {
+		t.Fatalf("WriteContent() error = %v", err)
+	}
+	path := filepath.Join(dir, "out.docx")
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		f, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(f)
+		f.Close()
+		parts[file.Name] = string(data)
+	}
+	for _, check := range []struct{ part, want string }{
+		{"[Content_Types].xml", `<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>`},
+		{"_rels/.rels", `Type="` + docxRelCoreProperties + `" Target="docProps/core.xml"`},
+		{"docProps/core.xml", `<dcterms:created xsi:type="dcterms:W3CDTF">2024-01-31T03:00:00Z</dcterms:created>`},
+	} {
+		if !strings.Contains(parts[check.part], check.want) {
+			t.Errorf("%s does not contain %s", check.part, check.want)
+		}
+	}
+
+	// The DOCX reader reads the properties back
+	read, err := readers.NewDOCXReader(path).ReadDOCX()
+	if err != nil {
+		t.Fatalf("ReadDOCX() error = %v", err)
+	}
+	want := map[string]string{
+		"Title":        "보고서 <초안>",
+		"Author":       "Kim & Lee",
+		"Subject":      "Budget",
+		"Keywords":     "a, b",
+		"CreationDate": "2024-01-31T03:00:00Z",
+		"ModDate":      "2024-02-01T08:30:00Z",
+	}
+	for key, value := range want {
+		if got := read.Metadata[key]; got != value {
+			t.Errorf("metadata %s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestDOCXRoundTrip(t *testing.T) {
+	content := testContent(t)
+	dir := t.TempDir()
+	if err := NewDOCXWriter(dir).WriteContent("out.docx", content); err != nil {
+		t.Fatalf("WriteContent() error = %v", err)
+	}
+	read, err := readers.NewDOCXReader(filepath.Join(dir, "out.docx")).ReadDOCX()
+	if err != nil {
+		t.Fatalf("ReadDOCX() error = %v", err)
+	}
+	// Headings are bold through their style
+	want := append([]string{`heading1 "Title"[b]`}, testContentBlocks[1:]...)
+	if got := describeBlocks(read.Blocks); !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks = %q, want %q", got, want)
+	}
+	if got, want := contentImages(read.Blocks), contentImages(content.Blocks); !reflect.DeepEqual(got, want) {
+		t.Errorf("images differ")
+	}
+	if size := read.Blocks[1].Runs[2].Size; size != 14 {
+		t.Errorf("run size = %v, want 14", size)
+	}
+}
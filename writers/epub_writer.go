@@ -0,0 +1,256 @@
+package writers
+
+import (
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"myconverter/interfaces"
+)
+
+// epubFontTypes maps font file extensions to their media types
+var epubFontTypes = map[string]string{
+	".ttf":   "font/ttf",
+	".otf":   "font/otf",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+}
+
+// EPUBWriter handles writing content as an EPUB 3 book
+type EPUBWriter struct {
+	// Output directory for EPUB files
+	OutputDir string
+	// FontPath is a font file to embed and use for the text, empty to rely
+	// on the fonts of the reading system
+	FontPath string
+}
+
+// NewEPUBWriter creates a new EPUBWriter
+func NewEPUBWriter(outputDir string) *EPUBWriter {
+	return &EPUBWriter{OutputDir: outputDir}
+}
+
+// epubChapter is a part of the book written as its own XHTML file
+type epubChapter struct {
+	Title  string
+	Blocks []interfaces.Block
+}
+
+// WriteContent converts the content to an EPUB package with one XHTML file
+// per section or top-level heading, the navigation document and the images.
+func (w *EPUBWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	title := strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+	if content.Metadata != nil && content.Metadata["Title"] != "" {
+		title = content.Metadata["Title"]
+	}
+	language := epubLanguage(content)
+
+	// Chapters share the builder so that the style sheet covers all of them
+	h := &htmlBuilder{
+		content:     content,
+		packaged:    true,
+		assetsDir:   "images",
+		assets:      make(map[string]bool),
+		charClasses: make(map[interfaces.Run]string),
+		paraClasses: make(map[string]string),
+	}
+
+	var entries []ZipEntry
+	var manifest, spine, nav strings.Builder
+	chapters := epubChapters(content.ContentBlocks())
+	for i, chapter := range chapters {
+		if chapter.Title == "" {
+			chapter.Title = title
+			if len(chapters) > 1 {
+				chapter.Title = fmt.Sprintf("%s (%d)", title, i+1)
+			}
+		}
+
+		h.out.Reset()
+		h.noteRefs = nil
+		if err := h.blocks(chapter.Blocks); err != nil {
+			return err
+		}
+		// Each chapter lists the notes it references
+		var notes []interfaces.Note
+		listed := make(map[string]bool)
+		for _, id := range h.noteRefs {
+			if note := content.FindNote(id); note != nil && !listed[id] {
+				notes = append(notes, *note)
+				listed[id] = true
+			}
+		}
+		h.notes(notes)
+
+		name := fmt.Sprintf("chapter%d.xhtml", i+1)
+		entries = append(entries, ZipEntry{Name: "OEBPS/" + name, Data: []byte(epubPage(chapter.Title, language, h.out.String()))})
+		id := "chapter" + strconv.Itoa(i+1)
+		manifest.WriteString(`<item id="` + id + `" href="` + name + `" media-type="application/xhtml+xml"/>`)
+		spine.WriteString(`<itemref idref="` + id + `"/>`)
+		nav.WriteString(`<li><a href="` + name + `">` + html.EscapeString(chapter.Title) + `</a></li>`)
+	}
+
+	for i, image := range h.images {
+		_, mediaType := imageType(&interfaces.Image{Name: image.Name, Data: image.Data})
+		manifest.WriteString(fmt.Sprintf(`<item id="image%d" href="%s" media-type="%s"/>`, i+1, html.EscapeString(image.Name), mediaType))
+		entries = append(entries, ZipEntry{Name: "OEBPS/" + image.Name, Data: image.Data})
+	}
+
+	css := htmlBaseStyle
+	if w.FontPath != "" {
+		font, err := os.ReadFile(w.FontPath)
+		if err != nil {
+			return fmt.Errorf("failed to read font file: %v", err)
+		}
+		ext := strings.ToLower(filepath.Ext(w.FontPath))
+		mediaType, ok := epubFontTypes[ext]
+		if !ok {
+			return fmt.Errorf("unsupported font file type: %s", ext)
+		}
+		name := "fonts/font" + ext
+		family := strings.TrimSuffix(filepath.Base(w.FontPath), filepath.Ext(w.FontPath))
+		css += fmt.Sprintf("@font-face { font-family: %q; src: url(%q); }\nbody { font-family: %q, sans-serif; }\n", family, name, family)
+		manifest.WriteString(`<item id="font" href="` + name + `" media-type="` + mediaType + `"/>`)
+		entries = append(entries, ZipEntry{Name: "OEBPS/" + name, Data: font})
+	}
+	css += h.css.String()
+
+	navPage := epubPage(title, language, `<nav epub:type="toc" id="toc"><h1>`+html.EscapeString(title)+`</h1><ol>`+nav.String()+`</ol></nav>`)
+	entries = append([]ZipEntry{
+		{Name: "mimetype", Data: []byte("application/epub+zip"), Store: true},
+		{Name: "META-INF/container.xml", Data: []byte(xml.Header + `<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">` +
+			`<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`)},
+		{Name: "OEBPS/content.opf", Data: []byte(epubPackage(content, title, language, manifest.String(), spine.String()))},
+		{Name: "OEBPS/nav.xhtml", Data: []byte(navPage)},
+		{Name: "OEBPS/style.css", Data: []byte(css)},
+	}, entries...)
+
+	zipWriter := &ZipWriter{TargetPath: filepath.Join(w.OutputDir, filepath.Base(outputPath))}
+	return zipWriter.WriteEntries(entries)
+}
+
+// Write writes sample text to the specified file.
+func (w *EPUBWriter) Write(outputPath string) error {
+	return w.WriteContent(outputPath, &interfaces.PDFContent{Text: "Sample Text"})
+}
+
+// epubChapters splits blocks into chapters at section breaks and at the
+// headings of the top level used in the document
+func epubChapters(blocks []interfaces.Block) []epubChapter {
+	top := 0
+	for _, block := range blocks {
+		if block.Kind == interfaces.BlockHeading && (top == 0 || block.Level < top) {
+			top = block.Level
+		}
+	}
+
+	chapters := []epubChapter{{}}
+	for _, block := range blocks {
+		chapter := &chapters[len(chapters)-1]
+		startsChapter := block.Kind == interfaces.BlockHeading && block.Level == top
+		if (block.Kind == interfaces.BlockPageBreak && block.Section) || startsChapter {
+			if len(chapter.Blocks) > 0 {
+				chapters = append(chapters, epubChapter{})
+				chapter = &chapters[len(chapters)-1]
+			}
+			if !startsChapter {
+				continue
+			}
+		}
+
+		// A chapter is named after its first heading
+		if block.Kind == interfaces.BlockHeading && chapter.Title == "" {
+			chapter.Title = strings.TrimSpace(interfaces.NoteRefPattern.ReplaceAllString(block.Text(), ""))
+		}
+		// Page breaks at the start of a chapter are implied by the new file
+		if block.Kind == interfaces.BlockPageBreak && len(chapter.Blocks) == 0 {
+			continue
+		}
+		chapter.Blocks = append(chapter.Blocks, block)
+	}
+
+	if len(chapters[len(chapters)-1].Blocks) == 0 && len(chapters) > 1 {
+		chapters = chapters[:len(chapters)-1]
+	}
+	return chapters
+}
+
+// epubPage returns an XHTML content document
+func epubPage(title, language, body string) string {
+	return xml.Header + "<!DOCTYPE html>\n" +
+		`<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="` + language + `" xml:lang="` + language + `">` + "\n" +
+		"<head>\n<meta charset=\"utf-8\"/>\n<title>" + html.EscapeString(title) + "</title>\n" +
+		"<link rel=\"stylesheet\" type=\"text/css\" href=\"style.css\"/>\n</head>\n<body>\n" +
+		body + "</body>\n</html>\n"
+}
+
+// epubPackage returns the OPF package document with the book metadata
+func epubPackage(content *interfaces.PDFContent, title, language, manifest, spine string) string {
+	metadata := content.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	// The identifier is derived from the content so that rebuilding the
+	// same document keeps it
+	sum := sha1.Sum([]byte(title + "\x00" + content.Text))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	identifier := fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+
+	modified := metadataDate(metadata["ModDate"])
+	if modified == "" {
+		modified = time.Now().UTC().Format("2006-01-02T15:04:05")
+	}
+
+	var opf strings.Builder
+	opf.WriteString(xml.Header + `<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="` + language + `">`)
+	opf.WriteString(`<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">`)
+	opf.WriteString(`<dc:identifier id="book-id">` + identifier + `</dc:identifier>`)
+	opf.WriteString(`<dc:title>` + xmlText(title) + `</dc:title>`)
+	opf.WriteString(`<dc:language>` + language + `</dc:language>`)
+	for _, e := range []struct{ key, element string }{
+		{"Author", "dc:creator"},
+		{"Subject", "dc:subject"},
+		{"Description", "dc:description"},
+	} {
+		if value := strings.TrimSpace(metadata[e.key]); value != "" {
+			opf.WriteString(`<` + e.element + `>` + xmlText(value) + `</` + e.element + `>`)
+		}
+	}
+	if date := metadataDate(metadata["CreationDate"]); date != "" {
+		opf.WriteString(`<dc:date>` + date + `Z</dc:date>`)
+	}
+	opf.WriteString(`<meta property="dcterms:modified">` + modified + `Z</meta>`)
+	opf.WriteString(`</metadata><manifest>`)
+	opf.WriteString(`<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>`)
+	opf.WriteString(`<item id="style" href="style.css" media-type="text/css"/>`)
+	opf.WriteString(manifest)
+	opf.WriteString(`</manifest><spine>` + spine + `</spine></package>`)
+	return opf.String()
+}
+
+// epubLanguage returns the language of the book: the Language metadata, or
+// Korean when the text contains Hangul and English otherwise
+func epubLanguage(content *interfaces.PDFContent) string {
+	if content.Metadata != nil && content.Metadata["Language"] != "" {
+		return xmlText(content.Metadata["Language"])
+	}
+	for _, r := range content.Text {
+		if unicode.Is(unicode.Hangul, r) {
+			return "ko"
+		}
+	}
+	return "en"
+}
@@ -0,0 +1,181 @@
+package writers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+
+	"myconverter/interfaces"
+)
+
+func TestEPUBChapters(t *testing.T) {
+	heading := func(level int, text string) interfaces.Block {
+		return interfaces.Block{Kind: interfaces.BlockHeading, Level: level, Runs: []interfaces.Run{{Text: text}}}
+	}
+	paragraph := func(text string) interfaces.Block {
+		return interfaces.Block{Kind: interfaces.BlockParagraph, Runs: []interfaces.Run{{Text: text}}}
+	}
+	section := interfaces.Block{Kind: interfaces.BlockPageBreak, Section: true}
+	pageBreak := interfaces.Block{Kind: interfaces.BlockPageBreak}
+
+	tests := []struct {
+		name   string
+		blocks []interfaces.Block
+		want   []string
+	}{
+		{
+			name:   "no headings",
+			blocks: []interfaces.Block{paragraph("a"), paragraph("b")},
+			want:   []string{`"": a b`},
+		},
+		{
+			name:   "top level headings",
+			blocks: []interfaces.Block{paragraph("intro"), heading(2, "One"), heading(3, "sub"), paragraph("a"), heading(2, "Two[^1]"), paragraph("b")},
+			want:   []string{`"": intro`, `"One": One sub a`, `"Two": Two[^1] b`},
+		},
+		{
+			name:   "sections",
+			blocks: []interfaces.Block{paragraph("a"), section, paragraph("b"), pageBreak, paragraph("c")},
+			want:   []string{`"": a`, `"": b page-break c`},
+		},
+		{
+			name:   "section before a heading",
+			blocks: []interfaces.Block{heading(1, "One"), section, pageBreak, heading(1, "Two")},
+			want:   []string{`"One": One`, `"Two": Two`},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, chapter := range epubChapters(tt.blocks) {
+			var texts []string
+			for _, block := range chapter.Blocks {
+				if block.Kind == interfaces.BlockPageBreak {
+					texts = append(texts, "page-break")
+				} else {
+					texts = append(texts, block.Text())
+				}
+			}
+			got = append(got, `"`+chapter.Title+`": `+strings.Join(texts, " "))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: chapters = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEPUBWriter(t *testing.T) {
+	dir := t.TempDir()
+	fontPath := filepath.Join(dir, "Go Regular.ttf")
+	if err := os.WriteFile(fontPath, goregular.TTF, 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := testContent(t)
+	content.Blocks = append(content.Blocks,
+		interfaces.Block{Kind: interfaces.BlockHeading, Level: 1, Runs: []interfaces.Run{{Text: "Second & last"}}},
+		interfaces.Block{Kind: interfaces.BlockImage, Image: testImages(t, 1)[0]},
+	)
+	w := NewEPUBWriter(dir)
+	w.FontPath = fontPath
+	if err := w.WriteContent("book.epub", content); err != nil {
+		t.Fatalf("WriteContent() error = %v", err)
+	}
+
+	archive, err := zip.OpenReader(filepath.Join(dir, "book.epub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	if first := archive.File[0]; first.Name != "mimetype" || first.Method != zip.Store || len(first.Extra) > 0 {
+		t.Errorf("first entry = %s, method %d, want stored mimetype", first.Name, first.Method)
+	}
+	parts := make(map[string][]byte)
+	for _, file := range archive.File {
+		f, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[file.Name], _ = io.ReadAll(f)
+		f.Close()
+	}
+
+	// Every XML part is well-formed
+	for name, data := range parts {
+		if !strings.HasSuffix(name, ".xhtml") && !strings.HasSuffix(name, ".opf") && !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s is not well-formed: %v", name, err)
+				break
+			}
+		}
+	}
+
+	// The manifest lists the package entries and the spine the chapters
+	var opf struct {
+		Items []struct {
+			ID         string `xml:"id,attr"`
+			Href       string `xml:"href,attr"`
+			MediaType  string `xml:"media-type,attr"`
+			Properties string `xml:"properties,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+		Title   string `xml:"metadata>title"`
+		Creator string `xml:"metadata>creator"`
+	}
+	if err := xml.Unmarshal(parts["OEBPS/content.opf"], &opf); err != nil {
+		t.Fatal(err)
+	}
+	var manifest, entries, spine []string
+	for _, item := range opf.Items {
+		manifest = append(manifest, "OEBPS/"+item.Href+" "+item.MediaType)
+	}
+	for name := range parts {
+		if strings.HasPrefix(name, "OEBPS/") && name != "OEBPS/content.opf" {
+			mediaType := map[string]string{".xhtml": "application/xhtml+xml", ".css": "text/css", ".png": "image/png", ".ttf": "font/ttf"}[filepath.Ext(name)]
+			entries = append(entries, name+" "+mediaType)
+		}
+	}
+	for _, item := range opf.Spine {
+		spine = append(spine, item.IDRef)
+	}
+	sort.Strings(manifest)
+	sort.Strings(entries)
+	if !reflect.DeepEqual(manifest, entries) {
+		t.Errorf("manifest = %q, want %q", manifest, entries)
+	}
+	if want := []string{"chapter1", "chapter2"}; !reflect.DeepEqual(spine, want) {
+		t.Errorf("spine = %q, want %q", spine, want)
+	}
+	if opf.Title != "Report" || opf.Creator != "Kim" {
+		t.Errorf("title and creator = %q, %q", opf.Title, opf.Creator)
+	}
+
+	// Images shared by the chapters are stored once
+	if len(parts["OEBPS/images/page.png"]) == 0 || len(parts) != 9 {
+		t.Errorf("package entries = %d, want one image", len(parts))
+	}
+	nav := string(parts["OEBPS/nav.xhtml"])
+	for _, want := range []string{`<a href="chapter1.xhtml">Title</a>`, `<a href="chapter2.xhtml">Second &amp; last</a>`} {
+		if !strings.Contains(nav, want) {
+			t.Errorf("nav.xhtml does not contain %s", want)
+		}
+	}
+	if css := string(parts["OEBPS/style.css"]); !strings.Contains(css, `@font-face { font-family: "Go Regular"; src: url("fonts/font.ttf"); }`) {
+		t.Errorf("style.css does not declare the font")
+	}
+}
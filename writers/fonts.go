@@ -0,0 +1,57 @@
+package writers
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/signintech/gopdf"
+)
+
+// FontPath is the TrueType font file the PDF and image writers draw text
+// in; the first system font found is used when empty
+var FontPath string
+
+// systemFonts are the regular and bold font files looked for when FontPath
+// is empty, Korean fonts first
+var systemFonts = []struct{ regular, bold string }{
+	{`C:\Windows\Fonts\malgun.ttf`, `C:\Windows\Fonts\malgunbd.ttf`},
+	{"/usr/share/fonts/truetype/nanum/NanumGothic.ttf", "/usr/share/fonts/truetype/nanum/NanumGothicBold.ttf"},
+	{"/Library/Fonts/NanumGothic.ttf", "/Library/Fonts/NanumGothicBold.ttf"},
+	{"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf", "/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf"},
+}
+
+// pdfFont is the family name text is drawn in on gopdf pages
+const pdfFont = "text"
+
+// fontFiles returns the font file text is drawn in and its bold variant,
+// empty when there is none. A FontPath has no bold variant.
+func fontFiles() (string, string, error) {
+	if FontPath != "" {
+		return FontPath, "", nil
+	}
+	for _, fonts := range systemFonts {
+		if _, err := os.Stat(fonts.regular); err != nil {
+			continue
+		}
+		if _, err := os.Stat(fonts.bold); err != nil {
+			return fonts.regular, "", nil
+		}
+		return fonts.regular, fonts.bold, nil
+	}
+	return "", "", fmt.Errorf("no font file found")
+}
+
+// addPDFFont adds the text font to a PDF as pdfFont and selects it at 10pt
+func addPDFFont(pdf *gopdf.GoPdf) error {
+	path, _, err := fontFiles()
+	if err != nil {
+		return fmt.Errorf("failed to load font: %v", err)
+	}
+	if err := pdf.AddTTFFont(pdfFont, path); err != nil {
+		return fmt.Errorf("failed to load font: %v", err)
+	}
+	if err := pdf.SetFont(pdfFont, "", 10); err != nil {
+		return fmt.Errorf("failed to set font: %v", err)
+	}
+	return nil
+}
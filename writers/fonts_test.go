@@ -0,0 +1,47 @@
+package writers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFontFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.ttf", "b.ttf", "b-bold.ttf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing := filepath.Join(dir, "missing.ttf")
+	a, b, bBold := filepath.Join(dir, "a.ttf"), filepath.Join(dir, "b.ttf"), filepath.Join(dir, "b-bold.ttf")
+
+	defer func(path string, fonts []struct{ regular, bold string }) {
+		FontPath, systemFonts = path, fonts
+	}(FontPath, systemFonts)
+
+	tests := []struct {
+		name        string
+		fontPath    string
+		systemFonts []struct{ regular, bold string }
+		regular     string
+		bold        string
+		wantErr     bool
+	}{
+		{name: "first found", systemFonts: []struct{ regular, bold string }{{missing, missing}, {b, bBold}, {a, ""}}, regular: b, bold: bBold},
+		{name: "no bold", systemFonts: []struct{ regular, bold string }{{a, missing}}, regular: a},
+		{name: "font path", fontPath: a, systemFonts: []struct{ regular, bold string }{{b, bBold}}, regular: a},
+		{name: "none", systemFonts: []struct{ regular, bold string }{{missing, missing}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		FontPath, systemFonts = tt.fontPath, tt.systemFonts
+		regular, bold, err := fontFiles()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: fontFiles() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if regular != tt.regular || bold != tt.bold {
+			t.Errorf("%s: fontFiles() = %q, %q, want %q, %q", tt.name, regular, bold, tt.regular, tt.bold)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+package writers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"myconverter/interfaces"
+)
+
+// htmlBaseStyle is the style sheet shared by all generated pages
+const htmlBaseStyle = `body { max-width: 50em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.6; }
+p, h1, h2, h3, h4, h5, h6, li, td { white-space: pre-wrap; }
+table { border-collapse: collapse; margin: 1em 0; }
+td { border: 1px solid #999; padding: 0.2em 0.5em; vertical-align: top; }
+td p { margin: 0; }
+figure { margin: 1em 0; }
+figure img { max-width: 100%; }
+.page-break { break-after: page; page-break-after: always; border-top: 1px dashed #ccc; margin: 2em 0; }
+.notes { border-top: 1px solid #999; margin-top: 2em; font-size: 0.9em; }
+@media print { .page-break { border: none; margin: 0; } }
+`
+
+// HTMLWriter handles writing content as a standalone HTML5 page
+type HTMLWriter struct {
+	// Output directory for HTML files
+	OutputDir string
+	// InlineImages embeds images as data URIs instead of writing them to a
+	// <name>_assets directory next to the page
+	InlineImages bool
+}
+
+// NewHTMLWriter creates a new HTMLWriter
+func NewHTMLWriter(outputDir string) *HTMLWriter {
+	return &HTMLWriter{OutputDir: outputDir}
+}
+
+// WriteContent converts the content blocks to HTML and saves the page.
+// Character and paragraph shapes become CSS classes, page and section
+// breaks become CSS page-break hints.
+func (w *HTMLWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	outputPath = filepath.Join(w.OutputDir, filepath.Base(outputPath))
+	base := strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+
+	h := &htmlBuilder{
+		content:      content,
+		inlineImages: w.InlineImages,
+		assetsDir:    base + "_assets",
+		assetsPath:   filepath.Join(w.OutputDir, base+"_assets"),
+		assets:       make(map[string]bool),
+		charClasses:  make(map[interfaces.Run]string),
+		paraClasses:  make(map[string]string),
+	}
+	if err := h.blocks(content.ContentBlocks()); err != nil {
+		return err
+	}
+	h.notes(content.Notes)
+
+	title := base
+	if content.Metadata != nil && content.Metadata["Title"] != "" {
+		title = content.Metadata["Title"]
+	}
+
+	var page strings.Builder
+	page.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	page.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	page.WriteString("<style>\n" + htmlBaseStyle + h.css.String() + "</style>\n")
+	page.WriteString("</head>\n<body>\n")
+	page.WriteString(h.out.String())
+	page.WriteString("</body>\n</html>\n")
+
+	return os.WriteFile(outputPath, []byte(page.String()), 0644)
+}
+
+// Write writes sample text to the specified file.
+func (w *HTMLWriter) Write(outputPath string) error {
+	return w.WriteContent(outputPath, &interfaces.PDFContent{Text: "Sample Text"})
+}
+
+// htmlBuilder accumulates the page body, the generated CSS classes and exported images
+type htmlBuilder struct {
+	content      *interfaces.PDFContent
+	out          strings.Builder
+	css          strings.Builder
+	inlineImages bool
+	assetsDir    string
+	assetsPath   string
+	assets       map[string]bool
+	// packaged collects images as package entries under assetsDir instead
+	// of writing them to assetsPath
+	packaged bool
+	images   []ZipEntry
+	// noteRefs lists the IDs of the notes referenced so far
+	noteRefs []string
+	// charClasses maps run formatting (without text) to its CSS class
+	charClasses map[interfaces.Run]string
+	// paraClasses maps paragraph shape keys to their CSS class
+	paraClasses map[string]string
+}
+
+// htmlList is a list opened while writing consecutive list items
+type htmlList struct {
+	tag string
+	// item marks a list item left open for nested lists
+	item bool
+}
+
+// blocks writes blocks, grouping consecutive list items into nested lists
+func (h *htmlBuilder) blocks(blocks []interfaces.Block) error {
+	var lists []htmlList
+	closeList := func() {
+		list := lists[len(lists)-1]
+		if list.item {
+			h.out.WriteString("</li>")
+		}
+		h.out.WriteString("</" + list.tag + ">\n")
+		lists = lists[:len(lists)-1]
+	}
+
+	for _, block := range blocks {
+		if block.Kind != interfaces.BlockListItem {
+			for len(lists) > 0 {
+				closeList()
+			}
+		}
+
+		switch block.Kind {
+		case interfaces.BlockHeading:
+			tag := "h" + strconv.Itoa(min(max(block.Level, 1), 6))
+			h.out.WriteString("<" + tag + h.paraClass(block) + ">" + h.runs(block.Runs) + "</" + tag + ">\n")
+
+		case interfaces.BlockListItem:
+			level := max(block.Level, 1)
+			tag := "ul"
+			if block.Ordered {
+				tag = "ol"
+			}
+			for len(lists) > level {
+				closeList()
+			}
+			if len(lists) == level && lists[level-1].tag != tag {
+				closeList()
+			}
+			if len(lists) == level && lists[level-1].item {
+				h.out.WriteString("</li>\n")
+				lists[level-1].item = false
+			}
+			for len(lists) < level {
+				h.out.WriteString("<" + tag + ">\n")
+				lists = append(lists, htmlList{tag: tag})
+			}
+			h.out.WriteString("<li" + h.paraClass(block) + ">" + h.runs(block.Runs))
+			lists[level-1].item = true
+
+		case interfaces.BlockTable:
+			if block.Table == nil {
+				continue
+			}
+			if err := h.table(block.Table); err != nil {
+				return err
+			}
+
+		case interfaces.BlockImage:
+			img, err := h.image(block.Image)
+			if err != nil {
+				return err
+			}
+			if img != "" {
+				h.out.WriteString("<figure>" + img + "</figure>\n")
+			}
+
+		case interfaces.BlockPageBreak:
+			h.out.WriteString("<div class=\"page-break\"></div>\n")
+
+		default:
+			h.out.WriteString("<p" + h.paraClass(block) + ">" + h.runs(block.Runs) + "</p>\n")
+		}
+	}
+
+	for len(lists) > 0 {
+		closeList()
+	}
+	return nil
+}
+
+// table writes a table, keeping merged cells as colspan/rowspan attributes
+func (h *htmlBuilder) table(table *interfaces.Table) error {
+	h.out.WriteString("<table>\n")
+	for _, row := range table.Rows {
+		h.out.WriteString("<tr>")
+		for _, cell := range row {
+			h.out.WriteString("<td")
+			if cell.ColSpan > 1 {
+				h.out.WriteString(fmt.Sprintf(` colspan="%d"`, cell.ColSpan))
+			}
+			if cell.RowSpan > 1 {
+				h.out.WriteString(fmt.Sprintf(` rowspan="%d"`, cell.RowSpan))
+			}
+			h.out.WriteString(">")
+			if err := h.blocks(cell.Blocks); err != nil {
+				return err
+			}
+			h.out.WriteString("</td>")
+		}
+		h.out.WriteString("</tr>\n")
+	}
+	h.out.WriteString("</table>\n")
+	return nil
+}
+
+// image returns the img element of an image, exporting it to the assets
+// directory unless images are inlined
+func (h *htmlBuilder) image(image *interfaces.Image) (string, error) {
+	if image == nil || len(image.Data) == 0 {
+		return "", nil
+	}
+
+	name := image.Name
+	if name == "" {
+		name = fmt.Sprintf("image%d.png", len(h.assets)+1)
+	}
+
+	src := h.assetsDir + "/" + name
+	if h.inlineImages {
+		src = "data:" + http.DetectContentType(image.Data) + ";base64," + base64.StdEncoding.EncodeToString(image.Data)
+	} else if h.packaged {
+		if !h.assets[name] {
+			h.images = append(h.images, ZipEntry{Name: src, Data: image.Data})
+		}
+	} else if !h.assets[name] {
+		if err := os.MkdirAll(h.assetsPath, 0755); err != nil {
+			return "", fmt.Errorf("failed to create assets directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(h.assetsPath, name), image.Data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write image %s: %v", name, err)
+		}
+	}
+	h.assets[name] = true
+
+	return fmt.Sprintf(`<img src="%s" alt="%s"/>`, html.EscapeString(src), html.EscapeString(name)), nil
+}
+
+// runs returns the HTML of runs, wrapping formatted runs in spans with
+// their character shape class
+func (h *htmlBuilder) runs(runs []interfaces.Run) string {
+	var out strings.Builder
+	for _, run := range runs {
+		text := h.text(run.Text)
+		if class := h.charClass(run); class != "" {
+			out.WriteString(`<span class="` + class + `">` + text + "</span>")
+		} else {
+			out.WriteString(text)
+		}
+	}
+	return out.String()
+}
+
+// text escapes text, turning note references into links to the notes
+func (h *htmlBuilder) text(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, match := range interfaces.NoteRefPattern.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(html.EscapeString(text[last:match[0]]))
+		id := text[match[2]:match[3]]
+		h.noteRefs = append(h.noteRefs, id)
+		label := id
+		if note := h.content.FindNote(id); note != nil && note.Label != "" {
+			label = note.Label
+		}
+		out.WriteString(fmt.Sprintf(`<sup id="ref-%s"><a href="#note-%s">%s</a></sup>`,
+			html.EscapeString(id), html.EscapeString(id), html.EscapeString(label)))
+		last = match[1]
+	}
+	out.WriteString(html.EscapeString(text[last:]))
+	return out.String()
+}
+
+// notes writes footnotes and endnotes after the body
+func (h *htmlBuilder) notes(notes []interfaces.Note) {
+	if len(notes) == 0 {
+		return
+	}
+
+	h.out.WriteString("<div class=\"notes\">\n")
+	for _, note := range notes {
+		label := note.Label
+		if label == "" {
+			label = note.ID
+		}
+		id := html.EscapeString(note.ID)
+		h.out.WriteString(fmt.Sprintf(`<p id="note-%s"><a href="#ref-%s">%s</a> %s</p>`,
+			id, id, html.EscapeString(label), html.EscapeString(note.Text)))
+		h.out.WriteString("\n")
+	}
+	h.out.WriteString("</div>\n")
+}
+
+// charClass returns the CSS class of a run's character shape, generating
+// its rule the first time the formatting is seen
+func (h *htmlBuilder) charClass(run interfaces.Run) string {
+	run.Text = ""
+	if class, ok := h.charClasses[run]; ok {
+		return class
+	}
+
+	var rules []string
+	if run.Bold {
+		rules = append(rules, "font-weight: bold")
+	}
+	if run.Italic {
+		rules = append(rules, "font-style: italic")
+	}
+	switch {
+	case run.Underline && run.Strike:
+		rules = append(rules, "text-decoration: underline line-through")
+	case run.Underline:
+		rules = append(rules, "text-decoration: underline")
+	case run.Strike:
+		rules = append(rules, "text-decoration: line-through")
+	}
+	if run.Size > 0 {
+		rules = append(rules, "font-size: "+strconv.FormatFloat(run.Size, 'f', -1, 64)+"pt")
+	}
+	if run.Color != "" {
+		rules = append(rules, "color: "+run.Color)
+	}
+	if run.Highlight != "" {
+		rules = append(rules, "background-color: "+run.Highlight)
+	}
+	if run.Font != "" {
+		rules = append(rules, fmt.Sprintf("font-family: %q, sans-serif", run.Font))
+	}
+
+	class := ""
+	if len(rules) > 0 {
+		// Classes are named after the shape ID when the reader provided one
+		id := run.CharShape
+		if id == "" {
+			id = "x" + strconv.Itoa(len(h.charClasses))
+		}
+		class = "char-" + cssIdent(id)
+		h.css.WriteString("." + class + " { " + strings.Join(rules, "; ") + "; }\n")
+	}
+	h.charClasses[run] = class
+	return class
+}
+
+// paraClass returns the class attribute of a block's paragraph shape,
+// generating its rule the first time the shape is seen
+func (h *htmlBuilder) paraClass(block interfaces.Block) string {
+	align := htmlAlign(block.Align)
+	if align == "" {
+		return ""
+	}
+
+	key := block.ParaShape + "/" + align
+	class, ok := h.paraClasses[key]
+	if !ok {
+		id := block.ParaShape
+		if id == "" {
+			id = "x" + strconv.Itoa(len(h.paraClasses))
+		}
+		class = "para-" + cssIdent(id)
+		h.paraClasses[key] = class
+		h.css.WriteString("." + class + " { text-align: " + align + "; }\n")
+	}
+	return ` class="` + class + `"`
+}
+
+// htmlAlign returns the CSS text alignment of a paragraph alignment
+func htmlAlign(align string) string {
+	switch strings.ToUpper(align) {
+	case "LEFT":
+		return "left"
+	case "RIGHT":
+		return "right"
+	case "CENTER":
+		return "center"
+	case "JUSTIFY", "DISTRIBUTE", "DISTRIBUTE_SPACE":
+		return "justify"
+	default:
+		return ""
+	}
+}
+
+// cssIdent replaces characters that are not valid in a CSS class name
+func cssIdent(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, id)
+}
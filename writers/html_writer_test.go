@@ -0,0 +1,157 @@
+package writers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"myconverter/interfaces"
+	"myconverter/readers"
+)
+
+// testContent returns content with a block of each kind, formatted runs
+// and a table with a merged cell
+func testContent(t *testing.T) *interfaces.PDFContent {
+	t.Helper()
+	cell := func(row, col, colSpan int, text string) interfaces.TableCell {
+		return interfaces.TableCell{Row: row, Col: col, RowSpan: 1, ColSpan: colSpan, Blocks: interfaces.TextBlocks(text)}
+	}
+	return &interfaces.PDFContent{
+		Metadata: map[string]string{"Title": "Report", "Author": "Kim"},
+		Blocks: []interfaces.Block{
+			{Kind: interfaces.BlockHeading, Level: 1, Runs: []interfaces.Run{{Text: "Title"}}},
+			{Kind: interfaces.BlockParagraph, Align: "CENTER", Runs: []interfaces.Run{
+				{Text: "plain "}, {Text: "bold", Bold: true}, {Text: " red", Italic: true, Size: 14, Color: "#FF0000"},
+			}},
+			{Kind: interfaces.BlockListItem, Level: 1, Runs: []interfaces.Run{{Text: "one"}}},
+			{Kind: interfaces.BlockListItem, Level: 2, Ordered: true, Runs: []interfaces.Run{{Text: "two"}}},
+			{Kind: interfaces.BlockTable, Table: &interfaces.Table{Rows: [][]interfaces.TableCell{
+				{cell(0, 0, 2, "wide")},
+				{cell(1, 0, 1, "a"), cell(1, 1, 1, "b")},
+			}}},
+			{Kind: interfaces.BlockImage, Image: testImages(t, 1)[0]},
+			{Kind: interfaces.BlockPageBreak},
+			{Kind: interfaces.BlockParagraph, Runs: []interfaces.Run{{Text: "after"}}},
+		},
+	}
+}
+
+// testContentBlocks describes the blocks of testContent as read back by
+// describeBlocks
+var testContentBlocks = []string{
+	`heading1 "Title"`,
+	`paragraph CENTER "plain " "bold"[b] " red"[i #FF0000]`,
+	`list-item1 "one"`,
+	`list-item2 ordered "two"`,
+	`table [0,0 1x2 wide] / [1,0 1x1 a] [1,1 1x1 b]`,
+	`image`,
+	`page-break`,
+	`paragraph "after"`,
+}
+
+// describeBlocks returns a line for each block with its kind, level,
+// alignment and runs with their formatting. Fonts and sizes, which readers
+// fill in from styles, are left out.
+func describeBlocks(blocks []interfaces.Block) []string {
+	var lines []string
+	for _, block := range blocks {
+		line := string(block.Kind)
+		if block.Level > 0 {
+			line += fmt.Sprint(block.Level)
+		}
+		if block.Ordered {
+			line += " ordered"
+		}
+		if block.Align != "" {
+			line += " " + block.Align
+		}
+		for _, run := range block.Runs {
+			line += fmt.Sprintf(" %q", run.Text)
+			var format []string
+			for _, flag := range []struct {
+				set  bool
+				name string
+			}{{run.Bold, "b"}, {run.Italic, "i"}, {run.Underline, "u"}, {run.Strike, "s"}, {run.Color != "", run.Color}} {
+				if flag.set {
+					format = append(format, flag.name)
+				}
+			}
+			if len(format) > 0 {
+				line += "[" + strings.Join(format, " ") + "]"
+			}
+		}
+		if block.Table != nil {
+			var rows []string
+			for _, row := range block.Table.Rows {
+				var cells []string
+				for _, cell := range row {
+					cells = append(cells, fmt.Sprintf("[%d,%d %dx%d %s]", cell.Row, cell.Col, cell.RowSpan, cell.ColSpan,
+						strings.Join(interfaces.BlockLines(cell.Blocks), " ")))
+				}
+				rows = append(rows, strings.Join(cells, " "))
+			}
+			line += " " + strings.Join(rows, " / ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// contentImages returns the data of the image blocks
+func contentImages(blocks []interfaces.Block) [][]byte {
+	var images [][]byte
+	for _, block := range blocks {
+		if block.Image != nil {
+			images = append(images, block.Image.Data)
+		}
+	}
+	return images
+}
+
+func TestHTMLWriterRoundTrip(t *testing.T) {
+	content := testContent(t)
+	for _, inline := range []bool{false, true} {
+		dir := t.TempDir()
+		w := NewHTMLWriter(dir)
+		w.InlineImages = inline
+		if err := w.WriteContent("out.html", content); err != nil {
+			t.Fatalf("WriteContent() error = %v", err)
+		}
+
+		page, err := os.ReadFile(filepath.Join(dir, "out.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"<title>Report</title>",
+			`.para-x0 { text-align: center; }`,
+			`.char-x1 { font-weight: bold; }`,
+			`.char-x2 { font-style: italic; font-size: 14pt; color: #FF0000; }`,
+			`<td colspan="2">`,
+			`<div class="page-break"></div>`,
+		} {
+			if !bytes.Contains(page, []byte(want)) {
+				t.Errorf("inline %v: page does not contain %s", inline, want)
+			}
+		}
+		_, err = os.Stat(filepath.Join(dir, "out_assets", "page.png"))
+		if inline != os.IsNotExist(err) {
+			t.Errorf("inline %v: asset file error = %v", inline, err)
+		}
+
+		read, err := readers.NewHTMLReader(filepath.Join(dir, "out.html")).ReadHTML()
+		if err != nil {
+			t.Fatalf("ReadHTML() error = %v", err)
+		}
+		if got := describeBlocks(read.Blocks); !reflect.DeepEqual(got, testContentBlocks) {
+			t.Errorf("inline %v: blocks = %q, want %q", inline, got, testContentBlocks)
+		}
+		if got, want := contentImages(read.Blocks), contentImages(content.Blocks); !reflect.DeepEqual(got, want) {
+			t.Errorf("inline %v: images differ", inline)
+		}
+	}
+}
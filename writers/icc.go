@@ -0,0 +1,86 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// srgbProfile returns an ICC version 2 display profile of the sRGB color
+// space, with its colorants adapted to the D50 white of the profile
+// connection space
+func srgbProfile() []byte {
+	be := binary.BigEndian
+	xyz := func(x, y, z float64) []byte {
+		data := []byte("XYZ \x00\x00\x00\x00")
+		for _, v := range []float64{x, y, z} {
+			data = be.AppendUint32(data, uint32(int32(math.Round(v*65536))))
+		}
+		return data
+	}
+
+	// The tone curve is sampled from the sRGB transfer function
+	curve := be.AppendUint32([]byte("curv\x00\x00\x00\x00"), 1024)
+	for i := 0; i < 1024; i++ {
+		v := float64(i) / 1023
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		curve = be.AppendUint16(curve, uint16(math.Round(v*65535)))
+	}
+
+	description := []byte("desc\x00\x00\x00\x00")
+	description = be.AppendUint32(description, uint32(len(srgbCondition)+1))
+	description = append(description, srgbCondition+"\x00"...)
+	// No Unicode or ScriptCode descriptions follow
+	description = append(description, make([]byte, 4+4+2+1+67)...)
+
+	tags := []struct {
+		signature string
+		data      []byte
+	}{
+		{"desc", description},
+		{"cprt", []byte("text\x00\x00\x00\x00No copyright, use freely\x00")},
+		{"wtpt", xyz(0.9642, 1, 0.8249)},
+		{"rXYZ", xyz(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", xyz(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", xyz(0.1431, 0.0606, 0.7141)},
+		{"rTRC", curve},
+		{"gTRC", curve},
+		{"bTRC", curve},
+	}
+
+	// Tag data is laid out after the header and tag table, each aligned to
+	// four bytes; the curves share one copy
+	var table, data bytes.Buffer
+	offset := 128 + 4 + 12*len(tags)
+	offsets := make(map[string]int)
+	binary.Write(&table, be, uint32(len(tags)))
+	for _, tag := range tags {
+		if _, ok := offsets[string(tag.data)]; !ok {
+			offsets[string(tag.data)] = offset + data.Len()
+			data.Write(tag.data)
+			for data.Len()%4 != 0 {
+				data.WriteByte(0)
+			}
+		}
+		table.WriteString(tag.signature)
+		binary.Write(&table, be, uint32(offsets[string(tag.data)]))
+		binary.Write(&table, be, uint32(len(tag.data)))
+	}
+
+	header := make([]byte, 128)
+	be.PutUint32(header[0:], uint32(128+table.Len()+data.Len()))
+	be.PutUint32(header[8:], 0x02100000)
+	copy(header[12:], "mntrRGB XYZ ")
+	// Creation date 2000-01-01
+	for i, v := range []uint16{2000, 1, 1, 0, 0, 0} {
+		be.PutUint16(header[24+2*i:], v)
+	}
+	copy(header[36:], "acsp")
+	copy(header[68:], xyz(0.9642, 1, 0.8249)[8:])
+
+	return append(append(header, table.Bytes()...), data.Bytes()...)
+}
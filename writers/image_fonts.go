@@ -0,0 +1,149 @@
+package writers
+
+import (
+	"image"
+	"math"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// obliqueSlant is the horizontal shift per pixel of height of synthetic
+// italics, about 11 degrees
+const obliqueSlant = 0.2
+
+// faceKey identifies a font face of the image renderer
+type faceKey struct {
+	size   float64
+	bold   bool
+	italic bool
+}
+
+// faceCache creates and keeps the font faces of each size and style
+type faceCache struct {
+	regular *truetype.Font
+	// bold is the bold variant of the font, nil to embolden the regular one
+	bold *truetype.Font
+	dpi  float64
+	// hinting fits glyphs to the pixel grid
+	hinting bool
+	// subPixel renders glyphs for positions at fractions of a pixel
+	subPixel bool
+	faces    map[faceKey]font.Face
+}
+
+// face returns the face of a size in points and style
+func (c *faceCache) face(size float64, bold, italic bool) font.Face {
+	key := faceKey{size, bold, italic}
+	if face, ok := c.faces[key]; ok {
+		return face
+	}
+
+	f := c.regular
+	if bold && c.bold != nil {
+		f = c.bold
+	}
+	options := &truetype.Options{Size: size, DPI: c.dpi, SubPixelsX: 1}
+	if c.hinting {
+		options.Hinting = font.HintingFull
+	}
+	if c.subPixel {
+		options.SubPixelsX = 16
+	}
+	face := truetype.NewFace(f, options)
+	if bold && c.bold == nil {
+		face = &emboldenedFace{Face: face, offset: max(1, int(math.Round(size*c.dpi/72/32)))}
+	}
+	if italic {
+		face = &obliqueFace{Face: face}
+	}
+	c.faces[key] = face
+	return face
+}
+
+// emboldenedFace draws glyphs of a face thicker by widening their masks
+type emboldenedFace struct {
+	font.Face
+	// offset is the added stroke width in pixels
+	offset int
+}
+
+// Glyph returns the glyph mask of r widened by the face offset
+func (f *emboldenedFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	dr, mask, maskp, advance, ok := f.Face.Glyph(dot, r)
+	if !ok || dr.Empty() {
+		return dr, mask, maskp, advance + fixed.I(f.offset), ok
+	}
+
+	wide := image.NewAlpha(image.Rect(dr.Min.X, dr.Min.Y, dr.Max.X+f.offset, dr.Max.Y))
+	for y := 0; y < dr.Dy(); y++ {
+		for x := 0; x < dr.Dx(); x++ {
+			_, _, _, a := mask.At(maskp.X+x, maskp.Y+y).RGBA()
+			if a == 0 {
+				continue
+			}
+			for dx := 0; dx <= f.offset; dx++ {
+				i := wide.PixOffset(dr.Min.X+x+dx, dr.Min.Y+y)
+				wide.Pix[i] = max(wide.Pix[i], uint8(a>>8))
+			}
+		}
+	}
+	return wide.Bounds(), wide, wide.Bounds().Min, advance + fixed.I(f.offset), true
+}
+
+// GlyphAdvance returns the advance of r including the added stroke width
+func (f *emboldenedFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	advance, ok := f.Face.GlyphAdvance(r)
+	return advance + fixed.I(f.offset), ok
+}
+
+// GlyphBounds returns the bounds of r including the added stroke width
+func (f *emboldenedFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	bounds, advance, ok := f.Face.GlyphBounds(r)
+	bounds.Max.X += fixed.I(f.offset)
+	return bounds, advance + fixed.I(f.offset), ok
+}
+
+// obliqueFace slants the glyphs of an upright face to stand in for italics
+type obliqueFace struct {
+	font.Face
+}
+
+// Glyph returns the glyph mask of r sheared around the baseline
+func (f *obliqueFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	dr, mask, maskp, advance, ok := f.Face.Glyph(dot, r)
+	if !ok || dr.Empty() {
+		return dr, mask, maskp, advance, ok
+	}
+
+	baseline := float64(dot.Y) / 64
+	shift := func(y int) float64 { return (baseline - float64(y) - 0.5) * obliqueSlant }
+	bounds := image.Rect(
+		dr.Min.X+int(math.Floor(shift(dr.Max.Y-1))), dr.Min.Y,
+		dr.Max.X+int(math.Ceil(shift(dr.Min.Y)))+1, dr.Max.Y)
+	slanted := image.NewAlpha(bounds)
+	for y := dr.Min.Y; y < dr.Max.Y; y++ {
+		// Each row moves right by its height above the baseline, spread
+		// over two pixels for the fraction
+		s := shift(y)
+		whole := int(math.Floor(s))
+		frac := s - float64(whole)
+		for x := dr.Min.X; x < dr.Max.X; x++ {
+			_, _, _, a := mask.At(maskp.X+x-dr.Min.X, maskp.Y+y-dr.Min.Y).RGBA()
+			if a == 0 {
+				continue
+			}
+			value := float64(a >> 8)
+			for i, part := range []float64{1 - frac, frac} {
+				px := x + whole + i
+				if px < bounds.Min.X || px >= bounds.Max.X {
+					continue
+				}
+				o := slanted.PixOffset(px, y)
+				slanted.Pix[o] = uint8(math.Min(255, float64(slanted.Pix[o])+value*part))
+			}
+		}
+	}
+	return bounds, slanted, bounds.Min, advance, true
+}
@@ -0,0 +1,549 @@
+package writers
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"myconverter/interfaces"
+)
+
+// Heading sizes relative to the body text size, by level
+var headingScales = []float64{2, 1.6, 1.3, 1.15}
+
+// imageSegment is a word or space of a run placed on a line
+type imageSegment struct {
+	text  string
+	run   interfaces.Run
+	size  float64
+	face  font.Face
+	x     fixed.Int26_6
+	width fixed.Int26_6
+	space bool
+	// notes are the footnotes referenced by the segment
+	notes []interfaces.Note
+}
+
+// imageLine is a line of segments positioned from the left margin
+type imageLine struct {
+	segments []imageSegment
+	// size is the largest font size of the line in points
+	size float64
+	// notes are the footnote lines placed on the page of the line
+	notes []imageLine
+	// pageBreak starts a new page before the line
+	pageBreak bool
+}
+
+// imageTextLayout breaks content blocks into lines of styled segments
+type imageTextLayout struct {
+	content *interfaces.PDFContent
+	faces   *faceCache
+	// base is the size of text without a size, in points
+	base float64
+	// px converts points to pixels
+	px float64
+	// subPixel keeps segment positions at fractions of a pixel
+	subPixel bool
+	// counters number the ordered list items of each level, and ordered
+	// tells whether the last item of each level was numbered
+	counters []int
+	ordered  []bool
+}
+
+// blocks lays out blocks within width pixels
+func (l *imageTextLayout) blocks(blocks []interfaces.Block, width int) []imageLine {
+	var lines []imageLine
+	pageBreak := false
+	for _, block := range blocks {
+		if block.Kind != interfaces.BlockListItem {
+			l.counters, l.ordered = nil, nil
+		}
+
+		var blockLines []imageLine
+		switch block.Kind {
+		case interfaces.BlockPageBreak:
+			pageBreak = len(lines) > 0
+			continue
+
+		case interfaces.BlockImage:
+			continue
+
+		case interfaces.BlockHeading:
+			level := min(max(block.Level, 1), len(headingScales))
+			runs := make([]interfaces.Run, len(block.Runs))
+			for i, run := range block.Runs {
+				run.Bold = true
+				if run.Size == 0 {
+					run.Size = l.base * headingScales[level-1]
+				}
+				runs[i] = run
+			}
+			blockLines = l.paragraph(runs, block.Align, width, 0, 0)
+
+		case interfaces.BlockListItem:
+			level := max(block.Level, 1)
+			if len(l.counters) > level {
+				l.counters, l.ordered = l.counters[:level], l.ordered[:level]
+			}
+			for len(l.counters) < level {
+				l.counters = append(l.counters, 0)
+				l.ordered = append(l.ordered, block.Ordered)
+			}
+			// A list of the other kind at the same level starts a new count
+			if l.ordered[level-1] != block.Ordered {
+				l.counters[level-1] = 0
+				l.ordered[level-1] = block.Ordered
+			}
+
+			marker := "• "
+			if block.Ordered {
+				l.counters[level-1]++
+				marker = strconv.Itoa(l.counters[level-1]) + ". "
+			}
+			format := interfaces.Run{}
+			if len(block.Runs) > 0 {
+				format = block.Runs[0]
+				format.Underline, format.Strike, format.Highlight = false, false, ""
+			}
+			format.Text = marker
+			indent := int(math.Round(float64(level-1) * 2 * l.base * l.px))
+			hang := indent + l.measure(format)
+			runs := append([]interfaces.Run{format}, block.Runs...)
+			blockLines = l.paragraph(runs, block.Align, width, indent, hang)
+
+		case interfaces.BlockTable:
+			if block.Table != nil {
+				blockLines = l.table(block.Table, width)
+			}
+
+		default:
+			blockLines = l.paragraph(block.Runs, block.Align, width, 0, 0)
+		}
+
+		if len(blockLines) > 0 && pageBreak {
+			blockLines[0].pageBreak = true
+			pageBreak = false
+		}
+		lines = append(lines, blockLines...)
+	}
+	return lines
+}
+
+// measure returns the width of a run's text in pixels
+func (l *imageTextLayout) measure(run interfaces.Run) int {
+	face := l.faces.face(l.size(run), run.Bold, run.Italic)
+	return font.MeasureString(face, run.Text).Ceil()
+}
+
+// size returns the font size of a run in points
+func (l *imageTextLayout) size(run interfaces.Run) float64 {
+	if run.Size > 0 {
+		return run.Size
+	}
+	return l.base
+}
+
+// paragraph wraps runs into lines of width pixels. The first line starts
+// indent pixels from the left and the following lines hang pixels.
+func (l *imageTextLayout) paragraph(runs []interfaces.Run, align string, width, indent, hang int) []imageLine {
+	segments := l.segments(runs)
+	if len(segments) == 0 {
+		// Empty paragraphs keep their line
+		size := l.base
+		if len(runs) > 0 {
+			size = l.size(runs[0])
+		}
+		return []imageLine{{size: size}}
+	}
+
+	var lines []imageLine
+	line := imageLine{}
+	x := fixed.I(indent)
+	limit := fixed.I(width)
+	flush := func(last bool) {
+		// Spaces do not hang past the end of a line
+		for len(line.segments) > 0 && line.segments[len(line.segments)-1].space {
+			line.segments = line.segments[:len(line.segments)-1]
+		}
+		alignLine(&line, limit, align, last)
+		if line.size == 0 {
+			line.size = l.base
+		}
+		lines = append(lines, line)
+		line = imageLine{}
+		x = fixed.I(hang)
+	}
+
+	for i := 0; i < len(segments); i++ {
+		segment := segments[i]
+		if segment.text == "\n" {
+			flush(true)
+			continue
+		}
+		if segment.space && len(line.segments) == 0 && len(lines) > 0 {
+			// Wrapped lines do not start with a space
+			continue
+		}
+		if x+segment.width > limit && !segment.space {
+			if hasWord(line.segments) {
+				flush(false)
+				i--
+				continue
+			}
+			// A word longer than the line is broken between characters
+			if head, tail := l.splitSegment(segment, limit-x); tail.text != "" {
+				segment = head
+				segments = append(segments[:i+1], append([]imageSegment{tail}, segments[i+1:]...)...)
+			}
+		}
+		segment.x = x
+		x += segment.width
+		line.segments = append(line.segments, segment)
+		line.size = math.Max(line.size, segment.size)
+		for _, note := range segment.notes {
+			line.notes = append(line.notes, l.noteLines(note, width)...)
+		}
+	}
+	flush(true)
+	return lines
+}
+
+// hasWord reports whether segments hold more than spaces
+func hasWord(segments []imageSegment) bool {
+	for _, segment := range segments {
+		if !segment.space {
+			return true
+		}
+	}
+	return false
+}
+
+// alignLine moves the segments of a line for its alignment. Justified
+// lines stretch their spaces, except for the last line of a paragraph.
+func alignLine(line *imageLine, width fixed.Int26_6, align string, last bool) {
+	if len(line.segments) == 0 {
+		return
+	}
+	end := line.segments[len(line.segments)-1]
+	extra := width - (end.x + end.width)
+	if extra <= 0 {
+		return
+	}
+
+	switch strings.ToUpper(align) {
+	case "CENTER":
+		for i := range line.segments {
+			line.segments[i].x += extra / 2
+		}
+	case "RIGHT":
+		for i := range line.segments {
+			line.segments[i].x += extra
+		}
+	case "JUSTIFY", "DISTRIBUTE":
+		if last && strings.ToUpper(align) == "JUSTIFY" {
+			return
+		}
+		spaces := 0
+		for _, segment := range line.segments {
+			if segment.space {
+				spaces++
+			}
+		}
+		if spaces == 0 {
+			return
+		}
+		shift := fixed.Int26_6(0)
+		gaps := 0
+		for i := range line.segments {
+			line.segments[i].x += shift
+			if line.segments[i].space {
+				gaps++
+				next := extra * fixed.Int26_6(gaps) / fixed.Int26_6(spaces)
+				line.segments[i].width += next - shift
+				shift = next
+			}
+		}
+	}
+}
+
+// segments splits runs into words and spaces, replacing note references by
+// their labels. Characters of scripts written without spaces are words of
+// their own so lines can break between them.
+func (l *imageTextLayout) segments(runs []interfaces.Run) []imageSegment {
+	var segments []imageSegment
+	for _, run := range runs {
+		size := l.size(run)
+		face := l.faces.face(size, run.Bold, run.Italic)
+		add := func(text string, space bool, notes []interfaces.Note) {
+			if text == "" {
+				return
+			}
+			segments = append(segments, imageSegment{
+				text: text, run: run, size: size, face: face,
+				width: font.MeasureString(face, text), space: space, notes: notes,
+			})
+		}
+
+		text := run.Text
+		for text != "" {
+			// Note references become their labels
+			var notes []interfaces.Note
+			loc := interfaces.NoteRefPattern.FindStringSubmatchIndex(text)
+			part := text
+			if loc != nil {
+				part = text[:loc[0]]
+			}
+
+			word := ""
+			for _, r := range part {
+				switch {
+				case r == '\n':
+					add(word, false, nil)
+					word = ""
+					add("\n", false, nil)
+				case unicode.IsSpace(r):
+					add(word, false, nil)
+					word = ""
+					add(string(r), true, nil)
+				case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+					add(word, false, nil)
+					word = ""
+					add(string(r), false, nil)
+				default:
+					word += string(r)
+				}
+			}
+			add(word, false, nil)
+			if loc == nil {
+				break
+			}
+
+			label := text[loc[0]:loc[1]]
+			if note := l.content.FindNote(text[loc[2]:loc[3]]); note != nil {
+				label = note.Label
+				if note.Kind == interfaces.Footnote {
+					notes = append(notes, *note)
+				}
+			}
+			add(label, false, notes)
+			text = text[loc[1]:]
+		}
+	}
+	return segments
+}
+
+// splitSegment breaks a word after the characters that fit in width,
+// keeping at least one character on the line
+func (l *imageTextLayout) splitSegment(segment imageSegment, width fixed.Int26_6) (imageSegment, imageSegment) {
+	runes := []rune(segment.text)
+	n := 1
+	for n < len(runes) && font.MeasureString(segment.face, string(runes[:n+1])) <= width {
+		n++
+	}
+	if n >= len(runes) {
+		return segment, imageSegment{}
+	}
+	head, tail := segment, segment
+	head.text, tail.text = string(runes[:n]), string(runes[n:])
+	head.width = font.MeasureString(segment.face, head.text)
+	tail.width = font.MeasureString(segment.face, tail.text)
+	// Notes belong to the end of the word
+	head.notes = nil
+	return head, tail
+}
+
+// noteLines lays out a note as lines of body text size prefixed with its label
+func (l *imageTextLayout) noteLines(note interfaces.Note, width int) []imageLine {
+	var lines []imageLine
+	for i, text := range strings.Split(note.Text, "\n") {
+		if i == 0 {
+			text = note.Label + " " + text
+		}
+		lines = append(lines, l.paragraph([]interfaces.Run{{Text: text}}, "", width, 0, 0)...)
+	}
+	return lines
+}
+
+// table lays out the rows of a table, each cell wrapped within its share
+// of the width. Merged cells take the width of the columns they span.
+func (l *imageTextLayout) table(table *interfaces.Table, width int) []imageLine {
+	columns := max(table.Columns(), 1)
+	padding := int(math.Round(l.base * l.px / 2))
+	column := width / columns
+
+	var lines []imageLine
+	for _, row := range table.Rows {
+		var rowLines []imageLine
+		for _, cell := range row {
+			x := cell.Col*column + padding
+			cellWidth := max(column*max(cell.ColSpan, 1)-2*padding, 1)
+			// Cells keep their own list numbering
+			counters := l.counters
+			l.counters = nil
+			cellLines := l.blocks(cell.Blocks, cellWidth)
+			l.counters = counters
+
+			for i, cellLine := range cellLines {
+				for len(rowLines) <= i {
+					rowLines = append(rowLines, imageLine{size: l.base})
+				}
+				for _, segment := range cellLine.segments {
+					segment.x += fixed.I(x)
+					rowLines[i].segments = append(rowLines[i].segments, segment)
+				}
+				rowLines[i].size = math.Max(rowLines[i].size, cellLine.size)
+				rowLines[i].notes = append(rowLines[i].notes, cellLine.notes...)
+			}
+		}
+		lines = append(lines, rowLines...)
+	}
+	return lines
+}
+
+// lineHeight returns the height of a line in pixels
+func (l *imageTextLayout) lineHeight(line imageLine) int {
+	return int(math.Round(line.size * 1.4 * l.px))
+}
+
+// drawLine draws a line with its top at y and returns the top of the next
+// line. Highlights are drawn behind the text and decorations over it.
+func (l *imageTextLayout) drawLine(dst draw.Image, line imageLine, left, y int) int {
+	baseline := y + int(math.Round(line.size*1.1*l.px))
+	for _, segment := range line.segments {
+		if segment.run.Highlight == "" {
+			continue
+		}
+		metrics := segment.face.Metrics()
+		rect := image.Rect(
+			left+segment.x.Floor(), baseline-metrics.Ascent.Ceil(),
+			left+(segment.x+segment.width).Ceil(), baseline+metrics.Descent.Ceil())
+		draw.Draw(dst, rect, image.NewUniform(runColor(segment.run.Highlight, color.White)), image.Point{}, draw.Over)
+	}
+
+	for _, segment := range line.segments {
+		src := image.NewUniform(runColor(segment.run.Color, color.Black))
+		if !segment.space {
+			drawer := font.Drawer{
+				Dst:  dst,
+				Src:  src,
+				Face: segment.face,
+				Dot:  fixed.Point26_6{X: l.position(fixed.I(left) + segment.x), Y: fixed.I(baseline)},
+			}
+			drawer.DrawString(segment.text)
+		}
+
+		px := segment.size * l.px
+		thickness := max(1, int(math.Round(px/16)))
+		x0, x1 := left+segment.x.Floor(), left+(segment.x+segment.width).Ceil()
+		if segment.run.Underline {
+			top := baseline + int(math.Round(px*0.12))
+			draw.Draw(dst, image.Rect(x0, top, x1, top+thickness), src, image.Point{}, draw.Over)
+		}
+		if segment.run.Strike {
+			top := baseline - int(math.Round(px*0.3))
+			draw.Draw(dst, image.Rect(x0, top, x1, top+thickness), src, image.Point{}, draw.Over)
+		}
+	}
+	return y + l.lineHeight(line)
+}
+
+// position returns a horizontal glyph position, rounded to whole pixels
+// unless glyphs are placed at sub-pixel positions
+func (l *imageTextLayout) position(x fixed.Int26_6) fixed.Int26_6 {
+	if l.subPixel {
+		return x
+	}
+	return fixed.I(x.Round())
+}
+
+// runColor parses a #RRGGBB run color, returning fallback when unset
+func runColor(value string, fallback color.Color) color.Color {
+	if len(value) != 7 || value[0] != '#' {
+		return fallback
+	}
+	rgb, err := strconv.ParseUint(value[1:], 16, 32)
+	if err != nil {
+		return fallback
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 255}
+}
+
+// textPage is the lines drawn on a page: body lines from the top and
+// footnote lines at the bottom of the body area
+type textPage struct {
+	lines []imageLine
+	notes []imageLine
+}
+
+// paginate fills pages of height pixels with lines, keeping the footnotes
+// of a line on its page
+func (l *imageTextLayout) paginate(lines []imageLine, height, width int) []textPage {
+	separator := l.paragraph([]interfaces.Run{{Text: noteSeparator}}, "", width, 0, 0)
+
+	var pages []textPage
+	var page textPage
+	used := 0
+	for _, line := range lines {
+		notes := line.notes
+		if len(notes) > 0 && len(page.notes) == 0 {
+			notes = append(append([]imageLine(nil), separator...), notes...)
+		}
+		need := l.lineHeight(line)
+		for _, note := range notes {
+			need += l.lineHeight(note)
+		}
+
+		// Start a new page when the line and its footnotes no longer fit
+		if len(page.lines) > 0 && (line.pageBreak || used+need > height) {
+			pages = append(pages, page)
+			page = textPage{}
+			used = 0
+			if len(line.notes) > 0 {
+				notes = append(append([]imageLine(nil), separator...), line.notes...)
+				need = l.lineHeight(line)
+				for _, note := range notes {
+					need += l.lineHeight(note)
+				}
+			}
+		}
+		page.lines = append(page.lines, line)
+		page.notes = append(page.notes, notes...)
+		used += need
+	}
+	return append(pages, page)
+}
+
+// textBlocks returns the blocks to draw: the content blocks followed by
+// the endnotes, or the lines of plain text content
+func textBlocks(content *interfaces.PDFContent) []interfaces.Block {
+	var blocks []interfaces.Block
+	if len(content.Blocks) > 0 {
+		blocks = append(blocks, content.Blocks...)
+	} else {
+		for _, line := range strings.Split(content.Text, "\n") {
+			blocks = append(blocks, interfaces.Block{Kind: interfaces.BlockParagraph, Runs: []interfaces.Run{{Text: line}}})
+		}
+	}
+
+	var endnotes []interfaces.Block
+	for _, note := range content.Notes {
+		if note.Kind == interfaces.Endnote {
+			endnotes = append(endnotes, interfaces.Block{Kind: interfaces.BlockParagraph, Runs: []interfaces.Run{{Text: fmt.Sprintf("%s %s", note.Label, note.Text)}}})
+		}
+	}
+	if len(endnotes) > 0 {
+		blocks = append(blocks,
+			interfaces.Block{Kind: interfaces.BlockParagraph},
+			interfaces.Block{Kind: interfaces.BlockParagraph, Runs: []interfaces.Run{{Text: noteSeparator}}})
+		blocks = append(blocks, endnotes...)
+	}
+	return blocks
+}
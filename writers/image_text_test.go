@@ -0,0 +1,71 @@
+package writers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font/gofont/goregular"
+	"myconverter/interfaces"
+)
+
+func TestListMarkers(t *testing.T) {
+	item := func(text string, level int, ordered bool) interfaces.Block {
+		return interfaces.Block{
+			Kind:    interfaces.BlockListItem,
+			Level:   level,
+			Ordered: ordered,
+			Runs:    []interfaces.Run{{Text: text}},
+		}
+	}
+	paragraph := interfaces.Block{Kind: interfaces.BlockParagraph, Runs: []interfaces.Run{{Text: "text"}}}
+
+	tests := []struct {
+		name   string
+		blocks []interfaces.Block
+		want   []string
+	}{
+		{
+			name:   "bullets then numbers",
+			blocks: []interfaces.Block{item("a", 1, false), item("b", 1, false), item("first", 1, true), item("second", 1, true)},
+			want:   []string{"• a", "• b", "1. first", "2. second"},
+		},
+		{
+			name:   "numbers then bullets then numbers",
+			blocks: []interfaces.Block{item("a", 1, true), item("b", 1, false), item("c", 1, true)},
+			want:   []string{"1. a", "• b", "1. c"},
+		},
+		{
+			name:   "paragraph restarts numbering",
+			blocks: []interfaces.Block{item("a", 1, true), item("b", 1, true), paragraph, item("c", 1, true)},
+			want:   []string{"1. a", "2. b", "text", "1. c"},
+		},
+		{
+			name:   "nested levels",
+			blocks: []interfaces.Block{item("a", 1, true), item("x", 2, false), item("y", 2, true), item("b", 1, true), item("z", 2, true)},
+			want:   []string{"1. a", "• x", "1. y", "2. b", "1. z"},
+		},
+	}
+
+	f, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &ImageWriter{font: f}
+			lines := w.textLayout(&interfaces.PDFContent{}).blocks(tt.blocks, 2000)
+			var got []string
+			for _, line := range lines {
+				var sb strings.Builder
+				for _, segment := range line.segments {
+					sb.WriteString(segment.text)
+				}
+				got = append(got, sb.String())
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,33 +4,113 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
 	"image/png"
+	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/golang/freetype"
 	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/bmp"
 	"golang.org/x/image/font"
+	"myconverter/interfaces"
 )
 
+// Color modes of ImageWriter
+const (
+	ColorModeColor = "color"
+	ColorModeGray  = "gray"
+	// ColorModeMono dithers pages to black and white, stored as CCITT
+	// Group 4 in TIFF files
+	ColorModeMono = "mono"
+)
+
+// Hinting modes of ImageWriter
+const (
+	HintingNone = "none"
+	HintingFull = "full"
+)
+
+// DefaultJPEGQuality is the JPEG quality used when none is set
+const DefaultJPEGQuality = 90
+
 // ImageWriter handles converting document content to image files
 type ImageWriter struct {
-	// Width and Height of the output image in pixels
+	// Width and Height of the output image in pixels at 300 DPI; other
+	// resolutions scale them with the margins and text
 	Width  int
 	Height int
 	// Output directory for image files
 	OutputDir string
-	// Font settings
+	// Quality of JPEG output from 1 to 100, DefaultJPEGQuality when 0
+	Quality int
+	// ColorMode is one of the ColorMode constants, color when empty
+	ColorMode string
+	// DPI is the resolution text is drawn at and recorded in TIFF output,
+	// 300 when 0
+	DPI float64
+	// Hinting is HintingFull to fit glyph outlines to the pixel grid for
+	// crisp small text, or HintingNone to keep their exact shapes
+	Hinting string
+	// SubPixel places glyphs at fractions of a pixel for even spacing
+	// instead of whole pixels
+	SubPixel bool
+	// Background fills the pages, white when nil. Transparent backgrounds
+	// are kept in PNG and GIF files and drawn on white in other formats.
+	Background color.Color
+	// FontSize is the size in points of text without a size of its own
+	FontSize float64
+	// Watermark is drawn over every page when set
+	Watermark *Watermark
+	// Fonts of regular and bold text; bold text is emboldened from the
+	// regular font when boldFont is nil
 	font     *truetype.Font
-	fontSize float64
+	boldFont *truetype.Font
 }
 
 // NewImageWriter creates a new ImageWriter with default settings
 func NewImageWriter(outputDir string) (*ImageWriter, error) {
-	// Load the font file (using NanumGothic as an example)
-	fontBytes, err := ioutil.ReadFile("C:\\Windows\\Fonts\\malgun.ttf")
+	f, err := loadFont()
+	if err != nil {
+		return nil, err
+	}
+
+	// The bold variant is optional
+	var bold *truetype.Font
+	if _, boldPath, _ := fontFiles(); boldPath != "" {
+		if boldBytes, err := ioutil.ReadFile(boldPath); err == nil {
+			bold, _ = freetype.ParseFont(boldBytes)
+		}
+	}
+
+	return &ImageWriter{
+		Width:     1920, // Default width for A4 at 300 DPI
+		Height:    2700, // Default height for A4 at 300 DPI
+		OutputDir: outputDir,
+		DPI:       300,
+		Hinting:   HintingFull,
+		SubPixel:  true,
+		FontSize:  10, // Default font size
+		font:      f,
+		boldFont:  bold,
+	}, nil
+}
+
+// loadFont loads the regular text font
+func loadFont() (*truetype.Font, error) {
+	path, _, err := fontFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load font file: %v", err)
+	}
+	fontBytes, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load font file: %v", err)
 	}
@@ -40,32 +120,56 @@ func NewImageWriter(outputDir string) (*ImageWriter, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse font: %v", err)
 	}
-
-	return &ImageWriter{
-		Width:     1920,    // Default width for A4 at 300 DPI
-		Height:    2700,    // Default height for A4 at 300 DPI
-		OutputDir: outputDir,
-		font:     f,
-		fontSize: 48,      // Default font size
-	}, nil
+	return f, nil
 }
 
-// CreateImage creates a blank image with the specified dimensions
+// CreateImage creates a blank image of the page size at the writer's
+// resolution, filled with the background color
 func (w *ImageWriter) CreateImage() *image.RGBA {
-	img := image.NewRGBA(image.Rect(0, 0, w.Width, w.Height))
+	img := image.NewRGBA(image.Rect(0, 0, w.scale(w.Width), w.scale(w.Height)))
 
-	// Fill with white background
-	for y := 0; y < w.Height; y++ {
-		for x := 0; x < w.Width; x++ {
-			img.Set(x, y, color.White)
-		}
+	background := w.Background
+	if background == nil {
+		background = color.White
 	}
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
 
 	return img
 }
 
-// SaveImage saves the image to a PNG file
-func (w *ImageWriter) SaveImage(img *image.RGBA, filename string) error {
+// dpi returns the resolution of the writer, 300 when unset
+func (w *ImageWriter) dpi() float64 {
+	if w.DPI <= 0 {
+		return 300
+	}
+	return w.DPI
+}
+
+// scale converts a length in pixels at 300 DPI to the writer's resolution
+func (w *ImageWriter) scale(length int) int {
+	return int(math.Round(float64(length) * w.dpi() / 300))
+}
+
+// SaveImage saves the image in the format of the filename's extension:
+// PNG, JPEG, GIF, BMP or TIFF
+func (w *ImageWriter) SaveImage(img image.Image, filename string) error {
+	return w.savePages([]image.Image{img}, filename)
+}
+
+// savePages saves images to one file, as pages of a TIFF file
+func (w *ImageWriter) savePages(pages []image.Image, filename string) error {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !IsImageExt(ext) {
+		return fmt.Errorf("unsupported image format: %s", ext)
+	}
+	converted := make([]image.Image, len(pages))
+	for i, img := range pages {
+		var err error
+		if converted[i], err = w.convertColor(img); err != nil {
+			return err
+		}
+	}
+
 	// Ensure output directory exists
 	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %v", err)
@@ -79,48 +183,289 @@ func (w *ImageWriter) SaveImage(img *image.RGBA, filename string) error {
 	}
 	defer f.Close()
 
-	// Encode and save as PNG
-	if err := png.Encode(f, img); err != nil {
+	if err := w.encode(f, converted, ext); err != nil {
 		return fmt.Errorf("failed to encode image: %v", err)
 	}
-
 	return nil
 }
 
+// encode writes images in the format of an extension; only TIFF holds
+// several images
+func (w *ImageWriter) encode(out io.Writer, pages []image.Image, ext string) error {
+	if ext == ".tif" || ext == ".tiff" {
+		return encodeTIFF(out, pages, w.dpi())
+	}
+	if len(pages) != 1 {
+		return fmt.Errorf("%s files hold one page, not %d", strings.TrimPrefix(ext, "."), len(pages))
+	}
+	switch ext {
+	case ".jpg", ".jpeg":
+		quality := w.Quality
+		if quality <= 0 {
+			quality = DefaultJPEGQuality
+		}
+		return jpeg.Encode(out, flatten(pages[0]), &jpeg.Options{Quality: min(quality, 100)})
+	case ".gif":
+		img := pages[0]
+		if gray, ok := img.(*image.Gray); ok {
+			// Keep all gray levels instead of the default color palette
+			paletted := image.NewPaletted(gray.Bounds(), grayPalette())
+			draw.Draw(paletted, gray.Bounds(), gray, gray.Bounds().Min, draw.Src)
+			img = paletted
+		} else if !isOpaque(img) {
+			// Keep a transparent palette entry, which the default palette
+			// lacks
+			paletted := image.NewPaletted(img.Bounds(), append(palette.WebSafe[:len(palette.WebSafe):len(palette.WebSafe)], color.Transparent))
+			draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, img.Bounds().Min)
+			img = paletted
+		}
+		return gif.Encode(out, img, &gif.Options{NumColors: 256, Drawer: draw.FloydSteinberg})
+	case ".bmp":
+		return bmp.Encode(out, flatten(pages[0]))
+	default:
+		return png.Encode(out, pages[0])
+	}
+}
+
+// convertColor converts an image to the writer's color mode. Mono images
+// are dithered to black and white with Floyd-Steinberg error diffusion.
+func (w *ImageWriter) convertColor(img image.Image) (image.Image, error) {
+	switch strings.ToLower(w.ColorMode) {
+	case "", ColorModeColor:
+		return img, nil
+	case ColorModeGray, ColorModeMono:
+	default:
+		return nil, fmt.Errorf("unknown color mode: %s", w.ColorMode)
+	}
+
+	bounds := img.Bounds()
+	gray, ok := img.(*image.Gray)
+	if !ok {
+		gray = image.NewGray(bounds)
+		draw.Draw(gray, bounds, flatten(img), bounds.Min, draw.Src)
+	}
+	if strings.ToLower(w.ColorMode) == ColorModeGray {
+		return gray, nil
+	}
+	mono := image.NewPaletted(bounds, color.Palette{color.Black, color.White})
+	draw.FloydSteinberg.Draw(mono, bounds, gray, bounds.Min)
+	return mono, nil
+}
+
+// flatten returns an image without transparency, drawn on white
+func flatten(img image.Image) image.Image {
+	switch img.(type) {
+	case *image.Gray, *image.Paletted, *image.YCbCr:
+		return img
+	}
+	bounds := img.Bounds()
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, img, bounds.Min, draw.Over)
+	return flat
+}
+
+// isOpaque reports whether an image has no transparent pixels
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// grayPalette returns the 256 levels of gray
+func grayPalette() color.Palette {
+	palette := make(color.Palette, 256)
+	for i := range palette {
+		palette[i] = color.Gray{Y: uint8(i)}
+	}
+	return palette
+}
+
+// ParseColor parses a #RRGGBB or #RRGGBBAA color, or transparent
+func ParseColor(value string) (color.Color, error) {
+	if strings.EqualFold(value, "transparent") {
+		return color.Transparent, nil
+	}
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 6 {
+		hex += "FF"
+	}
+	rgba, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return nil, fmt.Errorf("invalid color: %s", value)
+	}
+	return color.NRGBA{R: uint8(rgba >> 24), G: uint8(rgba >> 16), B: uint8(rgba >> 8), A: uint8(rgba)}, nil
+}
+
+// IsImageExt reports whether ImageWriter can save files with an extension
+func IsImageExt(ext string) bool {
+	switch ext {
+	case ".png", ".jpg", ".jpeg", ".gif", ".bmp", ".tif", ".tiff":
+		return true
+	}
+	return false
+}
+
 // WriteTexts renders text on the image and saves it
 func (w *ImageWriter) WriteTexts(outputPath string, text string) error {
-	// Split text into lines
-	texts := strings.Split(text, "\n")
-	// Create blank image
-	img := w.CreateImage()
-
-	// Create font context
-	c := freetype.NewContext()
-	c.SetDPI(300)
-	c.SetFont(w.font)
-	c.SetFontSize(10.0) // 10pt font size
-	c.SetClip(img.Bounds())
-	c.SetDst(img)
-	c.SetSrc(image.NewUniform(color.Black))
-	c.SetHinting(font.HintingFull)
+	return w.WriteContent(outputPath, &interfaces.PDFContent{Text: text})
+}
+
+// WriteContent renders the content text with its headers and footers on
+// page images. A single page is saved to outputPath; longer content is
+// saved as one numbered image per page, e.g. out-1.png, out-2.png, except
+// for TIFF files which hold all pages.
+func (w *ImageWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	images, err := w.RenderContent(content)
+	if err != nil {
+		return err
+	}
+	return w.WritePages(outputPath, images)
+}
+
+// RenderContent renders the content text with its headers and footers on
+// page images
+func (w *ImageWriter) RenderContent(content *interfaces.PDFContent) ([]image.Image, error) {
+	layout := w.textLayout(content)
 
 	// Start from top with margin
-	margin := 50
-	lineHeight := int(c.PointToFixed(14) >> 6) // 1.4x line spacing
-	pt := freetype.Pt(margin, margin+lineHeight)
+	margin := w.scale(50)
+	lineHeight := layout.lineHeight(imageLine{size: layout.base})
+
+	// Headers and footers sit in the top and bottom margins, pushing the
+	// body down when they span several lines
+	headerLines := maxHeaderFooterLines(content.Headers)
+	footerLines := maxHeaderFooterLines(content.Footers)
+	top := max(margin, margin/2+headerLines*lineHeight)
+	height := w.scale(w.Height)
+	bottom := height - max(margin, margin/2+footerLines*lineHeight)
+
+	// Lay out lines on pages before drawing so page totals are known
+	width := w.scale(w.Width) - 2*margin
+	pages := layout.paginate(layout.blocks(textBlocks(content), width), bottom-top, width)
+
+	images := make([]image.Image, 0, len(pages))
+	for i, textPage := range pages {
+		// Create blank image
+		img := w.CreateImage()
+
+		y := top
+		for _, line := range textPage.lines {
+			y = layout.drawLine(img, line, margin, y)
+		}
+
+		// Footnotes fill the bottom of the body area
+		y = bottom
+		for _, note := range textPage.notes {
+			y -= layout.lineHeight(note)
+		}
+		for _, note := range textPage.notes {
+			y = layout.drawLine(img, note, margin, y)
+		}
+
+		page := i + 1
+		y = margin / 2
+		for _, header := range interfaces.SelectHeaderFooters(content.Headers, page) {
+			y = w.drawHeaderFooter(layout, img, header.Resolve(page, len(pages)), header.Align, y, margin)
+		}
+		y = height - margin/2 - footerLines*lineHeight
+		for _, footer := range interfaces.SelectHeaderFooters(content.Footers, page) {
+			y = w.drawHeaderFooter(layout, img, footer.Resolve(page, len(pages)), footer.Align, y, margin)
+		}
+
+		images = append(images, img)
+	}
+
+	return images, nil
+}
+
+// textLayout returns the layout of content text in the writer's fonts
+func (w *ImageWriter) textLayout(content *interfaces.PDFContent) *imageTextLayout {
+	size := w.FontSize
+	if size <= 0 {
+		size = 10
+	}
+	return &imageTextLayout{
+		content: content,
+		faces: &faceCache{
+			regular:  w.font,
+			bold:     w.boldFont,
+			dpi:      w.dpi(),
+			hinting:  !strings.EqualFold(w.Hinting, HintingNone),
+			subPixel: w.SubPixel,
+			faces:    make(map[faceKey]font.Face),
+		},
+		base:     size,
+		px:       w.dpi() / 72,
+		subPixel: w.SubPixel,
+	}
+}
 
-	// Draw each line of text
-	for _, text := range texts {
-		_, err := c.DrawString(text, pt)
+// WritePages saves already drawn page images, such as rendered PDF pages,
+// with the page numbering of WriteContent, drawing the watermark on each.
+// It needs no font unless the watermark has text.
+func (w *ImageWriter) WritePages(outputPath string, pages []image.Image) error {
+	if w.Watermark != nil {
+		marked, err := w.watermarkPages(pages)
 		if err != nil {
-			return fmt.Errorf("failed to draw text: %v", err)
+			return err
 		}
-		// Move to next line
-		pt.Y += c.PointToFixed(14)
+		pages = marked
 	}
 
-	// Save the image
-	return w.SaveImage(img, filepath.Base(outputPath))
+	if ext := strings.ToLower(filepath.Ext(outputPath)); ext == ".tif" || ext == ".tiff" {
+		return w.savePages(pages, filepath.Base(outputPath))
+	}
+	for i, img := range pages {
+		if err := w.SaveImage(img, filepath.Base(pageImagePath(outputPath, i+1, len(pages)))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watermarkPages returns copies of the pages with the watermark drawn on them
+func (w *ImageWriter) watermarkPages(pages []image.Image) ([]image.Image, error) {
+	f := w.font
+	if f == nil && w.Watermark.Text != "" {
+		var err error
+		if f, err = loadFont(); err != nil {
+			return nil, err
+		}
+	}
+
+	marked := make([]image.Image, len(pages))
+	for i, page := range pages {
+		img := image.NewRGBA(page.Bounds())
+		draw.Draw(img, img.Bounds(), page, page.Bounds().Min, draw.Src)
+		if err := w.Watermark.drawImage(img, f, w.dpi()); err != nil {
+			return nil, err
+		}
+		marked[i] = img
+	}
+	return marked, nil
+}
+
+// drawHeaderFooter draws header or footer lines with their tops from y
+// and returns the top of the line below them
+func (w *ImageWriter) drawHeaderFooter(layout *imageTextLayout, dst draw.Image, text, align string, y, margin int) int {
+	for _, line := range strings.Split(text, "\n") {
+		lines := layout.paragraph([]interfaces.Run{{Text: line}}, align, w.scale(w.Width)-2*margin, 0, 0)
+		// Headers and footers keep to one line each
+		y = layout.drawLine(dst, lines[0], margin, y)
+	}
+	return y
+}
+
+// pageImagePath returns the output path for a page of a multi-page image,
+// or for a part of a split document
+func pageImagePath(outputPath string, page, total int) string {
+	if total <= 1 {
+		return outputPath
+	}
+	ext := filepath.Ext(outputPath)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(outputPath, ext), page, ext)
 }
 
 // Write creates an image with sample text and saves it
@@ -0,0 +1,71 @@
+package writers
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+func TestSaveImageBackground(t *testing.T) {
+	tests := []struct {
+		filename    string
+		decode      func(io.Reader) (image.Image, error)
+		transparent bool
+	}{
+		{filename: "out.png", decode: png.Decode, transparent: true},
+		{filename: "out.gif", decode: gif.Decode, transparent: true},
+		{filename: "out.jpg", decode: jpeg.Decode},
+		{filename: "out.bmp", decode: bmp.Decode},
+		{filename: "out.tif", decode: tiff.Decode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			w := &ImageWriter{Width: 20, Height: 20, DPI: 300, OutputDir: t.TempDir(), Background: color.Transparent}
+			img := w.CreateImage()
+			img.Set(15, 15, color.Black)
+			if err := w.SaveImage(img, tt.filename); err != nil {
+				t.Fatalf("SaveImage() error = %v", err)
+			}
+
+			f, err := os.Open(filepath.Join(w.OutputDir, tt.filename))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			decoded, err := tt.decode(f)
+			if err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			r, g, b, a := decoded.At(5, 5).RGBA()
+			if tt.transparent {
+				if a != 0 {
+					t.Errorf("pixel 5,5 = %v, want transparent", decoded.At(5, 5))
+				}
+			} else if r>>8 < 0xF0 || g>>8 < 0xF0 || b>>8 < 0xF0 || a != 0xFFFF {
+				t.Errorf("pixel 5,5 = %v, want white", decoded.At(5, 5))
+			}
+		})
+	}
+}
+
+func TestEncodePageCount(t *testing.T) {
+	w := &ImageWriter{}
+	pages := []image.Image{image.NewGray(image.Rect(0, 0, 2, 2)), image.NewGray(image.Rect(0, 0, 2, 2))}
+	for _, ext := range []string{".png", ".jpg", ".gif", ".bmp"} {
+		if err := w.encode(io.Discard, pages, ext); err == nil {
+			t.Errorf("encode(%s) of 2 pages succeeded, want an error", ext)
+		}
+	}
+	if err := w.encode(io.Discard, pages, ".tif"); err != nil {
+		t.Errorf("encode(.tif) of 2 pages error = %v", err)
+	}
+}
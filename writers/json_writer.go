@@ -0,0 +1,343 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"myconverter/interfaces"
+)
+
+// JSONSchemaVersion is the version of the JSON export schema. The minor
+// version grows with backward compatible additions such as new optional
+// fields, the major version with changes that break existing consumers.
+//
+// Schema 1.1:
+//
+//	{
+//	  "schemaVersion": "1.1",
+//	  "source": "report.hwpx",          // input file name, may be absent
+//	  "metadata": {"Title": "..."},     // document properties, always present
+//	  "pageSetup": {...},               // paper size and margins in points, may be absent
+//	  "pageCount": 3,                   // pages known from page and section breaks
+//	  "text": "...",                    // plain text, one line per paragraph
+//	  "sections": [{"index": 0, "blocks": [...]}],
+//	  "headers": [...], "footers": [...], "notes": [...]
+//	}
+//
+// Blocks have a "type" of paragraph, heading, list-item, table, image or
+// page-break and the "page" they start on, counted from 1. Text blocks carry
+// their "text", their "runs" and a "source" range locating the text in the
+// top-level "text" field as offsets in Unicode code points. Style references
+// are the "style" name and the "paraShape" ID of the block and the
+// "charShape" ID of each run, as read from the source document.
+//
+// Tables are "cells" matrices of rows by grid columns. A merged cell appears
+// at its top-left position with its spans, the positions it covers hold
+// "mergedFrom": [row, column] pointing at it. Images are references to the
+// embedded picture by name, media type and pixel size, without the data.
+// Since 1.1 images stored rotated or mirrored carry their EXIF "orientation".
+//
+// Notes are referenced from text as [^id] markers.
+const JSONSchemaVersion = "1.1"
+
+// JSONDocument is the root object of the JSON export
+type JSONDocument struct {
+	SchemaVersion string             `json:"schemaVersion"`
+	Source        string             `json:"source,omitempty"`
+	Metadata      map[string]string  `json:"metadata"`
+	PageSetup     *JSONPageSetup     `json:"pageSetup,omitempty"`
+	PageCount     int                `json:"pageCount"`
+	Text          string             `json:"text"`
+	Sections      []JSONSection      `json:"sections"`
+	Headers       []JSONHeaderFooter `json:"headers,omitempty"`
+	Footers       []JSONHeaderFooter `json:"footers,omitempty"`
+	Notes         []JSONNote         `json:"notes,omitempty"`
+}
+
+// JSONPageSetup is the paper size and margins in points, the header and
+// footer areas lying inside the top and bottom margins
+type JSONPageSetup struct {
+	Width        float64 `json:"width"`
+	Height       float64 `json:"height"`
+	MarginLeft   float64 `json:"marginLeft"`
+	MarginRight  float64 `json:"marginRight"`
+	MarginTop    float64 `json:"marginTop"`
+	MarginBottom float64 `json:"marginBottom"`
+	MarginHeader float64 `json:"marginHeader"`
+	MarginFooter float64 `json:"marginFooter"`
+}
+
+// JSONSection is a section of the document, started by a section break
+type JSONSection struct {
+	Index  int         `json:"index"`
+	Blocks []JSONBlock `json:"blocks"`
+}
+
+// JSONBlock is a paragraph, heading, list item, table, image or page break
+type JSONBlock struct {
+	Type      string     `json:"type"`
+	Page      int        `json:"page"`
+	Level     int        `json:"level,omitempty"`
+	Ordered   bool       `json:"ordered,omitempty"`
+	Align     string     `json:"align,omitempty"`
+	Style     string     `json:"style,omitempty"`
+	ParaShape string     `json:"paraShape,omitempty"`
+	Text      string     `json:"text,omitempty"`
+	Source    *JSONRange `json:"source,omitempty"`
+	Runs      []JSONRun  `json:"runs,omitempty"`
+	Table     *JSONTable `json:"table,omitempty"`
+	Image     *JSONImage `json:"image,omitempty"`
+}
+
+// JSONRange locates text in the document text in Unicode code points
+type JSONRange struct {
+	Offset int `json:"offset"`
+	Length int `json:"length"`
+}
+
+// JSONRun is a span of text sharing the same formatting
+type JSONRun struct {
+	Text      string  `json:"text"`
+	Bold      bool    `json:"bold,omitempty"`
+	Italic    bool    `json:"italic,omitempty"`
+	Underline bool    `json:"underline,omitempty"`
+	Strike    bool    `json:"strike,omitempty"`
+	Size      float64 `json:"size,omitempty"`
+	Color     string  `json:"color,omitempty"`
+	Highlight string  `json:"highlight,omitempty"`
+	Font      string  `json:"font,omitempty"`
+	CharShape string  `json:"charShape,omitempty"`
+}
+
+// JSONTable is a table as a matrix of rows by grid columns
+type JSONTable struct {
+	Rows    int          `json:"rows"`
+	Columns int          `json:"columns"`
+	Cells   [][]JSONCell `json:"cells"`
+}
+
+// JSONCell is a grid position of a table
+type JSONCell struct {
+	Text       string      `json:"text"`
+	RowSpan    int         `json:"rowSpan,omitempty"`
+	ColSpan    int         `json:"colSpan,omitempty"`
+	MergedFrom []int       `json:"mergedFrom,omitempty"`
+	Blocks     []JSONBlock `json:"blocks,omitempty"`
+}
+
+// JSONImage references an embedded picture
+type JSONImage struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int    `json:"size"`
+	// Orientation is the EXIF orientation of the data when it is not upright
+	Orientation int `json:"orientation,omitempty"`
+}
+
+// JSONHeaderFooter is a header or footer with its {{PAGE}} and
+// {{TOTAL_PAGES}} placeholders
+type JSONHeaderFooter struct {
+	Apply string `json:"apply,omitempty"`
+	Align string `json:"align,omitempty"`
+	Text  string `json:"text"`
+}
+
+// JSONNote is a footnote or endnote
+type JSONNote struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+	Text  string `json:"text"`
+}
+
+// JSONWriter handles writing the extracted content structure as JSON
+type JSONWriter struct {
+	// Output directory for JSON files
+	OutputDir string
+	// Source is the input file name recorded in the document
+	Source string
+}
+
+// NewJSONWriter creates a new JSONWriter
+func NewJSONWriter(outputDir string) *JSONWriter {
+	return &JSONWriter{OutputDir: outputDir}
+}
+
+// WriteContent writes the content as a JSON document following JSONSchemaVersion
+func (w *JSONWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	data, err := json.MarshalIndent(NewJSONDocument(content, w.Source), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %v", err)
+	}
+	return os.WriteFile(filepath.Join(w.OutputDir, filepath.Base(outputPath)), append(data, '\n'), 0644)
+}
+
+// Write writes sample text to the specified file.
+func (w *JSONWriter) Write(outputPath string) error {
+	return w.WriteContent(outputPath, &interfaces.PDFContent{Text: "Sample Text"})
+}
+
+// NewJSONDocument builds the JSON export of the content
+func NewJSONDocument(content *interfaces.PDFContent, source string) *JSONDocument {
+	doc := &JSONDocument{
+		SchemaVersion: JSONSchemaVersion,
+		Source:        source,
+		Metadata:      make(map[string]string),
+	}
+	if page := content.PageSetup; page != nil {
+		doc.PageSetup = &JSONPageSetup{
+			Width: page.Width, Height: page.Height,
+			MarginLeft: page.MarginLeft, MarginRight: page.MarginRight, MarginTop: page.MarginTop, MarginBottom: page.MarginBottom,
+			MarginHeader: page.MarginHeader, MarginFooter: page.MarginFooter,
+		}
+	}
+	for key, value := range content.Metadata {
+		doc.Metadata[key] = value
+	}
+
+	j := &jsonBuilder{page: 1}
+	doc.Sections = []JSONSection{{Index: 0, Blocks: []JSONBlock{}}}
+	for _, block := range content.ContentBlocks() {
+		if block.Kind == interfaces.BlockPageBreak {
+			j.page++
+			if block.Section {
+				doc.Sections = append(doc.Sections, JSONSection{Index: len(doc.Sections), Blocks: []JSONBlock{}})
+				continue
+			}
+		}
+		section := &doc.Sections[len(doc.Sections)-1]
+		section.Blocks = append(section.Blocks, j.block(block))
+	}
+	doc.Text = strings.Join(j.lines, "\n")
+	doc.PageCount = j.page
+
+	for _, item := range content.Headers {
+		doc.Headers = append(doc.Headers, JSONHeaderFooter{Apply: item.Apply, Align: item.Align, Text: item.Text})
+	}
+	for _, item := range content.Footers {
+		doc.Footers = append(doc.Footers, JSONHeaderFooter{Apply: item.Apply, Align: item.Align, Text: item.Text})
+	}
+	for _, note := range content.Notes {
+		doc.Notes = append(doc.Notes, JSONNote{ID: note.ID, Kind: note.Kind, Label: note.Label, Text: note.Text})
+	}
+	return doc
+}
+
+// jsonBuilder tracks the page and the text lines while converting blocks
+type jsonBuilder struct {
+	page int
+	// lines are the text lines of the document in the order of
+	// interfaces.BlockLines, offset the code points they take with separators
+	lines  []string
+	offset int
+}
+
+// block converts a block, recording its text line
+func (j *jsonBuilder) block(block interfaces.Block) JSONBlock {
+	out := JSONBlock{
+		Type:      string(block.Kind),
+		Page:      j.page,
+		Level:     block.Level,
+		Ordered:   block.Ordered,
+		Align:     block.Align,
+		Style:     block.Style,
+		ParaShape: block.ParaShape,
+	}
+
+	switch block.Kind {
+	case interfaces.BlockTable:
+		if block.Table != nil {
+			out.Table = j.table(block.Table)
+		}
+	case interfaces.BlockImage:
+		if block.Image != nil {
+			out.Image = jsonImage(block.Image)
+		}
+	case interfaces.BlockPageBreak:
+	default:
+		for _, run := range block.Runs {
+			out.Runs = append(out.Runs, JSONRun{
+				Text: run.Text, Bold: run.Bold, Italic: run.Italic, Underline: run.Underline, Strike: run.Strike,
+				Size: run.Size, Color: run.Color, Highlight: run.Highlight, Font: run.Font, CharShape: run.CharShape,
+			})
+		}
+		if text := strings.TrimSpace(block.Text()); text != "" {
+			out.Text = text
+			out.Source = &JSONRange{Offset: j.offset, Length: utf8.RuneCountInString(text)}
+			j.lines = append(j.lines, text)
+			j.offset += out.Source.Length + 1
+		}
+	}
+	return out
+}
+
+// table converts a table to a matrix of grid positions
+func (j *jsonBuilder) table(table *interfaces.Table) *JSONTable {
+	columns := table.Columns()
+	rows := len(table.Rows)
+	for _, row := range table.Rows {
+		for _, cell := range row {
+			rows = max(rows, cell.Row+max(cell.RowSpan, 1))
+		}
+	}
+
+	out := &JSONTable{Rows: rows, Columns: columns, Cells: make([][]JSONCell, rows)}
+	for r := range out.Cells {
+		out.Cells[r] = make([]JSONCell, columns)
+	}
+	// Cells are visited in reading order so that offsets follow the text
+	for _, row := range table.Rows {
+		for _, cell := range row {
+			if cell.Row >= rows || cell.Col >= columns {
+				continue
+			}
+			rowSpan, colSpan := max(cell.RowSpan, 1), max(cell.ColSpan, 1)
+			position := JSONCell{RowSpan: rowSpan, ColSpan: colSpan}
+			var lines []string
+			for _, block := range cell.Blocks {
+				converted := j.block(block)
+				if converted.Text != "" {
+					lines = append(lines, converted.Text)
+				}
+				position.Blocks = append(position.Blocks, converted)
+			}
+			position.Text = strings.Join(lines, "\n")
+			out.Cells[cell.Row][cell.Col] = position
+
+			for r := cell.Row; r < min(cell.Row+rowSpan, rows); r++ {
+				for c := cell.Col; c < min(cell.Col+colSpan, columns); c++ {
+					if r != cell.Row || c != cell.Col {
+						out.Cells[r][c] = JSONCell{MergedFrom: []int{cell.Row, cell.Col}}
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// jsonImage returns the reference of an embedded picture
+func jsonImage(img *interfaces.Image) *JSONImage {
+	_, contentType := imageType(img)
+	out := &JSONImage{Name: img.Name, ContentType: contentType, Size: len(img.Data)}
+	if img.Orientation > 1 {
+		out.Orientation = img.Orientation
+	}
+	// The size is 0x0 when the picture format cannot be decoded
+	if config, _, err := image.DecodeConfig(bytes.NewReader(img.Data)); err == nil {
+		out.Width, out.Height = config.Width, config.Height
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package writers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"myconverter/interfaces"
+)
+
+func TestJSONWriterSchema(t *testing.T) {
+	image := testImages(t, 1)[0]
+	image.Orientation = 6
+	cell := func(row, col, rowSpan int, text string) interfaces.TableCell {
+		return interfaces.TableCell{Row: row, Col: col, RowSpan: rowSpan, ColSpan: 1, Blocks: interfaces.TextBlocks(text)}
+	}
+	content := &interfaces.PDFContent{
+		Metadata:  map[string]string{"Title": "보고서"},
+		PageSetup: &interfaces.PageSetup{Width: 595, Height: 842, MarginLeft: 56, MarginRight: 56, MarginTop: 42, MarginBottom: 42, MarginHeader: 14, MarginFooter: 14},
+		Blocks: []interfaces.Block{
+			{Kind: interfaces.BlockHeading, Level: 1, Style: "Heading 1", ParaShape: "3", Runs: []interfaces.Run{{Text: "제목", Bold: true, CharShape: "7"}}},
+			{Kind: interfaces.BlockParagraph, Runs: []interfaces.Run{{Text: "note[^1]"}}},
+			{Kind: interfaces.BlockPageBreak},
+			{Kind: interfaces.BlockTable, Table: &interfaces.Table{Rows: [][]interfaces.TableCell{
+				{cell(0, 0, 2, "tall"), cell(0, 1, 1, "b")},
+				{cell(1, 1, 1, "d")},
+			}}},
+			{Kind: interfaces.BlockPageBreak, Section: true},
+			{Kind: interfaces.BlockImage, Image: image},
+		},
+		Headers: []interfaces.HeaderFooter{{Apply: "BOTH", Align: "CENTER", Text: "Page " + interfaces.PageNumberField}},
+		Notes:   []interfaces.Note{{Kind: interfaces.Footnote, ID: "1", Label: "1", Text: "the note"}},
+	}
+	w := NewJSONWriter(t.TempDir())
+	w.Source = "report.hwpx"
+	if err := w.WriteContent("out.json", content); err != nil {
+		t.Fatalf("WriteContent() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(w.OutputDir, "out.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(got, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// The empty footer list is left out
+	if want := []string{"headers", "metadata", "notes", "pageCount", "pageSetup", "schemaVersion", "sections", "source", "text"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+
+	tests := []struct {
+		path []interface{}
+		want interface{}
+	}{
+		{path: []interface{}{"schemaVersion"}, want: JSONSchemaVersion},
+		{path: []interface{}{"source"}, want: "report.hwpx"},
+		{path: []interface{}{"metadata", "Title"}, want: "보고서"},
+		{path: []interface{}{"pageSetup", "marginHeader"}, want: 14.0},
+		{path: []interface{}{"pageCount"}, want: 3.0},
+		{path: []interface{}{"text"}, want: "제목\nnote[^1]\ntall\nb\nd"},
+		{path: []interface{}{"sections", 0, "blocks", 0, "type"}, want: "heading"},
+		{path: []interface{}{"sections", 0, "blocks", 0, "style"}, want: "Heading 1"},
+		{path: []interface{}{"sections", 0, "blocks", 0, "paraShape"}, want: "3"},
+		{path: []interface{}{"sections", 0, "blocks", 0, "runs", 0, "charShape"}, want: "7"},
+		{path: []interface{}{"sections", 0, "blocks", 0, "runs", 0, "bold"}, want: true},
+		{path: []interface{}{"sections", 0, "blocks", 1, "source", "offset"}, want: 3.0},
+		{path: []interface{}{"sections", 0, "blocks", 2, "type"}, want: "page-break"},
+		{path: []interface{}{"sections", 0, "blocks", 3, "page"}, want: 2.0},
+		{path: []interface{}{"sections", 0, "blocks", 3, "table", "rows"}, want: 2.0},
+		{path: []interface{}{"sections", 0, "blocks", 3, "table", "cells", 0, 0, "rowSpan"}, want: 2.0},
+		{path: []interface{}{"sections", 0, "blocks", 3, "table", "cells", 1, 0, "mergedFrom"}, want: []interface{}{0.0, 0.0}},
+		{path: []interface{}{"sections", 0, "blocks", 3, "table", "cells", 1, 1, "text"}, want: "d"},
+		{path: []interface{}{"sections", 1, "index"}, want: 1.0},
+		{path: []interface{}{"sections", 1, "blocks", 0, "page"}, want: 3.0},
+		{path: []interface{}{"sections", 1, "blocks", 0, "image", "contentType"}, want: "image/png"},
+		{path: []interface{}{"sections", 1, "blocks", 0, "image", "width"}, want: 20.0},
+		{path: []interface{}{"sections", 1, "blocks", 0, "image", "orientation"}, want: 6.0},
+		{path: []interface{}{"headers", 0, "text"}, want: "Page {{PAGE}}"},
+		{path: []interface{}{"notes", 0, "kind"}, want: "footnote"},
+	}
+	for _, tt := range tests {
+		if got := jsonValue(doc, tt.path...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+
+	// Source ranges locate the block text in the document text
+	text := []rune(doc["text"].(string))
+	var check func(value interface{})
+	check = func(value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if source, ok := value["source"].(map[string]interface{}); ok {
+				offset, length := int(source["offset"].(float64)), int(source["length"].(float64))
+				if offset+length > len(text) || string(text[offset:offset+length]) != value["text"] {
+					t.Errorf("source %d+%d does not locate %q", offset, length, value["text"])
+				}
+			}
+			for _, child := range value {
+				check(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				check(child)
+			}
+		}
+	}
+	check(doc["sections"])
+}
+
+// jsonValue returns the value at a path of object keys and array indexes,
+// nil when it does not exist
+func jsonValue(value interface{}, path ...interface{}) interface{} {
+	for _, step := range path {
+		switch step := step.(type) {
+		case string:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = object[step]
+		case int:
+			array, ok := value.([]interface{})
+			if !ok || step >= len(array) {
+				return nil
+			}
+			value = array[step]
+		}
+	}
+	return value
+}
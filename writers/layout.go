@@ -0,0 +1,93 @@
+package writers
+
+import (
+	"strings"
+
+	"myconverter/interfaces"
+)
+
+// noteSeparator is drawn between the body and the notes
+const noteSeparator = "――――――――――"
+
+// pageLayout is the text drawn on a page: body lines and the footnote lines
+// placed at the bottom of the page
+type pageLayout struct {
+	Lines []string
+	Notes []string
+}
+
+// layoutPages splits the content text into pages holding at most perPage
+// lines. Note references are replaced by their labels, footnotes are placed
+// at the bottom of the page referencing them and endnotes at the end of the
+// document.
+func layoutPages(content *interfaces.PDFContent, perPage int) []pageLayout {
+	if perPage < 1 {
+		perPage = 1
+	}
+
+	lines := strings.Split(content.Text, "\n")
+	var endnotes []string
+	for _, note := range content.Notes {
+		if note.Kind == interfaces.Endnote {
+			endnotes = append(endnotes, noteLines(note)...)
+		}
+	}
+	if len(endnotes) > 0 {
+		lines = append(append(lines, "", noteSeparator), endnotes...)
+	}
+
+	var pages []pageLayout
+	var page pageLayout
+	for _, line := range lines {
+		var footnotes []string
+		line = interfaces.NoteRefPattern.ReplaceAllStringFunc(line, func(ref string) string {
+			note := content.FindNote(interfaces.NoteRefPattern.FindStringSubmatch(ref)[1])
+			if note == nil {
+				return ref
+			}
+			if note.Kind == interfaces.Footnote {
+				footnotes = append(footnotes, noteLines(*note)...)
+			}
+			return note.Label
+		})
+		if len(footnotes) > 0 && len(page.Notes) == 0 {
+			footnotes = append([]string{noteSeparator}, footnotes...)
+		}
+
+		// Start a new page when the line and its footnotes no longer fit
+		if len(page.Lines) > 0 && len(page.Lines)+1+len(page.Notes)+len(footnotes) > perPage {
+			pages = append(pages, page)
+			page = pageLayout{}
+			if len(footnotes) > 0 && footnotes[0] != noteSeparator {
+				footnotes = append([]string{noteSeparator}, footnotes...)
+			}
+		}
+		page.Lines = append(page.Lines, line)
+		page.Notes = append(page.Notes, footnotes...)
+	}
+	return append(pages, page)
+}
+
+// noteLines returns the lines of a note prefixed with its label
+func noteLines(note interfaces.Note) []string {
+	lines := strings.Split(note.Text, "\n")
+	lines[0] = note.Label + " " + lines[0]
+	return lines
+}
+
+// maxHeaderFooterLines returns the largest number of lines the headers or
+// footers occupy on any page
+func maxHeaderFooterLines(items []interfaces.HeaderFooter) int {
+	maxLines := 0
+	// Odd and even pages are the only variations
+	for page := 1; page <= 2; page++ {
+		lines := 0
+		for _, item := range interfaces.SelectHeaderFooters(items, page) {
+			lines += strings.Count(item.Text, "\n") + 1
+		}
+		if lines > maxLines {
+			maxLines = lines
+		}
+	}
+	return maxLines
+}
@@ -0,0 +1,367 @@
+package writers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"myconverter/interfaces"
+)
+
+// MarkdownWriter handles writing content as GitHub flavored Markdown
+type MarkdownWriter struct {
+	// Output directory for Markdown files
+	OutputDir string
+}
+
+// NewMarkdownWriter creates a new MarkdownWriter
+func NewMarkdownWriter(outputDir string) *MarkdownWriter {
+	return &MarkdownWriter{OutputDir: outputDir}
+}
+
+// WriteContent converts the content blocks to Markdown and saves them.
+// Images are exported to a <name>_assets directory next to the output file.
+func (w *MarkdownWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	outputPath = filepath.Join(w.OutputDir, filepath.Base(outputPath))
+	assetsDir := strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath)) + "_assets"
+
+	md := &markdownBuilder{
+		assetsDir:  assetsDir,
+		assetsPath: filepath.Join(w.OutputDir, assetsDir),
+		assets:     make(map[string][sha256.Size]byte),
+		names:      make(map[[sha256.Size]byte]string),
+	}
+	if err := md.blocks(content.ContentBlocks()); err != nil {
+		return err
+	}
+
+	// Note definitions follow the body
+	for _, note := range content.Notes {
+		md.out.WriteString(interfaces.NoteRef(note.ID) + ": ")
+		md.out.WriteString(strings.ReplaceAll(escapeMarkdown(note.Text), "\n", "\n    "))
+		md.out.WriteString("\n")
+	}
+
+	return os.WriteFile(outputPath, []byte(md.out.String()), 0644)
+}
+
+// Write writes sample text to the specified file.
+func (w *MarkdownWriter) Write(outputPath string) error {
+	return w.WriteContent(outputPath, &interfaces.PDFContent{Text: "Sample Text"})
+}
+
+// markdownBuilder accumulates Markdown output and exported images
+type markdownBuilder struct {
+	out        strings.Builder
+	assetsDir  string
+	assetsPath string
+	// assets holds the content hash of each exported file name, and names
+	// the file name of each exported content
+	assets map[string][sha256.Size]byte
+	names  map[[sha256.Size]byte]string
+}
+
+// blocks writes blocks separated by blank lines, list items on consecutive lines
+func (md *markdownBuilder) blocks(blocks []interfaces.Block) error {
+	for i, block := range blocks {
+		switch block.Kind {
+		case interfaces.BlockHeading:
+			level := min(max(block.Level, 1), 6)
+			// Headings are bold already
+			runs := make([]interfaces.Run, len(block.Runs))
+			for i, run := range block.Runs {
+				run.Bold = false
+				runs[i] = run
+			}
+			md.out.WriteString(strings.Repeat("#", level) + " " + markdownRuns(runs) + "\n")
+
+		case interfaces.BlockListItem:
+			indent := strings.Repeat("   ", max(block.Level, 1)-1)
+			marker := "- "
+			if block.Ordered {
+				marker = "1. "
+			}
+			text := strings.ReplaceAll(markdownRuns(block.Runs), "\n", "\n"+indent+"   ")
+			md.out.WriteString(indent + marker + text + "\n")
+			// Consecutive list items form one list
+			if i+1 < len(blocks) && blocks[i+1].Kind == interfaces.BlockListItem {
+				continue
+			}
+
+		case interfaces.BlockTable:
+			if block.Table == nil {
+				continue
+			}
+			if err := md.table(block.Table); err != nil {
+				return err
+			}
+
+		case interfaces.BlockImage:
+			link, err := md.image(block.Image)
+			if err != nil {
+				return err
+			}
+			md.out.WriteString(link + "\n")
+
+		case interfaces.BlockPageBreak:
+			continue
+
+		default:
+			if block.Style == "Code" {
+				md.out.WriteString(codeBlock(block.Text()))
+				break
+			}
+			md.out.WriteString(markdownRuns(block.Runs) + "\n")
+		}
+		md.out.WriteString("\n")
+	}
+	return nil
+}
+
+// table writes a table as a GFM pipe table, or as HTML when cells are merged
+func (md *markdownBuilder) table(table *interfaces.Table) error {
+	columns := table.Columns()
+	if len(table.Rows) == 0 || columns == 0 {
+		return nil
+	}
+
+	if table.HasMergedCells() {
+		return md.htmlTable(table)
+	}
+
+	for r, row := range table.Rows {
+		cells := make([]string, columns)
+		for _, cell := range row {
+			text, err := md.cellText(cell)
+			if err != nil {
+				return err
+			}
+			cells[cell.Col] = strings.ReplaceAll(text, "|", "\\|")
+		}
+		md.out.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+
+		// The first row serves as the header row
+		if r == 0 {
+			md.out.WriteString(strings.Repeat("| --- ", columns) + "|\n")
+		}
+	}
+	return nil
+}
+
+// htmlTable writes a table with merged cells as an HTML table
+func (md *markdownBuilder) htmlTable(table *interfaces.Table) error {
+	md.out.WriteString("<table>\n")
+	for _, row := range table.Rows {
+		md.out.WriteString("  <tr>")
+		for _, cell := range row {
+			text, err := md.htmlCellText(cell)
+			if err != nil {
+				return err
+			}
+			md.out.WriteString("<td")
+			if cell.ColSpan > 1 {
+				md.out.WriteString(fmt.Sprintf(` colspan="%d"`, cell.ColSpan))
+			}
+			if cell.RowSpan > 1 {
+				md.out.WriteString(fmt.Sprintf(` rowspan="%d"`, cell.RowSpan))
+			}
+			md.out.WriteString(">" + text + "</td>")
+		}
+		md.out.WriteString("</tr>\n")
+	}
+	md.out.WriteString("</table>\n")
+	return nil
+}
+
+// cellText returns the inline Markdown of a cell's blocks joined by line breaks
+func (md *markdownBuilder) cellText(cell interfaces.TableCell) (string, error) {
+	var parts []string
+	for _, block := range cell.Blocks {
+		switch block.Kind {
+		case interfaces.BlockImage:
+			link, err := md.image(block.Image)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, link)
+		case interfaces.BlockTable:
+			// Nested tables are flattened to their text
+			for _, line := range interfaces.BlockLines([]interfaces.Block{block}) {
+				parts = append(parts, escapeMarkdown(line))
+			}
+		default:
+			parts = append(parts, markdownRuns(block.Runs))
+		}
+	}
+	return strings.ReplaceAll(strings.Join(parts, "<br>"), "\n", "<br>"), nil
+}
+
+// htmlCellText returns the escaped HTML text of a cell's blocks, since
+// Markdown is not interpreted inside HTML tables
+func (md *markdownBuilder) htmlCellText(cell interfaces.TableCell) (string, error) {
+	var parts []string
+	for _, block := range cell.Blocks {
+		if block.Kind == interfaces.BlockImage {
+			name, err := md.asset(block.Image)
+			if err != nil {
+				return "", err
+			}
+			if name != "" {
+				parts = append(parts, fmt.Sprintf(`<img src="%s" alt="%s">`,
+					html.EscapeString(md.assetURL(name)), html.EscapeString(name)))
+			}
+			continue
+		}
+		for _, line := range interfaces.BlockLines([]interfaces.Block{block}) {
+			parts = append(parts, strings.ReplaceAll(html.EscapeString(line), "\n", "<br>"))
+		}
+	}
+	return strings.Join(parts, "<br>"), nil
+}
+
+// image exports an image to the assets directory and returns its Markdown link
+func (md *markdownBuilder) image(image *interfaces.Image) (string, error) {
+	name, err := md.asset(image)
+	if name == "" || err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("![%s](%s)", escapeMarkdown(name), md.assetURL(name)), nil
+}
+
+// asset exports an image to the assets directory once and returns its file
+// name, empty for images without data. Images with the same content share
+// a file; different images with the same name get a numeric suffix.
+func (md *markdownBuilder) asset(image *interfaces.Image) (string, error) {
+	if image == nil || len(image.Data) == 0 {
+		return "", nil
+	}
+	sum := sha256.Sum256(image.Data)
+	if name, ok := md.names[sum]; ok {
+		return name, nil
+	}
+
+	name := image.Name
+	if name == "" {
+		name = fmt.Sprintf("image%d.png", len(md.assets)+1)
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 2; ; n++ {
+		if _, taken := md.assets[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+
+	if err := os.MkdirAll(md.assetsPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create assets directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(md.assetsPath, name), image.Data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write image %s: %v", name, err)
+	}
+	md.assets[name] = sum
+	md.names[sum] = name
+	return name, nil
+}
+
+// assetURL returns the link target of an exported file, escaped so spaces
+// and parentheses do not end the target
+func (md *markdownBuilder) assetURL(name string) string {
+	return markdownURLEscaper.Replace(url.PathEscape(md.assetsDir) + "/" + url.PathEscape(name))
+}
+
+// markdownURLEscaper escapes the parentheses url.PathEscape keeps
+var markdownURLEscaper = strings.NewReplacer("(", "%28", ")", "%29")
+
+// codeBlock returns text as a fenced code block, the fence longer than any
+// run of backticks in the text
+func codeBlock(text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + text + "\n" + fence + "\n"
+}
+
+// markdownRuns returns the inline Markdown of runs, merging runs with the same
+// formatting and keeping whitespace outside emphasis markers
+func markdownRuns(runs []interfaces.Run) string {
+	var merged []interfaces.Run
+	for _, run := range runs {
+		if len(merged) > 0 && merged[len(merged)-1].SameFormat(run) {
+			merged[len(merged)-1].Text += run.Text
+		} else {
+			merged = append(merged, run)
+		}
+	}
+
+	var out strings.Builder
+	for _, run := range merged {
+		text := escapeMarkdown(run.Text)
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			out.WriteString(text)
+			continue
+		}
+
+		var open, close string
+		switch {
+		case run.Bold && run.Italic:
+			open, close = "***", "***"
+		case run.Bold:
+			open, close = "**", "**"
+		case run.Italic:
+			open, close = "*", "*"
+		}
+		if run.Strike {
+			open, close = open+"~~", "~~"+close
+		}
+		if run.Underline {
+			open, close = open+"<u>", "</u>"+close
+		}
+
+		start := strings.Index(text, trimmed)
+		out.WriteString(text[:start] + open + trimmed + close + text[start+len(trimmed):])
+	}
+
+	// Line breaks inside a paragraph become hard breaks
+	return strings.ReplaceAll(out.String(), "\n", "  \n")
+}
+
+// markdownEscaper escapes characters with inline meaning in Markdown
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;",
+)
+
+// orderedListPattern matches text that would start an ordered list
+var orderedListPattern = regexp.MustCompile(`^(\d+)[.)]\s`)
+
+// escapeMarkdown escapes text for Markdown, keeping note references intact
+func escapeMarkdown(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, match := range interfaces.NoteRefPattern.FindAllStringIndex(text, -1) {
+		out.WriteString(markdownEscaper.Replace(text[last:match[0]]))
+		out.WriteString(text[match[0]:match[1]])
+		last = match[1]
+	}
+	out.WriteString(markdownEscaper.Replace(text[last:]))
+
+	// Characters starting a line could turn a paragraph into a heading, list or quote
+	escaped := out.String()
+	if strings.HasPrefix(escaped, "#") || strings.HasPrefix(escaped, "- ") || strings.HasPrefix(escaped, "+ ") {
+		escaped = `\` + escaped
+	} else if match := orderedListPattern.FindStringSubmatch(escaped); match != nil {
+		escaped = match[1] + `\` + escaped[len(match[1]):]
+	}
+	return escaped
+}
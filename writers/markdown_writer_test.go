@@ -0,0 +1,103 @@
+package writers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"myconverter/interfaces"
+)
+
+func TestMarkdownWriterBlocks(t *testing.T) {
+	paragraph := func(style, text string) interfaces.Block {
+		return interfaces.Block{Kind: interfaces.BlockParagraph, Style: style, Runs: []interfaces.Run{{Text: text}}}
+	}
+	tests := []struct {
+		name   string
+		blocks []interfaces.Block
+		want   string
+	}{
+		{
+			name:   "paragraph",
+			blocks: []interfaces.Block{paragraph("", "a *b*")},
+			want:   "a \\*b\\*\n\n",
+		},
+		{
+			name:   "code block",
+			blocks: []interfaces.Block{paragraph("Code", "func f() {\n    return *p\n}")},
+			want:   "```\nfunc f() {\n    return *p\n}\n```\n\n",
+		},
+		{
+			name:   "code block with a fence",
+			blocks: []interfaces.Block{paragraph("Code", "```go\nx\n```")},
+			want:   "````\n```go\nx\n```\n````\n\n",
+		},
+		{
+			name: "list then code",
+			blocks: []interfaces.Block{
+				{Kind: interfaces.BlockListItem, Level: 1, Runs: []interfaces.Run{{Text: "item"}}},
+				paragraph("Code", "  indented"),
+			},
+			want: "- item\n\n```\n  indented\n```\n\n",
+		},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		content := &interfaces.PDFContent{Blocks: tt.blocks}
+		if err := NewMarkdownWriter(dir).WriteContent("out.md", content); err != nil {
+			t.Fatalf("%s: WriteContent() error = %v", tt.name, err)
+		}
+		got, err := os.ReadFile(filepath.Join(dir, "out.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: output = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownWriterAssets(t *testing.T) {
+	image := func(name, data string) interfaces.Block {
+		return interfaces.Block{Kind: interfaces.BlockImage, Image: &interfaces.Image{Name: name, Data: []byte(data)}}
+	}
+	content := &interfaces.PDFContent{Blocks: []interfaces.Block{
+		image("image1.png", "first"),
+		image("image1.png", "second"),
+		image("copy.png", "first"),
+		image("a (1).png", "third"),
+		image("", "fourth"),
+	}}
+
+	dir := t.TempDir()
+	if err := NewMarkdownWriter(dir).WriteContent("my notes.md", content); err != nil {
+		t.Fatalf("WriteContent() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "my notes.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "![image1.png](my%20notes_assets/image1.png)\n\n" +
+		"![image1-2.png](my%20notes_assets/image1-2.png)\n\n" +
+		"![image1.png](my%20notes_assets/image1.png)\n\n" +
+		"![a (1).png](my%20notes_assets/a%20%281%29.png)\n\n" +
+		"![image4.png](my%20notes_assets/image4.png)\n\n"
+	if string(got) != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	files := make(map[string]string)
+	entries, err := os.ReadDir(filepath.Join(dir, "my notes_assets"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		data, _ := os.ReadFile(filepath.Join(dir, "my notes_assets", entry.Name()))
+		files[entry.Name()] = string(data)
+	}
+	wantFiles := map[string]string{"image1.png": "first", "image1-2.png": "second", "a (1).png": "third", "image4.png": "fourth"}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("assets = %v, want %v", files, wantFiles)
+	}
+}
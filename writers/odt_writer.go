@@ -0,0 +1,686 @@
+package writers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"myconverter/interfaces"
+)
+
+// OpenDocument namespaces
+const odtNamespaces = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+	`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" ` +
+	`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+	`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+	`xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" ` +
+	`xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" ` +
+	`xmlns:xlink="http://www.w3.org/1999/xlink" ` +
+	`xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0" ` +
+	`xmlns:dc="http://purl.org/dc/elements/1.1/" ` +
+	`xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0"`
+
+// odtMimeType is the media type of OpenDocument text documents
+const odtMimeType = "application/vnd.oasis.opendocument.text"
+
+// odtCommonStyles are the paragraph styles of styles.xml
+const odtCommonStyles = `<style:default-style style:family="paragraph">` +
+	`<style:paragraph-properties fo:margin-top="0pt" fo:margin-bottom="4pt"/>` +
+	`<style:text-properties style:font-name="Malgun Gothic" fo:font-family="'Malgun Gothic'" style:font-family-asian="'맑은 고딕'" fo:font-size="10pt" style:font-size-asian="10pt"/>` +
+	`</style:default-style>` +
+	`<style:style style:name="Standard" style:family="paragraph" style:class="text"/>` +
+	`<style:style style:name="Heading" style:family="paragraph" style:parent-style-name="Standard" style:class="text">` +
+	`<style:paragraph-properties fo:margin-top="12pt" fo:margin-bottom="6pt" fo:keep-with-next="always"/>` +
+	`<style:text-properties fo:font-weight="bold" style:font-weight-asian="bold"/></style:style>` +
+	`<style:style style:name="Heading_20_1" style:display-name="Heading 1" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="1"><style:text-properties fo:font-size="20pt" style:font-size-asian="20pt"/></style:style>` +
+	`<style:style style:name="Heading_20_2" style:display-name="Heading 2" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="2"><style:text-properties fo:font-size="16pt" style:font-size-asian="16pt"/></style:style>` +
+	`<style:style style:name="Heading_20_3" style:display-name="Heading 3" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="3"><style:text-properties fo:font-size="14pt" style:font-size-asian="14pt"/></style:style>` +
+	`<style:style style:name="Heading_20_4" style:display-name="Heading 4" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="4"><style:text-properties fo:font-size="12pt" style:font-size-asian="12pt"/></style:style>` +
+	`<style:style style:name="Heading_20_5" style:display-name="Heading 5" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="5"><style:text-properties fo:font-size="11pt" style:font-size-asian="11pt"/></style:style>` +
+	`<style:style style:name="Heading_20_6" style:display-name="Heading 6" style:family="paragraph" style:parent-style-name="Heading" style:default-outline-level="6"><style:text-properties fo:font-size="10pt" style:font-size-asian="10pt"/></style:style>` +
+	`<style:style style:name="List_20_Paragraph" style:display-name="List Paragraph" style:family="paragraph" style:parent-style-name="Standard" style:class="list"/>` +
+	`<style:style style:name="Table_20_Contents" style:display-name="Table Contents" style:family="paragraph" style:parent-style-name="Standard" style:class="extra"/>` +
+	`<style:style style:name="Header" style:family="paragraph" style:parent-style-name="Standard" style:class="extra"/>` +
+	`<style:style style:name="Footer" style:family="paragraph" style:parent-style-name="Standard" style:class="extra"/>` +
+	`<style:style style:name="Footnote" style:family="paragraph" style:parent-style-name="Standard" style:class="extra"><style:text-properties fo:font-size="9pt" style:font-size-asian="9pt"/></style:style>` +
+	`<style:style style:name="Endnote" style:family="paragraph" style:parent-style-name="Standard" style:class="extra"><style:text-properties fo:font-size="9pt" style:font-size-asian="9pt"/></style:style>`
+
+// ODTWriter handles writing content as an OpenDocument text document
+type ODTWriter struct {
+	// Output directory for ODT files
+	OutputDir string
+}
+
+// NewODTWriter creates a new ODTWriter
+func NewODTWriter(outputDir string) *ODTWriter {
+	return &ODTWriter{OutputDir: outputDir}
+}
+
+// WriteContent converts the content to an ODF package with its styles,
+// metadata, pictures, headers, footers and notes.
+func (w *ODTWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	page := docxDefaultPage
+	if content.PageSetup != nil && content.PageSetup.Width > 0 && content.PageSetup.Height > 0 {
+		page = *content.PageSetup
+	}
+
+	o := &odtBuilder{
+		content:         content,
+		page:            page,
+		textStyles:      make(map[string]string),
+		paragraphStyles: make(map[string]string),
+		media:           make(map[string]string),
+	}
+	o.blocks(&o.body, content.ContentBlocks())
+
+	zipWriter := &ZipWriter{TargetPath: filepath.Join(w.OutputDir, filepath.Base(outputPath))}
+	return zipWriter.WriteEntries(o.entries())
+}
+
+// Write writes sample text to the specified file.
+func (w *ODTWriter) Write(outputPath string) error {
+	return w.WriteContent(outputPath, &interfaces.PDFContent{Text: "Sample Text"})
+}
+
+// odtBuilder accumulates the document body and the styles and pictures it uses
+type odtBuilder struct {
+	content *interfaces.PDFContent
+	page    interfaces.PageSetup
+	body    strings.Builder
+	// Automatic styles of content.xml, in order of first use
+	styles strings.Builder
+	// textStyles and paragraphStyles map formatting keys to style names
+	textStyles      map[string]string
+	paragraphStyles map[string]string
+	// media maps image names to their paths in the package
+	media    map[string]string
+	pictures []ZipEntry
+	// manifest lists the pictures with their media types
+	manifest []string
+	tables   int
+	frames   int
+	notes    int
+	// pageBreak marks a page break to apply to the next paragraph
+	pageBreak bool
+}
+
+// blocks writes the OpenDocument text of blocks
+func (o *odtBuilder) blocks(out *strings.Builder, blocks []interfaces.Block) {
+	// depth is the nesting level of the list being written
+	depth := 0
+	closeLists := func(level int) {
+		for ; depth > level; depth-- {
+			out.WriteString(`</text:list-item></text:list>`)
+		}
+	}
+
+	for _, block := range blocks {
+		if block.Kind != interfaces.BlockListItem {
+			closeLists(0)
+		}
+
+		switch block.Kind {
+		case interfaces.BlockPageBreak:
+			o.pageBreak = true
+		case interfaces.BlockTable:
+			if block.Table != nil {
+				// Tables cannot carry the page break, an empty paragraph does
+				if o.pageBreak {
+					out.WriteString(`<text:p text:style-name="` + o.paragraphStyle("Standard", "") + `"/>`)
+				}
+				o.table(out, block.Table)
+			}
+		case interfaces.BlockImage:
+			if frame := o.image(block.Image); frame != "" {
+				out.WriteString(`<text:p text:style-name="` + o.paragraphStyle("Standard", block.Align) + `">` + frame + `</text:p>`)
+			}
+		case interfaces.BlockHeading:
+			level := strconv.Itoa(min(max(block.Level, 1), 6))
+			out.WriteString(`<text:h text:style-name="` + o.paragraphStyle("Heading_20_"+level, block.Align) +
+				`" text:outline-level="` + level + `">` + o.runs(block.Runs) + `</text:h>`)
+		case interfaces.BlockListItem:
+			// Lists nest inside the item of the enclosing level
+			level := min(max(block.Level, 1), 10)
+			closeLists(level)
+			if depth == level {
+				out.WriteString(`</text:list-item>`)
+			}
+			for depth < level {
+				style := "L1"
+				if block.Ordered {
+					style = "L2"
+				}
+				out.WriteString(`<text:list text:style-name="` + style + `">`)
+				if depth++; depth < level {
+					out.WriteString(`<text:list-item>`)
+				}
+			}
+			out.WriteString(`<text:list-item><text:p text:style-name="` + o.paragraphStyle("List_20_Paragraph", block.Align) + `">` +
+				o.runs(block.Runs) + `</text:p>`)
+		default:
+			out.WriteString(`<text:p text:style-name="` + o.paragraphStyle("Standard", block.Align) + `">` + o.runs(block.Runs) + `</text:p>`)
+		}
+	}
+	closeLists(0)
+
+	// A page break at the end starts an empty page
+	if o.pageBreak && out == &o.body {
+		out.WriteString(`<text:p text:style-name="` + o.paragraphStyle("Standard", "") + `"/>`)
+	}
+}
+
+// paragraphStyle returns the style of a paragraph, adding an automatic style
+// for its alignment or a pending page break
+func (o *odtBuilder) paragraphStyle(parent, align string) string {
+	textAlign := odtTextAlign(align)
+	pageBreak := o.pageBreak
+	o.pageBreak = false
+	if textAlign == "" && !pageBreak {
+		return parent
+	}
+
+	key := parent + "\x00" + textAlign + "\x00" + strconv.FormatBool(pageBreak)
+	if name, ok := o.paragraphStyles[key]; ok {
+		return name
+	}
+	name := "P" + strconv.Itoa(len(o.paragraphStyles)+1)
+	o.paragraphStyles[key] = name
+
+	o.styles.WriteString(`<style:style style:name="` + name + `" style:family="paragraph" style:parent-style-name="` + parent + `"><style:paragraph-properties`)
+	if textAlign != "" {
+		o.styles.WriteString(` fo:text-align="` + textAlign + `"`)
+	}
+	if pageBreak {
+		o.styles.WriteString(` fo:break-before="page"`)
+	}
+	o.styles.WriteString(`/></style:style>`)
+	return name
+}
+
+// odtTextAlign converts an alignment of the content model to a fo:text-align value
+func odtTextAlign(align string) string {
+	switch strings.ToUpper(align) {
+	case "LEFT":
+		return "start"
+	case "RIGHT":
+		return "end"
+	case "CENTER":
+		return "center"
+	case "JUSTIFY", "DISTRIBUTE":
+		return "justify"
+	default:
+		return ""
+	}
+}
+
+// runs returns the spans of runs, turning note references into notes
+func (o *odtBuilder) runs(runs []interfaces.Run) string {
+	var out strings.Builder
+	for _, run := range runs {
+		style := o.textStyle(run)
+		last := 0
+		for _, match := range interfaces.NoteRefPattern.FindAllStringSubmatchIndex(run.Text, -1) {
+			out.WriteString(odtSpan(run.Text[last:match[0]], style))
+			out.WriteString(o.note(run.Text[match[2]:match[3]]))
+			last = match[1]
+		}
+		out.WriteString(odtSpan(run.Text[last:], style))
+	}
+	return out.String()
+}
+
+// textStyle returns the automatic text style of a run's formatting, "" for
+// runs without formatting
+func (o *odtBuilder) textStyle(run interfaces.Run) string {
+	var props strings.Builder
+	if run.Font != "" {
+		font := xmlText(odtFontFamily(run.Font))
+		props.WriteString(` fo:font-family="` + font + `" style:font-family-asian="` + font + `"`)
+	}
+	if run.Bold {
+		props.WriteString(` fo:font-weight="bold" style:font-weight-asian="bold"`)
+	}
+	if run.Italic {
+		props.WriteString(` fo:font-style="italic" style:font-style-asian="italic"`)
+	}
+	if run.Underline {
+		props.WriteString(` style:text-underline-style="solid" style:text-underline-width="auto" style:text-underline-color="font-color"`)
+	}
+	if run.Strike {
+		props.WriteString(` style:text-line-through-style="solid"`)
+	}
+	if run.Size > 0 {
+		size := strconv.FormatFloat(run.Size, 'f', -1, 64) + "pt"
+		props.WriteString(` fo:font-size="` + size + `" style:font-size-asian="` + size + `"`)
+	}
+	if run.Color != "" {
+		props.WriteString(` fo:color="` + xmlText(run.Color) + `"`)
+	}
+	if run.Highlight != "" {
+		props.WriteString(` fo:background-color="` + xmlText(run.Highlight) + `"`)
+	}
+	if props.Len() == 0 {
+		return ""
+	}
+
+	key := props.String()
+	if name, ok := o.textStyles[key]; ok {
+		return name
+	}
+	name := "T" + strconv.Itoa(len(o.textStyles)+1)
+	o.textStyles[key] = name
+	o.styles.WriteString(`<style:style style:name="` + name + `" style:family="text"><style:text-properties` + key + `/></style:style>`)
+	return name
+}
+
+// odtFontFamily quotes font names containing spaces
+func odtFontFamily(font string) string {
+	if strings.ContainsAny(font, " ,") {
+		return "'" + font + "'"
+	}
+	return font
+}
+
+// odtSpan returns text in a span of the given style
+func odtSpan(text, style string) string {
+	if text == "" {
+		return ""
+	}
+	if style == "" {
+		return odtText(text)
+	}
+	return `<text:span text:style-name="` + style + `">` + odtText(text) + `</text:span>`
+}
+
+// odtText escapes text, writing tabs, line breaks and repeated spaces as
+// elements since whitespace in ODF text collapses
+func odtText(text string) string {
+	var out strings.Builder
+	spaces := 0
+	flushSpaces := func() {
+		if spaces == 0 {
+			return
+		}
+		// A single space is kept unless it starts the text
+		if out.Len() > 0 {
+			out.WriteString(" ")
+			spaces--
+		}
+		if spaces == 1 {
+			out.WriteString(`<text:s/>`)
+		} else if spaces > 1 {
+			out.WriteString(`<text:s text:c="` + strconv.Itoa(spaces) + `"/>`)
+		}
+		spaces = 0
+	}
+
+	for _, ch := range text {
+		switch ch {
+		case ' ':
+			spaces++
+			continue
+		case '\t':
+			flushSpaces()
+			out.WriteString(`<text:tab/>`)
+		case '\n':
+			flushSpaces()
+			out.WriteString(`<text:line-break/>`)
+		default:
+			flushSpaces()
+			out.WriteString(xmlText(string(ch)))
+		}
+	}
+	// Trailing spaces would collapse with the text that follows
+	if spaces == 1 {
+		out.WriteString(`<text:s/>`)
+	} else if spaces > 1 {
+		out.WriteString(`<text:s text:c="` + strconv.Itoa(spaces) + `"/>`)
+	}
+	return out.String()
+}
+
+// note returns the text:note element of a note reference, shown with the
+// note's own label so the source numbering is kept
+func (o *odtBuilder) note(id string) string {
+	note := o.content.FindNote(id)
+	if note == nil {
+		return odtText(interfaces.NoteRef(id))
+	}
+
+	class, style, prefix := "footnote", "Footnote", "ftn"
+	if note.Kind == interfaces.Endnote {
+		class, style, prefix = "endnote", "Endnote", "edn"
+	}
+	o.notes++
+
+	label := note.Label
+	citation := `<text:note-citation>`
+	if label == "" {
+		label = note.ID
+	} else {
+		citation = `<text:note-citation text:label="` + xmlText(label) + `">`
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf(`<text:note text:id="%s%d" text:note-class="%s">`, prefix, o.notes, class))
+	out.WriteString(citation + xmlText(label) + `</text:note-citation><text:note-body>`)
+	for _, line := range strings.Split(note.Text, "\n") {
+		out.WriteString(`<text:p text:style-name="` + style + `">` + odtText(line) + `</text:p>`)
+	}
+	out.WriteString(`</text:note-body></text:note>`)
+	return out.String()
+}
+
+// table writes a table, expressing merged cells with spans and covered cells
+func (o *odtBuilder) table(out *strings.Builder, table *interfaces.Table) {
+	columns := table.Columns()
+	if columns == 0 {
+		return
+	}
+
+	// Locate the cells starting at and covering each grid position
+	rows := len(table.Rows)
+	starts := make(map[[2]int]interfaces.TableCell)
+	covered := make(map[[2]int]bool)
+	for _, row := range table.Rows {
+		for _, cell := range row {
+			cell.RowSpan, cell.ColSpan = max(cell.RowSpan, 1), max(cell.ColSpan, 1)
+			starts[[2]int{cell.Row, cell.Col}] = cell
+			for r := cell.Row; r < cell.Row+cell.RowSpan; r++ {
+				for c := cell.Col; c < cell.Col+cell.ColSpan; c++ {
+					if r != cell.Row || c != cell.Col {
+						covered[[2]int{r, c}] = true
+					}
+				}
+			}
+			rows = max(rows, cell.Row+cell.RowSpan)
+		}
+	}
+
+	o.tables++
+	name := "Table" + strconv.Itoa(o.tables)
+	width := o.page.Width - o.page.MarginLeft - o.page.MarginRight
+	if o.tables == 1 {
+		o.styles.WriteString(`<style:style style:name="TableCell" style:family="table-cell">` +
+			`<style:table-cell-properties fo:padding="2pt" fo:border="0.5pt solid #000000"/></style:style>`)
+	}
+	o.styles.WriteString(fmt.Sprintf(`<style:style style:name="%s" style:family="table"><style:table-properties style:width="%.2fpt" table:align="margins"/></style:style>`, name, width))
+	o.styles.WriteString(fmt.Sprintf(`<style:style style:name="%s.A" style:family="table-column"><style:table-column-properties style:column-width="%.2fpt"/></style:style>`, name, width/float64(columns)))
+
+	out.WriteString(fmt.Sprintf(`<table:table table:name="%s" table:style-name="%s"><table:table-column table:style-name="%s.A" table:number-columns-repeated="%d"/>`,
+		name, name, name, columns))
+	for r := 0; r < rows; r++ {
+		out.WriteString(`<table:table-row>`)
+		for c := 0; c < columns; c++ {
+			cell, starting := starts[[2]int{r, c}]
+			if !starting {
+				if covered[[2]int{r, c}] {
+					out.WriteString(`<table:covered-table-cell/>`)
+				} else {
+					out.WriteString(`<table:table-cell table:style-name="TableCell" office:value-type="string"><text:p text:style-name="Table_20_Contents"/></table:table-cell>`)
+				}
+				continue
+			}
+
+			out.WriteString(`<table:table-cell table:style-name="TableCell" office:value-type="string"`)
+			if cell.ColSpan > 1 {
+				out.WriteString(fmt.Sprintf(` table:number-columns-spanned="%d"`, cell.ColSpan))
+			}
+			if cell.RowSpan > 1 {
+				out.WriteString(fmt.Sprintf(` table:number-rows-spanned="%d"`, cell.RowSpan))
+			}
+			out.WriteString(`>`)
+			o.blocks(out, cell.Blocks)
+			if len(cell.Blocks) == 0 {
+				out.WriteString(`<text:p text:style-name="Table_20_Contents"/>`)
+			}
+			out.WriteString(`</table:table-cell>`)
+		}
+		out.WriteString(`</table:table-row>`)
+	}
+	out.WriteString(`</table:table>`)
+}
+
+// image adds an image to the pictures and returns its frame
+func (o *odtBuilder) image(img *interfaces.Image) string {
+	if img == nil || len(img.Data) == 0 {
+		return ""
+	}
+
+	key := img.Name + "\x00" + strconv.Itoa(len(img.Data))
+	name, ok := o.media[key]
+	if !ok {
+		ext, contentType := imageType(img)
+		name = fmt.Sprintf("Pictures/image%d%s", len(o.media)+1, ext)
+		o.media[key] = name
+		o.pictures = append(o.pictures, ZipEntry{Name: name, Data: img.Data})
+		o.manifest = append(o.manifest, `<manifest:file-entry manifest:full-path="`+name+`" manifest:media-type="`+contentType+`"/>`)
+	}
+
+	// Pictures keep their pixel size at 96 DPI, scaled down to the text width
+	pixelsWidth, pixelsHeight := imagePixels(img.Data)
+	width, height := float64(pixelsWidth)*0.75, float64(pixelsHeight)*0.75
+	if maxWidth := o.page.Width - o.page.MarginLeft - o.page.MarginRight; maxWidth > 0 && width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+
+	o.frames++
+	return fmt.Sprintf(`<draw:frame draw:name="Image%d" text:anchor-type="as-char" svg:width="%.2fpt" svg:height="%.2fpt" draw:z-index="0">`+
+		`<draw:image xlink:href="%s" xlink:type="simple" xlink:show="embed" xlink:actuate="onLoad"/></draw:frame>`,
+		o.frames, width, height, name)
+}
+
+// masterStyles returns the automatic styles and master page of styles.xml
+// with the page layout, headers and footers
+func (o *odtBuilder) masterStyles() string {
+	// Left pages get their own headers and footers when some item applies
+	// to odd or even pages
+	leftPages := false
+	for _, item := range append(append([]interfaces.HeaderFooter(nil), o.content.Headers...), o.content.Footers...) {
+		if apply := strings.ToUpper(item.Apply); apply == "ODD" || apply == "EVEN" {
+			leftPages = true
+		}
+	}
+
+	var styles, master strings.Builder
+	alignStyles := make(map[string]string)
+	headerFooter := func(element, style string, items []interfaces.HeaderFooter, page int) {
+		if len(items) == 0 {
+			return
+		}
+		selected := interfaces.SelectHeaderFooters(items, page)
+		master.WriteString(`<style:` + element + `>`)
+		for _, item := range selected {
+			name := style
+			if textAlign := odtTextAlign(item.Align); textAlign != "" {
+				key := style + textAlign
+				if name = alignStyles[key]; name == "" {
+					name = "MP" + strconv.Itoa(len(alignStyles)+1)
+					alignStyles[key] = name
+					styles.WriteString(`<style:style style:name="` + name + `" style:family="paragraph" style:parent-style-name="` + style + `">` +
+						`<style:paragraph-properties fo:text-align="` + textAlign + `"/></style:style>`)
+				}
+			}
+			for _, line := range strings.Split(item.Text, "\n") {
+				master.WriteString(`<text:p text:style-name="` + name + `">` + odtFields(line) + `</text:p>`)
+			}
+		}
+		if len(selected) == 0 {
+			master.WriteString(`<text:p text:style-name="` + style + `"/>`)
+		}
+		master.WriteString(`</style:` + element + `>`)
+	}
+
+	master.WriteString(`<office:master-styles><style:master-page style:name="Standard" style:page-layout-name="pm1">`)
+	headerFooter("header", "Header", o.content.Headers, 1)
+	if leftPages {
+		headerFooter("header-left", "Header", o.content.Headers, 2)
+	}
+	headerFooter("footer", "Footer", o.content.Footers, 1)
+	if leftPages {
+		headerFooter("footer-left", "Footer", o.content.Footers, 2)
+	}
+	master.WriteString(`</style:master-page></office:master-styles>`)
+
+	// ODF places the header inside the top margin like HWP, the body
+	// follows the header area. Without a header the area joins the margin.
+	page := o.page
+	orientation := "portrait"
+	if page.Width > page.Height {
+		orientation = "landscape"
+	}
+	marginTop, marginBottom := page.MarginTop, page.MarginBottom
+	if len(o.content.Headers) == 0 {
+		marginTop += page.MarginHeader
+	}
+	if len(o.content.Footers) == 0 {
+		marginBottom += page.MarginFooter
+	}
+	styles.WriteString(fmt.Sprintf(`<style:page-layout style:name="pm1"><style:page-layout-properties fo:page-width="%.2fpt" fo:page-height="%.2fpt" `+
+		`style:print-orientation="%s" fo:margin-top="%.2fpt" fo:margin-bottom="%.2fpt" fo:margin-left="%.2fpt" fo:margin-right="%.2fpt"/>`,
+		page.Width, page.Height, orientation, marginTop, marginBottom, page.MarginLeft, page.MarginRight))
+	if len(o.content.Headers) > 0 {
+		styles.WriteString(fmt.Sprintf(`<style:header-style><style:header-footer-properties fo:min-height="%.2fpt" fo:margin-bottom="0pt"/></style:header-style>`, page.MarginHeader))
+	}
+	if len(o.content.Footers) > 0 {
+		styles.WriteString(fmt.Sprintf(`<style:footer-style><style:header-footer-properties fo:min-height="%.2fpt" fo:margin-top="0pt"/></style:footer-style>`, page.MarginFooter))
+	}
+	styles.WriteString(`</style:page-layout>`)
+
+	return `<office:automatic-styles>` + styles.String() + `</office:automatic-styles>` + master.String()
+}
+
+// odtFields returns header or footer text, turning page number placeholders
+// into page number and page count fields
+func odtFields(text string) string {
+	var out strings.Builder
+	for text != "" {
+		page := strings.Index(text, interfaces.PageNumberField)
+		total := strings.Index(text, interfaces.TotalPagesField)
+		if page < 0 && total < 0 {
+			out.WriteString(odtText(text))
+			break
+		}
+
+		field, placeholder, index := `<text:page-number text:select-page="current">1</text:page-number>`, interfaces.PageNumberField, page
+		if page < 0 || (total >= 0 && total < page) {
+			field, placeholder, index = `<text:page-count>1</text:page-count>`, interfaces.TotalPagesField, total
+		}
+		out.WriteString(odtText(text[:index]) + field)
+		text = text[index+len(placeholder):]
+	}
+	return out.String()
+}
+
+// meta returns meta.xml with the document metadata
+func (o *odtBuilder) meta() string {
+	var meta strings.Builder
+	meta.WriteString(xml.Header + `<office:document-meta ` + odtNamespaces + ` office:version="1.3"><office:meta>`)
+	meta.WriteString(`<meta:generator>MyConverter</meta:generator>`)
+	elements := []struct{ key, element string }{
+		{"Title", "dc:title"},
+		{"Subject", "dc:subject"},
+		{"Description", "dc:description"},
+		{"Author", "meta:initial-creator"},
+		{"LastModifiedBy", "dc:creator"},
+		{"CreationDate", "meta:creation-date"},
+		{"ModDate", "dc:date"},
+	}
+	for _, e := range elements {
+		value := strings.TrimSpace(o.content.Metadata[e.key])
+		if strings.HasSuffix(e.element, "date") {
+			value = metadataDate(value)
+		}
+		if value != "" {
+			meta.WriteString(`<` + e.element + `>` + xmlText(value) + `</` + e.element + `>`)
+		}
+	}
+	for _, keyword := range strings.Split(o.content.Metadata["Keywords"], ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			meta.WriteString(`<meta:keyword>` + xmlText(keyword) + `</meta:keyword>`)
+		}
+	}
+	meta.WriteString(`</office:meta></office:document-meta>`)
+	return meta.String()
+}
+
+// metadataDate converts a date of the metadata to an xsd:dateTime, returning ""
+// for dates it cannot read
+func metadataDate(value string) string {
+	// PDF dates look like D:20240131120000+09'00'
+	value = strings.TrimPrefix(value, "D:")
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format("2006-01-02T15:04:05")
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "20060102150405", "2006-01-02"} {
+		if len(value) < len(layout) {
+			continue
+		}
+		if t, err := time.Parse(layout, value[:len(layout)]); err == nil {
+			return t.Format("2006-01-02T15:04:05")
+		}
+	}
+	return ""
+}
+
+// entries returns the files of the ODF package, the uncompressed mimetype first
+func (o *odtBuilder) entries() []ZipEntry {
+	var content strings.Builder
+	content.WriteString(xml.Header + `<office:document-content ` + odtNamespaces + ` office:version="1.3">`)
+	content.WriteString(`<office:automatic-styles>` + o.styles.String() + `</office:automatic-styles>`)
+	content.WriteString(`<office:body><office:text>` + o.body.String() + `</office:text></office:body></office:document-content>`)
+
+	var styles strings.Builder
+	styles.WriteString(xml.Header + `<office:document-styles ` + odtNamespaces + ` office:version="1.3">`)
+	styles.WriteString(`<office:font-face-decls><style:font-face style:name="Malgun Gothic" svg:font-family="'Malgun Gothic'"/></office:font-face-decls>`)
+	styles.WriteString(`<office:styles>` + odtCommonStyles + odtListStyles() + `</office:styles>`)
+	styles.WriteString(o.masterStyles())
+	styles.WriteString(`</office:document-styles>`)
+
+	sort.Strings(o.manifest)
+	manifest := xml.Header + `<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.3">` +
+		`<manifest:file-entry manifest:full-path="/" manifest:version="1.3" manifest:media-type="` + odtMimeType + `"/>` +
+		`<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>` +
+		`<manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>` +
+		`<manifest:file-entry manifest:full-path="meta.xml" manifest:media-type="text/xml"/>` +
+		strings.Join(o.manifest, "") + `</manifest:manifest>`
+
+	entries := []ZipEntry{
+		{Name: "mimetype", Data: []byte(odtMimeType), Store: true},
+		{Name: "META-INF/manifest.xml", Data: []byte(manifest)},
+		{Name: "content.xml", Data: []byte(content.String())},
+		{Name: "styles.xml", Data: []byte(styles.String())},
+		{Name: "meta.xml", Data: []byte(o.meta())},
+	}
+	return append(entries, o.pictures...)
+}
+
+// odtListStyles returns the bullet list style L1 and the numbered list style L2
+func odtListStyles() string {
+	var styles strings.Builder
+	bullets := []string{"•", "◦", "▪"}
+	for i, numbered := range []bool{false, true} {
+		styles.WriteString(fmt.Sprintf(`<text:list-style style:name="L%d">`, i+1))
+		for level := 1; level <= 10; level++ {
+			properties := fmt.Sprintf(`<style:list-level-properties text:list-level-position-and-space-mode="label-alignment">`+
+				`<style:list-level-label-alignment text:label-followed-by="listtab" fo:text-indent="-18pt" fo:margin-left="%dpt"/></style:list-level-properties>`, 36*level)
+			if numbered {
+				styles.WriteString(fmt.Sprintf(`<text:list-level-style-number text:level="%d" style:num-suffix="." style:num-format="1">%s</text:list-level-style-number>`, level, properties))
+			} else {
+				styles.WriteString(fmt.Sprintf(`<text:list-level-style-bullet text:level="%d" text:bullet-char="%s">%s</text:list-level-style-bullet>`, level, bullets[(level-1)%len(bullets)], properties))
+			}
+		}
+		styles.WriteString(`</text:list-style>`)
+	}
+	return styles.String()
+}
@@ -0,0 +1,51 @@
+package writers
+
+import (
+	"archive/zip"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"myconverter/readers"
+)
+
+func TestODTRoundTrip(t *testing.T) {
+	content := testContent(t)
+	dir := t.TempDir()
+	if err := NewODTWriter(dir).WriteContent("out.odt", content); err != nil {
+		t.Fatalf("WriteContent() error = %v", err)
+	}
+	path := filepath.Join(dir, "out.odt")
+
+	// The mimetype entry comes first, uncompressed
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := archive.File[0]
+	if first.Name != "mimetype" || first.Method != zip.Store || len(first.Extra) > 0 {
+		t.Errorf("first entry = %s, method %d, want stored mimetype", first.Name, first.Method)
+	}
+	archive.Close()
+
+	read, err := readers.NewODTReader(path).ReadODT()
+	if err != nil {
+		t.Fatalf("ReadODT() error = %v", err)
+	}
+	// Headings are bold through their style
+	want := append([]string{`heading1 "Title"[b]`}, testContentBlocks[1:]...)
+	if got := describeBlocks(read.Blocks); !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks = %q, want %q", got, want)
+	}
+	if got, want := contentImages(read.Blocks), contentImages(content.Blocks); !reflect.DeepEqual(got, want) {
+		t.Errorf("images differ")
+	}
+	if size := read.Blocks[1].Runs[2].Size; size != 14 {
+		t.Errorf("run size = %v, want 14", size)
+	}
+	for key, value := range map[string]string{"Title": "Report", "Author": "Kim"} {
+		if got := read.Metadata[key]; got != value {
+			t.Errorf("metadata %s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,649 @@
+package writers
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// inheritedPageKeys are the page attributes a page takes from the page tree
+// above it when it has none of its own
+var inheritedPageKeys = []core.PdfObjectName{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+// pdfSource is an existing PDF document whose pages are copied
+type pdfSource struct {
+	path   string
+	file   io.Closer
+	reader *model.PdfReader
+	// pages are the page dictionaries in order, with the inherited
+	// attributes of each
+	pages     []core.PdfObject
+	inherited []map[core.PdfObjectName]core.PdfObject
+	// info is the document information dictionary, nil when absent
+	info core.PdfObject
+}
+
+// sourcePage is a page of a source document placed in a new document
+type sourcePage struct {
+	source *pdfSource
+	// index of the page in the source, from 0
+	index int
+	// rotation added to the page, in degrees clockwise
+	rotation int
+	// watermark drawn over the page, nil for none
+	watermark *Watermark
+}
+
+// openPDFSource opens a PDF file to copy pages from, decrypting documents
+// that open with an empty password
+func openPDFSource(path string) (*pdfSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF file: %v", err)
+	}
+	source, err := newPDFSource(path, file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	source.file = file
+	return source, nil
+}
+
+// newPDFSource reads a PDF document to copy pages from, named path in
+// errors
+func newPDFSource(path string, rs io.ReadSeeker) (*pdfSource, error) {
+	source := &pdfSource{path: path}
+	var err error
+	source.reader, err = model.NewPdfReader(rs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF file %s: %v", path, err)
+	}
+	if encrypted, err := source.reader.IsEncrypted(); err == nil && encrypted {
+		if ok, err := source.reader.Decrypt([]byte("")); err != nil || !ok {
+			return nil, fmt.Errorf("failed to decrypt PDF file %s: password required", path)
+		}
+	}
+
+	trailer, err := source.reader.GetTrailer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF file %s: %v", path, err)
+	}
+	source.info = trailer.Get("Info")
+	catalog, ok := core.GetDict(trailer.Get("Root"))
+	if !ok {
+		return nil, fmt.Errorf("failed to read PDF file %s: missing catalog", path)
+	}
+	source.addPages(catalog.Get("Pages"), nil, make(map[core.PdfObject]bool))
+	if len(source.pages) == 0 {
+		return nil, fmt.Errorf("failed to read PDF file %s: no pages", path)
+	}
+	return source, nil
+}
+
+// addPages adds the pages under a node of the page tree
+func (s *pdfSource) addPages(node core.PdfObject, inherited map[core.PdfObjectName]core.PdfObject, visited map[core.PdfObject]bool) {
+	node = resolveObject(node)
+	dict, ok := core.GetDict(node)
+	if !ok || visited[node] {
+		return
+	}
+	visited[node] = true
+
+	attributes := make(map[core.PdfObjectName]core.PdfObject, len(inheritedPageKeys))
+	for key, value := range inherited {
+		attributes[key] = value
+	}
+	for _, key := range inheritedPageKeys {
+		if value := dict.Get(key); value != nil {
+			attributes[key] = value
+		}
+	}
+
+	if name, _ := core.GetNameVal(dict.Get("Type")); name == "Page" || dict.Get("Kids") == nil {
+		s.pages = append(s.pages, node)
+		s.inherited = append(s.inherited, attributes)
+		return
+	}
+	if kids, ok := core.GetArray(dict.Get("Kids")); ok {
+		for _, kid := range kids.Elements() {
+			s.addPages(kid, attributes, visited)
+		}
+	}
+}
+
+// Close closes the source file
+func (s *pdfSource) Close() {
+	if s.file != nil {
+		s.file.Close()
+	}
+}
+
+// resolveObject returns the object a reference points to
+func resolveObject(obj core.PdfObject) core.PdfObject {
+	if ref, ok := obj.(*core.PdfObjectReference); ok {
+		return ref.Resolve()
+	}
+	return obj
+}
+
+// pdfOutline is a bookmark of a new document
+type pdfOutline struct {
+	title string
+	// page is the object number of the page the bookmark leads to, 0 for
+	// none, and dest the rest of its destination array
+	page     int
+	dest     string
+	children []*pdfOutline
+}
+
+// pdfBuilder writes the objects of a new PDF file, copying objects of
+// source documents under new object numbers
+type pdfBuilder struct {
+	out     bytes.Buffer
+	offsets map[int]int
+	next    int
+	// numbers are the new numbers of copied objects and pages those of the
+	// copied pages; other pages are left out
+	numbers map[core.PdfObject]int
+	pages   map[core.PdfObject]int
+	queue   []core.PdfObject
+	// overlays are the form objects of watermarks by page size
+	overlays map[overlayKey]int
+	// pdfa is the PDF/A level of the file, empty for none
+	pdfa string
+	// crypt encrypts strings and streams, nil when the file is not
+	// encrypted, and id identifies the file for it
+	crypt *pdfCrypt
+	id    []byte
+}
+
+// pdfOptions are the settings of a file written from source pages
+type pdfOptions struct {
+	// titles of sources whose bookmarks are gathered under a bookmark
+	titles map[*pdfSource]string
+	// pdfa is PDFA1B or PDFA2B to write a PDF/A file, empty for none
+	pdfa string
+	// encryption protects the file, nil for none
+	encryption *Encryption
+}
+
+// newPDFBuilder starts a new PDF file with options
+func newPDFBuilder(options pdfOptions) (*pdfBuilder, error) {
+	b := &pdfBuilder{
+		offsets:  make(map[int]int),
+		next:     1,
+		numbers:  make(map[core.PdfObject]int),
+		pages:    make(map[core.PdfObject]int),
+		overlays: make(map[overlayKey]int),
+		pdfa:     options.pdfa,
+	}
+	if options.encryption != nil {
+		if options.pdfa != "" {
+			return nil, fmt.Errorf("PDF/A does not allow encryption")
+		}
+		// The key depends on the file identifier, so it is chosen first
+		b.id = randomBytes(16)
+		var err error
+		if b.crypt, err = newPDFCrypt(options.encryption, b.id); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(&b.out, "%%PDF-%s\n%%\xe2\xe3\xcf\xd3\n", pdfaVersion(options.pdfa))
+	return b, nil
+}
+
+// reserve returns a new object number
+func (b *pdfBuilder) reserve() int {
+	b.next++
+	return b.next - 1
+}
+
+// writeObject writes an object with its body
+func (b *pdfBuilder) writeObject(number int, body string) {
+	b.offsets[number] = b.out.Len()
+	fmt.Fprintf(&b.out, "%d 0 obj\n%s\nendobj\n", number, b.seal(number, body))
+}
+
+// writeStream writes a stream object with its dictionary and data, which
+// is already encrypted
+func (b *pdfBuilder) writeStream(number int, dict string, data []byte) {
+	b.offsets[number] = b.out.Len()
+	fmt.Fprintf(&b.out, "%d 0 obj\n%s\nstream\n", number, b.seal(number, dict))
+	b.out.Write(data)
+	b.out.WriteString("\nendstream\nendobj\n")
+}
+
+// addStream writes a new stream of data with the entries of dict, which
+// excludes the length, and returns its number
+func (b *pdfBuilder) addStream(dict string, data []byte) int {
+	number := b.reserve()
+	data = b.encrypt(number, data)
+	b.writeStream(number, fmt.Sprintf("<<%s /Length %d>>", dict, len(data)), data)
+	return number
+}
+
+// sealedString matches the strings of an object body left to be encrypted
+var sealedString = regexp.MustCompile("\x00([0-9a-f]*)\x00")
+
+// str returns the text of a string in an object body. Strings of an
+// encrypted file are marked to be encrypted with the object they are
+// written in.
+func (b *pdfBuilder) str(s *core.PdfObjectString) string {
+	if b.crypt == nil {
+		return s.WriteString()
+	}
+	return "\x00" + hex.EncodeToString(s.Bytes()) + "\x00"
+}
+
+// text returns the text of a text string in an object body
+func (b *pdfBuilder) text(s string) string {
+	if b.crypt == nil {
+		return pdfTextString(s)
+	}
+	return b.str(core.MakeString(string(textStringBytes(s))))
+}
+
+// seal encrypts the marked strings of the body of an object
+func (b *pdfBuilder) seal(number int, body string) string {
+	if b.crypt == nil {
+		return body
+	}
+	return sealedString.ReplaceAllStringFunc(body, func(marked string) string {
+		data, _ := hex.DecodeString(marked[1 : len(marked)-1])
+		return fmt.Sprintf("<%X>", b.crypt.encrypt(number, data))
+	})
+}
+
+// encrypt encrypts the data of a stream of an encrypted file
+func (b *pdfBuilder) encrypt(number int, data []byte) []byte {
+	if b.crypt == nil {
+		return data
+	}
+	return b.crypt.encrypt(number, data)
+}
+
+// value returns the text of an object of a source document. Indirect
+// objects become references to copies, queued to be written, and pages
+// that are not copied become null.
+func (b *pdfBuilder) value(obj core.PdfObject) string {
+	obj = resolveObject(obj)
+	switch o := obj.(type) {
+	case nil:
+		return "null"
+	case *core.PdfIndirectObject, *core.PdfObjectStream:
+		if number, ok := b.pages[obj]; ok {
+			return fmt.Sprintf("%d 0 R", number)
+		}
+		if dict, ok := core.GetDict(obj); ok {
+			if name, _ := core.GetNameVal(dict.Get("Type")); name == "Page" || name == "Pages" {
+				return "null"
+			}
+		}
+		number, ok := b.numbers[obj]
+		if !ok {
+			number = b.reserve()
+			b.numbers[obj] = number
+			b.queue = append(b.queue, obj)
+		}
+		return fmt.Sprintf("%d 0 R", number)
+	case *core.PdfObjectDictionary:
+		return b.dict(o, nil)
+	case *core.PdfObjectArray:
+		parts := make([]string, 0, o.Len())
+		for _, element := range o.Elements() {
+			parts = append(parts, b.value(element))
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	case *core.PdfObjectString:
+		return b.str(o)
+	default:
+		return obj.WriteString()
+	}
+}
+
+// dict returns the text of a dictionary with some entries replaced by
+// already written values
+func (b *pdfBuilder) dict(dict *core.PdfObjectDictionary, replaced map[core.PdfObjectName]string) string {
+	if b.pdfa != "" {
+		replaced = b.pdfaEntries(dict, replaced)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<<")
+	for _, key := range dict.Keys() {
+		if _, ok := replaced[key]; ok {
+			continue
+		}
+		sb.WriteString(key.WriteString() + " " + b.value(dict.Get(key)) + " ")
+	}
+	names := make([]core.PdfObjectName, 0, len(replaced))
+	for name := range replaced {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	for _, key := range names {
+		sb.WriteString(key.WriteString() + " " + replaced[key] + " ")
+	}
+	return strings.TrimSuffix(sb.String(), " ") + ">>"
+}
+
+// flush writes the queued copies of source objects
+func (b *pdfBuilder) flush() {
+	for len(b.queue) > 0 {
+		obj := b.queue[0]
+		b.queue = b.queue[1:]
+		number := b.numbers[obj]
+		switch o := obj.(type) {
+		case *core.PdfObjectStream:
+			data := b.encrypt(number, o.Stream)
+			b.writeStream(number, b.dict(o.PdfObjectDictionary, map[core.PdfObjectName]string{"Length": strconv.Itoa(len(data))}), data)
+		case *core.PdfIndirectObject:
+			b.writeObject(number, b.value(o.PdfObject))
+		}
+	}
+}
+
+// writePages writes the page tree of the pages and returns the numbers of
+// the pages and the tree
+func (b *pdfBuilder) writePages(pages []sourcePage) ([]int, int, error) {
+	root := b.reserve()
+	numbers := make([]int, len(pages))
+	for i, page := range pages {
+		numbers[i] = b.reserve()
+		// A page placed twice is copied twice; links lead to the first
+		obj := page.source.pages[page.index]
+		if _, ok := b.pages[obj]; !ok {
+			b.pages[obj] = numbers[i]
+		}
+	}
+
+	kids := make([]string, len(pages))
+	for i, page := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", numbers[i])
+		dict, _ := core.GetDict(page.source.pages[page.index])
+		replaced := map[core.PdfObjectName]string{
+			"Type":   "/Page",
+			"Parent": fmt.Sprintf("%d 0 R", root),
+		}
+		for key, value := range page.source.inherited[page.index] {
+			replaced[key] = b.value(value)
+		}
+		if _, ok := replaced["MediaBox"]; !ok {
+			// A page without a size is taken as A4
+			replaced["MediaBox"] = "[0 0 595.28 841.89]"
+		}
+		rotation, _ := core.GetIntVal(resolveObject(page.source.inherited[page.index]["Rotate"]))
+		rotation = ((rotation+page.rotation)%360 + 360) % 360
+		if page.rotation != 0 {
+			replaced["Rotate"] = strconv.Itoa(rotation)
+		}
+		if page.watermark != nil {
+			if err := b.addWatermark(page, dict, rotation, replaced); err != nil {
+				return nil, 0, err
+			}
+		}
+		b.writeObject(numbers[i], b.dict(dict, replaced))
+	}
+	b.writeObject(root, fmt.Sprintf("<</Type /Pages /Kids [%s] /Count %d>>", strings.Join(kids, " "), len(pages)))
+	return numbers, root, nil
+}
+
+// writeOutlines writes the bookmark tree and returns its number, 0 when
+// there are no bookmarks
+func (b *pdfBuilder) writeOutlines(outlines []*pdfOutline) int {
+	if len(outlines) == 0 {
+		return 0
+	}
+	root := b.reserve()
+	first, last, count := b.writeOutlineItems(outlines, root)
+	b.writeObject(root, fmt.Sprintf("<</Type /Outlines /First %d 0 R /Last %d 0 R /Count %d>>", first, last, count))
+	return root
+}
+
+// writeOutlineItems writes bookmarks under a parent and returns the
+// numbers of the first and last and the count of all bookmarks written
+func (b *pdfBuilder) writeOutlineItems(outlines []*pdfOutline, parent int) (int, int, int) {
+	numbers := make([]int, len(outlines))
+	for i := range outlines {
+		numbers[i] = b.reserve()
+	}
+	count := len(outlines)
+	for i, outline := range outlines {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "<</Title %s /Parent %d 0 R", b.text(outline.title), parent)
+		if i > 0 {
+			fmt.Fprintf(&sb, " /Prev %d 0 R", numbers[i-1])
+		}
+		if i < len(outlines)-1 {
+			fmt.Fprintf(&sb, " /Next %d 0 R", numbers[i+1])
+		}
+		if outline.page != 0 {
+			fmt.Fprintf(&sb, " /Dest [%d 0 R %s]", outline.page, outline.dest)
+		}
+		if len(outline.children) > 0 {
+			first, last, n := b.writeOutlineItems(outline.children, numbers[i])
+			fmt.Fprintf(&sb, " /First %d 0 R /Last %d 0 R /Count %d", first, last, n)
+			count += n
+		}
+		sb.WriteString(">>")
+		b.writeObject(numbers[i], sb.String())
+	}
+	return numbers[0], numbers[len(numbers)-1], count
+}
+
+// finish writes the catalog, cross-reference table and trailer with the
+// document information info, which may be nil, and returns the file data
+func (b *pdfBuilder) finish(pages, outlines int, info core.PdfObject) []byte {
+	catalog := b.reserve()
+	body := fmt.Sprintf("<</Type /Catalog /Pages %d 0 R", pages)
+	if outlines != 0 {
+		body += fmt.Sprintf(" /Outlines %d 0 R /PageMode /UseOutlines", outlines)
+	}
+	infoRef := ""
+	if b.pdfa != "" {
+		var entries string
+		entries, infoRef = b.writePDFA(info)
+		body += " " + entries
+	} else if info != nil {
+		infoRef = b.value(info)
+	}
+	if b.crypt != nil && b.crypt.method == EncryptAES256 {
+		body += " /Extensions <</ADBE <</BaseVersion /1.7 /ExtensionLevel 8>>>>"
+	}
+	b.writeObject(catalog, body+">>")
+	b.flush()
+	encrypt := 0
+	if b.crypt != nil {
+		encrypt = b.reserve()
+		b.writeObject(encrypt, b.crypt.dict)
+	} else {
+		sum := md5.Sum(b.out.Bytes())
+		b.id = sum[:]
+	}
+
+	xref := b.out.Len()
+	fmt.Fprintf(&b.out, "xref\n0 %d\n0000000000 65535 f \n", b.next)
+	for number := 1; number < b.next; number++ {
+		if offset, ok := b.offsets[number]; ok {
+			fmt.Fprintf(&b.out, "%010d 00000 n \n", offset)
+		} else {
+			b.out.WriteString("0000000000 65535 f \n")
+		}
+	}
+	fmt.Fprintf(&b.out, "trailer\n<</Size %d /Root %d 0 R", b.next, catalog)
+	if infoRef != "" {
+		fmt.Fprintf(&b.out, " /Info %s", infoRef)
+	}
+	if encrypt != 0 {
+		fmt.Fprintf(&b.out, " /Encrypt %d 0 R", encrypt)
+	}
+	fmt.Fprintf(&b.out, " /ID [<%X> <%X>]>>\nstartxref\n%d\n%%%%EOF\n", b.id, b.id, xref)
+	return b.out.Bytes()
+}
+
+// pdfTextString returns a PDF text string, in UTF-16 when it is not ASCII
+func pdfTextString(s string) string {
+	data := textStringBytes(s)
+	if len(data) == len(s) {
+		return core.MakeString(s).WriteString()
+	}
+	return fmt.Sprintf("<%X>", data)
+}
+
+// textStringBytes returns the bytes of a PDF text string, ASCII or UTF-16
+// with a byte order mark
+func textStringBytes(s string) []byte {
+	ascii := true
+	for _, r := range s {
+		if r >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return []byte(s)
+	}
+	data := []byte{0xfe, 0xff}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		data = append(data, byte(unit>>8), byte(unit))
+	}
+	return data
+}
+
+// sourceOutlines returns the bookmarks of a source leading to the placed
+// pages. Bookmarks of pages left out are dropped, keeping their children.
+func sourceOutlines(source *pdfSource, placed map[int]int) []*pdfOutline {
+	outline, err := source.reader.GetOutlines()
+	if err != nil || outline == nil {
+		return nil
+	}
+	return convertOutlines(outline.Entries, placed)
+}
+
+// convertOutlines converts bookmarks read from a source
+func convertOutlines(items []*model.OutlineItem, placed map[int]int) []*pdfOutline {
+	var outlines []*pdfOutline
+	for _, item := range items {
+		children := convertOutlines(item.Entries, placed)
+		// Bookmarks without a page destination, such as links, read as page 0
+		page, ok := placed[int(item.Dest.Page)]
+		if item.Dest.PageObj == nil {
+			ok = false
+		}
+		if !ok {
+			if item.Dest.PageObj == nil && len(children) > 0 {
+				outlines = append(outlines, &pdfOutline{title: item.Title, children: children})
+			} else {
+				outlines = append(outlines, children...)
+			}
+			continue
+		}
+		outlines = append(outlines, &pdfOutline{
+			title:    item.Title,
+			page:     page,
+			dest:     outlineDest(item.Dest),
+			children: children,
+		})
+	}
+	return outlines
+}
+
+// outlineDest returns the view part of a bookmark destination
+func outlineDest(dest model.OutlineDest) string {
+	number := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	switch dest.Mode {
+	case "XYZ":
+		if dest.X == 0 && dest.Y == 0 {
+			return "/XYZ null null null"
+		}
+		zoom := "null"
+		if dest.Zoom != 0 {
+			zoom = number(dest.Zoom)
+		}
+		return fmt.Sprintf("/XYZ %s %s %s", number(dest.X), number(dest.Y), zoom)
+	case "FitH", "FitBH":
+		return fmt.Sprintf("/%s %s", dest.Mode, number(dest.Y))
+	case "FitV", "FitBV":
+		return fmt.Sprintf("/%s %s", dest.Mode, number(dest.X))
+	case "FitB":
+		return "/FitB"
+	default:
+		return "/Fit"
+	}
+}
+
+// writeSourcePages writes a PDF file of pages from source documents with
+// the bookmarks leading to them and the document information of the
+// first source. The bookmarks of sources with a title are gathered under
+// a bookmark of that title leading to their first page. A PDF/A file is
+// checked once written and not saved when it breaks the requirements.
+func writeSourcePages(outputPath string, pages []sourcePage, options pdfOptions) error {
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages to write")
+	}
+	b, err := newPDFBuilder(options)
+	if err != nil {
+		return err
+	}
+	numbers, root, err := b.writePages(pages)
+	if err != nil {
+		return err
+	}
+
+	// Bookmarks follow the order of the sources, leading to the first
+	// placement of each page
+	var outlines []*pdfOutline
+	placed := make(map[*pdfSource]map[int]int)
+	first := make(map[*pdfSource]int)
+	var sources []*pdfSource
+	for i, page := range pages {
+		if placed[page.source] == nil {
+			placed[page.source] = make(map[int]int)
+			first[page.source] = numbers[i]
+			sources = append(sources, page.source)
+		}
+		if _, ok := placed[page.source][page.index]; !ok {
+			placed[page.source][page.index] = numbers[i]
+		}
+	}
+	for _, source := range sources {
+		children := sourceOutlines(source, placed[source])
+		if title, ok := options.titles[source]; ok {
+			outlines = append(outlines, &pdfOutline{title: title, page: first[source], dest: "/Fit", children: children})
+		} else {
+			outlines = append(outlines, children...)
+		}
+	}
+
+	data := b.finish(root, b.writeOutlines(outlines), pages[0].source.info)
+	if options.pdfa != "" {
+		report, err := validatePDFA(data, options.pdfa)
+		if err != nil {
+			return err
+		}
+		if len(report.Violations) > 0 {
+			return fmt.Errorf("failed to write PDF/A-%s: %s", strings.ToUpper(options.pdfa), strings.Join(report.Violations, "; "))
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save PDF: %v", err)
+	}
+	return nil
+}
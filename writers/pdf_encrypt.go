@@ -0,0 +1,286 @@
+package writers
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Encryption methods of PDF files
+const (
+	EncryptAES128 = "aes128"
+	EncryptAES256 = "aes256"
+)
+
+// Encryption protects written PDF files with passwords and permissions.
+// Readers opening the file with the user password may only do what the
+// permissions allow; the owner password allows everything.
+type Encryption struct {
+	// UserPassword is asked to open the file; the file opens without one
+	// when empty, with the permissions still applied
+	UserPassword string
+	// OwnerPassword lifts the permissions; a random one nobody knows is
+	// used when empty
+	OwnerPassword string
+	// Method is EncryptAES128 or EncryptAES256, EncryptAES256 when empty
+	Method string
+	// Permissions granted with the user password
+	AllowPrint    bool
+	AllowCopy     bool
+	AllowModify   bool
+	AllowAnnotate bool
+}
+
+// NewEncryption creates an AES-256 encryption with passwords that grants
+// no permissions
+func NewEncryption(userPassword, ownerPassword string) *Encryption {
+	return &Encryption{
+		UserPassword:  userPassword,
+		OwnerPassword: ownerPassword,
+		Method:        EncryptAES256,
+	}
+}
+
+// IsEncryptionMethod reports whether a method is EncryptAES128 or
+// EncryptAES256
+func IsEncryptionMethod(method string) bool {
+	return method == EncryptAES128 || method == EncryptAES256
+}
+
+// permissions returns the P entry of the encryption dictionary. Extracting
+// text for accessibility is always allowed; printing includes high quality
+// printing, modifying includes assembling pages and annotating includes
+// filling in forms.
+func (e *Encryption) permissions() int32 {
+	// Reserved bits 7, 8 and 13 to 32 are set
+	p := uint32(0xfffff0c0) | 1<<9
+	if e.AllowPrint {
+		p |= 1<<2 | 1<<11
+	}
+	if e.AllowModify {
+		p |= 1<<3 | 1<<10
+	}
+	if e.AllowCopy {
+		p |= 1 << 4
+	}
+	if e.AllowAnnotate {
+		p |= 1<<5 | 1<<8
+	}
+	return int32(p)
+}
+
+// Protect writes a copy of an existing PDF file encrypted with the writer's
+// encryption, keeping its pages, bookmarks and document information
+func (w *PDFWriter) Protect(inputPath, outputPath string) error {
+	if w.Encryption == nil {
+		return fmt.Errorf("no encryption to protect with")
+	}
+	source, err := openPDFSource(inputPath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	return writeSourcePages(w.outputFile(outputPath), selectPages(source, nil), w.options())
+}
+
+// passwordPadding pads passwords of AES-128 encryption
+var passwordPadding = []byte{
+	0x28, 0xbf, 0x4e, 0x5e, 0x4e, 0x75, 0x8a, 0x41, 0x64, 0x00, 0x4e, 0x56, 0xff, 0xfa, 0x01, 0x08,
+	0x2e, 0x2e, 0x00, 0xb6, 0xd0, 0x68, 0x3e, 0x80, 0x2f, 0x0c, 0xa9, 0xfe, 0x64, 0x53, 0x69, 0x7a,
+}
+
+// pdfCrypt encrypts the strings and streams of a PDF file
+type pdfCrypt struct {
+	method string
+	// key is the file encryption key
+	key []byte
+	// dict is the encryption dictionary
+	dict string
+}
+
+// newPDFCrypt derives the keys of an encryption for a file identified by
+// id with the standard security handler, revision 4 for AES-128 and
+// revision 6 for AES-256
+func newPDFCrypt(e *Encryption, id []byte) (*pdfCrypt, error) {
+	method := e.Method
+	if method == "" {
+		method = EncryptAES256
+	}
+	if !IsEncryptionMethod(method) {
+		return nil, fmt.Errorf("unknown encryption method: %s", e.Method)
+	}
+	owner := e.OwnerPassword
+	if owner == "" {
+		owner = hex.EncodeToString(randomBytes(16))
+	}
+	p := e.permissions()
+	c := &pdfCrypt{method: method}
+
+	if method == EncryptAES128 {
+		user, ownerPadded := padPassword(e.UserPassword), padPassword(owner)
+
+		// The owner entry holds the user password encrypted with the owner password
+		h := md5.Sum(ownerPadded)
+		for i := 0; i < 50; i++ {
+			h = md5.Sum(h[:])
+		}
+		o := rc4Rounds(h[:], user)
+
+		digest := md5.New()
+		digest.Write(user)
+		digest.Write(o)
+		binary.Write(digest, binary.LittleEndian, p)
+		digest.Write(id)
+		c.key = digest.Sum(nil)
+		for i := 0; i < 50; i++ {
+			sum := md5.Sum(c.key)
+			c.key = sum[:]
+		}
+
+		// The user entry holds the padding encrypted with the file key
+		h = md5.Sum(append(append([]byte{}, passwordPadding...), id...))
+		u := append(rc4Rounds(c.key, h[:]), make([]byte, 16)...)
+
+		c.dict = fmt.Sprintf("<</Filter /Standard /V 4 /R 4 /Length 128 /CF <</StdCF <</CFM /AESV2 /AuthEvent /DocOpen /Length 16>>>> /StmF /StdCF /StrF /StdCF /O <%X> /U <%X> /P %d>>", o, u, p)
+		return c, nil
+	}
+
+	c.key = randomBytes(32)
+	userBytes, ownerBytes := utf8Password(e.UserPassword), utf8Password(owner)
+
+	salts := randomBytes(16)
+	u := append(hashPassword(userBytes, salts[:8], nil), salts...)
+	ue := encryptKey(hashPassword(userBytes, salts[8:], nil), c.key)
+
+	salts = randomBytes(16)
+	o := append(hashPassword(ownerBytes, salts[:8], u), salts...)
+	oe := encryptKey(hashPassword(ownerBytes, salts[8:], u), c.key)
+
+	// The permissions are kept encrypted too, so they cannot be changed
+	// without the key
+	perms := make([]byte, 16)
+	binary.LittleEndian.PutUint32(perms, uint32(p))
+	copy(perms[4:], "\xff\xff\xff\xffTadb")
+	copy(perms[12:], randomBytes(4))
+	block, _ := aes.NewCipher(c.key)
+	block.Encrypt(perms, perms)
+
+	c.dict = fmt.Sprintf("<</Filter /Standard /V 5 /R 6 /Length 256 /CF <</StdCF <</CFM /AESV3 /AuthEvent /DocOpen /Length 32>>>> /StmF /StdCF /StrF /StdCF /O <%X> /U <%X> /OE <%X> /UE <%X> /Perms <%X> /P %d>>", o, u, oe, ue, perms, p)
+	return c, nil
+}
+
+// encrypt encrypts the data of a string or stream in an object with a
+// random initialization vector written before it
+func (c *pdfCrypt) encrypt(number int, data []byte) []byte {
+	key := c.key
+	if c.method == EncryptAES128 {
+		// AES-128 uses a key of each object
+		digest := md5.New()
+		digest.Write(key)
+		digest.Write([]byte{byte(number), byte(number >> 8), byte(number >> 16), 0, 0})
+		digest.Write([]byte("sAlT"))
+		key = digest.Sum(nil)
+	}
+
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	padded := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	out := append(randomBytes(aes.BlockSize), make([]byte, len(padded))...)
+	block, _ := aes.NewCipher(key)
+	cipher.NewCBCEncrypter(block, out[:aes.BlockSize]).CryptBlocks(out[aes.BlockSize:], padded)
+	return out
+}
+
+// padPassword returns a password of AES-128 encryption padded to 32 bytes
+func padPassword(password string) []byte {
+	padded := []byte(password)
+	if len(padded) > 32 {
+		padded = padded[:32]
+	}
+	return append(padded, passwordPadding[:32-len(padded)]...)
+}
+
+// rc4Rounds encrypts data with RC4 20 times, the key of each round
+// combined with the round number
+func rc4Rounds(key, data []byte) []byte {
+	out := append([]byte{}, data...)
+	roundKey := make([]byte, len(key))
+	for i := 0; i < 20; i++ {
+		for j := range key {
+			roundKey[j] = key[j] ^ byte(i)
+		}
+		stream, _ := rc4.NewCipher(roundKey)
+		stream.XORKeyStream(out, out)
+	}
+	return out
+}
+
+// utf8Password returns a password of AES-256 encryption, at most 127 bytes
+// of UTF-8
+func utf8Password(password string) []byte {
+	data := []byte(password)
+	if len(data) > 127 {
+		data = []byte(strings.ToValidUTF8(string(data[:127]), ""))
+	}
+	return data
+}
+
+// hashPassword returns the hash of a password with a salt and the user
+// entry for AES-256 encryption, which runs rounds of AES and SHA-2 to make
+// guessing passwords slow
+func hashPassword(password, salt, user []byte) []byte {
+	sum := sha256.Sum256(bytes.Join([][]byte{password, salt, user}, nil))
+	k := sum[:]
+	for round := 1; ; round++ {
+		k1 := bytes.Repeat(bytes.Join([][]byte{password, k, user}, nil), 64)
+		block, _ := aes.NewCipher(k[:16])
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+
+		// The first 16 bytes taken as a number modulo 3 pick the hash
+		remainder := 0
+		for _, b := range e[:16] {
+			remainder += int(b)
+		}
+		var h hash.Hash
+		switch remainder % 3 {
+		case 0:
+			h = sha256.New()
+		case 1:
+			h = sha512.New384()
+		default:
+			h = sha512.New()
+		}
+		h.Write(e)
+		k = h.Sum(nil)
+
+		if round >= 64 && int(e[len(e)-1]) <= round-32 {
+			break
+		}
+	}
+	return k[:32]
+}
+
+// encryptKey encrypts the file key with a key derived from a password
+func encryptKey(key, fileKey []byte) []byte {
+	out := make([]byte, len(fileKey))
+	block, _ := aes.NewCipher(key)
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(out, fileKey)
+	return out
+}
+
+// randomBytes returns n random bytes
+func randomBytes(n int) []byte {
+	data := make([]byte, n)
+	rand.Read(data)
+	return data
+}
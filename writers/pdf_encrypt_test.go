@@ -0,0 +1,196 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unipdf/v3/common/license"
+	"github.com/unidoc/unipdf/v3/core/security"
+	"github.com/unidoc/unipdf/v3/model"
+	"myconverter/readers"
+)
+
+// pageContent returns the content of the first page of a PDF file,
+// decrypted with password when the file is encrypted
+func pageContent(t *testing.T, path, password string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted, _ := reader.IsEncrypted(); encrypted {
+		if ok, err := reader.Decrypt([]byte(password)); err != nil || !ok {
+			t.Fatalf("failed to decrypt with %q: %v", password, err)
+		}
+	}
+	page, err := reader.GetPage(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestProtect(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestPDF(t, dir, 2)
+	want := pageContent(t, input, "")
+
+	tests := []struct {
+		name       string
+		encryption Encryption
+		method     string
+		user       security.Permissions
+	}{
+		{
+			name:       "aes128",
+			encryption: Encryption{UserPassword: "user", OwnerPassword: "owner", Method: EncryptAES128, AllowPrint: true},
+			method:     "AESV2",
+			user:       security.PermPrinting | security.PermFullPrintQuality | security.PermDisabilityExtract,
+		},
+		{
+			name:       "aes256",
+			encryption: Encryption{UserPassword: "user", OwnerPassword: "owner", Method: EncryptAES256, AllowCopy: true, AllowAnnotate: true},
+			method:     "AESV3",
+			user:       security.PermExtractGraphics | security.PermAnnotate | security.PermFillForms | security.PermDisabilityExtract,
+		},
+		{
+			name:       "aes256 non-ASCII passwords",
+			encryption: Encryption{UserPassword: "사용자", OwnerPassword: "소유자", AllowModify: true},
+			method:     "AESV3",
+			user:       security.PermModify | security.PermRotateInsert | security.PermDisabilityExtract,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewPDFWriter(dir)
+			w.Encryption = &tt.encryption
+			output := filepath.Join(dir, "protected.pdf")
+			if err := w.Protect(input, output); err != nil {
+				t.Fatalf("Protect() error = %v", err)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader, err := model.NewPdfReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if method := reader.GetEncryptionMethod(); !strings.Contains(method, tt.method) {
+				t.Errorf("encryption method = %s, want %s", method, tt.method)
+			}
+			if ok, _, _ := reader.CheckAccessRights([]byte("wrong")); ok {
+				t.Errorf("wrong password accepted")
+			}
+			ok, perms, err := reader.CheckAccessRights([]byte(tt.encryption.UserPassword))
+			if err != nil || !ok {
+				t.Fatalf("user password rejected: %v", err)
+			}
+			// Reserved bits are always set
+			if got := perms &^ 0xfffff0c0; got != tt.user {
+				t.Errorf("user permissions = %b, want %b", got, tt.user)
+			}
+			ok, perms, err = reader.CheckAccessRights([]byte(tt.encryption.OwnerPassword))
+			if err != nil || !ok {
+				t.Fatalf("owner password rejected: %v", err)
+			}
+			if perms != security.PermOwner {
+				t.Errorf("owner permissions = %b, want all", perms)
+			}
+
+			for _, password := range []string{tt.encryption.UserPassword, tt.encryption.OwnerPassword} {
+				if got := pageContent(t, output, password); got != want {
+					t.Errorf("content decrypted with %q = %q, want %q", password, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProtectEmptyUserPassword(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestPDF(t, dir, 1)
+	w := NewPDFWriter(dir)
+	w.Encryption = NewEncryption("", "owner")
+	output := filepath.Join(dir, "protected.pdf")
+	if err := w.Protect(input, output); err != nil {
+		t.Fatalf("Protect() error = %v", err)
+	}
+	if got, want := pageContent(t, output, ""), pageContent(t, input, ""); got != want {
+		t.Errorf("content opened without password = %q, want %q", got, want)
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	// Known answers of algorithm 2.B computed by an independent
+	// implementation of ISO 32000-2
+	tests := []struct {
+		password string
+		salt     string
+		user     string
+		want     string
+	}{
+		{
+			password: "user",
+			salt:     "0001020304050607",
+			want:     "731758c09c8b0160a34721d18bdd24220abada0070aa3f05b8103fd5b8d05f17",
+		},
+		{
+			// The owner hash takes in the user entry
+			password: "소유자",
+			salt:     "f0e1d2c3b4a59687",
+			user:     "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f",
+			want:     "19d615d50be9e6324a3da940fabf001c5a937f4f51c13865bacf459caa7f8e82",
+		},
+	}
+	for _, tt := range tests {
+		salt, _ := hex.DecodeString(tt.salt)
+		user, _ := hex.DecodeString(tt.user)
+		got := hex.EncodeToString(hashPassword([]byte(tt.password), salt, user))
+		if got != tt.want {
+			t.Errorf("hashPassword(%q, %s, %s) = %s, want %s", tt.password, tt.salt, tt.user, got, tt.want)
+		}
+	}
+}
+
+func TestNewPDFCryptUnknownMethod(t *testing.T) {
+	if _, err := newPDFCrypt(&Encryption{Method: "rc4"}, make([]byte, 16)); err == nil {
+		t.Error("newPDFCrypt() accepted an unknown method")
+	}
+}
+
+func TestReadTablesProtected(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestPDF(t, dir, 1)
+	w := NewPDFWriter(dir)
+	w.Encryption = &Encryption{OwnerPassword: "owner"}
+	output := filepath.Join(dir, "protected.pdf")
+	if err := w.Protect(input, output); err != nil {
+		t.Fatalf("Protect() error = %v", err)
+	}
+
+	// The pages are read once the document is decrypted; without a unipdf
+	// license detection stops after that
+	_, err := readers.NewPDFReader(output).ReadTables()
+	if license.GetLicenseKey().IsLicensed() {
+		if err != nil {
+			t.Errorf("ReadTables() error = %v", err)
+		}
+	} else if err == nil || !strings.Contains(err.Error(), "license") {
+		t.Errorf("ReadTables() error = %v, want a license error", err)
+	}
+}
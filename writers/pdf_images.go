@@ -0,0 +1,170 @@
+package writers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"github.com/signintech/gopdf"
+	"myconverter/interfaces"
+)
+
+// imagePages returns the images of content made only of images and page
+// breaks, such as scanned pages, or nil when it has other blocks
+func imagePages(content *interfaces.PDFContent) []*interfaces.Image {
+	var images []*interfaces.Image
+	for _, block := range content.Blocks {
+		switch {
+		case block.Kind == interfaces.BlockImage && block.Image != nil && len(block.Image.Data) > 0:
+			images = append(images, block.Image)
+		case block.Kind == interfaces.BlockPageBreak:
+		default:
+			return nil
+		}
+	}
+	return images
+}
+
+// writeImagePages writes each image on its own page. With ImageDPI set the
+// page takes the size of the image at that resolution, otherwise the image
+// is fitted and centered on a page of PageSize turned to the image's
+// orientation.
+func (w *PDFWriter) writeImagePages(outputPath string, images []*interfaces.Image) error {
+	watermark, err := w.watermark()
+	if err != nil {
+		return err
+	}
+	pdf := gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *w.PageSize})
+	if watermark != nil && watermark.Text != "" {
+		if err := addPDFFont(&pdf); err != nil {
+			return err
+		}
+	}
+
+	for i, img := range images {
+		data, width, height, err := pdfImageData(img.Data, w.PDFA == PDFA1B)
+		if err != nil {
+			return fmt.Errorf("failed to embed image %s: %v", img.Name, err)
+		}
+		// Quarter turns swap the displayed sides
+		if img.Orientation >= 5 && img.Orientation <= 8 {
+			width, height = height, width
+		}
+
+		var page, box gopdf.Rect
+		var x, y float64
+		if w.ImageDPI > 0 {
+			page = gopdf.Rect{W: width * 72 / w.ImageDPI, H: height * 72 / w.ImageDPI}
+			box = page
+		} else {
+			page = *w.PageSize
+			if (width > height) != (page.W > page.H) {
+				page.W, page.H = page.H, page.W
+			}
+			scale := math.Min(page.W/width, page.H/height)
+			box = gopdf.Rect{W: width * scale, H: height * scale}
+			x, y = (page.W-box.W)/2, (page.H-box.H)/2
+		}
+		pdf.AddPageWithOption(gopdf.PageOption{PageSize: &page})
+
+		holder, err := gopdf.ImageHolderByBytes(data)
+		if err != nil {
+			return fmt.Errorf("failed to embed image %s: %v", img.Name, err)
+		}
+		if err := pdf.ImageByHolderWithOptions(holder, orientedImageOptions(img.Orientation, x, y, box)); err != nil {
+			return fmt.Errorf("failed to draw image %d: %v", i+1, err)
+		}
+		if watermark != nil {
+			if err := watermark.drawPDF(&pdf, page); err != nil {
+				return err
+			}
+		}
+	}
+
+	return w.save(&pdf, outputPath)
+}
+
+// pdfImageData returns image data the PDF can embed, with its pixel size.
+// JPEG and 8-bit non-interlaced PNG data is embedded unchanged, other
+// images are converted to PNG. Opaque images have any transparency
+// flattened onto white.
+func pdfImageData(data []byte, opaque bool) ([]byte, float64, float64, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	width, height := float64(config.Width), float64(config.Height)
+
+	switch format {
+	case "jpeg":
+		return data, width, height, nil
+	case "png":
+		// IHDR holds the bit depth at byte 24 and the interlace method at byte 28
+		if len(data) > 28 && data[24] <= 8 && data[28] == 0 && !(opaque && pngTransparent(data)) {
+			return data, width, height, nil
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	rgba := image.NewNRGBA(img.Bounds())
+	if opaque {
+		draw.Draw(rgba, rgba.Bounds(), image.White, image.Point{}, draw.Src)
+		draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Over)
+	} else {
+		draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, rgba); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), width, height, nil
+}
+
+// pngTransparent reports whether PNG data has an alpha channel or a
+// transparent color
+func pngTransparent(data []byte) bool {
+	// IHDR holds the color type at byte 25; types 4 and 6 carry alpha
+	if data[25] == 4 || data[25] == 6 {
+		return true
+	}
+	return bytes.Contains(data, []byte("tRNS"))
+}
+
+// orientedImageOptions returns the options drawing image data stored with
+// an EXIF orientation upright in a box at x, y. The stored image is placed
+// around the box center, then flipped and turned counterclockwise.
+func orientedImageOptions(orientation int, x, y float64, box gopdf.Rect) gopdf.ImageOptions {
+	opts := gopdf.ImageOptions{X: x, Y: y, Rect: &gopdf.Rect{W: box.W, H: box.H}}
+	if orientation >= 5 && orientation <= 8 {
+		opts.X = x + (box.W-box.H)/2
+		opts.Y = y + (box.H-box.W)/2
+		opts.Rect = &gopdf.Rect{W: box.H, H: box.W}
+	}
+
+	switch orientation {
+	case 2:
+		opts.HorizontalFlip = true
+	case 3:
+		opts.DegreeAngle = 180
+	case 4:
+		opts.VerticalFlip = true
+	case 5:
+		opts.HorizontalFlip = true
+		opts.DegreeAngle = 90
+	case 6:
+		opts.DegreeAngle = 270
+	case 7:
+		opts.HorizontalFlip = true
+		opts.DegreeAngle = 270
+	case 8:
+		opts.DegreeAngle = 90
+	}
+	return opts
+}
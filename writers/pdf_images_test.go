@@ -0,0 +1,138 @@
+package writers
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unidoc/unipdf/v3/model"
+	"golang.org/x/image/tiff"
+
+	"myconverter/interfaces"
+)
+
+// pageSizes returns the media box size of each page of a PDF file in points
+func pageSizes(t *testing.T, path string) [][2]float64 {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sizes [][2]float64
+	for _, page := range reader.PageList {
+		box, err := page.GetMediaBox()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, [2]float64{box.Width(), box.Height()})
+	}
+	return sizes
+}
+
+func TestWriteImagePages(t *testing.T) {
+	upright := testImages(t, 1)[0]
+	turned := &interfaces.Image{Name: "turned.png", Data: upright.Data, Orientation: 6}
+	content := &interfaces.PDFContent{Blocks: []interfaces.Block{
+		{Kind: interfaces.BlockImage, Image: upright},
+		{Kind: interfaces.BlockPageBreak},
+		{Kind: interfaces.BlockImage, Image: turned},
+	}}
+
+	tests := []struct {
+		name     string
+		imageDPI float64
+		want     [][2]float64
+	}{
+		// Pages are turned to the orientation of the displayed image
+		{name: "fit to A4", want: [][2]float64{{842, 595}, {595, 842}}},
+		// 20x10 pixels at 144 DPI are 10x5 points
+		{name: "page sized to the image", imageDPI: 144, want: [][2]float64{{10, 5}, {5, 10}}},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		w := NewPDFWriter(dir)
+		w.ImageDPI = tt.imageDPI
+		if err := w.WriteContent("out.pdf", content); err != nil {
+			t.Fatalf("%s: WriteContent() error = %v", tt.name, err)
+		}
+		got := pageSizes(t, filepath.Join(dir, "out.pdf"))
+		for i := range got {
+			for j := range got[i] {
+				got[i][j] = float64(int(got[i][j]*100+0.5)) / 100
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: page sizes = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPDFImageData(t *testing.T) {
+	img := image.NewNRGBA64(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 128})
+	encode := func(encode func(*bytes.Buffer) error) []byte {
+		var buf bytes.Buffer
+		if err := encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	jpegData := encode(func(buf *bytes.Buffer) error { return jpeg.Encode(buf, img, nil) })
+	png16 := encode(func(buf *bytes.Buffer) error { return png.Encode(buf, img) })
+	png8 := encode(func(buf *bytes.Buffer) error { return png.Encode(buf, image.NewGray(img.Bounds())) })
+	tiffData := encode(func(buf *bytes.Buffer) error { return tiff.Encode(buf, img, nil) })
+
+	tests := []struct {
+		name      string
+		data      []byte
+		opaque    bool
+		unchanged bool
+		alpha     bool
+	}{
+		{name: "jpeg passes through", data: jpegData, unchanged: true},
+		{name: "8-bit png passes through", data: png8, unchanged: true},
+		{name: "16-bit png", data: png16, alpha: true},
+		{name: "16-bit png flattened", data: png16, opaque: true},
+		{name: "tiff", data: tiffData, alpha: true},
+	}
+	for _, tt := range tests {
+		data, width, height, err := pdfImageData(tt.data, tt.opaque)
+		if err != nil {
+			t.Errorf("%s: pdfImageData() error = %v", tt.name, err)
+			continue
+		}
+		if width != 4 || height != 3 {
+			t.Errorf("%s: size = %vx%v, want 4x3", tt.name, width, height)
+		}
+		if unchanged := bytes.Equal(data, tt.data); unchanged != tt.unchanged {
+			t.Errorf("%s: data unchanged = %v, want %v", tt.name, unchanged, tt.unchanged)
+		}
+		if tt.unchanged {
+			continue
+		}
+		// Converted images are 8-bit PNG
+		decoded, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: converted data is not PNG: %v", tt.name, err)
+			continue
+		}
+		_, _, _, a := decoded.At(1, 1).RGBA()
+		if alpha := a != 0xFFFF; alpha != tt.alpha {
+			t.Errorf("%s: pixel 1,1 = %v, want transparency %v", tt.name, decoded.At(1, 1), tt.alpha)
+		}
+		if data[24] != 8 {
+			t.Errorf("%s: bit depth = %d, want 8", tt.name, data[24])
+		}
+	}
+}
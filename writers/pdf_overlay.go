@@ -0,0 +1,156 @@
+package writers
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/signintech/gopdf"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// watermarkXObject is the resource name of the watermark form on copied pages
+const watermarkXObject = "MCWatermark"
+
+// overlayKey identifies the watermark form of a page size
+type overlayKey struct {
+	watermark     *Watermark
+	width, height float64
+}
+
+// addWatermark draws the watermark of a copied page over its content,
+// replacing its resources and contents. rotation is the page rotation in
+// degrees clockwise, which the mark is turned against to stay upright.
+func (b *pdfBuilder) addWatermark(page sourcePage, dict *core.PdfObjectDictionary, rotation int, replaced map[core.PdfObjectName]string) error {
+	inherited := page.source.inherited[page.index]
+	box := inherited["CropBox"]
+	if box == nil {
+		box = inherited["MediaBox"]
+	}
+	llx, lly, urx, ury := 0.0, 0.0, 595.28, 841.89
+	if array, ok := core.GetArray(box); ok && array.Len() == 4 {
+		if values, err := array.ToFloat64Array(); err == nil {
+			llx, urx = math.Min(values[0], values[2]), math.Max(values[0], values[2])
+			lly, ury = math.Min(values[1], values[3]), math.Max(values[1], values[3])
+		}
+	}
+	width, height := urx-llx, ury-lly
+
+	// The mark is drawn on a page of the displayed size, then turned and
+	// moved onto the page box
+	displayed := gopdf.Rect{W: width, H: height}
+	matrix := []float64{1, 0, 0, 1, 0, 0}
+	switch rotation {
+	case 90:
+		displayed = gopdf.Rect{W: height, H: width}
+		matrix = []float64{0, 1, -1, 0, width, 0}
+	case 180:
+		matrix = []float64{-1, 0, 0, -1, width, height}
+	case 270:
+		displayed = gopdf.Rect{W: height, H: width}
+		matrix = []float64{0, -1, 1, 0, 0, height}
+	}
+	matrix[4] += llx
+	matrix[5] += lly
+
+	form, err := b.overlay(page.watermark, displayed)
+	if err != nil {
+		return err
+	}
+
+	// Resources gain the form among their XObjects
+	resources, ok := core.GetDict(inherited["Resources"])
+	if !ok {
+		resources = core.MakeDict()
+	}
+	xobjects, ok := core.GetDict(resources.Get("XObject"))
+	if !ok {
+		xobjects = core.MakeDict()
+	}
+	replaced["Resources"] = b.dict(resources, map[core.PdfObjectName]string{
+		"XObject": b.dict(xobjects, map[core.PdfObjectName]string{watermarkXObject: fmt.Sprintf("%d 0 R", form)}),
+	})
+
+	// The page content is kept in a saved graphics state so the mark is
+	// drawn with none of its changes
+	contents := []string{fmt.Sprintf("%d 0 R", b.addStream("", []byte("q\n")))}
+	if array, ok := core.GetArray(resolveObject(dict.Get("Contents"))); ok {
+		for _, element := range array.Elements() {
+			contents = append(contents, b.value(element))
+		}
+	} else if dict.Get("Contents") != nil {
+		contents = append(contents, b.value(dict.Get("Contents")))
+	}
+	draw := fmt.Sprintf("Q\nq %s cm /%s Do Q\n", formatNumbers(matrix), watermarkXObject)
+	contents = append(contents, fmt.Sprintf("%d 0 R", b.addStream("", []byte(draw))))
+	replaced["Contents"] = "[" + strings.Join(contents, " ") + "]"
+	return nil
+}
+
+// overlay returns the number of a form drawing the watermark on a page of
+// a size, writing it the first time the size is used
+func (b *pdfBuilder) overlay(watermark *Watermark, size gopdf.Rect) (int, error) {
+	key := overlayKey{watermark, math.Round(size.W*100) / 100, math.Round(size.H*100) / 100}
+	if number, ok := b.overlays[key]; ok {
+		return number, nil
+	}
+
+	// The mark is drawn on a page of its own, whose content and resources
+	// become the form
+	pdf := gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: size})
+	if watermark.Text != "" {
+		if err := addPDFFont(&pdf); err != nil {
+			return 0, err
+		}
+	}
+	pdf.AddPage()
+	if err := watermark.drawPDF(&pdf, size); err != nil {
+		return 0, err
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		return 0, fmt.Errorf("failed to draw watermark: %v", err)
+	}
+	source, err := newPDFSource("watermark", bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	page, _ := core.GetDict(source.pages[0])
+
+	var content []byte
+	streams := []core.PdfObject{page.Get("Contents")}
+	if array, ok := core.GetArray(resolveObject(page.Get("Contents"))); ok {
+		streams = array.Elements()
+	}
+	for _, obj := range streams {
+		stream, ok := core.GetStream(resolveObject(obj))
+		if !ok {
+			continue
+		}
+		decoded, err := core.DecodeStream(stream)
+		if err != nil {
+			return 0, fmt.Errorf("failed to draw watermark: %v", err)
+		}
+		content = append(content, decoded...)
+		content = append(content, '\n')
+	}
+
+	number := b.addStream(fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 %s] /Resources %s",
+		formatNumbers([]float64{size.W, size.H}), b.value(source.inherited[0]["Resources"])), content)
+	b.overlays[key] = number
+	return number, nil
+}
+
+// formatNumbers returns numbers separated by spaces as written in PDF content
+func formatNumbers(numbers []float64) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.4f", n), "0"), ".")
+		if parts[i] == "-0" || parts[i] == "" {
+			parts[i] = "0"
+		}
+	}
+	return strings.Join(parts, " ")
+}
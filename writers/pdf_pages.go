@@ -0,0 +1,278 @@
+package writers
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PageRange is a range of page numbers counted from 1. End 0 runs to the
+// last page.
+type PageRange struct {
+	Start int
+	End   int
+}
+
+// ParsePageRanges parses page numbers and ranges such as "1-3,5,8-"
+func ParsePageRanges(value string) ([]PageRange, error) {
+	var ranges []PageRange
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		start, end, isRange := strings.Cut(part, "-")
+		var r PageRange
+		var err error
+		if r.Start, err = strconv.Atoi(strings.TrimSpace(start)); err != nil || r.Start < 1 {
+			return nil, fmt.Errorf("invalid page range: %s", part)
+		}
+		r.End = r.Start
+		if isRange {
+			r.End = 0
+			if end = strings.TrimSpace(end); end != "" {
+				if r.End, err = strconv.Atoi(end); err != nil || r.End < r.Start {
+					return nil, fmt.Errorf("invalid page range: %s", part)
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("invalid page range: %s", value)
+	}
+	return ranges, nil
+}
+
+// pages returns the page numbers of the range in a document of total pages
+func (r PageRange) pages(total int) ([]int, error) {
+	end := r.End
+	if end == 0 {
+		end = total
+	}
+	if r.Start > total || end > total {
+		return nil, fmt.Errorf("page range %d-%d out of range: document has %d pages", r.Start, end, total)
+	}
+	var pages []int
+	for page := r.Start; page <= end; page++ {
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// rangePages returns the page numbers of ranges in a document of total pages
+func rangePages(ranges []PageRange, total int) ([]int, error) {
+	var pages []int
+	for _, r := range ranges {
+		numbers, err := r.pages(total)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, numbers...)
+	}
+	return pages, nil
+}
+
+// outputFile returns the path of an output file in the output directory
+func (w *PDFWriter) outputFile(outputPath string) string {
+	return filepath.Join(w.OutputDir, filepath.Base(outputPath))
+}
+
+// MergeSection is a PDF file placed in a merged document
+type MergeSection struct {
+	Path string
+	// Title of the bookmark leading to the section, which holds the
+	// bookmarks of the file; none when empty
+	Title string
+}
+
+// MergePDFs writes the pages of PDF files one after another, each starting
+// on a new page, keeping their content, bookmarks and the document
+// information of the first. The writer's watermark is drawn on every page.
+func (w *PDFWriter) MergePDFs(outputPath string, sections []MergeSection) error {
+	watermark, err := w.watermark()
+	if err != nil {
+		return err
+	}
+	var pages []sourcePage
+	titles := make(map[*pdfSource]string)
+	for _, section := range sections {
+		source, err := openPDFSource(section.Path)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		if section.Title != "" {
+			titles[source] = section.Title
+		}
+		for i := range source.pages {
+			pages = append(pages, sourcePage{source: source, index: i, watermark: watermark})
+		}
+	}
+	return writeSourcePages(w.outputFile(outputPath), pages, pdfOptions{titles: titles, pdfa: w.PDFA, encryption: w.Encryption})
+}
+
+// SplitPDF writes each range of pages of a PDF file to its own file,
+// numbered like out-1.pdf, out-2.pdf even when there is one range, and
+// returns the paths written. No file is written when a range is out of
+// the document.
+func (w *PDFWriter) SplitPDF(inputPath, outputPath string, ranges []PageRange) ([]string, error) {
+	source, err := openPDFSource(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	// Every range is checked before any file is written
+	parts := make([][]int, len(ranges))
+	for i, r := range ranges {
+		if parts[i], err = r.pages(len(source.pages)); err != nil {
+			return nil, err
+		}
+	}
+
+	var paths []string
+	for i, numbers := range parts {
+		ext := filepath.Ext(outputPath)
+		path := w.outputFile(fmt.Sprintf("%s-%d%s", strings.TrimSuffix(outputPath, ext), i+1, ext))
+		if err := writeSourcePages(path, selectPages(source, numbers), w.options()); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// SplitPDFEvery splits a PDF file into files of count pages each
+func (w *PDFWriter) SplitPDFEvery(inputPath, outputPath string, count int) ([]string, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("invalid page count: %d", count)
+	}
+	source, err := openPDFSource(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	total := len(source.pages)
+	source.Close()
+
+	var ranges []PageRange
+	for start := 1; start <= total; start += count {
+		ranges = append(ranges, PageRange{Start: start, End: min(start+count-1, total)})
+	}
+	return w.SplitPDF(inputPath, outputPath, ranges)
+}
+
+// ReorderPDF writes the pages of a PDF file in the order of ranges; pages
+// not listed follow in their original order and pages listed twice appear
+// twice
+func (w *PDFWriter) ReorderPDF(inputPath, outputPath string, order []PageRange) error {
+	source, err := openPDFSource(inputPath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	listed, err := rangePages(order, len(source.pages))
+	if err != nil {
+		return err
+	}
+	return writeSourcePages(w.outputFile(outputPath), selectPages(source, pageOrder(listed, len(source.pages))), w.options())
+}
+
+// RotatePDF turns pages of a PDF file clockwise by a multiple of 90
+// degrees, all pages when ranges is empty
+func (w *PDFWriter) RotatePDF(inputPath, outputPath string, angle int, ranges []PageRange) error {
+	if angle%90 != 0 {
+		return fmt.Errorf("invalid rotation: %d is not a multiple of 90", angle)
+	}
+	source, err := openPDFSource(inputPath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	rotated := make(map[int]bool)
+	if len(ranges) == 0 {
+		ranges = []PageRange{{Start: 1}}
+	}
+	numbers, err := rangePages(ranges, len(source.pages))
+	if err != nil {
+		return err
+	}
+	for _, number := range numbers {
+		rotated[number] = true
+	}
+
+	pages := selectPages(source, nil)
+	for i := range pages {
+		if rotated[i+1] {
+			pages[i].rotation = angle
+		}
+	}
+	return writeSourcePages(w.outputFile(outputPath), pages, w.options())
+}
+
+// DeletePDFPages writes a PDF file without the pages in ranges
+func (w *PDFWriter) DeletePDFPages(inputPath, outputPath string, ranges []PageRange) error {
+	source, err := openPDFSource(inputPath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	numbers, err := rangePages(ranges, len(source.pages))
+	if err != nil {
+		return err
+	}
+	deleted := make(map[int]bool)
+	for _, number := range numbers {
+		deleted[number] = true
+	}
+	var pages []sourcePage
+	for _, page := range selectPages(source, nil) {
+		if !deleted[page.index+1] {
+			pages = append(pages, page)
+		}
+	}
+	if len(pages) == 0 {
+		return fmt.Errorf("cannot delete every page of %s", inputPath)
+	}
+	return writeSourcePages(w.outputFile(outputPath), pages, w.options())
+}
+
+// options returns the settings of files the writer copies pages into
+func (w *PDFWriter) options() pdfOptions {
+	return pdfOptions{pdfa: w.PDFA, encryption: w.Encryption}
+}
+
+// pageOrder returns the listed page numbers followed by the other pages of
+// a document of total pages
+func pageOrder(listed []int, total int) []int {
+	placed := make(map[int]bool)
+	numbers := append([]int{}, listed...)
+	for _, number := range listed {
+		placed[number] = true
+	}
+	for number := 1; number <= total; number++ {
+		if !placed[number] {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+// selectPages returns the pages of a source with the given numbers, all
+// pages when numbers is nil
+func selectPages(source *pdfSource, numbers []int) []sourcePage {
+	if numbers == nil {
+		for i := range source.pages {
+			numbers = append(numbers, i+1)
+		}
+	}
+	pages := make([]sourcePage, len(numbers))
+	for i, number := range numbers {
+		pages[i] = sourcePage{source: source, index: number - 1}
+	}
+	return pages
+}
@@ -0,0 +1,220 @@
+package writers
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/unidoc/unipdf/v3/model"
+
+	"myconverter/interfaces"
+)
+
+// testImages returns pages of small PNG images, a line on a transparent
+// background
+func testImages(t *testing.T, pages int) []*interfaces.Image {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for x := 0; x < 20; x++ {
+		img.Set(x, 5, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	images := make([]*interfaces.Image, pages)
+	for i := range images {
+		images[i] = &interfaces.Image{Name: "page.png", Data: buf.Bytes()}
+	}
+	return images
+}
+
+// writeTestPDF writes a PDF file of pages, each a small image, and returns
+// its path
+func writeTestPDF(t *testing.T, dir string, pages int) string {
+	t.Helper()
+	if err := NewPDFWriter(dir).writeImagePages("input.pdf", testImages(t, pages)); err != nil {
+		t.Fatalf("failed to write test PDF: %v", err)
+	}
+	return filepath.Join(dir, "input.pdf")
+}
+
+func TestParsePageRanges(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []PageRange
+		wantErr bool
+	}{
+		{value: "1-3,5,8-", want: []PageRange{{1, 3}, {5, 5}, {8, 0}}},
+		{value: "5-", want: []PageRange{{5, 0}}},
+		{value: " 2 , 4 - 6 ", want: []PageRange{{2, 2}, {4, 6}}},
+		{value: "1,,2", want: []PageRange{{1, 1}, {2, 2}}},
+		{value: "3-1", wantErr: true},
+		{value: "", wantErr: true},
+		{value: ",", wantErr: true},
+		{value: "0", wantErr: true},
+		{value: "-3", wantErr: true},
+		{value: "a-b", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePageRanges(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePageRanges(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePageRanges(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPageRangePages(t *testing.T) {
+	tests := []struct {
+		r       PageRange
+		total   int
+		want    []int
+		wantErr bool
+	}{
+		{r: PageRange{2, 4}, total: 5, want: []int{2, 3, 4}},
+		{r: PageRange{4, 0}, total: 5, want: []int{4, 5}},
+		{r: PageRange{5, 5}, total: 5, want: []int{5}},
+		{r: PageRange{6, 0}, total: 5, wantErr: true},
+		{r: PageRange{4, 9}, total: 5, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := tt.r.pages(tt.total)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v.pages(%d) error = %v, wantErr %v", tt.r, tt.total, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v.pages(%d) = %v, want %v", tt.r, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestPageOrder(t *testing.T) {
+	tests := []struct {
+		listed []int
+		total  int
+		want   []int
+	}{
+		{listed: []int{3, 1, 2}, total: 3, want: []int{3, 1, 2}},
+		{listed: []int{3}, total: 4, want: []int{3, 1, 2, 4}},
+		{listed: []int{2, 2}, total: 3, want: []int{2, 2, 1, 3}},
+		{listed: nil, total: 2, want: []int{1, 2}},
+	}
+	for _, tt := range tests {
+		if got := pageOrder(tt.listed, tt.total); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pageOrder(%v, %d) = %v, want %v", tt.listed, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestSplitPDF(t *testing.T) {
+	input := writeTestPDF(t, t.TempDir(), 7)
+
+	tests := []struct {
+		name    string
+		ranges  []PageRange
+		want    []int
+		wantErr bool
+	}{
+		{name: "ranges", ranges: []PageRange{{1, 3}, {4, 0}}, want: []int{3, 4}},
+		{name: "one range", ranges: []PageRange{{2, 2}}, want: []int{1}},
+		{name: "last range out of document", ranges: []PageRange{{1, 3}, {4, 9}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			paths, err := NewPDFWriter(dir).SplitPDF(input, "out.pdf", tt.ranges)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitPDF() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				// No part is written when a range is out of the document
+				if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+					t.Errorf("SplitPDF() wrote %d files before failing", len(entries))
+				}
+				return
+			}
+			var got []int
+			for i, path := range paths {
+				if want := filepath.Join(dir, "out-"+strconv.Itoa(i+1)+".pdf"); path != want {
+					t.Errorf("part %d written to %s, want %s", i+1, path, want)
+				}
+				source, err := openPDFSource(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, len(source.pages))
+				source.Close()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitPDF() page counts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePDFs(t *testing.T) {
+	// Sources of different page counts and sizes
+	a := writeTestPDF(t, t.TempDir(), 2)
+	b := filepath.Join(t.TempDir(), "b.pdf")
+	writer := NewPDFWriter(filepath.Dir(b))
+	writer.ImageDPI = 144
+	if err := writer.writeImagePages("b.pdf", testImages(t, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := NewPDFWriter(dir).MergePDFs("first.pdf", []MergeSection{{Path: a, Title: "a.pdf"}, {Path: b, Title: "b.pdf"}}); err != nil {
+		t.Fatalf("MergePDFs() error = %v", err)
+	}
+	// A merged file keeps its bookmarks under its own title
+	first := filepath.Join(dir, "first.pdf")
+	if err := NewPDFWriter(dir).MergePDFs("out.pdf", []MergeSection{{Path: b}, {Path: first, Title: "first"}}); err != nil {
+		t.Fatalf("MergePDFs() error = %v", err)
+	}
+
+	path := filepath.Join(dir, "out.pdf")
+	want := [][2]float64{{10, 5}, {842, 595}, {842, 595}, {10, 5}}
+	if got := pageSizes(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("page sizes = %v, want %v", got, want)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outline, err := reader.GetOutlines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var describe func(items []*model.OutlineItem) []string
+	describe = func(items []*model.OutlineItem) []string {
+		var lines []string
+		for _, item := range items {
+			lines = append(lines, item.Title+" "+strconv.FormatInt(item.Dest.Page+1, 10))
+			for _, child := range describe(item.Entries) {
+				lines = append(lines, "  "+child)
+			}
+		}
+		return lines
+	}
+	wantOutline := []string{"first 2", "  a.pdf 2", "  b.pdf 4"}
+	if got := describe(outline.Entries); !reflect.DeepEqual(got, wantOutline) {
+		t.Errorf("outline = %q, want %q", got, wantOutline)
+	}
+}
@@ -0,0 +1,30 @@
+package writers
+
+import (
+	"fmt"
+)
+
+// Stamp writes a copy of an existing PDF file with the writer's watermark
+// drawn over each page. The page content, bookmarks and document
+// information are kept as they are.
+func (w *PDFWriter) Stamp(inputPath, outputPath string) error {
+	if w.Watermark == nil {
+		return fmt.Errorf("no watermark to stamp")
+	}
+
+	watermark, err := w.watermark()
+	if err != nil {
+		return err
+	}
+	source, err := openPDFSource(inputPath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	pages := selectPages(source, nil)
+	for i := range pages {
+		pages[i].watermark = watermark
+	}
+	return writeSourcePages(w.outputFile(outputPath), pages, w.options())
+}
@@ -1,12 +1,16 @@
 package writers
 
 import (
+	"bytes"
 	"fmt"
+	"image/color"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/signintech/gopdf"
+	"myconverter/interfaces"
 )
 
 // PDFWriter handles converting document content to PDF files
@@ -15,7 +19,17 @@ type PDFWriter struct {
 	OutputDir string
 	// Page settings
 	PageSize *gopdf.Rect // e.g., A4 size
-	FontSize int        // in points
+	FontSize int         // in points
+	// ImageDPI sizes the pages of image-only content to the images at this
+	// resolution; 0 fits each image on a page of PageSize
+	ImageDPI float64
+	// Watermark is drawn over every page when set
+	Watermark *Watermark
+	// PDFA is PDFA1B or PDFA2B to write PDF/A files for archiving, empty
+	// for plain PDF
+	PDFA string
+	// Encryption protects written files with passwords when set
+	Encryption *Encryption
 }
 
 // NewPDFWriter creates a new PDFWriter with default settings
@@ -23,53 +37,99 @@ func NewPDFWriter(outputDir string) *PDFWriter {
 	return &PDFWriter{
 		OutputDir: outputDir,
 		PageSize:  gopdf.PageSizeA4,
-		FontSize: 48,
+		FontSize:  48,
 	}
 }
 
 // WriteTexts renders text in the PDF
 func (w *PDFWriter) WriteTexts(outputPath string, text string) error {
-	// Split text into lines
-	lines := strings.Split(text, "\n")
+	return w.WriteContent(outputPath, &interfaces.PDFContent{Text: text})
+}
+
+// WriteContent renders the content text in the PDF, drawing the headers and
+// footers of the content on every page. Content made only of images is
+// written one image per page.
+func (w *PDFWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	watermark, err := w.watermark()
+	if err != nil {
+		return err
+	}
+
+	// Scanned pages and other image-only content become one image per page
+	if images := imagePages(content); len(images) > 0 {
+		return w.writeImagePages(outputPath, images)
+	}
 
 	// Create new PDF
 	pdf := gopdf.GoPdf{}
 	pdf.Start(gopdf.Config{PageSize: *w.PageSize})
-	
-	// Add a new page
-	pdf.AddPage()
 
-	// Add Korean font (using Malgun Gothic)
-	err := pdf.AddTTFFont("malgun", "C:\\Windows\\Fonts\\malgun.ttf")
-	if err != nil {
-		return fmt.Errorf("failed to load font: %v", err)
-	}
-
-	// Set font
-	err = pdf.SetFont("malgun", "", 10) // 10pt font size
-	if err != nil {
-		return fmt.Errorf("failed to set font: %v", err)
+	// Add the text font, 10pt
+	if err := addPDFFont(&pdf); err != nil {
+		return err
 	}
 
 	// Start from top with margin
 	margin := 50.0
 	lineHeight := 14.0 // 1.4x line spacing for 10pt font
-	y := margin
 
-	// Draw each line of text
-	for _, line := range lines {
-		pdf.SetX(margin)
-		pdf.SetY(y)
-		pdf.Cell(nil, line)
-		y += lineHeight
+	// Headers and footers sit in the top and bottom margins, pushing the
+	// body down when they span several lines
+	headerLines := maxHeaderFooterLines(content.Headers)
+	footerLines := maxHeaderFooterLines(content.Footers)
+	top := math.Max(margin, margin/2+float64(headerLines)*lineHeight)
+	bottom := w.PageSize.H - math.Max(margin, margin/2+float64(footerLines)*lineHeight)
+
+	// Lay out lines on pages before drawing so page totals are known
+	pages := layoutPages(content, int((bottom-top)/lineHeight))
+
+	for i, layout := range pages {
+		pdf.AddPage()
+
+		// Draw each line of text
+		y := top
+		for _, line := range layout.Lines {
+			pdf.SetX(margin)
+			pdf.SetY(y)
+			pdf.Cell(nil, line)
+			y += lineHeight
+		}
+
+		// Footnotes fill the bottom of the body area
+		y = bottom - float64(len(layout.Notes))*lineHeight
+		for _, line := range layout.Notes {
+			pdf.SetX(margin)
+			pdf.SetY(y)
+			pdf.Cell(nil, line)
+			y += lineHeight
+		}
 
-		// Add new page if we're near the bottom
-		if y > w.PageSize.H-margin {
-			pdf.AddPage()
-			y = margin
+		page := i + 1
+		y = margin / 2
+		for _, header := range interfaces.SelectHeaderFooters(content.Headers, page) {
+			y = w.drawHeaderFooter(&pdf, header.Resolve(page, len(pages)), header.Align, y, margin, lineHeight)
+		}
+		y = w.PageSize.H - margin/2 - float64(footerLines)*lineHeight
+		for _, footer := range interfaces.SelectHeaderFooters(content.Footers, page) {
+			w.drawHeaderFooter(&pdf, footer.Resolve(page, len(pages)), footer.Align, y, margin, lineHeight)
+		}
+
+		if watermark != nil {
+			if err := watermark.drawPDF(&pdf, *w.PageSize); err != nil {
+				return err
+			}
+			pdf.SetFontSize(10)
+			pdf.SetTextColor(0, 0, 0)
 		}
 	}
 
+	return w.save(&pdf, outputPath)
+}
+
+// save writes a PDF to a file in the output directory. PDF/A files are
+// rewritten with the metadata and output intent the level requires, and
+// encrypted files with their strings and streams encrypted.
+func (w *PDFWriter) save(pdf *gopdf.GoPdf, outputPath string) error {
 	// Ensure output directory exists
 	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %v", err)
@@ -77,12 +137,65 @@ func (w *PDFWriter) WriteTexts(outputPath string, text string) error {
 
 	// Save the PDF
 	outputPath = filepath.Join(w.OutputDir, filepath.Base(outputPath))
-	err = pdf.WritePdf(outputPath)
+	if w.PDFA == "" && w.Encryption == nil {
+		if err := pdf.WritePdf(outputPath); err != nil {
+			return fmt.Errorf("failed to save PDF: %v", err)
+		}
+		return nil
+	}
+
+	data, err := pdf.GetBytesPdfReturnErr()
 	if err != nil {
 		return fmt.Errorf("failed to save PDF: %v", err)
 	}
+	source, err := newPDFSource(outputPath, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return writeSourcePages(outputPath, selectPages(source, nil), w.options())
+}
 
-	return nil
+// watermark returns the watermark drawn on pages, nil for none. PDF/A-1
+// forbids transparency, so a text mark is drawn opaque in its color faded
+// by the opacity, and image marks are refused.
+func (w *PDFWriter) watermark() (*Watermark, error) {
+	if w.PDFA != "" && !IsPDFALevel(w.PDFA) {
+		return nil, fmt.Errorf("unknown PDF/A level: %s", w.PDFA)
+	}
+	if w.Watermark == nil || w.PDFA != PDFA1B {
+		return w.Watermark, nil
+	}
+	if len(w.Watermark.Image) > 0 {
+		return nil, fmt.Errorf("PDF/A-1 does not allow transparent watermark images")
+	}
+	opaque := *w.Watermark
+	r, g, b, _ := color.NRGBAModel.Convert(w.Watermark.color()).RGBA()
+	fade := func(v uint32) uint8 {
+		return uint8(math.Round(float64(v>>8)*w.Watermark.opacity() + 255*(1-w.Watermark.opacity())))
+	}
+	opaque.Color = color.NRGBA{R: fade(r), G: fade(g), B: fade(b), A: 0xff}
+	opaque.Opacity = 1
+	return &opaque, nil
+}
+
+// drawHeaderFooter draws header or footer lines from y and returns the y below them
+func (w *PDFWriter) drawHeaderFooter(pdf *gopdf.GoPdf, text, align string, y, margin, lineHeight float64) float64 {
+	for _, line := range strings.Split(text, "\n") {
+		x := margin
+		if width, err := pdf.MeasureTextWidth(line); err == nil {
+			switch align {
+			case "CENTER":
+				x = (w.PageSize.W - width) / 2
+			case "RIGHT":
+				x = w.PageSize.W - margin - width
+			}
+		}
+		pdf.SetX(x)
+		pdf.SetY(y)
+		pdf.Cell(nil, line)
+		y += lineHeight
+	}
+	return y
 }
 
 // Write creates a PDF with sample text and saves it
@@ -0,0 +1,172 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// PDF/A levels PDFWriter writes
+const (
+	PDFA1B = "1b"
+	PDFA2B = "2b"
+)
+
+// srgbCondition names the output condition of PDF/A files
+const srgbCondition = "sRGB IEC61966-2.1"
+
+// infoKeys are the document information entries mirrored in XMP metadata
+var infoKeys = []core.PdfObjectName{"Title", "Author", "Subject", "Keywords", "Creator", "Producer", "CreationDate", "ModDate"}
+
+// IsPDFALevel reports whether a level is PDFA1B or PDFA2B
+func IsPDFALevel(level string) bool {
+	return level == PDFA1B || level == PDFA2B
+}
+
+// pdfaPart returns the part of ISO 19005 and the conformance level of a
+// PDF/A level such as "1b"
+func pdfaPart(level string) (string, string) {
+	level = strings.ToLower(level)
+	if level == "" {
+		return "", ""
+	}
+	return level[:1], strings.ToUpper(level[1:])
+}
+
+// pdfaVersion returns the PDF version written for a PDF/A level
+func pdfaVersion(level string) string {
+	if part, _ := pdfaPart(level); part == "1" {
+		return "1.4"
+	}
+	return "1.7"
+}
+
+// writePDFA writes the document information, XMP metadata and sRGB output
+// intent of a PDF/A file and returns the catalog entries and the reference
+// of the information dictionary. Text entries of info are kept; the dates
+// are set to the time of writing unless the creation date is known.
+func (b *pdfBuilder) writePDFA(info core.PdfObject) (string, string) {
+	values := make(map[core.PdfObjectName]string)
+	if dict, ok := core.GetDict(resolveObject(info)); ok {
+		for _, key := range infoKeys {
+			if s, ok := core.GetString(resolveObject(dict.Get(key))); ok && s.Decoded() != "" {
+				values[key] = s.Decoded()
+			}
+		}
+	}
+	modified := time.Now()
+	created := modified
+	if date, err := model.NewPdfDate(values["CreationDate"]); err == nil {
+		created = date.ToGoTime()
+	}
+	delete(values, "CreationDate")
+	delete(values, "ModDate")
+
+	var entries []string
+	for _, key := range infoKeys {
+		if value, ok := values[key]; ok {
+			entries = append(entries, key.WriteString()+" "+b.text(value))
+		}
+	}
+	entries = append(entries, "/CreationDate "+b.text(pdfDate(created)), "/ModDate "+b.text(pdfDate(modified)))
+	infoNumber := b.reserve()
+	b.writeObject(infoNumber, "<<"+strings.Join(entries, " ")+">>")
+
+	metadata := b.addStream(" /Type /Metadata /Subtype /XML", xmpMetadata(b.pdfa, values, created, modified))
+	profile := b.addStream(" /N 3", srgbProfile())
+	catalog := fmt.Sprintf("/Metadata %d 0 R /OutputIntents [<</Type /OutputIntent /S /GTS_PDFA1 /OutputConditionIdentifier %s /Info %s /RegistryName (http://www.color.org) /DestOutputProfile %d 0 R>>]",
+		metadata, b.text(srgbCondition), b.text(srgbCondition), profile)
+	return catalog, fmt.Sprintf("%d 0 R", infoNumber)
+}
+
+// pdfaEntries returns the replaced entries of a copied dictionary with the
+// entries PDF/A asks of it: the glyph mapping of TrueType CID fonts spelled
+// out and annotations made visible and printed
+func (b *pdfBuilder) pdfaEntries(dict *core.PdfObjectDictionary, replaced map[core.PdfObjectName]string) map[core.PdfObjectName]string {
+	entries := make(map[core.PdfObjectName]string)
+	typ, _ := core.GetNameVal(resolveObject(dict.Get("Type")))
+	subtype, _ := core.GetNameVal(resolveObject(dict.Get("Subtype")))
+	if subtype == "CIDFontType2" && dict.Get("CIDToGIDMap") == nil {
+		entries["CIDToGIDMap"] = "/Identity"
+	}
+	if typ == "Annot" {
+		// Print is flag 3; Invisible, Hidden and NoView are flags 1, 2 and 6
+		flags, _ := core.GetIntVal(resolveObject(dict.Get("F")))
+		entries["F"] = strconv.Itoa(flags&^(1|2|32) | 4)
+	}
+	if len(entries) == 0 {
+		return replaced
+	}
+	for key, value := range replaced {
+		entries[key] = value
+	}
+	return entries
+}
+
+// pdfDate returns a time as a PDF date string
+func pdfDate(t time.Time) string {
+	zone := t.Format("-07:00")
+	return "D:" + t.Format("20060102150405") + zone[:3] + "'" + zone[4:] + "'"
+}
+
+// xmpMetadata returns the XMP packet identifying a PDF/A level, holding
+// the document information values and dates
+func xmpMetadata(level string, values map[core.PdfObjectName]string, created, modified time.Time) []byte {
+	escape := func(s string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(s))
+		return buf.String()
+	}
+	alt := func(s string) string {
+		return `<rdf:Alt><rdf:li xml:lang="x-default">` + escape(s) + `</rdf:li></rdf:Alt>`
+	}
+	part, conformance := pdfaPart(level)
+
+	var sb strings.Builder
+	sb.WriteString("<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	sb.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/">` + "\n")
+	sb.WriteString(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` + "\n")
+
+	sb.WriteString(`<rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/">`)
+	fmt.Fprintf(&sb, "<pdfaid:part>%s</pdfaid:part><pdfaid:conformance>%s</pdfaid:conformance>", part, conformance)
+	sb.WriteString("</rdf:Description>\n")
+
+	sb.WriteString(`<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">`)
+	sb.WriteString("<dc:format>application/pdf</dc:format>")
+	if title, ok := values["Title"]; ok {
+		sb.WriteString("<dc:title>" + alt(title) + "</dc:title>")
+	}
+	if author, ok := values["Author"]; ok {
+		sb.WriteString("<dc:creator><rdf:Seq><rdf:li>" + escape(author) + "</rdf:li></rdf:Seq></dc:creator>")
+	}
+	if subject, ok := values["Subject"]; ok {
+		sb.WriteString("<dc:description>" + alt(subject) + "</dc:description>")
+	}
+	sb.WriteString("</rdf:Description>\n")
+
+	sb.WriteString(`<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/">`)
+	fmt.Fprintf(&sb, "<xmp:CreateDate>%s</xmp:CreateDate><xmp:ModifyDate>%s</xmp:ModifyDate><xmp:MetadataDate>%s</xmp:MetadataDate>",
+		created.Format(time.RFC3339), modified.Format(time.RFC3339), modified.Format(time.RFC3339))
+	if creator, ok := values["Creator"]; ok {
+		sb.WriteString("<xmp:CreatorTool>" + escape(creator) + "</xmp:CreatorTool>")
+	}
+	sb.WriteString("</rdf:Description>\n")
+
+	sb.WriteString(`<rdf:Description rdf:about="" xmlns:pdf="http://ns.adobe.com/pdf/1.3/">`)
+	if producer, ok := values["Producer"]; ok {
+		sb.WriteString("<pdf:Producer>" + escape(producer) + "</pdf:Producer>")
+	}
+	if keywords, ok := values["Keywords"]; ok {
+		sb.WriteString("<pdf:Keywords>" + escape(keywords) + "</pdf:Keywords>")
+	}
+	sb.WriteString("</rdf:Description>\n")
+
+	sb.WriteString("</rdf:RDF>\n</x:xmpmeta>\n<?xpacket end=\"w\"?>")
+	return []byte(sb.String())
+}
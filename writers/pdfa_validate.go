@@ -0,0 +1,444 @@
+package writers
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// PDFAReport is the result of checking a PDF file against a PDF/A level
+type PDFAReport struct {
+	// Claimed is the level the file's metadata identifies, such as "1b",
+	// empty when it claims none
+	Claimed string
+	// Level is the level the file was checked against
+	Level string
+	// Violations describe the requirements the file breaks, none when it
+	// passes
+	Violations []string
+}
+
+// pdfaChecker collects the violations found in a file
+type pdfaChecker struct {
+	// part is the part of ISO 19005 checked, "1" or "2"
+	part       string
+	violations []string
+	found      map[string]bool
+}
+
+// pdfaForbiddenActions are the action types PDF/A forbids in every part
+var pdfaForbiddenActions = map[string]bool{
+	"Launch": true, "Sound": true, "Movie": true, "ResetForm": true, "ImportData": true,
+	"JavaScript": true, "Hide": true, "SetOCGState": true, "Rendition": true, "Trans": true,
+	"GoTo3DView": true,
+}
+
+// pdfaForbiddenAnnotations are the annotation types PDF/A forbids, by part
+var pdfaForbiddenAnnotations = map[string]map[string]bool{
+	"1": {"Sound": true, "Movie": true, "FileAttachment": true, "Screen": true, "3D": true, "RichMedia": true},
+	"2": {"Sound": true, "Movie": true, "Screen": true, "3D": true, "RichMedia": true},
+}
+
+// xmpInfoProperties are the XMP properties matching document information
+// entries
+var xmpInfoProperties = map[core.PdfObjectName]string{
+	"Title":        "dc:title",
+	"Author":       "dc:creator",
+	"Subject":      "dc:description",
+	"Keywords":     "pdf:Keywords",
+	"Creator":      "xmp:CreatorTool",
+	"Producer":     "pdf:Producer",
+	"CreationDate": "xmp:CreateDate",
+	"ModDate":      "xmp:ModifyDate",
+}
+
+// ValidatePDFA checks a PDF file against the requirements of a PDF/A level
+// that can be checked from its structure: identification, metadata, output
+// intent, embedded fonts, transparency, actions, annotations and encryption.
+// The level the file claims is checked when level is empty, PDF/A-1b when
+// it claims none. Content streams are not interpreted, so passing is not a
+// proof of conformance.
+func ValidatePDFA(path, level string) (*PDFAReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF file: %v", err)
+	}
+	return validatePDFA(data, level)
+}
+
+// validatePDFA checks PDF data against a PDF/A level
+func validatePDFA(data []byte, level string) (*PDFAReport, error) {
+	if level != "" && !IsPDFALevel(strings.ToLower(level)) {
+		return nil, fmt.Errorf("unknown PDF/A level: %s", level)
+	}
+	reader, err := model.NewPdfReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF file: %v", err)
+	}
+	report := &PDFAReport{}
+	trailer, err := reader.GetTrailer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF file: %v", err)
+	}
+	catalog, ok := core.GetDict(trailer.Get("Root"))
+	if !ok {
+		return nil, fmt.Errorf("failed to read PDF file: missing catalog")
+	}
+
+	var xmp []byte
+	if stream, ok := core.GetStream(resolveObject(catalog.Get("Metadata"))); ok {
+		if decoded, err := core.DecodeStream(stream); err == nil {
+			xmp = decoded
+		}
+	}
+	report.Claimed = claimedPDFALevel(xmp)
+	report.Level = strings.ToLower(level)
+	if report.Level == "" {
+		report.Level = PDFA1B
+		if IsPDFALevel(report.Claimed) {
+			report.Level = report.Claimed
+		}
+	}
+	c := &pdfaChecker{found: make(map[string]bool)}
+	c.part, _ = pdfaPart(report.Level)
+
+	c.checkFile(data)
+	if encrypted, _ := reader.IsEncrypted(); encrypted || trailer.Get("Encrypt") != nil {
+		c.fail("the file is encrypted")
+	}
+	if ids, ok := core.GetArray(resolveObject(trailer.Get("ID"))); !ok || ids.Len() != 2 {
+		c.fail("the trailer has no file identifier")
+	}
+
+	switch {
+	case xmp == nil:
+		c.fail("the catalog has no XMP metadata stream")
+	case report.Claimed == "":
+		c.fail("the XMP metadata does not identify a PDF/A level")
+	case report.Claimed[:1] != c.part:
+		c.fail("the file identifies as PDF/A-%s, not PDF/A-%s", strings.ToUpper(report.Claimed), strings.ToUpper(report.Level))
+	}
+	if stream, ok := core.GetStream(resolveObject(catalog.Get("Metadata"))); ok && c.part == "1" && stream.Get("Filter") != nil {
+		c.fail("the XMP metadata stream is compressed")
+	}
+	if xmp != nil {
+		c.checkInfo(resolveObject(trailer.Get("Info")), string(xmp))
+	}
+	c.checkCatalog(catalog)
+
+	for _, number := range reader.GetObjectNums() {
+		obj, err := reader.GetIndirectObjectByNumber(number)
+		if err != nil {
+			continue
+		}
+		switch o := obj.(type) {
+		case *core.PdfIndirectObject:
+			c.checkObject(o.PdfObject)
+		case *core.PdfObjectStream:
+			c.checkStream(o)
+			c.checkObject(o.PdfObjectDictionary)
+		}
+	}
+
+	report.Violations = c.violations
+	return report, nil
+}
+
+// fail records a violation once
+func (c *pdfaChecker) fail(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if !c.found[message] {
+		c.found[message] = true
+		c.violations = append(c.violations, message)
+	}
+}
+
+// checkFile checks the layout of the file data
+func (c *pdfaChecker) checkFile(data []byte) {
+	// The header is followed by a comment of at least four binary bytes
+	lines := bytes.SplitN(data, []byte("\n"), 3)
+	binary := 0
+	if len(lines) > 1 && bytes.HasPrefix(lines[1], []byte("%")) {
+		for _, ch := range lines[1][1:] {
+			if ch > 127 {
+				binary++
+			}
+		}
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) || binary < 4 {
+		c.fail("the header is not followed by a binary comment")
+	}
+	if end := bytes.LastIndex(data, []byte("%%EOF")); end < 0 || len(bytes.TrimRight(data[end+5:], "\r\n")) > 0 {
+		c.fail("data follows the end of file marker")
+	}
+	if c.part == "1" && regexp.MustCompile(`/Type\s*/(ObjStm|XRef)\b`).Match(data) {
+		c.fail("object streams and cross-reference streams are not allowed in PDF/A-1")
+	}
+}
+
+// checkCatalog checks the output intent and document-level entries
+func (c *pdfaChecker) checkCatalog(catalog *core.PdfObjectDictionary) {
+	intent := false
+	if intents, ok := core.GetArray(resolveObject(catalog.Get("OutputIntents"))); ok {
+		for _, obj := range intents.Elements() {
+			dict, ok := core.GetDict(resolveObject(obj))
+			if !ok {
+				continue
+			}
+			if s, _ := core.GetNameVal(dict.Get("S")); s != "GTS_PDFA1" {
+				continue
+			}
+			profile, ok := core.GetStream(resolveObject(dict.Get("DestOutputProfile")))
+			if !ok {
+				c.fail("the PDF/A output intent has no ICC profile")
+				continue
+			}
+			intent = true
+			if data, err := core.DecodeStream(profile); err == nil && len(data) > 8 && c.part == "1" && data[8] >= 4 {
+				c.fail("the output intent ICC profile is newer than version 2, which PDF/A-1 requires")
+			}
+		}
+	}
+	if !intent {
+		c.fail("the catalog has no PDF/A output intent")
+	}
+
+	if catalog.Get("AA") != nil {
+		c.fail("the catalog has additional actions")
+	}
+	if names, ok := core.GetDict(resolveObject(catalog.Get("Names"))); ok {
+		if names.Get("JavaScript") != nil {
+			c.fail("the document has JavaScript")
+		}
+		if names.Get("EmbeddedFiles") != nil && c.part == "1" {
+			c.fail("embedded files are not allowed in PDF/A-1")
+		}
+	}
+	if c.part == "1" && catalog.Get("OCProperties") != nil {
+		c.fail("optional content is not allowed in PDF/A-1")
+	}
+	if form, ok := core.GetDict(resolveObject(catalog.Get("AcroForm"))); ok {
+		if need, ok := core.GetBool(resolveObject(form.Get("NeedAppearances"))); ok && bool(*need) {
+			c.fail("form fields need appearances generated by the viewer")
+		}
+	}
+}
+
+// checkInfo checks that the document information matches the XMP metadata
+func (c *pdfaChecker) checkInfo(info core.PdfObject, xmp string) {
+	dict, ok := core.GetDict(info)
+	if !ok {
+		return
+	}
+	for _, key := range infoKeys {
+		s, ok := core.GetString(resolveObject(dict.Get(key)))
+		if !ok || s.Decoded() == "" {
+			continue
+		}
+		property := xmpInfoProperties[key]
+		value, ok := xmpProperty(xmp, property)
+		if !ok {
+			c.fail("document information %s is missing from the XMP metadata as %s", key, property)
+			continue
+		}
+		if key == "CreationDate" || key == "ModDate" {
+			date, err := model.NewPdfDate(s.Decoded())
+			xmpDate, ok := parseXMPDate(value)
+			if err != nil || !ok || !date.ToGoTime().Equal(xmpDate) {
+				c.fail("document information %s does not match XMP %s", key, property)
+			}
+		} else if value != s.Decoded() {
+			c.fail("document information %s does not match XMP %s", key, property)
+		}
+	}
+}
+
+// checkObject checks a direct object and the direct objects in it; objects
+// it refers to are checked on their own
+func (c *pdfaChecker) checkObject(obj core.PdfObject) {
+	switch o := obj.(type) {
+	case *core.PdfObjectDictionary:
+		c.checkDict(o)
+		for _, key := range o.Keys() {
+			c.checkObject(o.Get(key))
+		}
+	case *core.PdfObjectArray:
+		for _, element := range o.Elements() {
+			c.checkObject(element)
+		}
+	}
+}
+
+// checkStream checks the filters of a stream
+func (c *pdfaChecker) checkStream(stream *core.PdfObjectStream) {
+	if stream.Get("F") != nil || stream.Get("FFilter") != nil || stream.Get("FDecodeParms") != nil {
+		c.fail("a stream refers to an external file")
+	}
+	filters := []core.PdfObject{resolveObject(stream.Get("Filter"))}
+	if array, ok := core.GetArray(filters[0]); ok {
+		filters = array.Elements()
+	}
+	for _, filter := range filters {
+		switch name, _ := core.GetNameVal(resolveObject(filter)); name {
+		case "LZWDecode":
+			c.fail("LZW compression is not allowed")
+		case "Crypt":
+			c.fail("a stream is encrypted")
+		}
+	}
+}
+
+// checkDict checks a dictionary by the kind of object it describes
+func (c *pdfaChecker) checkDict(dict *core.PdfObjectDictionary) {
+	typ, _ := core.GetNameVal(resolveObject(dict.Get("Type")))
+	subtype, _ := core.GetNameVal(resolveObject(dict.Get("Subtype")))
+
+	switch {
+	case typ == "Font":
+		c.checkFont(dict, subtype)
+	case typ == "Annot":
+		c.checkAnnotation(dict, subtype)
+	case subtype == "Image":
+		if interpolate, ok := core.GetBool(resolveObject(dict.Get("Interpolate"))); ok && bool(*interpolate) {
+			c.fail("image interpolation is not allowed")
+		}
+		if dict.Get("Alternates") != nil || dict.Get("OPI") != nil {
+			c.fail("images with alternates or OPI are not allowed")
+		}
+	case subtype == "PS" || dict.Get("Subtype2") != nil:
+		c.fail("PostScript XObjects are not allowed")
+	case subtype == "Form" && dict.Get("Ref") != nil:
+		c.fail("reference XObjects are not allowed")
+	}
+
+	// Actions are told by their type entry
+	if action, ok := core.GetNameVal(resolveObject(dict.Get("S"))); ok && (typ == "" || typ == "Action") {
+		if pdfaForbiddenActions[action] {
+			c.fail("%s actions are not allowed", action)
+		}
+		if named, _ := core.GetNameVal(resolveObject(dict.Get("N"))); action == "Named" {
+			switch named {
+			case "NextPage", "PrevPage", "FirstPage", "LastPage":
+			default:
+				c.fail("named action %s is not allowed", named)
+			}
+		}
+	}
+
+	// Graphics states
+	if tr := dict.Get("TR"); tr != nil {
+		c.fail("transfer functions are not allowed")
+	}
+	if tr2, ok := core.GetNameVal(resolveObject(dict.Get("TR2"))); dict.Get("TR2") != nil && (!ok || tr2 != "Default") {
+		c.fail("transfer functions are not allowed")
+	}
+	if c.part == "1" {
+		c.checkTransparency(dict)
+	}
+}
+
+// checkTransparency checks a dictionary for the transparency PDF/A-1
+// forbids: soft masks, constant alpha, blend modes and transparency groups
+func (c *pdfaChecker) checkTransparency(dict *core.PdfObjectDictionary) {
+	const message = "transparency is not allowed in PDF/A-1"
+	if smask := resolveObject(dict.Get("SMask")); smask != nil {
+		if name, ok := core.GetNameVal(smask); !ok || name != "None" {
+			c.fail(message)
+		}
+	}
+	for _, key := range []core.PdfObjectName{"CA", "ca"} {
+		if alpha, err := core.GetNumberAsFloat(resolveObject(dict.Get(key))); err == nil && alpha < 1 {
+			c.fail(message)
+		}
+	}
+	if bm := resolveObject(dict.Get("BM")); bm != nil {
+		if name, ok := core.GetNameVal(bm); !ok || (name != "Normal" && name != "Compatible") {
+			c.fail(message)
+		}
+	}
+	if group, ok := core.GetDict(resolveObject(dict.Get("Group"))); ok {
+		if s, _ := core.GetNameVal(resolveObject(group.Get("S"))); s == "Transparency" {
+			c.fail(message)
+		}
+	}
+}
+
+// checkFont checks that a font program is embedded
+func (c *pdfaChecker) checkFont(dict *core.PdfObjectDictionary, subtype string) {
+	name, _ := core.GetNameVal(resolveObject(dict.Get("BaseFont")))
+	switch subtype {
+	case "Type1", "MMType1", "TrueType", "CIDFontType0", "CIDFontType2":
+	default:
+		// Type 0 fonts are checked by their descendants, Type 3 fonts
+		// are drawn by their own content
+		return
+	}
+	descriptor, ok := core.GetDict(resolveObject(dict.Get("FontDescriptor")))
+	if !ok || (descriptor.Get("FontFile") == nil && descriptor.Get("FontFile2") == nil && descriptor.Get("FontFile3") == nil) {
+		c.fail("font %s is not embedded", name)
+	}
+	if subtype == "CIDFontType2" && c.part == "1" && dict.Get("CIDToGIDMap") == nil {
+		c.fail("font %s has no CIDToGIDMap", name)
+	}
+}
+
+// checkAnnotation checks that an annotation is allowed and printed
+func (c *pdfaChecker) checkAnnotation(dict *core.PdfObjectDictionary, subtype string) {
+	if pdfaForbiddenAnnotations[c.part][subtype] {
+		c.fail("%s annotations are not allowed", subtype)
+	}
+	if subtype == "Popup" && c.part != "1" {
+		return
+	}
+	// Print is flag 3; Invisible, Hidden and NoView are flags 1, 2 and 6
+	flags, _ := core.GetIntVal(resolveObject(dict.Get("F")))
+	if flags&4 == 0 || flags&(1|2|32) != 0 {
+		c.fail("%s annotations must be printed and visible", subtype)
+	}
+}
+
+// claimedPDFALevel returns the PDF/A level identified in XMP metadata, such
+// as "1b", empty when there is none
+func claimedPDFALevel(xmp []byte) string {
+	part, ok := xmpProperty(string(xmp), "pdfaid:part")
+	if !ok {
+		return ""
+	}
+	conformance, _ := xmpProperty(string(xmp), "pdfaid:conformance")
+	return strings.ToLower(part + conformance)
+}
+
+// xmpProperty returns the value of a simple or language alternative XMP
+// property, written as an element or an attribute
+func xmpProperty(xmp, property string) (string, bool) {
+	quoted := regexp.QuoteMeta(property)
+	if m := regexp.MustCompile(`(?s)<` + quoted + `(?:\s[^>]*)?>(.*?)</` + quoted + `>`).FindStringSubmatch(xmp); m != nil {
+		value := m[1]
+		// Alternatives and sequences hold the value in their first item
+		if li := regexp.MustCompile(`(?s)<rdf:li(?:\s[^>]*)?>(.*?)</rdf:li>`).FindStringSubmatch(value); li != nil {
+			value = li[1]
+		}
+		return strings.TrimSpace(html.UnescapeString(value)), true
+	}
+	if m := regexp.MustCompile(`\s` + quoted + `\s*=\s*["']([^"']*)["']`).FindStringSubmatch(xmp); m != nil {
+		return strings.TrimSpace(html.UnescapeString(m[1])), true
+	}
+	return "", false
+}
+
+// parseXMPDate parses an XMP date, which may leave out the time or seconds
+func parseXMPDate(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04Z07:00", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,114 @@
+package writers
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidatePDFA(t *testing.T) {
+	dir := t.TempDir()
+	plain := writeTestPDF(t, dir, 1)
+
+	// write returns the path of the test images written by a writer
+	write := func(name string, configure func(w *PDFWriter)) string {
+		w := NewPDFWriter(dir)
+		configure(w)
+		if err := w.writeImagePages(name, testImages(t, 2)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return filepath.Join(dir, name)
+	}
+	pdfa1 := write("pdfa1.pdf", func(w *PDFWriter) { w.PDFA = PDFA1B })
+	pdfa2 := write("pdfa2.pdf", func(w *PDFWriter) { w.PDFA = PDFA2B })
+	encrypted := write("encrypted.pdf", func(w *PDFWriter) { w.Encryption = NewEncryption("", "owner") })
+
+	tests := []struct {
+		name    string
+		path    string
+		level   string
+		claimed string
+		checked string
+		want    []string
+	}{
+		{name: "PDF/A-1b", path: pdfa1, claimed: PDFA1B, checked: PDFA1B},
+		{name: "PDF/A-2b", path: pdfa2, claimed: PDFA2B, checked: PDFA2B},
+		{name: "PDF/A-1b checked as 2b", path: pdfa1, level: "2B", claimed: PDFA1B, checked: PDFA2B, want: []string{
+			"the file identifies as PDF/A-1B, not PDF/A-2B",
+		}},
+		{name: "PDF/A-2b checked as 1b", path: pdfa2, level: PDFA1B, claimed: PDFA2B, checked: PDFA1B, want: []string{
+			"the file identifies as PDF/A-2B, not PDF/A-1B",
+			"transparency is not allowed in PDF/A-1",
+		}},
+		{name: "plain", path: plain, checked: PDFA1B, want: []string{
+			"the trailer has no file identifier",
+			"the catalog has no XMP metadata stream",
+			"the catalog has no PDF/A output intent",
+			"transparency is not allowed in PDF/A-1",
+		}},
+		{name: "encrypted", path: encrypted, checked: PDFA1B, want: []string{
+			"the file is encrypted",
+			"the catalog has no XMP metadata stream",
+			"the catalog has no PDF/A output intent",
+			"transparency is not allowed in PDF/A-1",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ValidatePDFA(tt.path, tt.level)
+			if err != nil {
+				t.Fatalf("ValidatePDFA() error = %v", err)
+			}
+			if report.Claimed != tt.claimed || report.Level != tt.checked {
+				t.Errorf("ValidatePDFA() claimed %q checked %q, want %q and %q", report.Claimed, report.Level, tt.claimed, tt.checked)
+			}
+			if !reflect.DeepEqual(report.Violations, tt.want) {
+				t.Errorf("ValidatePDFA() violations = %q, want %q", report.Violations, tt.want)
+			}
+		})
+	}
+
+	if _, err := ValidatePDFA(pdfa1, "3u"); err == nil {
+		t.Error("ValidatePDFA() accepted an unknown level")
+	}
+}
+
+func TestXMPProperty(t *testing.T) {
+	tests := []struct {
+		xmp      string
+		property string
+		want     string
+		found    bool
+	}{
+		{xmp: `<pdfaid:part>1</pdfaid:part>`, property: "pdfaid:part", want: "1", found: true},
+		{xmp: `<rdf:Description pdfaid:part="2" pdfaid:conformance='B'/>`, property: "pdfaid:conformance", want: "B", found: true},
+		{xmp: `<dc:title><rdf:Alt><rdf:li xml:lang="x-default">A &amp; B</rdf:li></rdf:Alt></dc:title>`, property: "dc:title", want: "A & B", found: true},
+		{xmp: `<pdfaid:part>1</pdfaid:part>`, property: "pdfaid:conformance"},
+	}
+	for _, tt := range tests {
+		got, found := xmpProperty(tt.xmp, tt.property)
+		if got != tt.want || found != tt.found {
+			t.Errorf("xmpProperty(%q, %s) = %q, %v, want %q, %v", tt.xmp, tt.property, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestParseXMPDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{value: "2024-03-01T10:20:30+09:00", want: time.Date(2024, 3, 1, 1, 20, 30, 0, time.UTC), ok: true},
+		{value: "2024-03-01T10:20+09:00", want: time.Date(2024, 3, 1, 1, 20, 0, 0, time.UTC), ok: true},
+		{value: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), ok: true},
+		{value: "March 2024"},
+	}
+	for _, tt := range tests {
+		got, ok := parseXMPDate(tt.value)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseXMPDate(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
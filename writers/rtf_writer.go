@@ -0,0 +1,558 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"myconverter/interfaces"
+)
+
+// rtfDefaultFont is the font of text without a font name
+const rtfDefaultFont = "Malgun Gothic"
+
+// rtfHeadingSizes are the font sizes of heading levels 1 to 6 in half points
+var rtfHeadingSizes = []int{32, 28, 26, 24, 22, 20}
+
+// RTFWriter handles writing content as a Rich Text Format document
+type RTFWriter struct {
+	// Output directory for RTF files
+	OutputDir string
+}
+
+// NewRTFWriter creates a new RTFWriter
+func NewRTFWriter(outputDir string) *RTFWriter {
+	return &RTFWriter{OutputDir: outputDir}
+}
+
+// WriteContent converts the content to an RTF document with its styles,
+// lists, tables, pictures, headers, footers and notes.
+func (w *RTFWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	page := docxDefaultPage
+	if content.PageSetup != nil && content.PageSetup.Width > 0 && content.PageSetup.Height > 0 {
+		page = *content.PageSetup
+	}
+
+	b := &rtfBuilder{
+		content: content,
+		page:    page,
+		fonts:   map[string]int{rtfDefaultFont: 0},
+		colors:  make(map[string]int),
+	}
+	var body strings.Builder
+	b.blocks(&body, content.ContentBlocks(), false)
+	headerFooters := b.headerFooters()
+
+	outputPath = filepath.Join(w.OutputDir, filepath.Base(outputPath))
+	if err := os.WriteFile(outputPath, []byte(b.document(headerFooters, body.String())), 0644); err != nil {
+		return fmt.Errorf("failed to write RTF file: %v", err)
+	}
+	return nil
+}
+
+// Write writes sample text to the specified file.
+func (w *RTFWriter) Write(outputPath string) error {
+	return w.WriteContent(outputPath, &interfaces.PDFContent{Text: "Sample Text"})
+}
+
+// rtfBuilder accumulates the font and color tables used by the document body
+type rtfBuilder struct {
+	content *interfaces.PDFContent
+	page    interfaces.PageSetup
+	// fonts and colors map names and #RRGGBB values to their table indexes
+	fonts      map[string]int
+	fontNames  []string
+	colors     map[string]int
+	colorNames []string
+	// orderedLists counts the list overrides of ordered lists
+	orderedLists int
+	// listOverride is the \ls index of the ordered list being written
+	listOverride int
+	endnotes     bool
+	// evenAndOdd marks documents with different odd and even page headers
+	evenAndOdd bool
+}
+
+// document returns the RTF document: the header tables, the document
+// properties and the body
+func (b *rtfBuilder) document(headerFooters, body string) string {
+	var out strings.Builder
+	out.WriteString(`{\rtf1\ansi\ansicpg949\deff0\uc1\deflang1033\deflangfe1042` + "\n")
+
+	out.WriteString(`{\fonttbl{\f0\fnil\fcharset129 ` + rtfText(rtfDefaultFont) + `;}`)
+	for i, name := range b.fontNames {
+		out.WriteString(fmt.Sprintf(`{\f%d\fnil\fcharset129 %s;}`, i+1, rtfText(name)))
+	}
+	out.WriteString("}\n{\\colortbl;")
+	for _, color := range b.colorNames {
+		var r, g, bl int
+		fmt.Sscanf(strings.TrimPrefix(color, "#"), "%02x%02x%02x", &r, &g, &bl)
+		out.WriteString(fmt.Sprintf(`\red%d\green%d\blue%d;`, r, g, bl))
+	}
+	out.WriteString("}\n")
+
+	out.WriteString(`{\stylesheet{\s0\f0\fs20 Normal;}`)
+	for level, size := range rtfHeadingSizes {
+		out.WriteString(fmt.Sprintf(`{\s%d\sbasedon0\snext0\outlinelevel%d\b\f0\fs%d heading %d;}`, level+1, level, size, level+1))
+	}
+	out.WriteString(`{\s7\sbasedon0\snext7\f0\fs20 List Paragraph;}{\s8\sbasedon0\snext8\f0\fs18 footnote text;}}` + "\n")
+	out.WriteString(b.listTables())
+	out.WriteString(b.info())
+
+	// RTF places the body at the top margin and the header at its own
+	// distance from the paper edge, HWP places the header inside the top
+	// margin and the body below it
+	twips := func(points float64) int {
+		return int(points*20 + 0.5)
+	}
+	out.WriteString(fmt.Sprintf(`\paperw%d\paperh%d\margl%d\margr%d\margt%d\margb%d\headery%d\footery%d`,
+		twips(b.page.Width), twips(b.page.Height), twips(b.page.MarginLeft), twips(b.page.MarginRight),
+		twips(b.page.MarginTop+b.page.MarginHeader), twips(b.page.MarginBottom+b.page.MarginFooter),
+		twips(b.page.MarginTop), twips(b.page.MarginBottom)))
+	if b.page.Width > b.page.Height {
+		out.WriteString(`\landscape`)
+	}
+	if b.evenAndOdd {
+		out.WriteString(`\facingp`)
+	}
+	if b.endnotes {
+		out.WriteString(`\fet2\aenddoc\aftnnrlc`)
+	}
+	out.WriteString("\n\\sectd\n" + headerFooters)
+	out.WriteString(body)
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// listTables returns the list table with a bullet list and a numbered list,
+// and the overrides restarting the numbered list for each ordered list
+func (b *rtfBuilder) listTables() string {
+	var out strings.Builder
+	out.WriteString(`{\*\listtable`)
+	bullets := []string{"•", "◦", "▪"}
+	for id := 1; id <= 2; id++ {
+		out.WriteString(fmt.Sprintf(`{\list\listtemplateid%d\listhybrid`, id))
+		for level := 0; level < 9; level++ {
+			indent := fmt.Sprintf(`\fi-360\li%d`, 720*(level+1))
+			if id == 1 {
+				out.WriteString(`{\listlevel\levelnfc23\levelnfcn23\leveljc0\levelfollow0\levelstartat1{\leveltext\'01` +
+					rtfText(bullets[level%len(bullets)]) + `;}{\levelnumbers;}` + indent + `}`)
+			} else {
+				out.WriteString(fmt.Sprintf(`{\listlevel\levelnfc0\levelnfcn0\leveljc0\levelfollow0\levelstartat1{\leveltext\'02\'%02x.;}{\levelnumbers\'01;}%s}`, level, indent))
+			}
+		}
+		out.WriteString(fmt.Sprintf(`{\listname ;}\listid%d}`, id))
+	}
+	out.WriteString("}\n")
+
+	out.WriteString(`{\*\listoverridetable{\listoverride\listid1\listoverridecount0\ls1}{\listoverride\listid2\listoverridecount0\ls2}`)
+	for i := 1; i <= b.orderedLists; i++ {
+		out.WriteString(fmt.Sprintf(`{\listoverride\listid2\listoverridecount1{\lfolevel\listoverridestartat\levelstartat1}\ls%d}`, 2+i))
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// info returns the document information group
+func (b *rtfBuilder) info() string {
+	var out strings.Builder
+	out.WriteString(`{\info`)
+	for _, e := range []struct{ key, control string }{
+		{"Title", "title"},
+		{"Subject", "subject"},
+		{"Author", "author"},
+		{"LastModifiedBy", "operator"},
+		{"Keywords", "keywords"},
+		{"Description", "doccomm"},
+	} {
+		if value := strings.TrimSpace(b.content.Metadata[e.key]); value != "" {
+			out.WriteString(`{\` + e.control + ` ` + rtfText(value) + `}`)
+		}
+	}
+	for _, e := range []struct{ key, control string }{{"CreationDate", "creatim"}, {"ModDate", "revtim"}} {
+		if t, err := time.Parse("2006-01-02T15:04:05", metadataDate(b.content.Metadata[e.key])); err == nil {
+			out.WriteString(fmt.Sprintf(`{\%s\yr%d\mo%d\dy%d\hr%d\min%d\sec%d}`, e.control, t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()))
+		}
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// blocks writes the paragraphs of blocks, marking them as table text inside cells
+func (b *rtfBuilder) blocks(out *strings.Builder, blocks []interfaces.Block, inTable bool) {
+	intbl := ""
+	if inTable {
+		intbl = `\intbl`
+	}
+	for i, block := range blocks {
+		switch block.Kind {
+		case interfaces.BlockTable:
+			if block.Table == nil {
+				break
+			}
+			if inTable {
+				// Nested tables are written as the paragraphs of their cells
+				for _, row := range block.Table.Rows {
+					for _, cell := range row {
+						b.blocks(out, cell.Blocks, true)
+					}
+				}
+			} else {
+				b.table(out, block.Table)
+				// An empty paragraph keeps adjacent tables apart
+				if i+1 < len(blocks) && blocks[i+1].Kind == interfaces.BlockTable {
+					out.WriteString("\\par\n")
+				}
+			}
+		case interfaces.BlockImage:
+			if pict := b.image(block.Image); pict != "" {
+				out.WriteString(`\pard\plain` + intbl + rtfAlign(block.Align) + ` ` + pict + "\\par\n")
+			}
+		case interfaces.BlockPageBreak:
+			if inTable {
+				break
+			}
+			if block.Section {
+				out.WriteString("\\sect\\sectd\n")
+			} else {
+				out.WriteString("\\page\n")
+			}
+		case interfaces.BlockHeading:
+			level := min(max(block.Level, 1), len(rtfHeadingSizes))
+			out.WriteString(fmt.Sprintf(`\pard\plain\s%d\outlinelevel%d%s%s\b\fs%d `, level, level-1, intbl, rtfAlign(block.Align), rtfHeadingSizes[level-1]))
+			out.WriteString(b.runs(block.Runs) + "\\par\n")
+		case interfaces.BlockListItem:
+			// Each ordered list restarts its numbering
+			ls := 1
+			if block.Ordered {
+				if i == 0 || blocks[i-1].Kind != interfaces.BlockListItem || b.listOverride == 0 {
+					b.orderedLists++
+					b.listOverride = 2 + b.orderedLists
+				}
+				ls = b.listOverride
+			}
+			level := min(max(block.Level, 1), 9) - 1
+			out.WriteString(fmt.Sprintf(`\pard\plain\s7\ls%d\ilvl%d\fi-360\li%d%s%s\fs20 `, ls, level, 720*(level+1), intbl, rtfAlign(block.Align)))
+			out.WriteString(b.runs(block.Runs) + "\\par\n")
+		default:
+			out.WriteString(`\pard\plain` + intbl + rtfAlign(block.Align) + `\fs20 ` + b.runs(block.Runs) + "\\par\n")
+		}
+		if block.Kind != interfaces.BlockListItem {
+			b.listOverride = 0
+		}
+	}
+}
+
+// rtfAlign returns the alignment control of a paragraph
+func rtfAlign(align string) string {
+	switch strings.ToUpper(align) {
+	case "LEFT":
+		return `\ql`
+	case "RIGHT":
+		return `\qr`
+	case "CENTER":
+		return `\qc`
+	case "JUSTIFY":
+		return `\qj`
+	case "DISTRIBUTE", "DISTRIBUTE_SPACE":
+		return `\qd`
+	default:
+		return ""
+	}
+}
+
+// runs returns the groups of runs, turning note references into footnotes
+func (b *rtfBuilder) runs(runs []interfaces.Run) string {
+	var out strings.Builder
+	for _, run := range runs {
+		format := b.runFormat(run)
+		last := 0
+		for _, match := range interfaces.NoteRefPattern.FindAllStringSubmatchIndex(run.Text, -1) {
+			out.WriteString(rtfGroup(format, run.Text[last:match[0]]))
+			out.WriteString(b.note(run.Text[match[2]:match[3]]))
+			last = match[1]
+		}
+		out.WriteString(rtfGroup(format, run.Text[last:]))
+	}
+	return out.String()
+}
+
+// rtfGroup returns text in a group with its character formatting
+func rtfGroup(format, text string) string {
+	if text == "" {
+		return ""
+	}
+	if format == "" {
+		return rtfText(text)
+	}
+	return `{` + format + ` ` + rtfText(text) + `}`
+}
+
+// runFormat returns the character formatting controls of a run, adding its
+// font and colors to the tables
+func (b *rtfBuilder) runFormat(run interfaces.Run) string {
+	var format strings.Builder
+	if run.Font != "" {
+		index, ok := b.fonts[run.Font]
+		if !ok {
+			b.fontNames = append(b.fontNames, run.Font)
+			index = len(b.fontNames)
+			b.fonts[run.Font] = index
+		}
+		format.WriteString(`\f` + strconv.Itoa(index))
+	}
+	if run.Bold {
+		format.WriteString(`\b`)
+	}
+	if run.Italic {
+		format.WriteString(`\i`)
+	}
+	if run.Underline {
+		format.WriteString(`\ul`)
+	}
+	if run.Strike {
+		format.WriteString(`\strike`)
+	}
+	if run.Size > 0 {
+		// Sizes are in half points
+		format.WriteString(`\fs` + strconv.Itoa(int(run.Size*2+0.5)))
+	}
+	if run.Color != "" {
+		format.WriteString(`\cf` + strconv.Itoa(b.color(run.Color)))
+	}
+	if run.Highlight != "" {
+		format.WriteString(`\chcbpat` + strconv.Itoa(b.color(run.Highlight)))
+	}
+	return format.String()
+}
+
+// color returns the color table index of a #RRGGBB color, index 0 being the automatic color
+func (b *rtfBuilder) color(color string) int {
+	color = strings.ToUpper(color)
+	index, ok := b.colors[color]
+	if !ok {
+		b.colorNames = append(b.colorNames, color)
+		index = len(b.colorNames)
+		b.colors[color] = index
+	}
+	return index
+}
+
+// note returns the automatically numbered reference and text of a note
+func (b *rtfBuilder) note(id string) string {
+	note := b.content.FindNote(id)
+	if note == nil {
+		return rtfText(interfaces.NoteRef(id))
+	}
+	kind := ""
+	if note.Kind == interfaces.Endnote {
+		kind = `\ftnalt`
+		b.endnotes = true
+	}
+	text := strings.ReplaceAll(rtfText(note.Text), `\line `, "\\par\n")
+	return `{\super\chftn}{\footnote` + kind + `\pard\plain\s8\fs18 {\super\chftn} ` + text + `}`
+}
+
+// table writes a table row by row. Merged columns become wider cells and
+// merged rows use vertical merge controls.
+func (b *rtfBuilder) table(out *strings.Builder, table *interfaces.Table) {
+	columns := table.Columns()
+	if columns == 0 {
+		return
+	}
+
+	// Locate the cells starting at and covering each grid position
+	rows := len(table.Rows)
+	starts := make(map[[2]int]interfaces.TableCell)
+	covers := make(map[[2]int]interfaces.TableCell)
+	for _, row := range table.Rows {
+		for _, cell := range row {
+			cell.RowSpan, cell.ColSpan = max(cell.RowSpan, 1), max(cell.ColSpan, 1)
+			starts[[2]int{cell.Row, cell.Col}] = cell
+			for r := cell.Row; r < cell.Row+cell.RowSpan; r++ {
+				covers[[2]int{r, cell.Col}] = cell
+			}
+			rows = max(rows, cell.Row+cell.RowSpan)
+		}
+	}
+
+	contentWidth := int((b.page.Width - b.page.MarginLeft - b.page.MarginRight) * 20)
+	columnWidth := max(contentWidth/columns, 1)
+	const border = `\clbrdrt\brdrs\brdrw10\clbrdrl\brdrs\brdrw10\clbrdrb\brdrs\brdrw10\clbrdrr\brdrs\brdrw10`
+
+	for r := 0; r < rows; r++ {
+		var defs, cells strings.Builder
+		defs.WriteString(`\trowd\trgaph108\trleft0`)
+		for c := 0; c < columns; {
+			cell, starting := starts[[2]int{r, c}]
+			merge := ""
+			if starting {
+				if cell.RowSpan > 1 {
+					merge = `\clvmgf`
+				}
+			} else if covering, ok := covers[[2]int{r, c}]; ok && covering.Row < r {
+				cell, merge = covering, `\clvmrg`
+				cell.Blocks = nil
+			} else {
+				cell = interfaces.TableCell{ColSpan: 1}
+			}
+
+			span := max(cell.ColSpan, 1)
+			defs.WriteString(fmt.Sprintf(`%s%s\cellx%d`, merge, border, columnWidth*(c+span)))
+
+			// The last paragraph of a cell ends with \cell instead of \par
+			var text strings.Builder
+			b.blocks(&text, cell.Blocks, true)
+			cellText := strings.TrimSuffix(text.String(), "\\par\n")
+			if cellText == text.String() {
+				cellText += `\pard\plain\intbl `
+			}
+			cells.WriteString(cellText + "\\cell\n")
+			c += span
+		}
+		out.WriteString(defs.String() + "\n" + cells.String() + defs.String() + "\\row\n")
+	}
+	// Text after the table starts a paragraph outside it
+	out.WriteString(`\pard\plain` + "\n")
+}
+
+// image returns the picture group of an image, converting formats RTF
+// cannot embed to PNG
+func (b *rtfBuilder) image(img *interfaces.Image) string {
+	if img == nil || len(img.Data) == 0 {
+		return ""
+	}
+
+	data := img.Data
+	_, contentType := imageType(img)
+	blip := `\pngblip`
+	switch contentType {
+	case "image/jpeg":
+		blip = `\jpegblip`
+	case "image/png":
+	default:
+		decoded, _, err := image.Decode(bytes.NewReader(img.Data))
+		if err != nil {
+			return ""
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, decoded); err != nil {
+			return ""
+		}
+		data = buf.Bytes()
+	}
+
+	// Pictures keep their pixel size at 96 DPI, scaled down to the text width
+	width, height := imagePixels(data)
+	goalWidth, goalHeight := width*15, height*15
+	if maxWidth := int((b.page.Width - b.page.MarginLeft - b.page.MarginRight) * 20); maxWidth > 0 && goalWidth > maxWidth {
+		goalHeight = goalHeight * maxWidth / goalWidth
+		goalWidth = maxWidth
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf(`{\pict%s\picw%d\pich%d\picwgoal%d\pichgoal%d`+"\n", blip, width, height, goalWidth, goalHeight))
+	encoded := hex.EncodeToString(data)
+	for len(encoded) > 128 {
+		out.WriteString(encoded[:128] + "\n")
+		encoded = encoded[128:]
+	}
+	out.WriteString(encoded + "}")
+	return out.String()
+}
+
+// headerFooters returns the header and footer groups. Documents with
+// different odd and even page items get right and left page groups.
+func (b *rtfBuilder) headerFooters() string {
+	for _, item := range append(append([]interfaces.HeaderFooter(nil), b.content.Headers...), b.content.Footers...) {
+		if apply := strings.ToUpper(item.Apply); apply == "ODD" || apply == "EVEN" {
+			b.evenAndOdd = true
+		}
+	}
+
+	var out strings.Builder
+	for _, kind := range []string{"header", "footer"} {
+		items := b.content.Headers
+		if kind == "footer" {
+			items = b.content.Footers
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		groups := []string{kind}
+		if b.evenAndOdd {
+			groups = []string{kind + "r", kind + "l"}
+		}
+		for i, group := range groups {
+			out.WriteString(`{\` + group)
+			for _, item := range interfaces.SelectHeaderFooters(items, i+1) {
+				for _, line := range strings.Split(item.Text, "\n") {
+					out.WriteString(`\pard\plain` + rtfAlign(item.Align) + `\fs18 ` + rtfFields(line) + `\par`)
+				}
+			}
+			out.WriteString("}\n")
+		}
+	}
+	return out.String()
+}
+
+// rtfFields returns header or footer text, turning page number placeholders
+// into PAGE and NUMPAGES fields
+func rtfFields(text string) string {
+	var out strings.Builder
+	for text != "" {
+		page := strings.Index(text, interfaces.PageNumberField)
+		total := strings.Index(text, interfaces.TotalPagesField)
+		if page < 0 && total < 0 {
+			out.WriteString(rtfText(text))
+			break
+		}
+
+		field, placeholder, index := "PAGE", interfaces.PageNumberField, page
+		if page < 0 || (total >= 0 && total < page) {
+			field, placeholder, index = "NUMPAGES", interfaces.TotalPagesField, total
+		}
+		out.WriteString(rtfText(text[:index]))
+		out.WriteString(`{\field{\*\fldinst ` + field + `}{\fldrslt 1}}`)
+		text = text[index+len(placeholder):]
+	}
+	return out.String()
+}
+
+// rtfText escapes text for RTF. Characters outside ASCII are written as
+// \u escapes with a question mark for readers without Unicode support.
+func rtfText(text string) string {
+	var out strings.Builder
+	for _, c := range text {
+		switch {
+		case c == '\\' || c == '{' || c == '}':
+			out.WriteString(`\` + string(c))
+		case c == '\t':
+			out.WriteString(`\tab `)
+		case c == '\n':
+			out.WriteString(`\line `)
+		case c == '\r':
+		case c < 0x80:
+			out.WriteRune(c)
+		case c > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(c)
+			out.WriteString(fmt.Sprintf(`\u%d?\u%d?`, int16(r1), int16(r2)))
+		default:
+			out.WriteString(fmt.Sprintf(`\u%d?`, int16(c)))
+		}
+	}
+	return out.String()
+}
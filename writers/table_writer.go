@@ -0,0 +1,182 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"myconverter/interfaces"
+)
+
+// Merged cell modes of TableWriter
+const (
+	// MergedBlank leaves the positions covered by a merged cell empty
+	MergedBlank = "blank"
+	// MergedRepeat repeats the value of a merged cell in every position it covers
+	MergedRepeat = "repeat"
+)
+
+// xlsxNumberPattern matches cell text stored as numbers: plain decimals
+// and integers grouped by thousands. Leading zeros mark codes kept as text.
+var xlsxNumberPattern = regexp.MustCompile(`^-?(0|[1-9]\d*|[1-9]\d{0,2}(,\d{3})+)(\.\d+)?$`)
+
+// xlsxStyles has the default cell format, a thousands separated number
+// format and a wrapping format for multi-line text
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="1"><font><sz val="11"/><name val="Malgun Gothic"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0" applyAlignment="1"><alignment vertical="top" wrapText="1"/></xf></cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`
+
+// TableWriter handles writing document tables as CSV files or an XLSX workbook
+type TableWriter struct {
+	// Output directory for table files
+	OutputDir string
+	// Merged is MergedBlank or MergedRepeat
+	Merged string
+}
+
+// NewTableWriter creates a new TableWriter leaving merged positions blank
+func NewTableWriter(outputDir string) *TableWriter {
+	return &TableWriter{OutputDir: outputDir, Merged: MergedBlank}
+}
+
+// WriteContent writes the tables of the content
+func (w *TableWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	return w.WriteTables(outputPath, interfaces.Tables(content.ContentBlocks()))
+}
+
+// WriteTables writes tables to one CSV file per table or to an XLSX
+// workbook with one sheet per table, depending on the output extension
+func (w *TableWriter) WriteTables(outputPath string, tables []*interfaces.Table) error {
+	if len(tables) == 0 {
+		return fmt.Errorf("no tables found")
+	}
+	if w.Merged != MergedBlank && w.Merged != MergedRepeat {
+		return fmt.Errorf("unknown merged cell mode: %s", w.Merged)
+	}
+	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	outputPath = filepath.Join(w.OutputDir, filepath.Base(outputPath))
+	switch ext := strings.ToLower(filepath.Ext(outputPath)); ext {
+	case ".csv":
+		for i, table := range tables {
+			if err := w.writeCSV(pageImagePath(outputPath, i+1, len(tables)), table); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ".xlsx":
+		return w.writeXLSX(outputPath, tables)
+	default:
+		return fmt.Errorf("unsupported table format: %s", ext)
+	}
+}
+
+// Write writes a sample table to the specified file.
+func (w *TableWriter) Write(outputPath string) error {
+	table := &interfaces.Table{Rows: [][]interfaces.TableCell{{{RowSpan: 1, ColSpan: 1, Blocks: interfaces.TextBlocks("Sample Text")}}}}
+	return w.WriteTables(outputPath, []*interfaces.Table{table})
+}
+
+// writeCSV writes a table as a CSV file
+func (w *TableWriter) writeCSV(outputPath string, table *interfaces.Table) error {
+	var buf bytes.Buffer
+	// The byte order mark lets spreadsheet applications detect UTF-8
+	buf.WriteString("\ufeff")
+	writer := csv.NewWriter(&buf)
+	writer.UseCRLF = true
+	if err := writer.WriteAll(table.Matrix(w.Merged == MergedRepeat)); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", outputPath, err)
+	}
+	return nil
+}
+
+// writeXLSX writes tables as the sheets of an XLSX workbook
+func (w *TableWriter) writeXLSX(outputPath string, tables []*interfaces.Table) error {
+	var sheets, rels, overrides strings.Builder
+	var entries []ZipEntry
+	for i, table := range tables {
+		n := strconv.Itoa(i + 1)
+		sheets.WriteString(`<sheet name="Table ` + n + `" sheetId="` + n + `" r:id="rId` + n + `"/>`)
+		rels.WriteString(`<Relationship Id="rId` + n + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet` + n + `.xml"/>`)
+		overrides.WriteString(`<Override PartName="/xl/worksheets/sheet` + n + `.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`)
+		entries = append(entries, ZipEntry{Name: "xl/worksheets/sheet" + n + ".xml", Data: []byte(xlsxSheet(table.Matrix(w.Merged == MergedRepeat)))})
+	}
+	stylesID := "rId" + strconv.Itoa(len(tables)+1)
+	rels.WriteString(`<Relationship Id="` + stylesID + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+
+	entries = append([]ZipEntry{
+		{Name: "[Content_Types].xml", Data: []byte(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			overrides.String() + `</Types>`)},
+		{Name: "_rels/.rels", Data: []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + docxRelOfficeDocument + `" Target="xl/workbook.xml"/></Relationships>`)},
+		{Name: "xl/workbook.xml", Data: []byte(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + docxNamespaceR + `">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`)},
+		{Name: "xl/_rels/workbook.xml.rels", Data: []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`)},
+		{Name: "xl/styles.xml", Data: []byte(xlsxStyles)},
+	}, entries...)
+
+	zipWriter := &ZipWriter{TargetPath: outputPath}
+	return zipWriter.WriteEntries(entries)
+}
+
+// xlsxSheet returns the worksheet part of a table matrix
+func xlsxSheet(matrix [][]string) string {
+	var sheet strings.Builder
+	sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range matrix {
+		sheet.WriteString(fmt.Sprintf(`<row r="%d">`, r+1))
+		for c, text := range row {
+			if text == "" {
+				continue
+			}
+			ref := xlsxColumn(c) + strconv.Itoa(r+1)
+			switch {
+			case xlsxNumberPattern.MatchString(text):
+				style := ""
+				if strings.Contains(text, ",") {
+					style = ` s="1"`
+				}
+				sheet.WriteString(`<c r="` + ref + `"` + style + `><v>` + strings.ReplaceAll(text, ",", "") + `</v></c>`)
+			case strings.Contains(text, "\n"):
+				sheet.WriteString(`<c r="` + ref + `" s="2" t="inlineStr"><is><t xml:space="preserve">` + xmlText(text) + `</t></is></c>`)
+			default:
+				sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">` + xmlText(text) + `</t></is></c>`)
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	return sheet.String()
+}
+
+// xlsxColumn returns the column letters of a zero-based column index
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
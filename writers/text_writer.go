@@ -4,27 +4,166 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/korean"
+
+	"myconverter/interfaces"
+)
+
+// Encodings of TextWriter
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	// EncodingUTF16LE is written with a byte order mark
+	EncodingUTF16LE = "utf-16le"
+	// EncodingCP949 is the Windows Korean code page, which extends EUC-KR
+	// with all modern Hangul syllables
+	EncodingCP949 = "cp949"
+	// EncodingEUCKR is limited to the KS X 1001 characters
+	EncodingEUCKR = "euc-kr"
+)
+
+// Line endings of TextWriter
+const (
+	EOLLF   = "lf"
+	EOLCRLF = "crlf"
+	// EOLCR is the line ending of classic Mac OS
+	EOLCR = "cr"
+)
+
+// Policies for characters the encoding cannot represent
+const (
+	// UnmappableError fails with the position of the first such character
+	UnmappableError = "error"
+	// UnmappableReplace writes a question mark in its place
+	UnmappableReplace = "replace"
 )
 
 // TextWriter handles writing plain text files.
 type TextWriter struct {
 	// Output directory for text files
 	OutputDir string
+	// Encoding is one of the Encoding constants, UTF-8 when empty
+	Encoding string
+	// EOL is one of the EOL constants, LF when empty
+	EOL string
+	// Unmappable is UnmappableError or UnmappableReplace, error when empty
+	Unmappable string
 }
 
-// NewTextWriter creates a new TextWriter.
+// NewTextWriter creates a new TextWriter writing UTF-8 with LF line endings.
 func NewTextWriter(outputDir string) *TextWriter {
-	return &TextWriter{OutputDir: outputDir}
+	return &TextWriter{OutputDir: outputDir, Encoding: EncodingUTF8, EOL: EOLLF, Unmappable: UnmappableError}
 }
 
-// WriteTexts saves the given text to a file.
+// WriteTexts saves the given text to a file in the writer's encoding and line endings.
 func (w *TextWriter) WriteTexts(outputPath string, text string) error {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	switch strings.ToLower(w.EOL) {
+	case "", EOLLF:
+	case EOLCRLF:
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	case EOLCR:
+		text = strings.ReplaceAll(text, "\n", "\r")
+	default:
+		return fmt.Errorf("unknown line ending: %s", w.EOL)
+	}
+
+	data, err := w.encode(text)
+	if err != nil {
+		return err
+	}
+
 	if err := os.MkdirAll(w.OutputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %v", err)
 	}
 
 	outputPath = filepath.Join(w.OutputDir, filepath.Base(outputPath))
-	return os.WriteFile(outputPath, []byte(text), 0644)
+	return os.WriteFile(outputPath, data, 0644)
+}
+
+// encode converts text to the writer's encoding
+func (w *TextWriter) encode(text string) ([]byte, error) {
+	if w.Unmappable != "" && w.Unmappable != UnmappableError && w.Unmappable != UnmappableReplace {
+		return nil, fmt.Errorf("unknown unmappable character policy: %s", w.Unmappable)
+	}
+
+	encoding := strings.ToLower(w.Encoding)
+	switch encoding {
+	case "", EncodingUTF8:
+		return []byte(text), nil
+	case EncodingUTF8BOM:
+		return append([]byte("\ufeff"), text...), nil
+	case EncodingUTF16LE:
+		data := []byte{0xFF, 0xFE}
+		for _, unit := range utf16.Encode([]rune(text)) {
+			data = append(data, byte(unit), byte(unit>>8))
+		}
+		return data, nil
+	case EncodingCP949, EncodingEUCKR:
+	default:
+		return nil, fmt.Errorf("unknown encoding: %s", w.Encoding)
+	}
+
+	// Characters are encoded one at a time to locate those that cannot be
+	// represented
+	encoder := korean.EUCKR.NewEncoder()
+	data := make([]byte, 0, len(text))
+	line, column := 1, 0
+	var previous rune
+	for _, r := range text {
+		column++
+		if r < 0x80 {
+			data = append(data, byte(r))
+			// Lines end with LF, CRLF or CR
+			if r == '\r' || r == '\n' && previous != '\r' {
+				line, column = line+1, 0
+			} else if r == '\n' {
+				column = 0
+			}
+			previous = r
+			continue
+		}
+		previous = r
+
+		encoded, err := encoder.Bytes([]byte(string(r)))
+		// Unified Hangul code extensions of CP949 use lead or trail bytes
+		// below 0xA1, which EUC-KR does not allow
+		if err == nil && encoding == EncodingEUCKR && len(encoded) == 2 && (encoded[0] < 0xA1 || encoded[1] < 0xA1) {
+			err = fmt.Errorf("not a KS X 1001 character")
+		}
+		if err != nil {
+			if w.Unmappable == UnmappableReplace {
+				data = append(data, '?')
+				continue
+			}
+			return nil, fmt.Errorf("character %q (U+%04X) at line %d, column %d cannot be encoded in %s", r, r, line, column, encoding)
+		}
+		data = append(data, encoded...)
+	}
+	return data, nil
+}
+
+// WriteContent saves the content text followed by its notes. Note
+// references stay in the text as [^1]-style markers and each note is listed
+// at the end as a matching [^1]: definition.
+func (w *TextWriter) WriteContent(outputPath string, content *interfaces.PDFContent) error {
+	var b strings.Builder
+	b.WriteString(content.Text)
+
+	if len(content.Notes) > 0 {
+		b.WriteString("\n")
+		for _, note := range content.Notes {
+			b.WriteString("\n" + interfaces.NoteRef(note.ID) + ": ")
+			// Continuation lines are indented to stay part of the note
+			b.WriteString(strings.ReplaceAll(note.Text, "\n", "\n    "))
+		}
+		b.WriteString("\n")
+	}
+
+	return w.WriteTexts(outputPath, b.String())
 }
 
 // Write writes sample text to the specified file.
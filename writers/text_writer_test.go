@@ -0,0 +1,62 @@
+package writers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTextWriterEncoding(t *testing.T) {
+	tests := []struct {
+		name       string
+		encoding   string
+		eol        string
+		unmappable string
+		text       string
+		want       []byte
+		err        string
+	}{
+		{name: "utf-8", encoding: EncodingUTF8, text: "a한", want: []byte("a\xED\x95\x9C")},
+		{name: "utf-8 with BOM", encoding: EncodingUTF8BOM, text: "a", want: []byte("\xEF\xBB\xBFa")},
+		{name: "utf-16le", encoding: EncodingUTF16LE, text: "a한", want: []byte{0xFF, 0xFE, 'a', 0, 0x5C, 0xD5}},
+		{name: "utf-16le surrogate pair", encoding: EncodingUTF16LE, text: "😀", want: []byte{0xFF, 0xFE, 0x3D, 0xD8, 0x00, 0xDE}},
+		{name: "cp949", encoding: EncodingCP949, text: "a한", want: []byte{'a', 0xC7, 0xD1}},
+		{name: "cp949 extension", encoding: EncodingCP949, text: "똠", want: []byte{0x8C, 0x63}},
+		{name: "euc-kr", encoding: EncodingEUCKR, text: "한", want: []byte{0xC7, 0xD1}},
+		{name: "euc-kr error", encoding: EncodingEUCKR, unmappable: UnmappableError, text: "한\nx똠", err: "line 2, column 2"},
+		{name: "euc-kr replace", encoding: EncodingEUCKR, unmappable: UnmappableReplace, text: "한똠", want: []byte{0xC7, 0xD1, '?'}},
+		{name: "crlf", encoding: EncodingUTF8, eol: EOLCRLF, text: "a\nb\r\nc", want: []byte("a\r\nb\r\nc")},
+		{name: "cr", encoding: EncodingUTF8, eol: EOLCR, text: "a\nb\r\nc", want: []byte("a\rb\rc")},
+		{name: "crlf utf-16le", encoding: EncodingUTF16LE, eol: EOLCRLF, text: "\n", want: []byte{0xFF, 0xFE, '\r', 0, '\n', 0}},
+		{name: "cr error position", encoding: EncodingEUCKR, eol: EOLCR, text: "a\nb\n똠", err: "line 3, column 1"},
+		{name: "unknown line ending", encoding: EncodingUTF8, eol: "nel", text: "a", err: "unknown line ending"},
+		{name: "unknown encoding", encoding: "latin-1", text: "a", err: "unknown encoding"},
+		{name: "unknown policy with utf-8", encoding: EncodingUTF8, unmappable: "ignore", text: "a", err: "unknown unmappable character policy"},
+		{name: "unknown policy with utf-16le", encoding: EncodingUTF16LE, unmappable: "ignore", text: "a", err: "unknown unmappable character policy"},
+		{name: "unknown policy with cp949", encoding: EncodingCP949, unmappable: "ignore", text: "a", err: "unknown unmappable character policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &TextWriter{OutputDir: t.TempDir(), Encoding: tt.encoding, EOL: tt.eol, Unmappable: tt.unmappable}
+			err := w.WriteTexts("out.txt", tt.text)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("WriteTexts() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WriteTexts() error = %v", err)
+			}
+			got, err := os.ReadFile(filepath.Join(w.OutputDir, "out.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("output = % X, want % X", got, tt.want)
+			}
+		})
+	}
+}
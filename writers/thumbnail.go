@@ -0,0 +1,24 @@
+package writers
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// Thumbnail scales an image to fit a size by size box, keeping its aspect
+// ratio, with Catmull-Rom resampling. Transparent areas are drawn on white.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := size, size
+	if bounds.Dx() > bounds.Dy() {
+		height = max(1, bounds.Dy()*size/bounds.Dx())
+	} else {
+		width = max(1, bounds.Dx()*size/bounds.Dy())
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(thumb, thumb.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(thumb, thumb.Bounds(), img, bounds, draw.Over, nil)
+	return thumb
+}
@@ -0,0 +1,48 @@
+package writers
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		size          int
+		want          image.Point
+	}{
+		{name: "landscape", width: 200, height: 100, size: 64, want: image.Pt(64, 32)},
+		{name: "portrait", width: 100, height: 200, size: 64, want: image.Pt(32, 64)},
+		{name: "square", width: 50, height: 50, size: 64, want: image.Pt(64, 64)},
+		{name: "thin", width: 1000, height: 1, size: 64, want: image.Pt(64, 1)},
+	}
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{B: 255, A: 255}), image.Point{}, draw.Src)
+		thumb := Thumbnail(img, tt.size)
+		if size := thumb.Bounds().Size(); size != tt.want {
+			t.Errorf("%s: size = %v, want %v", tt.name, size, tt.want)
+		}
+		if r, g, b, a := thumb.At(0, 0).RGBA(); r != 0 || g != 0 || b != 0xFFFF || a != 0xFFFF {
+			t.Errorf("%s: pixel = %v, want blue", tt.name, thumb.At(0, 0))
+		}
+	}
+
+	// Transparent areas are drawn on white, images not at the origin are
+	// scaled from their bounds
+	img := image.NewNRGBA(image.Rect(10, 10, 110, 60))
+	draw.Draw(img, image.Rect(10, 10, 60, 60), image.NewUniform(color.NRGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	thumb := Thumbnail(img, 20)
+	if size := thumb.Bounds().Size(); size != image.Pt(20, 10) {
+		t.Fatalf("size = %v, want 20x10", size)
+	}
+	if got := color.RGBAModel.Convert(thumb.At(2, 5)); got != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("left pixel = %v, want red", got)
+	}
+	if got := color.RGBAModel.Convert(thumb.At(17, 5)); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("right pixel = %v, want white", got)
+	}
+}
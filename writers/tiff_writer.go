@@ -0,0 +1,346 @@
+package writers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+)
+
+// TIFF tags written to image directories
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffPhotometric     = 262
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffXResolution     = 282
+	tiffYResolution     = 283
+	tiffResolutionUnit  = 296
+	tiffPageNumber      = 297
+)
+
+// TIFF compression schemes
+const (
+	tiffCompressionG4      = 4
+	tiffCompressionDeflate = 8
+)
+
+// tiffEntry is a tag of an image directory with its SHORT, LONG or
+// RATIONAL values
+type tiffEntry struct {
+	tag    uint16
+	kind   uint16
+	values []uint32
+}
+
+// encodeTIFF writes pages as one multi-page TIFF at dpi. Two-color
+// paletted pages are stored as bilevel CCITT Group 4 images, gray pages
+// as 8-bit gray and other pages as RGB, both Deflate compressed.
+func encodeTIFF(w io.Writer, pages []image.Image, dpi float64) error {
+	le := binary.LittleEndian
+	var buf bytes.Buffer
+	buf.Write([]byte{'I', 'I', 42, 0, 0, 0, 0, 0})
+	// Offset of the pointer to the next directory
+	link := 4
+
+	resolution := []uint32{uint32(math.Round(dpi * 100)), 100}
+	for i, page := range pages {
+		bounds := page.Bounds()
+		width, height := bounds.Dx(), bounds.Dy()
+
+		var data []byte
+		var err error
+		bits := []uint32{8}
+		compression, photometric := uint32(tiffCompressionDeflate), uint32(1)
+		if black, ok := bilevelRows(page); ok {
+			data = encodeG4(black, width)
+			bits = []uint32{1}
+			// WhiteIsZero stores black pixels as 1 bits
+			compression, photometric = tiffCompressionG4, 0
+		} else if gray, ok := page.(*image.Gray); ok {
+			data, err = deflateRows(width, height, 1, func(x, y int) []uint8 {
+				return []uint8{gray.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y}
+			})
+		} else {
+			bits = []uint32{8, 8, 8}
+			photometric = 2
+			data, err = deflateRows(width, height, 3, func(x, y int) []uint8 {
+				c := color.NRGBAModel.Convert(page.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+				// Transparent pixels are flattened on white
+				a := uint32(c.A)
+				blend := func(v uint8) uint8 { return uint8((uint32(v)*a + 255*(255-a)) / 255) }
+				return []uint8{blend(c.R), blend(c.G), blend(c.B)}
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to compress page %d: %v", i+1, err)
+		}
+
+		// Strip data, then values too long for their entries, then the directory
+		stripOffset := buf.Len()
+		buf.Write(data)
+		if buf.Len()%2 == 1 {
+			buf.WriteByte(0)
+		}
+
+		entries := []tiffEntry{
+			{tiffImageWidth, 4, []uint32{uint32(width)}},
+			{tiffImageLength, 4, []uint32{uint32(height)}},
+			{tiffBitsPerSample, 3, bits},
+			{tiffCompression, 3, []uint32{compression}},
+			{tiffPhotometric, 3, []uint32{photometric}},
+			{tiffStripOffsets, 4, []uint32{uint32(stripOffset)}},
+			{tiffSamplesPerPixel, 3, []uint32{uint32(len(bits))}},
+			{tiffRowsPerStrip, 4, []uint32{uint32(height)}},
+			{tiffStripByteCounts, 4, []uint32{uint32(len(data))}},
+			{tiffXResolution, 5, resolution},
+			{tiffYResolution, 5, resolution},
+			{tiffResolutionUnit, 3, []uint32{2}},
+			{tiffPageNumber, 3, []uint32{uint32(i), uint32(len(pages))}},
+		}
+
+		external := make(map[int]uint32)
+		for j, entry := range entries {
+			if entrySize(entry) > 4 {
+				external[j] = uint32(buf.Len())
+				writeValues(&buf, entry)
+			}
+		}
+
+		le.PutUint32(buf.Bytes()[link:], uint32(buf.Len()))
+		binary.Write(&buf, le, uint16(len(entries)))
+		for j, entry := range entries {
+			binary.Write(&buf, le, entry.tag)
+			binary.Write(&buf, le, entry.kind)
+			count := len(entry.values)
+			if entry.kind == 5 {
+				count /= 2
+			}
+			binary.Write(&buf, le, uint32(count))
+			if offset, ok := external[j]; ok {
+				binary.Write(&buf, le, offset)
+				continue
+			}
+			var value bytes.Buffer
+			writeValues(&value, entry)
+			value.Write(make([]byte, 4-value.Len()))
+			buf.Write(value.Bytes())
+		}
+		link = buf.Len()
+		buf.Write([]byte{0, 0, 0, 0})
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// entrySize returns the size in bytes of the values of a directory entry
+func entrySize(entry tiffEntry) int {
+	if entry.kind == 3 {
+		return 2 * len(entry.values)
+	}
+	return 4 * len(entry.values)
+}
+
+// writeValues writes the values of a directory entry in little endian order
+func writeValues(buf *bytes.Buffer, entry tiffEntry) {
+	for _, v := range entry.values {
+		if entry.kind == 3 {
+			binary.Write(buf, binary.LittleEndian, uint16(v))
+		} else {
+			binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+}
+
+// deflateRows compresses the samples of each pixel, row by row
+func deflateRows(width, height, samples int, pixel func(x, y int) []uint8) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	row := make([]byte, 0, width*samples)
+	for y := 0; y < height; y++ {
+		row = row[:0]
+		for x := 0; x < width; x++ {
+			row = append(row, pixel(x, y)...)
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// bilevelRows returns the black pixels of each row of a two-color paletted
+// image, reporting false for other images
+func bilevelRows(img image.Image) ([][]bool, bool) {
+	paletted, ok := img.(*image.Paletted)
+	if !ok || len(paletted.Palette) > 2 {
+		return nil, false
+	}
+	black := make([]bool, len(paletted.Palette))
+	for i, c := range paletted.Palette {
+		black[i] = color.GrayModel.Convert(c).(color.Gray).Y < 128
+	}
+
+	bounds := paletted.Bounds()
+	rows := make([][]bool, bounds.Dy())
+	for y := range rows {
+		rows[y] = make([]bool, bounds.Dx())
+		for x := range rows[y] {
+			rows[y][x] = black[paletted.ColorIndexAt(bounds.Min.X+x, bounds.Min.Y+y)]
+		}
+	}
+	return rows, true
+}
+
+// g4Writer packs CCITT codes into bytes, most significant bit first
+type g4Writer struct {
+	data  []byte
+	bits  uint8
+	nbits int
+}
+
+// write appends a code given as a string of 0 and 1 characters
+func (g *g4Writer) write(code string) {
+	for i := 0; i < len(code); i++ {
+		g.bits = g.bits<<1 | (code[i] - '0')
+		g.nbits++
+		if g.nbits == 8 {
+			g.data = append(g.data, g.bits)
+			g.bits, g.nbits = 0, 0
+		}
+	}
+}
+
+// writeRun appends the make-up and terminating codes of a run of pixels
+func (g *g4Writer) writeRun(length int, black bool) {
+	term, makeup := whiteTermCodes[:], whiteMakeupCodes[:]
+	if black {
+		term, makeup = blackTermCodes[:], blackMakeupCodes[:]
+	}
+	for length >= 2560 {
+		g.write(makeup[len(makeup)-1])
+		length -= 2560
+	}
+	if length >= 64 {
+		g.write(makeup[length/64-1])
+		length %= 64
+	}
+	g.write(term[length])
+}
+
+// encodeG4 encodes rows of black pixels with two-dimensional CCITT Group 4
+// (T.6) coding, each row coded against the one above it
+func encodeG4(rows [][]bool, width int) []byte {
+	g := &g4Writer{}
+	ref := make([]bool, width)
+	for _, line := range rows {
+		a0, black := -1, false
+		for a0 < width {
+			a1 := nextChange(line, a0, width)
+			b1 := nextChange(ref, a0, width)
+			for b1 < width && ref[b1] == black {
+				b1 = nextChange(ref, b1, width)
+			}
+			b2 := nextChange(ref, b1, width)
+
+			switch {
+			case b2 < a1:
+				// Pass mode
+				g.write("0001")
+				a0 = b2
+			case a1-b1 >= -3 && a1-b1 <= 3:
+				// Vertical mode
+				g.write([]string{"0000010", "000010", "010", "1", "011", "000011", "0000011"}[a1-b1+3])
+				a0 = a1
+				black = !black
+			default:
+				// Horizontal mode
+				a2 := nextChange(line, a1, width)
+				g.write("001")
+				g.writeRun(a1-max(a0, 0), black)
+				g.writeRun(a2-a1, !black)
+				a0 = a2
+			}
+		}
+		ref = line
+	}
+
+	// End of facsimile block, padded to a byte
+	g.write("000000000001000000000001")
+	if g.nbits > 0 {
+		g.write("0000000"[:8-g.nbits])
+	}
+	return g.data
+}
+
+// nextChange returns the first position after a0 whose pixel differs from
+// the one before it, the pixel before a row being white. It returns width
+// when the row has no further change.
+func nextChange(line []bool, a0, width int) int {
+	for p := max(a0+1, 0); p < width; p++ {
+		previous := p > 0 && line[p-1]
+		if line[p] != previous {
+			return p
+		}
+	}
+	return width
+}
+
+// whiteTermCodes are the codes of white runs of 0 to 63 pixels
+var whiteTermCodes = [64]string{
+	"00110101", "000111", "0111", "1000", "1011", "1100", "1110", "1111",
+	"10011", "10100", "00111", "01000", "001000", "000011", "110100", "110101",
+	"101010", "101011", "0100111", "0001100", "0001000", "0010111", "0000011", "0000100",
+	"0101000", "0101011", "0010011", "0100100", "0011000", "00000010", "00000011", "00011010",
+	"00011011", "00010010", "00010011", "00010100", "00010101", "00010110", "00010111", "00101000",
+	"00101001", "00101010", "00101011", "00101100", "00101101", "00000100", "00000101", "00001010",
+	"00001011", "01010010", "01010011", "01010100", "01010101", "00100100", "00100101", "01011000",
+	"01011001", "01011010", "01011011", "01001010", "01001011", "00110010", "00110011", "00110100",
+}
+
+// whiteMakeupCodes are the codes of white runs of 64 to 2560 pixels in steps of 64
+var whiteMakeupCodes = [40]string{
+	"11011", "10010", "010111", "0110111", "00110110", "00110111",
+	"01100100", "01100101", "01101000", "01100111", "011001100", "011001101",
+	"011010010", "011010011", "011010100", "011010101", "011010110", "011010111",
+	"011011000", "011011001", "011011010", "011011011", "010011000", "010011001",
+	"010011010", "011000", "010011011", "00000001000", "00000001100", "00000001101",
+	"000000010010", "000000010011", "000000010100", "000000010101", "000000010110", "000000010111",
+	"000000011100", "000000011101", "000000011110", "000000011111",
+}
+
+// blackTermCodes are the codes of black runs of 0 to 63 pixels
+var blackTermCodes = [64]string{
+	"0000110111", "010", "11", "10", "011", "0011", "0010", "00011",
+	"000101", "000100", "0000100", "0000101", "0000111", "00000100", "00000111", "000011000",
+	"0000010111", "0000011000", "0000001000", "00001100111", "00001101000", "00001101100", "00000110111", "00000101000",
+	"00000010111", "00000011000", "000011001010", "000011001011", "000011001100", "000011001101", "000001101000", "000001101001",
+	"000001101010", "000001101011", "000011010010", "000011010011", "000011010100", "000011010101", "000011010110", "000011010111",
+	"000001101100", "000001101101", "000011011010", "000011011011", "000001010100", "000001010101", "000001010110", "000001010111",
+	"000001100100", "000001100101", "000001010010", "000001010011", "000000100100", "000000110111", "000000111000", "000000100111",
+	"000000101000", "000001011000", "000001011001", "000000101011", "000000101100", "000001011010", "000001100110", "000001100111",
+}
+
+// blackMakeupCodes are the codes of black runs of 64 to 2560 pixels in steps of 64
+var blackMakeupCodes = [40]string{
+	"0000001111", "000011001000", "000011001001", "000001011011", "000000110011", "000000110100",
+	"000000110101", "0000001101100", "0000001101101", "0000001001010", "0000001001011", "0000001001100",
+	"0000001001101", "0000001110010", "0000001110011", "0000001110100", "0000001110101", "0000001110110",
+	"0000001110111", "0000001010010", "0000001010011", "0000001010100", "0000001010101", "0000001011010",
+	"0000001011011", "0000001100100", "0000001100101", "00000001000", "00000001100", "00000001101",
+	"000000010010", "000000010011", "000000010100", "000000010101", "000000010110", "000000010111",
+	"000000011100", "000000011101", "000000011110", "000000011111",
+}
@@ -0,0 +1,105 @@
+package writers
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"io"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/ccitt"
+	"golang.org/x/image/tiff"
+)
+
+func TestEncodeG4(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name          string
+		width, height int
+		black         func(x, y int) bool
+	}{
+		{name: "white", width: 17, height: 3, black: func(x, y int) bool { return false }},
+		{name: "black", width: 17, height: 3, black: func(x, y int) bool { return true }},
+		{name: "one column", width: 1, height: 5, black: func(x, y int) bool { return y%2 == 0 }},
+		{name: "checkerboard", width: 40, height: 12, black: func(x, y int) bool { return (x/3+y/2)%2 == 0 }},
+		{name: "diagonal", width: 64, height: 64, black: func(x, y int) bool { return x == y || x == 63-y }},
+		{name: "long runs", width: 6000, height: 4, black: func(x, y int) bool { return x > 2700+y*100 && x < 5900 }},
+		{name: "noise", width: 123, height: 40, black: func(x, y int) bool { return random.Intn(3) == 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := make([][]bool, tt.height)
+			for y := range rows {
+				rows[y] = make([]bool, tt.width)
+				for x := range rows[y] {
+					rows[y][x] = tt.black(x, y)
+				}
+			}
+
+			encoded := encodeG4(rows, tt.width)
+			decoded, err := io.ReadAll(ccitt.NewReader(bytes.NewReader(encoded), ccitt.MSB, ccitt.Group4, tt.width, tt.height, &ccitt.Options{Invert: true}))
+			if err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			stride := (tt.width + 7) / 8
+			if len(decoded) != stride*tt.height {
+				t.Fatalf("decoded %d bytes, want %d", len(decoded), stride*tt.height)
+			}
+			for y, row := range rows {
+				for x, black := range row {
+					if got := decoded[y*stride+x/8]&(0x80>>(x%8)) != 0; got != black {
+						t.Fatalf("pixel %d,%d black = %v, want %v", x, y, got, black)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeTIFF(t *testing.T) {
+	bounds := image.Rect(0, 0, 9, 7)
+	bilevel := image.NewPaletted(bounds, color.Palette{color.White, color.Black})
+	gray := image.NewGray(bounds)
+	rgb := image.NewRGBA(bounds)
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			bilevel.SetColorIndex(x, y, uint8((x+y)%2))
+			gray.SetGray(x, y, color.Gray{Y: uint8(x * 25)})
+			rgb.Set(x, y, color.RGBA{R: uint8(x * 25), G: uint8(y * 30), B: 200, A: 255})
+		}
+	}
+
+	tests := []struct {
+		name string
+		page image.Image
+	}{
+		{name: "bilevel", page: bilevel},
+		{name: "gray", page: gray},
+		{name: "rgb", page: rgb},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encodeTIFF(&buf, []image.Image{tt.page}, 150); err != nil {
+				t.Fatalf("encodeTIFF() error = %v", err)
+			}
+			decoded, err := tiff.Decode(&buf)
+			if err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			if decoded.Bounds() != bounds {
+				t.Fatalf("decoded bounds = %v, want %v", decoded.Bounds(), bounds)
+			}
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					r1, g1, b1, _ := tt.page.At(x, y).RGBA()
+					r2, g2, b2, _ := decoded.At(x, y).RGBA()
+					if r1>>8 != r2>>8 || g1>>8 != g2>>8 || b1>>8 != b2>>8 {
+						t.Fatalf("pixel %d,%d = %v, want %v", x, y, decoded.At(x, y), tt.page.At(x, y))
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package writers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/math/fixed"
+)
+
+// Watermark positions on the page
+const (
+	WatermarkCenter      = "center"
+	WatermarkTop         = "top"
+	WatermarkBottom      = "bottom"
+	WatermarkLeft        = "left"
+	WatermarkRight       = "right"
+	WatermarkTopLeft     = "top-left"
+	WatermarkTopRight    = "top-right"
+	WatermarkBottomLeft  = "bottom-left"
+	WatermarkBottomRight = "bottom-right"
+)
+
+// Defaults of Watermark
+const (
+	DefaultWatermarkOpacity  = 0.3
+	DefaultWatermarkRotation = 45
+	DefaultWatermarkSize     = 60
+	DefaultWatermarkScale    = 0.4
+)
+
+// watermarkMargin is the distance in points between the page edges and a
+// watermark placed against them
+const watermarkMargin = 36
+
+// Watermark is text or an image drawn over every page, such as a
+// "대외비" or "CONFIDENTIAL" mark
+type Watermark struct {
+	// Text of the mark, drawn over the image when both are set
+	Text string
+	// Image is PNG, JPEG, GIF or BMP data of the mark
+	Image []byte
+	// Opacity from 0 to 1, DefaultWatermarkOpacity when 0
+	Opacity float64
+	// Rotation in degrees counterclockwise around the mark's center
+	Rotation float64
+	// Position is one of the Watermark position constants, center when empty
+	Position string
+	// Size of the text in points, DefaultWatermarkSize when 0
+	Size float64
+	// Color of the text, gray when nil
+	Color color.Color
+	// Scale is the width of the image as a fraction of the page width,
+	// DefaultWatermarkScale when 0
+	Scale float64
+}
+
+// NewWatermark creates a text watermark with default settings
+func NewWatermark(text string) *Watermark {
+	return &Watermark{
+		Text:     text,
+		Opacity:  DefaultWatermarkOpacity,
+		Rotation: DefaultWatermarkRotation,
+		Position: WatermarkCenter,
+		Size:     DefaultWatermarkSize,
+	}
+}
+
+// IsWatermarkPosition reports whether a position is one of the Watermark
+// position constants
+func IsWatermarkPosition(position string) bool {
+	switch position {
+	case WatermarkCenter, WatermarkTop, WatermarkBottom, WatermarkLeft, WatermarkRight,
+		WatermarkTopLeft, WatermarkTopRight, WatermarkBottomLeft, WatermarkBottomRight:
+		return true
+	}
+	return false
+}
+
+// opacity returns the opacity of the mark
+func (wm *Watermark) opacity() float64 {
+	if wm.Opacity <= 0 {
+		return DefaultWatermarkOpacity
+	}
+	return math.Min(wm.Opacity, 1)
+}
+
+// size returns the text size in points
+func (wm *Watermark) size() float64 {
+	if wm.Size <= 0 {
+		return DefaultWatermarkSize
+	}
+	return wm.Size
+}
+
+// color returns the text color
+func (wm *Watermark) color() color.Color {
+	if wm.Color == nil {
+		return color.Gray{Y: 0x80}
+	}
+	return wm.Color
+}
+
+// imageWidth returns the width of the image mark on a page of pageWidth
+func (wm *Watermark) imageWidth(pageWidth float64) float64 {
+	scale := wm.Scale
+	if scale <= 0 {
+		scale = DefaultWatermarkScale
+	}
+	return pageWidth * scale
+}
+
+// center returns the center of a mark of width by height on a page. The
+// mark is kept a margin away from the edges it is placed against,
+// measured by the bounds of the mark after rotation.
+func (wm *Watermark) center(pageWidth, pageHeight, width, height, margin float64) (float64, float64, error) {
+	position := strings.ToLower(wm.Position)
+	if position == "" {
+		position = WatermarkCenter
+	}
+	if !IsWatermarkPosition(position) {
+		return 0, 0, fmt.Errorf("unknown watermark position: %s", wm.Position)
+	}
+
+	angle := wm.Rotation * math.Pi / 180
+	cos, sin := math.Abs(math.Cos(angle)), math.Abs(math.Sin(angle))
+	boundsWidth := width*cos + height*sin
+	boundsHeight := width*sin + height*cos
+
+	x, y := pageWidth/2, pageHeight/2
+	if strings.Contains(position, WatermarkLeft) {
+		x = margin + boundsWidth/2
+	} else if strings.Contains(position, WatermarkRight) {
+		x = pageWidth - margin - boundsWidth/2
+	}
+	if strings.Contains(position, WatermarkTop) {
+		y = margin + boundsHeight/2
+	} else if strings.Contains(position, WatermarkBottom) {
+		y = pageHeight - margin - boundsHeight/2
+	}
+	return x, y, nil
+}
+
+// drawPDF draws the mark on the current page of a PDF of a page size. Text
+// is drawn in pdfFont, which must be added to the PDF; the font
+// size and text color are left changed.
+func (wm *Watermark) drawPDF(pdf *gopdf.GoPdf, page gopdf.Rect) error {
+	transparency := &gopdf.Transparency{Alpha: wm.opacity(), BlendModeType: gopdf.NormalBlendMode}
+
+	if len(wm.Image) > 0 {
+		data, width, height, err := pdfImageData(wm.Image, false)
+		if err != nil {
+			return fmt.Errorf("failed to embed watermark image: %v", err)
+		}
+		box := gopdf.Rect{W: wm.imageWidth(page.W)}
+		box.H = box.W * height / width
+		x, y, err := wm.center(page.W, page.H, box.W, box.H, watermarkMargin)
+		if err != nil {
+			return err
+		}
+		holder, err := gopdf.ImageHolderByBytes(data)
+		if err != nil {
+			return fmt.Errorf("failed to embed watermark image: %v", err)
+		}
+		pdf.Rotate(wm.Rotation, x, y)
+		err = pdf.ImageByHolderWithOptions(holder, gopdf.ImageOptions{
+			X:            x - box.W/2,
+			Y:            y - box.H/2,
+			Rect:         &box,
+			Transparency: transparency,
+		})
+		pdf.RotateReset()
+		if err != nil {
+			return fmt.Errorf("failed to draw watermark image: %v", err)
+		}
+	}
+
+	if wm.Text != "" {
+		if err := pdf.SetFontSize(wm.size()); err != nil {
+			return fmt.Errorf("failed to set font: %v", err)
+		}
+		width, err := pdf.MeasureTextWidth(wm.Text)
+		if err != nil {
+			return fmt.Errorf("failed to measure watermark text: %v", err)
+		}
+		height := wm.size()
+		x, y, err := wm.center(page.W, page.H, width, height, watermarkMargin)
+		if err != nil {
+			return err
+		}
+		r, g, b, _ := color.NRGBAModel.Convert(wm.color()).RGBA()
+		pdf.SetTextColor(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+		pdf.Rotate(wm.Rotation, x, y)
+		pdf.SetX(x - width/2)
+		pdf.SetY(y - height/2)
+		err = pdf.CellWithOption(&gopdf.Rect{W: width, H: height}, wm.Text, gopdf.CellOption{
+			Align:        gopdf.Left | gopdf.Middle,
+			Transparency: transparency,
+		})
+		pdf.RotateReset()
+		if err != nil {
+			return fmt.Errorf("failed to draw watermark text: %v", err)
+		}
+	}
+	return nil
+}
+
+// drawImage draws the mark on a page image at dpi, with text in font
+func (wm *Watermark) drawImage(dst draw.Image, f *truetype.Font, dpi float64) error {
+	bounds := dst.Bounds()
+	px := dpi / 72
+
+	if len(wm.Image) > 0 {
+		src, _, err := image.Decode(bytes.NewReader(wm.Image))
+		if err != nil {
+			return fmt.Errorf("failed to decode watermark image: %v", err)
+		}
+		width := int(math.Round(wm.imageWidth(float64(bounds.Dx()))))
+		height := int(math.Round(float64(width) * float64(src.Bounds().Dy()) / float64(src.Bounds().Dx())))
+		mark := image.NewNRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+		draw.CatmullRom.Scale(mark, mark.Bounds(), src, src.Bounds(), draw.Src, nil)
+		if err := wm.composite(dst, mark, px); err != nil {
+			return err
+		}
+	}
+
+	if wm.Text != "" {
+		face := truetype.NewFace(f, &truetype.Options{Size: wm.size(), DPI: dpi, Hinting: font.HintingFull})
+		defer face.Close()
+		metrics := face.Metrics()
+		drawer := &font.Drawer{Face: face, Src: image.NewUniform(wm.color())}
+		width := drawer.MeasureString(wm.Text).Ceil()
+		mark := image.NewNRGBA(image.Rect(0, 0, max(width, 1), (metrics.Ascent + metrics.Descent).Ceil()))
+		drawer.Dst = mark
+		drawer.Dot = fixed.Point26_6{Y: metrics.Ascent}
+		drawer.DrawString(wm.Text)
+		if err := wm.composite(dst, mark, px); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// composite draws an upright mark image turned by the rotation at its
+// position with the opacity, px pixels to the point
+func (wm *Watermark) composite(dst draw.Image, mark image.Image, px float64) error {
+	bounds := dst.Bounds()
+	size := mark.Bounds().Size()
+	x, y, err := wm.center(float64(bounds.Dx()), float64(bounds.Dy()), float64(size.X), float64(size.Y), watermarkMargin*px)
+	if err != nil {
+		return err
+	}
+	x += float64(bounds.Min.X)
+	y += float64(bounds.Min.Y)
+
+	// Turn counterclockwise around the mark center, with y pointing down
+	angle := wm.Rotation * math.Pi / 180
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	cx, cy := float64(size.X)/2, float64(size.Y)/2
+	transform := f64.Aff3{
+		cos, sin, x - cos*cx - sin*cy,
+		-sin, cos, y + sin*cx - cos*cy,
+	}
+	opacity := image.NewUniform(color.Alpha16{A: uint16(wm.opacity() * 0xffff)})
+	draw.CatmullRom.Transform(dst, transform, mark, mark.Bounds(), draw.Over, &draw.Options{SrcMask: opacity})
+	return nil
+}
@@ -0,0 +1,135 @@
+package writers
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWatermarkCenter(t *testing.T) {
+	tests := []struct {
+		position string
+		rotation float64
+		x, y     float64
+		wantErr  bool
+	}{
+		{position: "", x: 300, y: 200},
+		{position: WatermarkCenter, x: 300, y: 200},
+		{position: WatermarkTopLeft, x: 60, y: 35},
+		{position: WatermarkBottomRight, x: 540, y: 365},
+		{position: "Top", x: 300, y: 35},
+		{position: WatermarkLeft, rotation: 90, x: 35, y: 200},
+		{position: "middle", wantErr: true},
+	}
+	for _, tt := range tests {
+		wm := &Watermark{Position: tt.position, Rotation: tt.rotation}
+		x, y, err := wm.center(600, 400, 100, 50, 10)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: center() error = %v, wantErr %v", tt.position, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (math.Abs(x-tt.x) > 1e-9 || math.Abs(y-tt.y) > 1e-9) {
+			t.Errorf("%q: center() = %v, %v, want %v, %v", tt.position, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestWatermarkDrawImage(t *testing.T) {
+	// A red mark twice as wide as it is high, drawn half the page wide
+	mark := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	draw.Draw(mark, mark.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, mark); err != nil {
+		t.Fatal(err)
+	}
+
+	red := color.RGBA{R: 255, A: 255}
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	tests := []struct {
+		name   string
+		wm     Watermark
+		pixels map[image.Point]color.RGBA
+	}{
+		{
+			name:   "center",
+			wm:     Watermark{Opacity: 1, Scale: 0.5},
+			pixels: map[image.Point]color.RGBA{{50, 50}: red, {30, 45}: red, {50, 30}: white, {5, 5}: white},
+		},
+		{
+			name:   "rotated",
+			wm:     Watermark{Opacity: 1, Scale: 0.5, Rotation: 90},
+			pixels: map[image.Point]color.RGBA{{50, 50}: red, {50, 30}: red, {30, 50}: white},
+		},
+		{
+			name:   "top left",
+			wm:     Watermark{Opacity: 1, Scale: 0.5, Position: WatermarkTopLeft},
+			pixels: map[image.Point]color.RGBA{{40, 40}: red, {80, 45}: red, {30, 30}: white, {60, 70}: white},
+		},
+		{
+			name:   "half opacity",
+			wm:     Watermark{Opacity: 0.5, Scale: 0.5},
+			pixels: map[image.Point]color.RGBA{{50, 50}: {R: 255, G: 128, B: 128, A: 255}},
+		},
+	}
+	for _, tt := range tests {
+		tt.wm.Image = buf.Bytes()
+		page := image.NewRGBA(image.Rect(0, 0, 100, 100))
+		draw.Draw(page, page.Bounds(), image.White, image.Point{}, draw.Src)
+		if err := tt.wm.drawImage(page, nil, 72); err != nil {
+			t.Fatalf("%s: drawImage() error = %v", tt.name, err)
+		}
+		for point, want := range tt.pixels {
+			got := page.RGBAAt(point.X, point.Y)
+			if diff(got.R, want.R) > 2 || diff(got.G, want.G) > 2 || diff(got.B, want.B) > 2 {
+				t.Errorf("%s: pixel %v = %v, want %v", tt.name, point, got, want)
+			}
+		}
+	}
+}
+
+// diff returns the difference of two color components
+func diff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func TestStamp(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestPDF(t, dir, 3)
+	original := pageContent(t, input, "")
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	writer := NewPDFWriter(out)
+	if err := writer.Stamp(input, "stamped.pdf"); err == nil {
+		t.Errorf("Stamp() without a watermark succeeded, want an error")
+	}
+	writer.Watermark = &Watermark{Image: buf.Bytes()}
+	if err := writer.Stamp(input, "stamped.pdf"); err != nil {
+		t.Fatalf("Stamp() error = %v", err)
+	}
+
+	// Pages keep their size and content with the mark drawn after it
+	path := filepath.Join(out, "stamped.pdf")
+	if got, want := pageSizes(t, path), pageSizes(t, input); !reflect.DeepEqual(got, want) {
+		t.Errorf("page sizes = %v, want %v", got, want)
+	}
+	content := pageContent(t, path, "")
+	index := strings.Index(content, strings.TrimSpace(original))
+	mark := strings.Index(content, "/"+watermarkXObject+" Do")
+	if index < 0 || mark < index {
+		t.Errorf("stamped content = %q, want the original content followed by the watermark", content)
+	}
+}
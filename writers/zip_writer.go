@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // ZipWriter handles ZIP file creation
@@ -15,6 +16,14 @@ type ZipWriter struct {
 	Sources    []string
 }
 
+// ZipEntry is a file written to a ZIP archive from memory
+type ZipEntry struct {
+	Name string
+	Data []byte
+	// Store writes the entry without compression
+	Store bool
+}
+
 // Write implements FileWriter interface
 func (w *ZipWriter) Write(filePath string) error {
 	w.TargetPath = filePath
@@ -83,6 +92,46 @@ func (w *ZipWriter) CreateZip() error {
 	return nil
 }
 
+// WriteEntries creates a ZIP file at TargetPath holding the given entries in order
+func (w *ZipWriter) WriteEntries(entries []ZipEntry) error {
+	zipFile, err := os.Create(w.TargetPath)
+	if err != nil {
+		return fmt.Errorf("failed to create zip file: %v", err)
+	}
+	defer zipFile.Close()
+
+	// Entries are dated now in MS-DOS time only, since setting Modified
+	// adds an extra field that ODF and EPUB forbid on the mimetype entry
+	date, clock := msDosTime(time.Now())
+	zipWriter := zip.NewWriter(zipFile)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.Name, Method: zip.Deflate, ModifiedDate: date, ModifiedTime: clock}
+		if entry.Store {
+			header.Method = zip.Store
+		}
+
+		writer, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to zip: %v", entry.Name, err)
+		}
+		if _, err := writer.Write(entry.Data); err != nil {
+			return fmt.Errorf("failed to write %s to zip: %v", entry.Name, err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip file: %v", err)
+	}
+	return nil
+}
+
+// msDosTime returns the MS-DOS date and time of t, counted to two seconds
+func msDosTime(t time.Time) (uint16, uint16) {
+	date := uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	clock := uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, clock
+}
+
 // addFileToZip adds a single file to the zip archive
 func (w *ZipWriter) addFileToZip(zipWriter *zip.Writer, sourcePath, zipPath string) error {
 	// Open the source file
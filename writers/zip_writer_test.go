@@ -0,0 +1,57 @@
+package writers
+
+import (
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.zip")
+	writer := &ZipWriter{TargetPath: path}
+	entries := []ZipEntry{
+		{Name: "mimetype", Data: []byte("application/epub+zip"), Store: true},
+		{Name: "a/b.xml", Data: []byte("<b/>")},
+	}
+	if err := writer.WriteEntries(entries); err != nil {
+		t.Fatalf("WriteEntries() error = %v", err)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	if len(archive.File) != len(entries) {
+		t.Fatalf("%d entries, want %d", len(archive.File), len(entries))
+	}
+	// Times are read back as written, in local time marked UTC
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.UTC)
+	for i, file := range archive.File {
+		entry := entries[i]
+		if file.Name != entry.Name {
+			t.Errorf("entry %d = %s, want %s", i, file.Name, entry.Name)
+		}
+		if method := file.Method == zip.Store; method != entry.Store {
+			t.Errorf("%s stored = %v, want %v", file.Name, method, entry.Store)
+		}
+		if len(file.Extra) > 0 {
+			t.Errorf("%s has an extra field", file.Name)
+		}
+		if age := now.Sub(file.Modified); age < 0 || age > time.Minute {
+			t.Errorf("%s modified %v, want about %v", file.Name, file.Modified, now)
+		}
+		f, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(f)
+		f.Close()
+		if string(data) != string(entry.Data) {
+			t.Errorf("%s = %q, want %q", file.Name, data, entry.Data)
+		}
+	}
+}